@@ -3,6 +3,7 @@ package command
 import (
 	"bytes"
 	"fmt"
+	"log"
 	"os"
 	"sort"
 	"strings"
@@ -28,7 +29,9 @@ type ApplyCommand struct {
 }
 
 func (c *ApplyCommand) Run(args []string) int {
-	var destroyForce, refresh bool
+	var autoApprove, destroyForce, refresh, jsonOutput, stats bool
+	var display FormatPlanOpts
+	var policyCmd string
 	args = c.Meta.process(args, true)
 
 	cmdName := "apply"
@@ -37,19 +40,41 @@ func (c *ApplyCommand) Run(args []string) int {
 	}
 
 	cmdFlags := c.Meta.flagSet(cmdName)
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
+	cmdFlags.BoolVar(&c.Meta.compactOutput, "compact-output", false, "compact-output")
+	cmdFlags.BoolVar(&c.Meta.progressBar, "progress", false, "progress")
 	if c.Destroy {
 		cmdFlags.BoolVar(&destroyForce, "force", false, "force")
 	}
 	cmdFlags.BoolVar(&refresh, "refresh", true, "refresh")
+	c.addModuleDepthFlag(cmdFlags, &display.ModuleDepth)
+	c.addPlanDisplayFlags(cmdFlags, &display)
 	cmdFlags.IntVar(
 		&c.Meta.parallelism, "parallelism", DefaultParallelism, "parallelism")
-	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
 	cmdFlags.StringVar(&c.Meta.stateOutPath, "state-out", "", "path")
 	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.BoolVar(&stats, "stats", false, "stats")
+	cmdFlags.StringVar(&policyCmd, "policy-command", "", "command")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
+	if display.Compact && display.Full {
+		c.Ui.Error("The -compact and -full flags can't be used together.")
+		return 1
+	}
+
+	if jsonOutput {
+		c.Meta.enableJSONUi()
+
+		// The destroy confirmation can't be answered with JSON output
+		if c.Destroy && !destroyForce && !autoApprove {
+			c.Ui.Error("The -json flag requires -auto-approve when destroying.")
+			return 1
+		}
+	}
 
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -73,8 +98,10 @@ func (c *ApplyCommand) Run(args []string) int {
 
 	// Prepare the extra hooks to count resources
 	countHook := new(CountHook)
-	stateHook := new(StateHook)
-	c.Meta.extraHooks = []terraform.Hook{countHook, stateHook}
+	statsHook := new(StatsHook)
+	stateHook := &StateHook{PersistInterval: DefaultStatePersistInterval}
+	c.Meta.extraHooks = []terraform.Hook{countHook, statsHook, stateHook}
+	c.Meta.progress = new(applyProgress)
 
 	if !c.Destroy && maybeInit {
 		// Do a detect to determine if we need to do an init + apply.
@@ -96,7 +123,7 @@ func (c *ApplyCommand) Run(args []string) int {
 		}
 	}
 
-	terraform.SetDebugInfo(DefaultDataDir)
+	terraform.SetDebugInfo(c.DataDir())
 
 	// Check for the legacy graph
 	if experiment.Enabled(experiment.X_legacyGraph) {
@@ -113,21 +140,38 @@ func (c *ApplyCommand) Run(args []string) int {
 
 	// Build the context based on the arguments given
 	ctx, planned, err := c.Context(contextOpts{
-		Destroy:     c.Destroy,
-		Path:        configPath,
-		StatePath:   c.Meta.statePath,
-		Parallelism: c.Meta.parallelism,
+		Destroy:         c.Destroy,
+		Path:            configPath,
+		StatePath:       c.Meta.statePath,
+		Parallelism:     c.Meta.parallelism,
+		CheckPlanRemote: true,
 	})
 	if err != nil {
 		c.Ui.Error(err.Error())
 		return 1
 	}
-	if c.Destroy && planned {
-		c.Ui.Error(fmt.Sprintf(
-			"Destroy can't be called with a plan file."))
-		return 1
+
+	// A plan file records whether it's a destroy plan, so that it's
+	// applied as one whichever command is used. The destroy command only
+	// accepts destroy plans.
+	destroy := c.Destroy
+	var plan *terraform.Plan
+	if planned {
+		plan, err = readPlanFile(configPath)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		if c.Destroy && !plan.Destroy {
+			c.Ui.Error(
+				"Destroy can only be called with a destroy plan file. Create one\n" +
+					"with \"terraform plan -destroy -out=FILE\".")
+			return 1
+		}
+
+		destroy = plan.Destroy
 	}
-	if !destroyForce && c.Destroy {
+	if !destroyForce && !autoApprove && c.Destroy {
 		// Default destroy message
 		desc := "Terraform will delete all your managed infrastructure.\n" +
 			"There is no undo. Only 'yes' will be accepted to confirm."
@@ -159,6 +203,18 @@ func (c *ApplyCommand) Run(args []string) int {
 			return 1
 		}
 	}
+	if !c.confirmRemoteState(autoApprove || destroyForce) {
+		return 1
+	}
+
+	// A plan made by apply hasn't been reviewed, so it's shown and must be
+	// approved. A plan file was reviewed when it was created, and destroy
+	// has been confirmed already. Nothing is asked in tests.
+	reviewPlan := !planned && !c.Destroy && !autoApprove && !test
+	if reviewPlan && c.InputMode() == 0 {
+		c.Ui.Error(strings.TrimSpace(errApplyApproveInput))
+		return 1
+	}
 	if !planned {
 		if err := ctx.Input(c.InputMode()); err != nil {
 			c.Ui.Error(fmt.Sprintf("Error configuring: %s", err))
@@ -175,6 +231,16 @@ func (c *ApplyCommand) Run(args []string) int {
 		return 1
 	}
 
+	// The state before the refresh, for the statistics
+	var stateBefore *terraform.State
+	if c.state != nil {
+		stateBefore = c.state.State()
+	}
+
+	if !planned && !c.checkTargets(ctx.Module(), stateBefore) {
+		return 1
+	}
+
 	// Plan if we haven't already
 	if !planned {
 		if refresh {
@@ -184,7 +250,8 @@ func (c *ApplyCommand) Run(args []string) int {
 			}
 		}
 
-		if _, err := ctx.Plan(); err != nil {
+		plan, err = ctx.Plan()
+		if err != nil {
 			c.Ui.Error(fmt.Sprintf(
 				"Error creating plan: %s", err))
 			return 1
@@ -197,6 +264,26 @@ func (c *ApplyCommand) Run(args []string) int {
 		}
 	}
 
+	// The plan must be approved by the policy command, if there is one
+	if cmd := policyCommand(policyCmd, ctx.Module()); cmd != "" {
+		if plan == nil {
+			plan, err = readPlanFile(configPath)
+			if err != nil {
+				c.Ui.Error(err.Error())
+				return 1
+			}
+		}
+
+		if err := c.checkPolicy(cmd, plan); err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+	}
+
+	if reviewPlan && !c.approvePlan(plan, &display) {
+		return 1
+	}
+
 	// Setup the state hook for continuous state updates
 	{
 		state, err := c.State()
@@ -209,12 +296,17 @@ func (c *ApplyCommand) Run(args []string) int {
 		stateHook.State = state
 	}
 
-	// Start the apply in a goroutine so that we can be interrupted.
+	// The changes are counted to tell how many are left while applying,
+	// and timed to tell how long is left in later applies
+	c.progress.SetPlan(plan)
+	history := c.loadApplyHistory()
+	c.progress.SetHistory(history)
+
+	// Run the apply so that we can be interrupted, and wait for it
+	// to finish so we can handle it properly.
 	var state *terraform.State
 	var applyErr error
-	doneCh := make(chan struct{})
-	go func() {
-		defer close(doneCh)
+	_, aborted := c.runInterruptible(ctx, c.ShutdownCh, func() {
 		state, applyErr = ctx.Apply()
 
 		// Record any shadow errors for later
@@ -222,28 +314,15 @@ func (c *ApplyCommand) Run(args []string) int {
 			shadowErr = multierror.Append(shadowErr, multierror.Prefix(
 				err, "apply operation:"))
 		}
-	}()
-
-	// Wait for the apply to finish or for us to be interrupted so
-	// we can handle it properly.
-	err = nil
-	select {
-	case <-c.ShutdownCh:
-		c.Ui.Output("Interrupt received. Gracefully shutting down...")
-
-		// Stop execution
-		go ctx.Stop()
+	})
+	if aborted {
+		return 1
+	}
 
-		// Still get the result, since there is still one
-		select {
-		case <-c.ShutdownCh:
-			c.Ui.Error(
-				"Two interrupts received. Exiting immediately. Note that data\n" +
-					"loss may have occurred.")
-			return 1
-		case <-doneCh:
+	if history != nil {
+		if err := c.saveApplyHistory(history); err != nil {
+			log.Printf("[WARN] Error writing the apply history: %s", err)
 		}
-	case <-doneCh:
 	}
 
 	// Persist the state
@@ -266,7 +345,15 @@ func (c *ApplyCommand) Run(args []string) int {
 		return 1
 	}
 
-	if c.Destroy {
+	c.outputStats(stats, statsHook, stateBefore, state)
+
+	c.jsonEvent("apply_summary", map[string]interface{}{
+		"added":     countHook.Added,
+		"changed":   countHook.Changed,
+		"destroyed": countHook.Removed,
+	})
+
+	if destroy {
 		c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
 			"[reset][bold][green]\n"+
 				"Destroy complete! Resources: %d destroyed.",
@@ -291,7 +378,7 @@ func (c *ApplyCommand) Run(args []string) int {
 			c.Meta.StateOutPath())))
 	}
 
-	if !c.Destroy {
+	if !destroy {
 		if outputs := outputsAsString(state, terraform.RootModulePath, ctx.Module().Config().Outputs, true); outputs != "" {
 			c.Ui.Output(c.Colorize().Color(outputs))
 		}
@@ -303,6 +390,41 @@ func (c *ApplyCommand) Run(args []string) int {
 	return 0
 }
 
+// approvePlan shows the plan and asks for it to be approved before it's
+// applied, formatted with the given options. It returns false if it wasn't
+// approved. A plan that changes nothing is approved without asking.
+func (c *ApplyCommand) approvePlan(plan *terraform.Plan, display *FormatPlanOpts) bool {
+	if plan.Diff.Empty() {
+		return true
+	}
+
+	c.Ui.Output(strings.TrimSpace(applyPlanHeader) + "\n")
+	c.Ui.Output(FormatPlan(&FormatPlanOpts{
+		Plan:        plan,
+		Color:       c.Colorize(),
+		ModuleDepth: display.ModuleDepth,
+		Compact:     display.Compact,
+		Full:        display.Full,
+	}) + "\n")
+
+	v, err := c.UIInput().Input(&terraform.InputOpts{
+		Id:    "approve",
+		Query: "Do you want to apply these changes?",
+		Description: "Terraform will make the changes shown above.\n" +
+			"Only 'yes' will be accepted to approve.",
+	})
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error asking for approval: %s", err))
+		return false
+	}
+	if v != "yes" {
+		c.Ui.Output("Apply cancelled.")
+		return false
+	}
+
+	return true
+}
+
 func (c *ApplyCommand) Help() string {
 	if c.Destroy {
 		return c.helpDestroy()
@@ -331,6 +453,11 @@ Usage: terraform apply [options] [DIR-OR-PLAN]
   configuration or an execution plan can be provided. Execution plans can be
   used to only execute a pre-determined set of actions.
 
+  A plan created with "terraform plan -destroy" is applied as a destroy.
+
+  Unless a plan is given, the changes are shown and apply asks for them
+  to be approved before making them.
+
   DIR can also be a SOURCE as given to the "init" command. In this case,
   apply behaves as though "init" was called followed by "apply". This only
   works for sources that aren't files, and only if the current working
@@ -339,17 +466,55 @@ Usage: terraform apply [options] [DIR-OR-PLAN]
 
 Options:
 
+  -auto-approve          Apply without showing the plan and asking for it
+                         to be approved, and without asking for the name
+                         of a remote state configured with
+                         confirm_required. A plan file is always applied
+                         without approval.
+
   -backup=path           Path to backup the existing state file before
                          modifying. Defaults to the "-state-out" path with
                          ".backup" extension. Set to "-" to disable backup.
 
-  -input=true            Ask for input for variables if not directly set.
+  -compact               Show each changed value of an attribute holding a
+                         JSON document on one line in the plan shown for
+                         approval.
+
+  -compact-output        Show the progress of the changes on a single
+                         status line, updated in place, instead of a
+                         message for each resource.
+
+  -full                  Show the unchanged values of attributes holding
+                         JSON documents in the plan shown for approval.
+
+  -input=true            Ask for input for variables if not directly set,
+                         and for the plan to be approved. With
+                         -input=false, -auto-approve is required unless a
+                         plan file is applied.
+
+  -json                  Write all output as newline delimited JSON events,
+                         including an event for each resource that is
+                         changed. Implies -input=false, so -auto-approve
+                         is required unless a plan file is applied.
+
+  -module-depth=n        Specifies the depth of modules to show in the plan
+                         shown for approval. -1 will expand all.
 
   -no-color              If specified, output won't contain any color.
 
   -parallelism=n         Limit the number of concurrent operations.
                          Defaults to 10.
 
+  -policy-command=cmd    Run a command that must approve the plan before it
+                         is applied. The plan is given to the command as JSON
+                         on stdin, and is rejected if the command fails. This
+                         overrides policy_command in the terraform block.
+
+  -progress              Show the progress of the changes on a single status
+                         line with a progress bar, and an estimate of the
+                         time left from how long the changes took before.
+                         The durations are recorded in the data directory.
+
   -refresh=true          Update state prior to checking for differences. This
                          has no effect if a plan file is given to apply.
 
@@ -360,6 +525,10 @@ Options:
                          "-state". This can be used to preserve the old
                          state.
 
+  -stats                 Show statistics of the operation: the resources
+                         walked, the calls made to each provider, the
+                         slowest resources and the size of the state.
+
   -target=resource       Resource to target. Operation will be limited to this
                          resource and its dependencies. This flag can be used
                          multiple times.
@@ -378,23 +547,49 @@ Options:
 
 func (c *ApplyCommand) helpDestroy() string {
 	helpText := `
-Usage: terraform destroy [options] [DIR]
+Usage: terraform destroy [options] [DIR-OR-PLAN]
 
   Destroy Terraform-managed infrastructure.
 
+  A plan created with "terraform plan -destroy -out=FILE" can be given
+  instead of a directory, to destroy only what was planned. Other plans
+  can't be given to destroy.
+
 Options:
 
+  -auto-approve          Don't ask for input for destroy confirmation,
+                         or for the name of a remote state configured with
+                         confirm_required.
+
   -backup=path           Path to backup the existing state file before
                          modifying. Defaults to the "-state-out" path with
                          ".backup" extension. Set to "-" to disable backup.
 
-  -force                 Don't ask for input for destroy confirmation.
+  -compact-output        Show the progress of the changes on a single
+                         status line, updated in place, instead of a
+                         message for each resource.
+
+  -force                 The same as -auto-approve.
+
+  -json                  Write all output as newline delimited JSON events,
+                         including an event for each resource that is
+                         destroyed. Requires -auto-approve.
 
   -no-color              If specified, output won't contain any color.
 
   -parallelism=n         Limit the number of concurrent operations.
                          Defaults to 10.
 
+  -policy-command=cmd    Run a command that must approve the plan before it
+                         is applied. The plan is given to the command as JSON
+                         on stdin, and is rejected if the command fails. This
+                         overrides policy_command in the terraform block.
+
+  -progress              Show the progress of the changes on a single status
+                         line with a progress bar, and an estimate of the
+                         time left from how long the changes took before.
+                         The durations are recorded in the data directory.
+
   -refresh=true          Update state prior to checking for differences. This
                          has no effect if a plan file is given to apply.
 
@@ -405,6 +600,10 @@ Options:
                          "-state". This can be used to preserve the old
                          state.
 
+  -stats                 Show statistics of the operation: the resources
+                         walked, the calls made to each provider, the
+                         slowest resources and the size of the state.
+
   -target=resource       Resource to target. Operation will be limited to this
                          resource and its dependencies. This flag can be used
                          multiple times.
@@ -479,3 +678,17 @@ func outputsAsString(state *terraform.State, modPath []string, schema []*config.
 
 	return strings.TrimSpace(outputBuf.String())
 }
+
+const applyPlanHeader = `
+The Terraform execution plan has been generated and is shown below.
+Resources are shown in alphabetical order for quick scanning. Green resources
+will be created (or destroyed and then created if an existing resource
+exists), yellow resources are being changed in-place, and red resources
+will be destroyed. Cyan entries are data sources to be read.
+`
+
+const errApplyApproveInput = `
+The plan can't be approved because input is disabled. Use -auto-approve
+to apply without approval, or apply a plan file created with
+"terraform plan -out=FILE" after reviewing it.
+`
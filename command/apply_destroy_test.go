@@ -116,6 +116,62 @@ func TestApply_destroyPlan(t *testing.T) {
 	}
 }
 
+func TestApply_destroyPlanFile(t *testing.T) {
+	for _, destroy := range []bool{false, true} {
+		originalState := testState()
+		statePath := testStateFile(t, originalState)
+		planPath := testTempFile(t)
+
+		p := testProvider()
+		ui := new(cli.MockUi)
+		pc := &PlanCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		args := []string{
+			"-destroy",
+			"-out", planPath,
+			"-state", statePath,
+			testFixturePath("apply"),
+		}
+		if code := pc.Run(args); code != 0 {
+			t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+		}
+
+		// A destroy plan is applied as a destroy by both commands
+		ui = new(cli.MockUi)
+		c := &ApplyCommand{
+			Destroy: destroy,
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		args = []string{
+			"-state", statePath,
+			planPath,
+		}
+		if destroy {
+			args = append([]string{"-force"}, args...)
+		}
+		if code := c.Run(args); code != 0 {
+			t.Fatalf("%t: bad: %d\n\n%s", destroy, code, ui.ErrorWriter.String())
+		}
+		if !strings.Contains(ui.OutputWriter.String(), "Destroy complete! Resources: 1 destroyed.") {
+			t.Fatalf("%t: bad:\n\n%s", destroy, ui.OutputWriter.String())
+		}
+
+		state := testReadState(t, statePath)
+		if len(state.RootModule().Resources) > 0 {
+			t.Fatalf("%t: state should be empty:\n\n%s", destroy, state)
+		}
+	}
+}
+
 func TestApply_destroyTargeted(t *testing.T) {
 	originalState := &terraform.State{
 		Modules: []*terraform.ModuleState{
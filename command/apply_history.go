@@ -0,0 +1,143 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ApplyHistoryFilename is the name of the file in the data directory that
+// records how long resource changes took, to estimate how long an apply
+// has left with -progress.
+const ApplyHistoryFilename = "apply_history.json"
+
+// applyHistoryWeight is the weight of the latest duration of a change in
+// its recorded duration, so that the estimates follow recent applies.
+const applyHistoryWeight = 0.5
+
+// applyHistory is the content of the apply history file. Durations are in
+// seconds, and keyed by the action and either the resource, such as
+// "create aws_instance.web", or the resource type, such as
+// "create aws_instance", for resources that weren't changed before.
+type applyHistory struct {
+	Durations map[string]float64 `json:"durations"`
+}
+
+// Estimate returns how long the given action on a resource is expected to
+// take, and false if nothing like it was recorded.
+func (h *applyHistory) Estimate(action, id, typ string) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+
+	v, ok := h.Durations[action+" "+id]
+	if !ok {
+		v, ok = h.Durations[action+" "+typ]
+	}
+
+	return time.Duration(v * float64(time.Second)), ok
+}
+
+// Record records how long the given action on a resource took.
+func (h *applyHistory) Record(action, id, typ string, d time.Duration) {
+	if h.Durations == nil {
+		h.Durations = make(map[string]float64)
+	}
+
+	for _, k := range []string{action + " " + id, action + " " + typ} {
+		v, ok := h.Durations[k]
+		if !ok {
+			v = d.Seconds()
+		}
+		h.Durations[k] = v + applyHistoryWeight*(d.Seconds()-v)
+	}
+}
+
+// Mean returns the mean of the recorded durations of resources, to
+// estimate the changes that nothing like was recorded, and false if
+// nothing was recorded.
+func (h *applyHistory) Mean() (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+
+	var sum float64
+	n := 0
+	for k, v := range h.Durations {
+		// The keys of resources have a dot in their name
+		if strings.Contains(k, ".") {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+
+	return time.Duration(sum / float64(n) * float64(time.Second)), true
+}
+
+// loadApplyHistory returns the apply history to record the durations of
+// the changes of an apply in, or nil if it isn't kept. It's kept if the
+// data directory exists, so that applying doesn't create it, or if
+// -progress is set. A history that can't be read is started again.
+func (m *Meta) loadApplyHistory() *applyHistory {
+	if !m.progressBar {
+		if _, err := os.Stat(m.DataDir()); err != nil {
+			return nil
+		}
+	}
+
+	path := filepath.Join(m.DataDir(), ApplyHistoryFilename)
+	history, err := readApplyHistory(path)
+	if err != nil {
+		log.Printf("[WARN] Starting a new apply history: %s", err)
+		history = &applyHistory{}
+	}
+
+	return history
+}
+
+// saveApplyHistory writes the apply history to the data directory.
+func (m *Meta) saveApplyHistory(history *applyHistory) error {
+	if err := os.MkdirAll(m.DataDir(), 0755); err != nil {
+		return err
+	}
+
+	return writeApplyHistory(filepath.Join(m.DataDir(), ApplyHistoryFilename), history)
+}
+
+// readApplyHistory reads the apply history file at the given path. An
+// empty history is returned if it doesn't exist.
+func readApplyHistory(path string) (*applyHistory, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &applyHistory{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var history applyHistory
+	if err := json.NewDecoder(f).Decode(&history); err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", path, err)
+	}
+
+	return &history, nil
+}
+
+// writeApplyHistory writes the apply history file at the given path.
+func writeApplyHistory(path string, history *applyHistory) error {
+	data, err := json.MarshalIndent(history, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
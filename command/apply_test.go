@@ -1,6 +1,7 @@
 package command
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"io/ioutil"
@@ -10,11 +11,13 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/terraform/state/remote"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/cli"
 )
@@ -58,6 +61,262 @@ func TestApply(t *testing.T) {
 	}
 }
 
+func TestApply_progress(t *testing.T) {
+	statePath := testTempFile(t)
+	dataDir := tempDir(t)
+	defer os.RemoveAll(dataDir)
+	historyPath := filepath.Join(dataDir, ApplyHistoryFilename)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+			dataDir:     dataDir,
+		},
+	}
+
+	// Without a data directory, the history isn't kept
+	args := []string{
+		"-state", statePath,
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if _, err := os.Stat(historyPath); !os.IsNotExist(err) {
+		t.Fatalf("history shouldn't be written: %s", err)
+	}
+
+	os.Remove(statePath)
+	ui = new(cli.MockUi)
+	c = &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+			dataDir:     dataDir,
+		},
+	}
+	args = []string{
+		"-progress",
+		"-state", statePath,
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	expected := "Applying... [====================] 1 of 1 done"
+	if actual := ui.OutputWriter.String(); !strings.Contains(actual, expected) {
+		t.Fatalf("bad:\n\n%s", actual)
+	}
+
+	history, err := readApplyHistory(historyPath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := history.Estimate("create", "test_instance.foo", "test_instance"); !ok {
+		t.Fatalf("bad: %#v", history)
+	}
+}
+
+func TestApply_policy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("policy commands in this test need a Unix shell")
+	}
+
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// The policy command in the configuration passes the plan
+	args := []string{
+		"-state", statePath,
+		testFixturePath("apply-policy"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !p.ApplyCalled {
+		t.Fatal("apply should be called")
+	}
+}
+
+func TestApply_policyRejected(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("policy commands in this test need a Unix shell")
+	}
+
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// The command line overrides the configuration
+	args := []string{
+		"-state", statePath,
+		"-policy-command", "echo denied; exit 1",
+		testFixturePath("apply-policy"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if p.ApplyCalled {
+		t.Fatal("apply should not be called")
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "denied") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "rejected by the policy command") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+}
+
+func TestApply_policyPlan(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("policy commands in this test need a Unix shell")
+	}
+
+	planPath := testPlanFile(t, &terraform.Plan{
+		Module: testModule(t, "apply"),
+	})
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// Plan files are checked as well
+	args := []string{
+		"-state-out", statePath,
+		"-policy-command", "grep -q resource_changes && exit 1",
+		planPath,
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+}
+
+func TestApply_json(t *testing.T) {
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-json",
+		"-state", statePath,
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	es := testJSONEvents(t, ui.OutputWriter.String())
+	if e := testJSONEvent(t, es, "apply_start"); e.Data["action"] != "create" {
+		t.Fatalf("bad: %#v", e)
+	}
+	testJSONEvent(t, es, "apply_complete")
+	if e := testJSONEvent(t, es, "apply_summary"); e.Data["added"] != float64(1) {
+		t.Fatalf("bad: %#v", e)
+	}
+	if e := testJSONEvent(t, es, "operation_stats"); e.Data["resources_walked"] != float64(1) {
+		t.Fatalf("bad: %#v", e)
+	}
+}
+
+func TestApply_stats(t *testing.T) {
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-stats",
+		"-state", statePath,
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	for _, expected := range []string{
+		"Resources walked: 1",
+		"test: 3",
+		"test_instance.foo: ",
+		"State size:       0 bytes before",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("expected %q in output:\n\n%s", expected, output)
+		}
+	}
+
+	// Without the flag, no statistics are shown
+	ui = new(cli.MockUi)
+	c.Meta.Ui = ui
+	args = []string{
+		"-state", statePath,
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if strings.Contains(ui.OutputWriter.String(), "Statistics") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestApply_jsonDestroyNoForce(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Destroy: true,
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-json",
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+}
+
 // high water mark counter
 type hwm struct {
 	sync.Mutex
@@ -379,8 +638,8 @@ func TestApply_input(t *testing.T) {
 	test = false
 	defer func() { test = true }()
 
-	// Set some default reader/writers for the inputs
-	defaultInputReader = bytes.NewBufferString("foo\n")
+	// Set some default reader/writers for the inputs, and approve the plan
+	defaultInputReader = bufio.NewReader(bytes.NewBufferString("foo\nyes\n"))
 	defaultInputWriter = new(bytes.Buffer)
 
 	statePath := testTempFile(t)
@@ -679,6 +938,306 @@ func TestApply_plan_remoteState(t *testing.T) {
 	}
 }
 
+func TestApply_planRemoteStateMismatch(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	// The plan is created against one remote state...
+	state := testState()
+	conf, srv := testRemoteState(t, state, 200)
+	defer srv.Close()
+	state.Remote = conf
+
+	planPath := testPlanFile(t, &terraform.Plan{
+		Module: testModule(t, "apply"),
+		State:  state,
+	})
+
+	// ...but the directory is configured for another.
+	current := terraform.NewState()
+	current.Remote = &terraform.RemoteState{
+		Type:   "http",
+		Config: map[string]string{"address": "http://example.com/other"},
+	}
+	testStateFileRemote(t, current)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{planPath}); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "different remote state") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+	if p.ApplyCalled {
+		t.Fatal("apply should not be called")
+	}
+}
+
+func TestApply_planRemoteStateSettingsChanged(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	// The plan is created with settings that have changed since...
+	state := testState()
+	conf, srv := testRemoteState(t, state, 200)
+	defer srv.Close()
+	state.Remote = conf
+	state.Remote.Config["skip_cert_verification"] = "true"
+
+	planPath := testPlanFile(t, &terraform.Plan{
+		Module: testModule(t, "apply"),
+		State:  state,
+	})
+
+	// ...but the state is still in the same place.
+	current := terraform.NewState()
+	current.Remote = &terraform.RemoteState{
+		Type: "http",
+		Config: map[string]string{
+			"address":   conf.Config["address"],
+			"retry_max": "3",
+		},
+	}
+	testStateFileRemote(t, current)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{planPath}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The current settings are kept
+	actual := testReadState(t, filepath.Join(tmp, DefaultDataDir, DefaultStateFilename))
+	if !actual.Remote.Equals(current.Remote) {
+		t.Fatalf("bad: %#v", actual.Remote)
+	}
+}
+
+func TestApply_approve(t *testing.T) {
+	// Disable test mode so approval would be asked
+	test = false
+	defer func() { test = true }()
+
+	cases := []struct {
+		Input string
+		Args  []string
+		Code  int
+	}{
+		{"yes\n", nil, 0},
+		{"no\n", nil, 1},
+		{"", []string{"-input=false"}, 1},
+		{"", []string{"-json"}, 1},
+		{"", []string{"-input=false", "-auto-approve"}, 0},
+	}
+
+	for i, tc := range cases {
+		defaultInputReader = bytes.NewBufferString(tc.Input)
+		defaultInputWriter = new(bytes.Buffer)
+
+		statePath := testTempFile(t)
+
+		p := testProvider()
+		ui := new(cli.MockUi)
+		c := &ApplyCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		args := append(tc.Args, "-state", statePath, testFixturePath("apply"))
+		if code := c.Run(args); code != tc.Code {
+			t.Fatalf("%d: bad: %d\n\n%s", i, code, ui.ErrorWriter.String())
+		}
+		if p.ApplyCalled != (tc.Code == 0) {
+			t.Fatalf("%d: apply called: %t", i, p.ApplyCalled)
+		}
+		if tc.Input != "" && !strings.Contains(ui.OutputWriter.String(), "+ test_instance.foo") {
+			t.Fatalf("%d: plan not shown:\n\n%s", i, ui.OutputWriter.String())
+		}
+	}
+}
+
+func TestApply_approvePlanFile(t *testing.T) {
+	// Disable test mode so approval would be asked
+	test = false
+	defer func() { test = true }()
+
+	planPath := testPlanFile(t, &terraform.Plan{
+		Module: testModule(t, "apply"),
+	})
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"-input=false", "-state-out", statePath, planPath}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+}
+
+func TestApply_confirmRequired(t *testing.T) {
+	// Disable test mode so input would be asked
+	test = false
+	defer func() { test = true }()
+
+	cases := []struct {
+		Input string
+		Args  []string
+		Code  int
+	}{
+		{"prod\nyes\n", nil, 0},
+		{"yes\n", nil, 1},
+		{"", []string{"-input=false"}, 1},
+		{"", []string{"-input=false", "-auto-approve"}, 0},
+	}
+
+	for i, tc := range cases {
+		func() {
+			tmp, cwd := testCwd(t)
+			defer testFixCwd(t, tmp, cwd)
+
+			defaultInputReader = bufio.NewReader(bytes.NewBufferString(tc.Input))
+			defaultInputWriter = new(bytes.Buffer)
+
+			current := terraform.NewState()
+			current.Remote = &terraform.RemoteState{
+				Type: "inmem",
+				Config: map[string]string{
+					"name":             fmt.Sprintf("confirm-%d", i),
+					"confirm_required": "true",
+					"confirm_name":     "prod",
+				},
+			}
+			testStateFileRemote(t, current)
+
+			p := testProvider()
+			ui := new(cli.MockUi)
+			c := &ApplyCommand{
+				Meta: Meta{
+					ContextOpts: testCtxConfig(p),
+					Ui:          ui,
+				},
+			}
+
+			args := append(tc.Args, testFixturePath("apply"))
+			if code := c.Run(args); code != tc.Code {
+				t.Fatalf("%d: bad: %d\n\n%s", i, code, ui.ErrorWriter.String())
+			}
+			if p.ApplyCalled != (tc.Code == 0) {
+				t.Fatalf("%d: apply called: %t", i, p.ApplyCalled)
+			}
+		}()
+	}
+}
+
+func TestApply_planLocalWithRemoteConfigured(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	planPath := testPlanFile(t, &terraform.Plan{
+		Module: testModule(t, "apply"),
+	})
+
+	current := terraform.NewState()
+	current.Remote = &terraform.RemoteState{
+		Type:   "http",
+		Config: map[string]string{"address": "http://example.com"},
+	}
+	testStateFileRemote(t, current)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{planPath}); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "created with local state") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+}
+
+func TestApply_remoteStatePersistError(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	// The state is read and pushed to the remote state when it's loaded,
+	// and persisted while applying. Every call after those fails, so
+	// saving the state at the end fails.
+	spy := &remote.SpyClient{Client: new(remote.InmemClient), FailAfter: 3}
+	remote.BuiltinClients["spy"] = func(map[string]string) (remote.Client, error) {
+		return spy, nil
+	}
+	defer delete(remote.BuiltinClients, "spy")
+
+	current := terraform.NewState()
+	current.Remote = &terraform.RemoteState{Type: "spy"}
+	cachePath := testStateFileRemote(t, current)
+
+	p := testProvider()
+	p.DiffReturn = &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"ami": &terraform.ResourceAttrDiff{
+				New: "bar",
+			},
+		},
+	}
+	p.ApplyReturn = &terraform.InstanceState{ID: "foo"}
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{testFixturePath("apply")}); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "Failed to save state") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+
+	calls := spy.Calls()
+	if last := calls[len(calls)-1]; last != "put (failed)" {
+		t.Fatalf("bad: %#v", calls)
+	}
+
+	// The applied state is kept in the local cache, to be pushed later
+	cache := testReadState(t, cachePath)
+	if cache.RootModule().Resources["test_instance.foo"] == nil {
+		t.Fatalf("bad: %s", cache)
+	}
+}
+
 func TestApply_planWithVarFile(t *testing.T) {
 	varFileDir := testTempDir(t)
 	varFilePath := filepath.Join(varFileDir, "terraform.tfvars")
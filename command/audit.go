@@ -0,0 +1,158 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/terraform/state"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// AuditLog is the log that a record is appended to every time a command
+// saves a changed state. Nothing is logged if Path is empty.
+type AuditLog struct {
+	// Path is the file the log is written to. It is created, along with
+	// its directory, if it doesn't exist and is only ever appended to.
+	Path string
+
+	// Command is the command being run, such as "apply" or "state rm".
+	Command string
+}
+
+// auditRecord is a single entry of the audit log. Entries are written
+// as one JSON object per line.
+type auditRecord struct {
+	Time         string `json:"time"`
+	User         string `json:"user"`
+	Host         string `json:"host"`
+	Command      string `json:"command"`
+	Dir          string `json:"dir"`
+	Backend      string `json:"backend"`
+	Path         string `json:"path,omitempty"`
+	Lineage      string `json:"lineage"`
+	SerialBefore *int64 `json:"serial_before"`
+	SerialAfter  int64  `json:"serial_after"`
+}
+
+// Enabled returns true if records should be written to the log.
+func (l *AuditLog) Enabled() bool {
+	return l != nil && l.Path != ""
+}
+
+// record appends a record of the change of a state from the serial
+// before to the given state, which was saved to path if it is local.
+// The serial before is nil if there was no state.
+func (l *AuditLog) record(before *int64, s *terraform.State, path string) error {
+	r := &auditRecord{
+		Time:         time.Now().UTC().Format(time.RFC3339),
+		User:         auditUser(),
+		Command:      l.Command,
+		Backend:      "local",
+		Path:         path,
+		SerialBefore: before,
+	}
+	r.Host, _ = os.Hostname()
+	r.Dir, _ = os.Getwd()
+	if s != nil {
+		r.Lineage = s.Lineage
+		r.SerialAfter = s.Serial
+		if s.Remote != nil && !s.Remote.Empty() {
+			r.Backend = s.Remote.Type
+			r.Path = ""
+		}
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// auditUser returns the name of the user running Terraform.
+func auditUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+
+	return os.Getenv("USERNAME")
+}
+
+// auditState wraps a State to append a record to the audit log each time
+// a changed state is persisted.
+type auditState struct {
+	Real state.State
+	Log  *AuditLog
+	Path string
+
+	// serial is the serial of the state when it was last persisted, or
+	// nil if there was no state yet.
+	serial *int64
+}
+
+func newAuditState(s state.State, log *AuditLog, path string) *auditState {
+	result := &auditState{Real: s, Log: log, Path: path}
+	if current := s.State(); current != nil {
+		serial := current.Serial
+		result.serial = &serial
+	}
+
+	return result
+}
+
+func (s *auditState) unwrap() state.State {
+	return s.Real
+}
+
+func (s *auditState) State() *terraform.State {
+	return s.Real.State()
+}
+
+func (s *auditState) RefreshState() error {
+	return s.Real.RefreshState()
+}
+
+func (s *auditState) WriteState(state *terraform.State) error {
+	return s.Real.WriteState(state)
+}
+
+func (s *auditState) PersistState() error {
+	if err := s.Real.PersistState(); err != nil {
+		return err
+	}
+
+	current := s.Real.State()
+	if current == nil || (s.serial != nil && current.Serial == *s.serial) {
+		return nil
+	}
+
+	if err := s.Log.record(s.serial, current, s.Path); err != nil {
+		return fmt.Errorf(
+			"The state was saved, but writing the audit log failed: %s", err)
+	}
+
+	serial := current.Serial
+	s.serial = &serial
+	return nil
+}
@@ -0,0 +1,155 @@
+package command
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform/state"
+	"github.com/mitchellh/cli"
+)
+
+func TestAuditState(t *testing.T) {
+	td := tempDir(t)
+	defer os.RemoveAll(td)
+
+	log := &AuditLog{
+		Path:    filepath.Join(td, "audit.log"),
+		Command: "apply",
+	}
+
+	statePath := filepath.Join(td, "terraform.tfstate")
+	ls := &state.LocalState{Path: statePath}
+	if err := ls.WriteState(testState()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	before := ls.State().Serial
+
+	s := newAuditState(ls, log, statePath)
+
+	// Persisting an unchanged state isn't recorded
+	if err := s.PersistState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := os.Stat(log.Path); !os.IsNotExist(err) {
+		t.Fatalf("audit log shouldn't exist: %s", err)
+	}
+
+	newState := testState()
+	newState.Serial = before + 1
+	if err := s.WriteState(newState); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := s.PersistState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	records := testAuditRecords(t, log.Path)
+	if len(records) != 1 {
+		t.Fatalf("bad: %#v", records)
+	}
+
+	r := records[0]
+	if r.Command != "apply" || r.Backend != "local" || r.Path != statePath {
+		t.Fatalf("bad: %#v", r)
+	}
+	if r.SerialBefore == nil || *r.SerialBefore != before || r.SerialAfter != before+1 {
+		t.Fatalf("bad: %#v", r)
+	}
+	if r.Lineage != newState.Lineage || r.Time == "" {
+		t.Fatalf("bad: %#v", r)
+	}
+}
+
+func TestApply_auditLog(t *testing.T) {
+	td := tempDir(t)
+	defer os.RemoveAll(td)
+
+	statePath := testTempFile(t)
+	log := &AuditLog{
+		Path:    filepath.Join(td, "audit.log"),
+		Command: "apply",
+	}
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+			AuditLog:    log,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	records := testAuditRecords(t, log.Path)
+	if len(records) == 0 {
+		t.Fatal("apply should be recorded in the audit log")
+	}
+
+	// The state didn't exist before the apply
+	first := records[0]
+	if first.Command != "apply" || first.Path != statePath {
+		t.Fatalf("bad: %#v", first)
+	}
+	if first.SerialBefore != nil {
+		t.Fatalf("bad: %#v", first)
+	}
+}
+
+func TestApply_auditLogDisabled(t *testing.T) {
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+			AuditLog:    &AuditLog{Command: "apply"},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if _, ok := c.state.(*auditState); ok {
+		t.Fatal("state shouldn't be audited without an audit log path")
+	}
+}
+
+func testAuditRecords(t *testing.T, path string) []*auditRecord {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+
+	var result []*auditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r auditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		result = append(result, &r)
+	}
+
+	return result
+}
+
+var _ state.State = new(auditState)
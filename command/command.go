@@ -3,6 +3,7 @@ package command
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/cli"
@@ -23,6 +24,15 @@ const DefaultVarsFilename = "terraform.tfvars"
 // DefaultBackupExtension is added to the state file to form the path
 const DefaultBackupExtension = ".backup"
 
+// DefaultChecksumExtension is added to the remote state cache path to form
+// the path where its checksum is stored.
+const DefaultChecksumExtension = ".sha256"
+
+// DefaultStatePersistInterval is the minimum time between persisting the
+// state while it is being modified by apply. The state is always persisted
+// once more when apply completes.
+const DefaultStatePersistInterval = 20 * time.Second
+
 // DefaultParallelism is the limit Terraform places on total parallel
 // operations as it walks the dependency graph.
 const DefaultParallelism = 10
@@ -1,6 +1,8 @@
 package command
 
 import (
+	"bufio"
+	"bytes"
 	"flag"
 	"io"
 	"io/ioutil"
@@ -13,6 +15,7 @@ import (
 	"github.com/hashicorp/go-getter"
 	"github.com/hashicorp/terraform/config/module"
 	"github.com/hashicorp/terraform/helper/logging"
+	"github.com/hashicorp/terraform/state/remote"
 	"github.com/hashicorp/terraform/terraform"
 )
 
@@ -144,6 +147,21 @@ func testReadPlan(t *testing.T, path string) *terraform.Plan {
 	return p
 }
 
+func testReadState(t *testing.T, path string) *terraform.State {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+
+	s, err := terraform.ReadState(f)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return s
+}
+
 // testState returns a test State structure that we use for a lot of tests.
 func testState() *terraform.State {
 	state := &terraform.State{
@@ -220,6 +238,69 @@ func testStateFileRemote(t *testing.T, s *terraform.State) string {
 	return path
 }
 
+// testConfirmRequiredState sets up the given states as the versions of
+// an in-memory remote state configured with confirm_required, where "prod"
+// must be typed to confirm changes, and answers "no" when that's asked.
+// The last state is the current one, which is cached locally too. The
+// returned function restores the defaults changed for the test.
+func testConfirmRequiredState(t *testing.T, states ...*terraform.State) (*remote.InmemClient, func()) {
+	client := new(remote.InmemClient)
+	old := remote.BuiltinClients["inmem"]
+	remote.BuiltinClients["inmem"] = func(map[string]string) (remote.Client, error) {
+		return client, nil
+	}
+
+	conf := &terraform.RemoteState{
+		Type: "inmem",
+		Config: map[string]string{
+			"confirm_required": "true",
+			"confirm_name":     "prod",
+		},
+	}
+	for _, s := range states {
+		s.Remote = conf
+
+		var buf bytes.Buffer
+		if err := terraform.WriteState(s, &buf); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := client.Put(buf.Bytes()); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	testStateFileRemote(t, states[len(states)-1])
+
+	// Disable test mode so the confirmation is asked
+	test = false
+	defaultInputReader = bufio.NewReader(bytes.NewBufferString("no\n"))
+	defaultInputWriter = new(bytes.Buffer)
+
+	return client, func() {
+		test = true
+		defaultInputReader = nil
+		defaultInputWriter = nil
+		remote.BuiltinClients["inmem"] = old
+	}
+}
+
+// testConfirmRequiredUnchanged tests that neither the remote state set up
+// by testConfirmRequiredState nor its local cache were changed from the
+// expected state.
+func testConfirmRequiredUnchanged(t *testing.T, client *remote.InmemClient, expected *terraform.State) {
+	actual, err := terraform.ReadState(bytes.NewReader(client.Data))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !actual.Equal(expected) || actual.Serial != expected.Serial {
+		t.Fatalf("remote state changed: %s", actual)
+	}
+
+	actual = testReadState(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
+	if !actual.Equal(expected) || actual.Serial != expected.Serial {
+		t.Fatalf("cached state changed: %s", actual)
+	}
+}
+
 // testStateOutput tests that the state at the given path contains
 // the expected state string.
 func testStateOutput(t *testing.T, path string, expected string) {
@@ -24,7 +24,7 @@ type ConsoleCommand struct {
 func (c *ConsoleCommand) Run(args []string) int {
 	args = c.Meta.process(args, true)
 	cmdFlags := c.Meta.flagSet("console")
-	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
@@ -50,9 +50,10 @@ func (c *ConsoleCommand) Run(args []string) int {
 
 	// Build the context based on the arguments given
 	ctx, _, err := c.Context(contextOpts{
-		Path:        configPath,
-		PathEmptyOk: true,
-		StatePath:   c.Meta.statePath,
+		Path:          configPath,
+		PathEmptyOk:   true,
+		StatePath:     c.Meta.statePath,
+		StateReadOnly: true,
 	})
 	if err != nil {
 		c.Ui.Error(err.Error())
@@ -0,0 +1,31 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+// CredentialsCommand is a Command implementation that just shows help for
+// the subcommands nested below it.
+type CredentialsCommand struct {
+	Meta
+}
+
+func (c *CredentialsCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+func (c *CredentialsCommand) Help() string {
+	helpText := `
+Usage: terraform credentials <subcommand> [options] [args]
+
+  This command has subcommands for managing the tokens of remote state
+  hosts in the credential store of the operating system.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CredentialsCommand) Synopsis() string {
+	return "Manage tokens in the OS credential store"
+}
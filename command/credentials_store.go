@@ -0,0 +1,84 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+// CredentialsStoreCommand is a Command implementation that stores the token
+// for a host in the credential store of the operating system, where the
+// built-in "os" credentials helper reads it.
+type CredentialsStoreCommand struct {
+	Meta
+
+	// StoreFunc stores the token for a host in the credential store.
+	StoreFunc func(host, token string) error
+
+	input io.Reader // STDIN if nil
+}
+
+func (c *CredentialsStoreCommand) Run(args []string) int {
+	if c.input == nil {
+		c.input = os.Stdin
+	}
+
+	args = c.Meta.process(args, false)
+	cmdFlags := c.Meta.flagSet("credentials store")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("The credentials store command expects exactly one argument.")
+		return cli.RunResultHelp
+	}
+	host := args[0]
+
+	// The token is read from stdin so that it doesn't show up in the
+	// shell history or the process list
+	data, err := ioutil.ReadAll(c.input)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading the token: %s", err))
+		return 1
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		c.Ui.Error("No token was given on stdin.")
+		return 1
+	}
+
+	if err := c.StoreFunc(host, token); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error storing the token for %s: %s", host, err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("The token for %s was stored.", host))
+	return 0
+}
+
+func (c *CredentialsStoreCommand) Help() string {
+	helpText := `
+Usage: terraform credentials store HOST
+
+  Stores the token read from stdin for the given host in the credential
+  store of the operating system, replacing the token stored for it before.
+  The built-in "os" credentials helper reads the token from there:
+
+      credentials_helper "os" {}
+
+  The store is the macOS Keychain, the Windows Credential Manager, or the
+  Secret Service (such as GNOME Keyring or KWallet) on other systems.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CredentialsStoreCommand) Synopsis() string {
+	return "Store a token in the OS credential store"
+}
@@ -0,0 +1,115 @@
+package command
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestCredentialsStore(t *testing.T) {
+	store := make(map[string]string)
+	ui := new(cli.MockUi)
+	c := &CredentialsStoreCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+		StoreFunc: testCredentialsStoreFunc(store),
+		input:     strings.NewReader("foo.bar\n"),
+	}
+
+	if code := c.Run([]string{"example.com"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if store["example.com"] != "foo.bar" {
+		t.Fatalf("bad: %#v", store)
+	}
+
+	// The token isn't echoed back
+	if strings.Contains(ui.OutputWriter.String(), "foo.bar") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+}
+
+func TestCredentialsStore_replace(t *testing.T) {
+	store := map[string]string{"example.com": "old"}
+	ui := new(cli.MockUi)
+	c := &CredentialsStoreCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+		StoreFunc: testCredentialsStoreFunc(store),
+		input:     strings.NewReader("new"),
+	}
+
+	if code := c.Run([]string{"example.com"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if store["example.com"] != "new" {
+		t.Fatalf("bad: %#v", store)
+	}
+}
+
+func TestCredentialsStore_noToken(t *testing.T) {
+	store := make(map[string]string)
+	ui := new(cli.MockUi)
+	c := &CredentialsStoreCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+		StoreFunc: testCredentialsStoreFunc(store),
+		input:     strings.NewReader("\n"),
+	}
+
+	if code := c.Run([]string{"example.com"}); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	if len(store) != 0 {
+		t.Fatalf("bad: %#v", store)
+	}
+}
+
+func TestCredentialsStore_noHost(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &CredentialsStoreCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+		StoreFunc: testCredentialsStoreFunc(make(map[string]string)),
+		input:     strings.NewReader("foo"),
+	}
+
+	if code := c.Run(nil); code != cli.RunResultHelp {
+		t.Fatalf("bad: %d", code)
+	}
+}
+
+func TestCredentialsStore_error(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &CredentialsStoreCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+		StoreFunc: func(string, string) error {
+			return errors.New("locked")
+		},
+		input: strings.NewReader("foo"),
+	}
+
+	if code := c.Run([]string{"example.com"}); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "locked") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+}
+
+// testCredentialsStoreFunc returns a StoreFunc that stores the tokens in
+// the given map instead of the credential store.
+func testCredentialsStoreFunc(store map[string]string) func(string, string) error {
+	return func(host, token string) error {
+		store[host] = token
+		return nil
+	}
+}
@@ -0,0 +1,180 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl"
+	hclParser "github.com/hashicorp/hcl/hcl/parser"
+	"github.com/mitchellh/colorstring"
+)
+
+const (
+	diagError   = "error"
+	diagWarning = "warning"
+)
+
+// diagnostic is a single problem found in the configuration. The position
+// is only known for some problems, such as syntax errors. When it is, the
+// problem is shown with the source code around it.
+type diagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+func (d *diagnostic) String() string {
+	var pos string
+	switch {
+	case d.Line > 0:
+		pos = fmt.Sprintf("%s:%d,%d: ", d.File, d.Line, d.Column)
+	case d.File != "":
+		pos = fmt.Sprintf("%s: ", d.File)
+	}
+
+	return pos + d.Summary
+}
+
+// formatDiagnostic formats a diagnostic for the CLI: the summary, the
+// source code it refers to with a caret under the position, and the
+// detail.
+func formatDiagnostic(d *diagnostic, color *colorstring.Colorize) string {
+	var buf bytes.Buffer
+	if d.Severity == diagError {
+		buf.WriteString(color.Color("[reset][bold][red]Error:[reset] "))
+	} else {
+		buf.WriteString(color.Color("[reset][bold][yellow]Warning:[reset] "))
+	}
+	buf.WriteString(d.String())
+
+	if snippet := diagnosticSnippet(d.File, d.Line, d.Column); snippet != "" {
+		buf.WriteString("\n\n")
+		buf.WriteString(snippet)
+	}
+	if d.Detail != "" {
+		buf.WriteString("\n\n")
+		buf.WriteString(d.Detail)
+	}
+
+	return buf.String()
+}
+
+// diagnosticSnippet returns the given line of the file and the one before
+// it, with a caret marking the column. An empty string is returned if the
+// file can't be read.
+func diagnosticSnippet(path string, line, column int) string {
+	if path == "" || line <= 0 {
+		return ""
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan() && n <= line; n++ {
+		if n < line-1 {
+			continue
+		}
+
+		text := strings.Replace(scanner.Text(), "\t", " ", -1)
+		fmt.Fprintf(&buf, "%5d | %s\n", n, text)
+	}
+	if buf.Len() == 0 {
+		return ""
+	}
+
+	if column > 0 {
+		fmt.Fprintf(&buf, "      | %s^", strings.Repeat(" ", column-1))
+	}
+
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// errorDiagnostics returns an error diagnostic for each of the errors
+// wrapped in err.
+func errorDiagnostics(err error) []*diagnostic {
+	errs := []error{err}
+	if merr, ok := err.(*multierror.Error); ok {
+		errs = merr.Errors
+	}
+
+	diags := make([]*diagnostic, len(errs))
+	for i, err := range errs {
+		diags[i] = &diagnostic{Severity: diagError, Summary: err.Error()}
+	}
+
+	return diags
+}
+
+// loadDiagnostics returns the diagnostics for an error loading the
+// configuration in dir. The HCL parser doesn't keep the position of a
+// syntax error once the loader has wrapped it, so the files are parsed
+// again to find it.
+func loadDiagnostics(dir string, err error) []*diagnostic {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.tf"))
+	jsonFiles, _ := filepath.Glob(filepath.Join(dir, "*.tf.json"))
+	files = append(files, jsonFiles...)
+	sort.Strings(files)
+
+	var diags []*diagnostic
+	for _, path := range files {
+		d, readErr := ioutil.ReadFile(path)
+		if readErr != nil {
+			continue
+		}
+
+		_, parseErr := hcl.Parse(string(d))
+		if parseErr == nil {
+			continue
+		}
+
+		diag := &diagnostic{
+			Severity: diagError,
+			Summary:  parseErr.Error(),
+			File:     path,
+		}
+		if perr, ok := parseErr.(*hclParser.PosError); ok {
+			diag.Summary = perr.Err.Error()
+			diag.Line = perr.Pos.Line
+			diag.Column = perr.Pos.Column
+		}
+
+		diags = append(diags, diag)
+	}
+
+	if len(diags) == 0 {
+		return errorDiagnostics(err)
+	}
+
+	return diags
+}
+
+// formatLoadError returns the message for an error loading the
+// configuration in dir, with the source code of any syntax errors.
+func formatLoadError(dir string, err error, color *colorstring.Colorize) string {
+	var snippets []string
+	for _, d := range loadDiagnostics(dir, err) {
+		if d.Line > 0 {
+			snippets = append(snippets, formatDiagnostic(d, color))
+		}
+	}
+	if len(snippets) == 0 {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("%s\n\n%s", err, strings.Join(snippets, "\n\n"))
+}
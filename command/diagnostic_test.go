@@ -0,0 +1,74 @@
+package command
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/colorstring"
+)
+
+func TestFormatDiagnostic(t *testing.T) {
+	d := &diagnostic{
+		Severity: diagError,
+		Summary:  "bad thing",
+		Detail:   "Fix it.",
+		File:     filepath.Join(testFixturePath("validate-invalid"), "missing_quote", "main.tf"),
+		Line:     6,
+		Column:   14,
+	}
+
+	actual := formatDiagnostic(d, testDiagColorize)
+	expected := strings.TrimSpace(`
+Error: ` + d.File + `:6,14: bad thing
+
+    5 |       device_index = 0
+    6 |       name = test
+      |              ^
+
+Fix it.
+`)
+	if actual != expected {
+		t.Fatalf("bad:\n%s\n\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestFormatDiagnostic_noFile(t *testing.T) {
+	d := &diagnostic{
+		Severity: diagWarning,
+		Summary:  "careful",
+		File:     "does-not-exist.tf",
+		Line:     1,
+		Column:   1,
+	}
+
+	actual := formatDiagnostic(d, testDiagColorize)
+	expected := "Warning: does-not-exist.tf:1,1: careful"
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestFormatLoadError(t *testing.T) {
+	dir := testFixturePath("validate-invalid/missing_quote")
+	actual := formatLoadError(dir, errors.New("load failed"), testDiagColorize)
+	if !strings.HasPrefix(actual, "load failed\n\nError: ") {
+		t.Fatalf("bad: %s", actual)
+	}
+	if !strings.Contains(actual, "    6 |       name = test\n") {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// Without a syntax error the error is unchanged
+	dir = testFixturePath("validate-valid")
+	actual = formatLoadError(dir, errors.New("load failed"), testDiagColorize)
+	if actual != "load failed" {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+var testDiagColorize = &colorstring.Colorize{
+	Colors:  colorstring.DefaultColors,
+	Disable: true,
+}
@@ -0,0 +1,274 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// DiscoverCommand is a cli.Command implementation that lists the resources
+// that providers can see and reports the ones that aren't managed by
+// Terraform, with the commands to import them.
+type DiscoverCommand struct {
+	Meta
+}
+
+func (c *DiscoverCommand) Run(args []string) int {
+	var types []string
+	var detailed bool
+
+	args = c.Meta.process(args, true)
+
+	cmdFlags := c.Meta.flagSet("discover")
+	cmdFlags.Var((*FlagStringSlice)(&types), "type", "type")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
+	cmdFlags.BoolVar(&detailed, "detailed-exitcode", false, "detailed-exitcode")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		return 1
+	}
+
+	var path string
+	args = cmdFlags.Args()
+	if len(args) > 1 {
+		c.Ui.Error("The discover command expects at most one argument.")
+		cmdFlags.Usage()
+		return 1
+	} else if len(args) == 1 {
+		path = args[0]
+	} else {
+		var err error
+		path, err = os.Getwd()
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error getting pwd: %s", err))
+			return 1
+		}
+	}
+
+	ctx, _, err := c.Context(contextOpts{
+		Path:      path,
+		StatePath: c.Meta.statePath,
+	})
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	listable, err := c.listableTypes(moduleProviders(ctx.Module().Config()))
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	if len(types) == 0 {
+		types = listable
+	}
+	listableSet := make(map[string]struct{})
+	for _, t := range listable {
+		listableSet[t] = struct{}{}
+	}
+	for _, t := range types {
+		if _, ok := listableSet[t]; !ok {
+			c.Ui.Error(fmt.Sprintf(
+				"The resources of type %q can't be listed. Either its provider\n"+
+					"isn't used by the configuration, or it doesn't support listing\n"+
+					"resources of this type.", t))
+			return 1
+		}
+	}
+	if len(types) == 0 {
+		c.Ui.Output(
+			"None of the providers used by the configuration support listing\n" +
+				"resources, so no resources can be discovered.")
+		return 0
+	}
+
+	if err := ctx.Input(c.InputMode()); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error configuring: %s", err))
+		return 1
+	}
+
+	discovered, err := ctx.Discover(&terraform.DiscoverOpts{Types: types})
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error discovering resources: %s", err))
+		return 1
+	}
+
+	var state *terraform.State
+	if c.state != nil {
+		state = c.state.State()
+	}
+	unmanaged := unmanagedResources(discovered, state)
+
+	if len(unmanaged) == 0 {
+		c.Ui.Output(fmt.Sprintf(
+			"All %d resources discovered are managed by Terraform.", len(discovered)))
+		return 0
+	}
+
+	c.Ui.Output(fmt.Sprintf(
+		"Discovered %d resources, %d of which aren't managed by Terraform:\n",
+		len(discovered), len(unmanaged)))
+	c.Ui.Output(formatUnmanaged(unmanaged) + "\n")
+	c.Ui.Output(strings.TrimSpace(discoverImportHelp) + "\n")
+	c.Ui.Output(formatImportCommands(unmanaged))
+
+	if detailed {
+		return 2
+	}
+	return 0
+}
+
+// listableTypes returns the sorted types of the resources that the given
+// providers can list. Aliased providers are skipped, since they provide
+// the same types as the provider without an alias.
+func (c *DiscoverCommand) listableTypes(providers []string) ([]string, error) {
+	var result []string
+	for _, name := range providers {
+		if strings.Contains(name, ".") {
+			continue
+		}
+
+		f, ok := c.ContextOpts.Providers[name]
+		if !ok {
+			continue
+		}
+		p, err := f()
+		if err != nil {
+			return nil, fmt.Errorf("Error loading the %q provider: %s", name, err)
+		}
+
+		for _, r := range p.Resources() {
+			if r.Listable {
+				result = append(result, r.Name)
+			}
+		}
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+// unmanagedResources returns the discovered resources whose type and ID
+// don't match a resource in any module of the state.
+func unmanagedResources(
+	discovered []*terraform.DiscoveredResource,
+	s *terraform.State) []*terraform.DiscoveredResource {
+	managed := make(map[string]struct{})
+	if s != nil {
+		for _, m := range s.Modules {
+			for _, r := range m.Resources {
+				if r.Primary != nil && r.Primary.ID != "" {
+					managed[r.Type+"."+r.Primary.ID] = struct{}{}
+				}
+			}
+		}
+	}
+
+	var result []*terraform.DiscoveredResource
+	for _, r := range discovered {
+		if _, ok := managed[r.Type+"."+r.ID]; !ok {
+			result = append(result, r)
+		}
+	}
+
+	return result
+}
+
+// formatUnmanaged returns the type and ID of the resources in aligned
+// columns.
+func formatUnmanaged(resources []*terraform.DiscoveredResource) string {
+	typeLen := 0
+	for _, r := range resources {
+		if len(r.Type) > typeLen {
+			typeLen = len(r.Type)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, r := range resources {
+		buf.WriteString(fmt.Sprintf(
+			"  %s%s  %s\n", r.Type, strings.Repeat(" ", typeLen-len(r.Type)), r.ID))
+	}
+
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// discoverNameInvalid matches the characters of an ID that can't be used
+// in a resource name.
+var discoverNameInvalid = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// formatImportCommands returns the import commands for the resources. The
+// resource names are made from the IDs, and suffixed to make them unique
+// like import does.
+func formatImportCommands(resources []*terraform.DiscoveredResource) string {
+	nameCounter := make(map[string]int)
+
+	var buf bytes.Buffer
+	for _, r := range resources {
+		addr := r.Type + "." + discoverNameInvalid.ReplaceAllString(r.ID, "_")
+		count, ok := nameCounter[addr]
+		nameCounter[addr] = count + 1
+		if ok {
+			addr += fmt.Sprintf("-%d", count)
+		}
+
+		buf.WriteString(fmt.Sprintf("  terraform import %s %s\n", addr, r.ID))
+	}
+
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+func (c *DiscoverCommand) Help() string {
+	helpText := `
+Usage: terraform discover [options] [DIR]
+
+  Find the resources that aren't managed by Terraform.
+
+  The providers used by the configuration are asked for all the resources
+  they can see, for the resource types that support it, and the resources
+  that aren't in the state are reported along with the commands to import
+  them. Nothing is changed.
+
+  The providers are configured as for the import command, so their
+  configuration may only depend on variables.
+
+Options:
+
+  -detailed-exitcode  Return exit code 2 if resources that aren't managed
+                      by Terraform are found, 0 if none are, and 1 on error.
+
+  -input=true         Ask for input for variables if not directly set.
+
+  -no-color           If specified, output won't contain any color.
+
+  -state=path         Path to the state file to compare the resources with.
+                      Defaults to "terraform.tfstate". Ignored when remote
+                      state is used.
+
+  -type=type          Only list the resources of this type. This flag can be
+                      used multiple times. Defaults to all the types that
+                      the providers can list.
+
+  -var 'foo=bar'      Set a variable in the Terraform configuration. This
+                      flag can be set multiple times.
+
+  -var-file=foo       Set variables in the Terraform configuration from
+                      a file. If "terraform.tfvars" is present, it will be
+                      automatically loaded if this flag is not specified.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DiscoverCommand) Synopsis() string {
+	return "Find resources that aren't managed by Terraform"
+}
+
+const discoverImportHelp = `
+To manage these resources with Terraform, import them into the state, then
+write their configuration, named as in the commands below:
+`
@@ -0,0 +1,131 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+func testDiscoverProvider() *terraform.MockResourceProvider {
+	p := testProvider()
+	p.ResourcesReturn = []terraform.ResourceType{
+		terraform.ResourceType{Name: "test_instance", Listable: true},
+		terraform.ResourceType{Name: "test_other"},
+	}
+	p.ListResourcesReturn = []*terraform.InstanceState{
+		&terraform.InstanceState{ID: "bar"},
+		&terraform.InstanceState{ID: "i-1/a"},
+		&terraform.InstanceState{ID: "i-1.a"},
+	}
+
+	return p
+}
+
+func TestDiscover(t *testing.T) {
+	statePath := testStateFile(t, testState())
+
+	p := testDiscoverProvider()
+	ui := new(cli.MockUi)
+	c := &DiscoverCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-detailed-exitcode",
+		"-state", statePath,
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 2 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if p.ListResourcesInfo.Type != "test_instance" {
+		t.Fatalf("bad: %#v", p.ListResourcesInfo)
+	}
+
+	// The resource "bar" is in the state
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "Discovered 3 resources, 2 of which") {
+		t.Fatalf("bad:\n\n%s", output)
+	}
+	expected := strings.TrimSpace(`
+  terraform import test_instance.i-1_a i-1.a
+  terraform import test_instance.i-1_a-1 i-1/a
+`)
+	if !strings.Contains(output, expected) {
+		t.Fatalf("bad:\n\n%s", output)
+	}
+}
+
+func TestDiscover_allManaged(t *testing.T) {
+	statePath := testStateFile(t, testState())
+
+	p := testDiscoverProvider()
+	p.ListResourcesReturn = []*terraform.InstanceState{
+		&terraform.InstanceState{ID: "bar"},
+	}
+	ui := new(cli.MockUi)
+	c := &DiscoverCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-detailed-exitcode",
+		"-state", statePath,
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "All 1 resources discovered") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestDiscover_typeNotListable(t *testing.T) {
+	p := testDiscoverProvider()
+	ui := new(cli.MockUi)
+	c := &DiscoverCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-type", "test_other",
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	if p.ListResourcesCalled {
+		t.Fatal("ListResources should not be called")
+	}
+}
+
+func TestDiscover_notSupported(t *testing.T) {
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &DiscoverCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{testFixturePath("plan")}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "no resources can be discovered") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+}
@@ -0,0 +1,164 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/colorstring"
+)
+
+// resourceDrift is the difference, found by a refresh, between a managed
+// resource instance recorded in the state and the real resource.
+type resourceDrift struct {
+	Address string
+
+	// Deleted is true if the resource no longer exists.
+	Deleted bool
+
+	// Attributes are the attributes that changed, with their values
+	// before and after the refresh. Removed attributes have no value
+	// after.
+	Attributes map[string]*attrDrift
+}
+
+type attrDrift struct {
+	Before  string
+	After   string
+	Removed bool
+}
+
+type resourceDriftsByAddress []*resourceDrift
+
+func (s resourceDriftsByAddress) Len() int      { return len(s) }
+func (s resourceDriftsByAddress) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s resourceDriftsByAddress) Less(i, j int) bool {
+	return s[i].Address < s[j].Address
+}
+
+// stateDrift compares the state before a refresh with the refreshed state
+// and returns the managed resources that changed, sorted by address. Data
+// sources are read again on each refresh, so they aren't drift.
+func stateDrift(before, after *terraform.State) []*resourceDrift {
+	var result []*resourceDrift
+	if before == nil {
+		return result
+	}
+
+	for _, m := range before.Modules {
+		var modAfter *terraform.ModuleState
+		if after != nil {
+			modAfter = after.ModuleByPath(m.Path)
+		}
+
+		prefix := ""
+		if len(m.Path) > 1 {
+			prefix = "module." + strings.Join(m.Path[1:], ".module.") + "."
+		}
+
+		for name, rs := range m.Resources {
+			if strings.HasPrefix(name, "data.") {
+				continue
+			}
+			if rs.Primary == nil || rs.Primary.ID == "" {
+				continue
+			}
+
+			var is *terraform.InstanceState
+			if modAfter != nil {
+				if rsAfter, ok := modAfter.Resources[name]; ok {
+					is = rsAfter.Primary
+				}
+			}
+
+			if is == nil || is.ID == "" {
+				result = append(result, &resourceDrift{
+					Address: prefix + name,
+					Deleted: true,
+				})
+				continue
+			}
+
+			attrs := instanceAttrDrift(rs.Primary, is)
+			if len(attrs) > 0 {
+				result = append(result, &resourceDrift{
+					Address:    prefix + name,
+					Attributes: attrs,
+				})
+			}
+		}
+	}
+
+	sort.Sort(resourceDriftsByAddress(result))
+	return result
+}
+
+// instanceAttrDrift returns the attributes of an instance that differ
+// between before and after.
+func instanceAttrDrift(before, after *terraform.InstanceState) map[string]*attrDrift {
+	result := make(map[string]*attrDrift)
+	for k, v := range before.Attributes {
+		newV, ok := after.Attributes[k]
+		if !ok {
+			result[k] = &attrDrift{Before: v, Removed: true}
+		} else if newV != v {
+			result[k] = &attrDrift{Before: v, After: newV}
+		}
+	}
+	for k, v := range after.Attributes {
+		if _, ok := before.Attributes[k]; !ok {
+			result[k] = &attrDrift{After: v}
+		}
+	}
+
+	return result
+}
+
+// FormatDrift returns the drift of the resources in a form for output to
+// the user, in the same style as plans. The state has no record of which
+// attributes are secrets, so the values of the attributes are only shown
+// if values is set; otherwise they're shown as "<sensitive>".
+func FormatDrift(drift []*resourceDrift, c *colorstring.Colorize, values bool) string {
+	var buf bytes.Buffer
+	for _, d := range drift {
+		if d.Deleted {
+			buf.WriteString(c.Color(fmt.Sprintf(
+				"[red]- %s[reset] (deleted outside of Terraform)\n", d.Address)))
+			continue
+		}
+
+		buf.WriteString(c.Color(fmt.Sprintf("[yellow]~ %s\n", d.Address)))
+
+		keys := make([]string, 0, len(d.Attributes))
+		keyLen := 0
+		for k := range d.Attributes {
+			keys = append(keys, k)
+			if len(k) > keyLen {
+				keyLen = len(k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			a := d.Attributes[k]
+			var v string
+			switch {
+			case !values && a.Removed:
+				v = "<removed>"
+			case !values:
+				v = "<sensitive>"
+			case a.Removed:
+				v = fmt.Sprintf("%q => <removed>", a.Before)
+			default:
+				v = fmt.Sprintf("%q => %q", a.Before, a.After)
+			}
+			buf.WriteString(fmt.Sprintf(
+				"    %s:%s %s\n", k, strings.Repeat(" ", keyLen-len(k)), v))
+		}
+		buf.WriteString("\n")
+	}
+
+	return strings.TrimSpace(buf.String())
+}
@@ -0,0 +1,125 @@
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/colorstring"
+)
+
+func TestStateDrift(t *testing.T) {
+	before := testState()
+	before.Modules[0].Resources["test_instance.foo"].Primary.Attributes = map[string]string{
+		"ami":  "bar",
+		"tags": "a",
+	}
+	before.Modules[0].Resources["test_instance.gone"] = &terraform.ResourceState{
+		Type:    "test_instance",
+		Primary: &terraform.InstanceState{ID: "gone"},
+	}
+	before.Modules[0].Resources["test_instance.same"] = &terraform.ResourceState{
+		Type:    "test_instance",
+		Primary: &terraform.InstanceState{ID: "same"},
+	}
+	before.Modules[0].Resources["data.test_data.foo"] = &terraform.ResourceState{
+		Type: "test_data",
+		Primary: &terraform.InstanceState{
+			ID:         "foo",
+			Attributes: map[string]string{"value": "a"},
+		},
+	}
+	child := before.AddModule([]string{"root", "child"})
+	child.Resources["test_instance.bar"] = &terraform.ResourceState{
+		Type:    "test_instance",
+		Primary: &terraform.InstanceState{ID: "bar"},
+	}
+
+	after := before.DeepCopy()
+	root := after.RootModule()
+	root.Resources["test_instance.foo"].Primary.Attributes = map[string]string{
+		"ami":  "baz",
+		"size": "large",
+	}
+	delete(root.Resources, "test_instance.gone")
+	root.Resources["data.test_data.foo"].Primary.Attributes["value"] = "b"
+	after.ModuleByPath([]string{"root", "child"}).Resources["test_instance.bar"].Primary = nil
+
+	actual := stateDrift(before, after)
+	expected := []*resourceDrift{
+		&resourceDrift{Address: "module.child.test_instance.bar", Deleted: true},
+		&resourceDrift{
+			Address: "test_instance.foo",
+			Attributes: map[string]*attrDrift{
+				"ami":  &attrDrift{Before: "bar", After: "baz"},
+				"size": &attrDrift{After: "large"},
+				"tags": &attrDrift{Before: "a", Removed: true},
+			},
+		},
+		&resourceDrift{Address: "test_instance.gone", Deleted: true},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestStateDrift_none(t *testing.T) {
+	before := testState()
+	if drift := stateDrift(before, before.DeepCopy()); len(drift) != 0 {
+		t.Fatalf("bad: %#v", drift)
+	}
+}
+
+func TestFormatDrift(t *testing.T) {
+	drift := []*resourceDrift{
+		&resourceDrift{
+			Address: "test_instance.foo",
+			Attributes: map[string]*attrDrift{
+				"ami":      &attrDrift{Before: "bar", After: "baz"},
+				"tags.foo": &attrDrift{Before: "a", Removed: true},
+			},
+		},
+		&resourceDrift{Address: "test_instance.gone", Deleted: true},
+	}
+
+	actual := FormatDrift(drift, &colorstring.Colorize{
+		Colors:  colorstring.DefaultColors,
+		Disable: true,
+	}, true)
+	expected := strings.TrimSpace(`
+~ test_instance.foo
+    ami:      "bar" => "baz"
+    tags.foo: "a" => <removed>
+
+- test_instance.gone (deleted outside of Terraform)
+`)
+	if actual != expected {
+		t.Fatalf("expected:\n%s\n\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestFormatDrift_hideValues(t *testing.T) {
+	drift := []*resourceDrift{
+		&resourceDrift{
+			Address: "test_instance.foo",
+			Attributes: map[string]*attrDrift{
+				"password": &attrDrift{Before: "hunter2", After: "correct-horse"},
+				"tags.foo": &attrDrift{Before: "a", Removed: true},
+			},
+		},
+	}
+
+	actual := FormatDrift(drift, &colorstring.Colorize{
+		Colors:  colorstring.DefaultColors,
+		Disable: true,
+	}, false)
+	expected := strings.TrimSpace(`
+~ test_instance.foo
+    password: <sensitive>
+    tags.foo: <removed>
+`)
+	if actual != expected {
+		t.Fatalf("expected:\n%s\n\nactual:\n%s", expected, actual)
+	}
+}
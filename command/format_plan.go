@@ -21,6 +21,13 @@ type FormatPlanOpts struct {
 	// ModuleDepth is the depth of the modules to expand. By default this
 	// is zero which will not expand modules at all.
 	ModuleDepth int
+
+	// The changes to attributes holding JSON documents are shown in the
+	// structure of the documents, without the unchanged values. Full
+	// shows the unchanged values as well, and Compact shows each changed
+	// value on one line with its path instead.
+	Compact bool
+	Full    bool
 }
 
 // FormatPlan takes a plan and returns a
@@ -167,6 +174,11 @@ func formatPlanModuleExpand(
 				updateMsg = opts.Color.Color(" [yellow](attribute changed)")
 			}
 
+			if !attrDiff.Sensitive && !attrDiff.NewComputed && formatJSONAttr(
+				buf, attrK, keyLen, attrDiff.Old, v, oldValues, updateMsg, opts) {
+				continue
+			}
+
 			if oldValues {
 				var u string
 				if attrDiff.Sensitive {
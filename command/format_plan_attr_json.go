@@ -0,0 +1,284 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/colorstring"
+)
+
+// formatJSONAttr writes the change of an attribute holding a JSON document,
+// such as a policy, to buf. Rather than as two long strings, the change is
+// shown as the changes to the values in the document. If the attribute
+// is being created, oldValues is false and the document is shown
+// formatted. It returns false, writing nothing, if the attribute doesn't
+// hold a JSON object or array.
+func formatJSONAttr(
+	buf *bytes.Buffer, key string, keyLen int, old, new string,
+	oldValues bool, updateMsg string, opts *FormatPlanOpts) bool {
+	newDoc, ok := parseJSONAttr(new)
+	if !ok {
+		return false
+	}
+	var oldDoc interface{}
+	if oldValues {
+		if oldDoc, ok = parseJSONAttr(old); !ok {
+			return false
+		}
+	} else if opts.Compact {
+		return false
+	}
+
+	label := "(JSON)"
+	if oldValues && reflect.DeepEqual(oldDoc, newDoc) {
+		label = "(JSON, only the formatting changed)"
+	}
+	buf.WriteString(fmt.Sprintf(
+		"    %s:%s %s%s\n",
+		key, strings.Repeat(" ", keyLen-len(key)), label, updateMsg))
+
+	p := &jsonDiffPrinter{buf: buf, color: opts.Color, full: opts.Full}
+	switch {
+	case !oldValues:
+		p.line(0, " ", "", jsonIndent(newDoc, 0))
+	case opts.Compact:
+		jsonChanges("", oldDoc, newDoc, true, true, func(marker, path, v string) {
+			p.line(0, marker, "", fmt.Sprintf("%s: %s", path, v))
+		})
+	default:
+		p.change(0, "", oldDoc, newDoc, true, true)
+	}
+
+	return true
+}
+
+// parseJSONAttr parses the value of an attribute holding a JSON object or
+// array. Numbers are kept as they're written.
+func parseJSONAttr(v string) (interface{}, bool) {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "{") && !strings.HasPrefix(v, "[") {
+		return nil, false
+	}
+
+	dec := json.NewDecoder(strings.NewReader(v))
+	dec.UseNumber()
+	var result interface{}
+	if err := dec.Decode(&result); err != nil || dec.More() {
+		return nil, false
+	}
+
+	return result, true
+}
+
+// jsonDiffPrinter writes the changes between two JSON documents in their
+// structure, marking the values added, removed and changed. Unchanged
+// values are only shown if full is set, and counted otherwise.
+type jsonDiffPrinter struct {
+	buf   *bytes.Buffer
+	color *colorstring.Colorize
+	full  bool
+}
+
+// change writes the change of a value at the given depth. key is the key
+// of the value in its object, or empty for an array element. It returns
+// false, writing nothing, if the value is unchanged and full isn't set.
+func (p *jsonDiffPrinter) change(
+	depth int, key string, old, new interface{}, hasOld, hasNew bool) bool {
+	switch {
+	case !hasOld:
+		p.line(depth, "+", key, jsonIndent(new, depth))
+		return true
+	case !hasNew:
+		p.line(depth, "-", key, jsonIndent(old, depth))
+		return true
+	case reflect.DeepEqual(old, new):
+		if p.full {
+			p.line(depth, " ", key, jsonIndent(new, depth))
+		}
+		return p.full
+	}
+
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		p.line(depth, "~", key, "{")
+		keys := make(map[string]struct{})
+		for k := range oldMap {
+			keys[k] = struct{}{}
+		}
+		for k := range newMap {
+			keys[k] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		unchanged := 0
+		for _, k := range sorted {
+			o, hasO := oldMap[k]
+			n, hasN := newMap[k]
+			if !p.change(depth+1, k, o, n, hasO, hasN) {
+				unchanged++
+			}
+		}
+		p.unchanged(depth+1, unchanged)
+		p.line(depth, " ", "", "}")
+		return true
+	}
+
+	oldList, oldIsList := old.([]interface{})
+	newList, newIsList := new.([]interface{})
+	if oldIsList && newIsList {
+		p.line(depth, "~", key, "[")
+		unchanged := 0
+		for i := 0; i < len(oldList) || i < len(newList); i++ {
+			var o, n interface{}
+			if i < len(oldList) {
+				o = oldList[i]
+			}
+			if i < len(newList) {
+				n = newList[i]
+			}
+			if !p.change(depth+1, "", o, n, i < len(oldList), i < len(newList)) {
+				unchanged++
+			}
+		}
+		p.unchanged(depth+1, unchanged)
+		p.line(depth, " ", "", "]")
+		return true
+	}
+
+	p.line(depth, "~", key, fmt.Sprintf("%s => %s", jsonCompact(old), jsonCompact(new)))
+	return true
+}
+
+// unchanged writes how many unchanged values weren't shown.
+func (p *jsonDiffPrinter) unchanged(depth, n int) {
+	if n > 0 {
+		p.line(depth, " ", "", fmt.Sprintf("# (%d unchanged)", n))
+	}
+}
+
+// line writes a line of the document at the given depth, starting with
+// the marker of the change.
+func (p *jsonDiffPrinter) line(depth int, marker, key, v string) {
+	switch marker {
+	case "+":
+		marker = p.color.Color("[green]+[reset]")
+	case "-":
+		marker = p.color.Color("[red]-[reset]")
+	case "~":
+		marker = p.color.Color("[yellow]~[reset]")
+	}
+	if key != "" {
+		v = fmt.Sprintf("%q: %s", key, v)
+	}
+
+	p.buf.WriteString(fmt.Sprintf(
+		"      %s%s %s\n", strings.Repeat("    ", depth), marker, v))
+}
+
+// jsonChanges calls f with each value that differs between two JSON
+// documents, with the path of the value, such as "Statement[0].Action",
+// and the value or its change.
+func jsonChanges(
+	path string, old, new interface{}, hasOld, hasNew bool,
+	f func(marker, path, v string)) {
+	switch {
+	case !hasOld:
+		f("+", path, jsonCompact(new))
+		return
+	case !hasNew:
+		f("-", path, jsonCompact(old))
+		return
+	case reflect.DeepEqual(old, new):
+		return
+	}
+
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make([]string, 0, len(oldMap)+len(newMap))
+		for k := range oldMap {
+			keys = append(keys, k)
+		}
+		for k := range newMap {
+			if _, ok := oldMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			o, hasO := oldMap[k]
+			n, hasN := newMap[k]
+			jsonChanges(jsonPath(path, k), o, n, hasO, hasN, f)
+		}
+		return
+	}
+
+	oldList, oldIsList := old.([]interface{})
+	newList, newIsList := new.([]interface{})
+	if oldIsList && newIsList {
+		for i := 0; i < len(oldList) || i < len(newList); i++ {
+			var o, n interface{}
+			if i < len(oldList) {
+				o = oldList[i]
+			}
+			if i < len(newList) {
+				n = newList[i]
+			}
+			jsonChanges(fmt.Sprintf("%s[%d]", path, i), o, n,
+				i < len(oldList), i < len(newList), f)
+		}
+		return
+	}
+
+	f("~", path, fmt.Sprintf("%s => %s", jsonCompact(old), jsonCompact(new)))
+}
+
+// jsonPathKey matches the keys that can be written in a path without
+// quoting them.
+var jsonPathKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// jsonPath returns the path of the member of the object at path with the
+// given key.
+func jsonPath(path, key string) string {
+	if !jsonPathKey.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// jsonCompact returns the value as JSON on one line.
+func jsonCompact(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+
+	return string(data)
+}
+
+// jsonIndent returns the value as indented JSON, for a line of a
+// jsonDiffPrinter at the given depth.
+func jsonIndent(v interface{}, depth int) string {
+	data, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+
+	return strings.Replace(
+		string(data), "\n", "\n      "+strings.Repeat("    ", depth)+"  ", -1)
+}
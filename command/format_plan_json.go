@@ -0,0 +1,203 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// planJSON is the machine readable representation of a plan written by
+// FormatPlanJSON.
+type planJSON struct {
+	Summary planJSONSummary   `json:"summary"`
+	Changes []*planJSONChange `json:"resource_changes"`
+}
+
+type planJSONSummary struct {
+	Add     int `json:"add"`
+	Change  int `json:"change"`
+	Destroy int `json:"destroy"`
+}
+
+// planJSONChange is the planned change of a single resource instance.
+type planJSONChange struct {
+	Address  string `json:"address"`
+	Module   string `json:"module,omitempty"`
+	Mode     string `json:"mode"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+
+	// Action is one of "create", "read", "update", "replace" or "delete".
+	Action  string `json:"action"`
+	Tainted bool   `json:"tainted,omitempty"`
+	Deposed bool   `json:"deposed,omitempty"`
+
+	Attributes map[string]*planJSONAttr `json:"attributes,omitempty"`
+}
+
+type planJSONChangesByAddress []*planJSONChange
+
+func (s planJSONChangesByAddress) Len() int      { return len(s) }
+func (s planJSONChangesByAddress) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s planJSONChangesByAddress) Less(i, j int) bool {
+	return s[i].Address < s[j].Address
+}
+
+// planJSONAttr is the planned change of a single attribute. The values of
+// sensitive attributes are never included.
+type planJSONAttr struct {
+	Before      string `json:"before,omitempty"`
+	After       string `json:"after,omitempty"`
+	Computed    bool   `json:"computed,omitempty"`
+	Removed     bool   `json:"removed,omitempty"`
+	RequiresNew bool   `json:"requires_new,omitempty"`
+	Sensitive   bool   `json:"sensitive,omitempty"`
+}
+
+// FormatPlanJSON returns the changes in a plan as indented JSON, so that
+// they can be inspected by other tools.
+func FormatPlanJSON(plan *terraform.Plan) (string, error) {
+	result := &planJSON{Changes: make([]*planJSONChange, 0)}
+
+	if plan.Diff != nil {
+		for _, m := range plan.Diff.Modules {
+			result.Changes = append(result.Changes, planJSONModuleChanges(plan, m)...)
+		}
+	}
+
+	sort.Sort(planJSONChangesByAddress(result.Changes))
+
+	for _, c := range result.Changes {
+		switch c.Action {
+		case "create":
+			result.Summary.Add++
+		case "update":
+			result.Summary.Change++
+		case "replace":
+			result.Summary.Add++
+			result.Summary.Destroy++
+		case "delete":
+			result.Summary.Destroy++
+		}
+	}
+
+	data, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode plan: %s", err)
+	}
+
+	return string(data), nil
+}
+
+func planJSONModuleChanges(plan *terraform.Plan, m *terraform.ModuleDiff) []*planJSONChange {
+	var moduleName string
+	if !m.IsRoot() {
+		moduleName = fmt.Sprintf("module.%s", strings.Join(m.Path[1:], "."))
+	}
+
+	var result []*planJSONChange
+	for name, rdiff := range m.Resources {
+		if rdiff.Empty() {
+			continue
+		}
+
+		change := &planJSONChange{
+			Address: name,
+			Module:  moduleName,
+			Mode:    "managed",
+			Tainted: rdiff.DestroyTainted,
+			Deposed: rdiff.DestroyDeposed,
+		}
+		if moduleName != "" {
+			change.Address = moduleName + "." + name
+		}
+
+		// Resource diffs are keyed by "[data.]TYPE.NAME[.INDEX]"
+		parts := strings.Split(name, ".")
+		if parts[0] == "data" {
+			change.Mode = "data"
+			parts = parts[1:]
+		}
+		if len(parts) >= 2 {
+			change.Type = parts[0]
+			change.Name = parts[1]
+		}
+		change.Provider = planJSONProvider(plan, m.Path, change)
+
+		switch rdiff.ChangeType() {
+		case terraform.DiffCreate:
+			change.Action = "create"
+			if change.Mode == "data" {
+				change.Action = "read"
+			}
+		case terraform.DiffDestroyCreate:
+			change.Action = "replace"
+		case terraform.DiffDestroy:
+			change.Action = "delete"
+		default:
+			change.Action = "update"
+		}
+
+		for k, a := range rdiff.Attributes {
+			if change.Attributes == nil {
+				change.Attributes = make(map[string]*planJSONAttr)
+			}
+
+			attr := &planJSONAttr{
+				Computed:    a.NewComputed,
+				Removed:     a.NewRemoved,
+				RequiresNew: a.RequiresNew,
+				Sensitive:   a.Sensitive,
+			}
+			if !a.Sensitive {
+				attr.Before = a.Old
+				if !a.NewComputed {
+					attr.After = a.New
+				}
+			}
+
+			change.Attributes[k] = attr
+		}
+
+		result = append(result, change)
+	}
+
+	return result
+}
+
+// planJSONProvider returns the name of the provider for a resource change,
+// using the provider set in the configuration if there is one.
+func planJSONProvider(plan *terraform.Plan, path []string, c *planJSONChange) string {
+	var alias string
+	if plan.Module != nil {
+		tree := plan.Module
+		for _, name := range path[1:] {
+			if tree == nil {
+				break
+			}
+
+			tree = tree.Children()[name]
+		}
+
+		if tree != nil && tree.Config() != nil {
+			mode := config.ManagedResourceMode
+			if c.Mode == "data" {
+				mode = config.DataResourceMode
+			}
+
+			for _, r := range tree.Config().Resources {
+				if r.Mode == mode && r.Type == c.Type && r.Name == c.Name {
+					alias = r.Provider
+					break
+				}
+			}
+		}
+	}
+
+	return resourceProviderName(c.Type, alias)
+}
@@ -168,3 +168,143 @@ func TestFormatPlan_nestedDataSource(t *testing.T) {
 		t.Fatalf("expected:\n\n%s\n\ngot:\n\n%s", expected, actual)
 	}
 }
+
+func TestFormatPlan_jsonAttr(t *testing.T) {
+	old := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+	new := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"*"},{"Effect":"Deny","Action":"s3:DeleteBucket","Resource":"*"}]}`
+
+	cases := []struct {
+		Compact, Full bool
+		Expected      string
+	}{
+		{
+			false, false, `
+~ aws_iam_policy.foo
+    policy: (JSON)
+      ~ {
+          ~ "Statement": [
+              ~ {
+                  ~ "Action": "s3:GetObject" => "s3:*"
+                    # (2 unchanged)
+                }
+              + {
+                    "Action": "s3:DeleteBucket",
+                    "Effect": "Deny",
+                    "Resource": "*"
+                }
+            ]
+            # (1 unchanged)
+        }
+`,
+		},
+		{
+			true, false, `
+~ aws_iam_policy.foo
+    policy: (JSON)
+      ~ Statement[0].Action: "s3:GetObject" => "s3:*"
+      + Statement[1]: {"Action":"s3:DeleteBucket","Effect":"Deny","Resource":"*"}
+`,
+		},
+		{
+			false, true, `
+~ aws_iam_policy.foo
+    policy: (JSON)
+      ~ {
+          ~ "Statement": [
+              ~ {
+                  ~ "Action": "s3:GetObject" => "s3:*"
+                    "Effect": "Allow"
+                    "Resource": "*"
+                }
+              + {
+                    "Action": "s3:DeleteBucket",
+                    "Effect": "Deny",
+                    "Resource": "*"
+                }
+            ]
+            "Version": "2012-10-17"
+        }
+`,
+		},
+	}
+
+	for i, tc := range cases {
+		plan := &terraform.Plan{
+			Diff: &terraform.Diff{
+				Modules: []*terraform.ModuleDiff{
+					&terraform.ModuleDiff{
+						Path: []string{"root"},
+						Resources: map[string]*terraform.InstanceDiff{
+							"aws_iam_policy.foo": &terraform.InstanceDiff{
+								Attributes: map[string]*terraform.ResourceAttrDiff{
+									"policy": &terraform.ResourceAttrDiff{
+										Old: old,
+										New: new,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		opts := &FormatPlanOpts{
+			Plan: plan,
+			Color: &colorstring.Colorize{
+				Colors:  colorstring.DefaultColors,
+				Disable: true,
+			},
+			Compact: tc.Compact,
+			Full:    tc.Full,
+		}
+
+		actual := FormatPlan(opts)
+		expected := strings.TrimSpace(tc.Expected)
+		if actual != expected {
+			t.Fatalf("%d: expected:\n\n%s\n\ngot:\n\n%s", i, expected, actual)
+		}
+	}
+}
+
+func TestFormatPlan_jsonAttrFormatting(t *testing.T) {
+	plan := &terraform.Plan{
+		Diff: &terraform.Diff{
+			Modules: []*terraform.ModuleDiff{
+				&terraform.ModuleDiff{
+					Path: []string{"root"},
+					Resources: map[string]*terraform.InstanceDiff{
+						"aws_instance.foo": &terraform.InstanceDiff{
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"tags": &terraform.ResourceAttrDiff{
+									Old: `{"a": 1}`,
+									New: `{"a":1}`,
+								},
+								"user_data": &terraform.ResourceAttrDiff{
+									Old: "{not json",
+									New: "echo hi",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	opts := &FormatPlanOpts{
+		Plan: plan,
+		Color: &colorstring.Colorize{
+			Colors:  colorstring.DefaultColors,
+			Disable: true,
+		},
+	}
+
+	actual := FormatPlan(opts)
+	expected := strings.TrimSpace(`
+~ aws_instance.foo
+    tags:      (JSON, only the formatting changed)
+    user_data: "{not json" => "echo hi"
+`)
+	if actual != expected {
+		t.Fatalf("expected:\n\n%s\n\ngot:\n\n%s", expected, actual)
+	}
+}
@@ -58,9 +58,15 @@ func FormatState(opts *FormatStateOpts) string {
 		}
 		sort.Strings(ks)
 
-		// Output each output k/v pair
+		// Output each output k/v pair. The values of sensitive outputs
+		// are only stored in the state, never shown.
 		for _, k := range ks {
 			v := m.Outputs[k]
+			if v.Sensitive {
+				buf.WriteString(fmt.Sprintf("%s = <sensitive>\n", k))
+				continue
+			}
+
 			switch output := v.Value.(type) {
 			case string:
 				buf.WriteString(fmt.Sprintf("%s = %s", k, output))
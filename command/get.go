@@ -22,6 +22,7 @@ func (c *GetCommand) Run(args []string) int {
 
 	cmdFlags := flag.NewFlagSet("get", flag.ContinueOnError)
 	cmdFlags.BoolVar(&update, "update", false, "update")
+	cmdFlags.StringVar(&c.Meta.moduleMirror, "module-mirror", "", "mirror")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
@@ -74,6 +75,9 @@ Usage: terraform get [options] PATH
 
 Options:
 
+  -module-mirror=dir  Download modules from the given directory or HTTP(S)
+                      URL instead of from their sources.
+
   -update=false       If true, modules already downloaded will be checked
                       for updates and updated if necessary.
 
@@ -2,6 +2,7 @@ package command
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -34,6 +35,34 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGet_dataDirEnv(t *testing.T) {
+	td := tempDir(t)
+	defer os.RemoveAll(td)
+
+	old := os.Getenv(DataDirEnvVar)
+	defer os.Setenv(DataDirEnvVar, old)
+	os.Setenv(DataDirEnvVar, td)
+
+	ui := new(cli.MockUi)
+	c := &GetCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		testFixturePath("get"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	if _, err := os.Stat(filepath.Join(td, "modules")); err != nil {
+		t.Fatalf("modules should be in the data dir: %s", err)
+	}
+}
+
 func TestGet_multipleArgs(t *testing.T) {
 	ui := new(cli.MockUi)
 	c := &GetCommand{
@@ -51,8 +51,9 @@ func (c *GraphCommand) Run(args []string) int {
 	}
 
 	ctx, planFile, err := c.Context(contextOpts{
-		Path:      path,
-		StatePath: "",
+		Path:          path,
+		StatePath:     "",
+		StateReadOnly: true,
 	})
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Error loading Terraform: %s", err))
@@ -86,6 +87,16 @@ func (c *GraphCommand) Run(args []string) int {
 		return 1
 	}
 
+	// The graph is still shown when it has cycles, so that they can be
+	// found in it, but explain them since the operation would fail.
+	if len(g.Cycles()) > 0 {
+		if err := g.Validate(); err != nil {
+			c.Ui.Error(fmt.Sprintf(
+				"Warning: the graph has cycles, so the operation it is for "+
+					"would fail. Use -draw-cycles to highlight them.\n\n%s", err))
+		}
+	}
+
 	graphStr, err := terraform.GraphDot(g, &dag.DotOpts{
 		DrawCycles: drawCycles,
 		MaxDepth:   moduleDepth,
@@ -31,6 +31,34 @@ func TestGraph(t *testing.T) {
 	}
 }
 
+func TestGraph_cycle(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		testFixturePath("graph-cycle"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "test_instance.a") {
+		t.Fatalf("doesn't look like digraph: %s", output)
+	}
+
+	errOutput := ui.ErrorWriter.String()
+	if !strings.Contains(errOutput, "test_instance.a -> test_instance.b") ||
+		!strings.Contains(errOutput, "test_instance.b -> test_instance.a") {
+		t.Fatalf("cycle should be explained: %s", errOutput)
+	}
+}
+
 func TestGraph_multipleArgs(t *testing.T) {
 	ui := new(cli.MockUi)
 	c := &GraphCommand{
@@ -0,0 +1,199 @@
+package command
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// JSONHook is the Hook used instead of UiHook when a command is asked for
+// JSON output. Rather than human readable progress messages it writes a
+// JSON event for every resource level step of an operation.
+type JSONHook struct {
+	terraform.NilHook
+
+	Ui *JSONUi
+
+	// Progress, if set, counts the changes of an apply so that the events
+	// can tell how many are left.
+	Progress *applyProgress
+
+	l      sync.Mutex
+	starts map[string]time.Time
+}
+
+func (h *JSONHook) PreApply(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState,
+	d *terraform.InstanceDiff) (terraform.HookAction, error) {
+	id := n.HumanId()
+	action := applyAction(s, d)
+	h.Progress.Start(n, action)
+
+	h.l.Lock()
+	if h.starts == nil {
+		h.starts = make(map[string]time.Time)
+	}
+	h.starts[id] = time.Now()
+	h.l.Unlock()
+
+	// Set a timer to show an operation is still happening
+	time.AfterFunc(periodicUiTimer, func() { h.stillApplying(id) })
+
+	h.Ui.Event(&JSONEvent{
+		Type: "apply_start",
+		Data: map[string]interface{}{
+			"resource": id,
+			"action":   action,
+		},
+	})
+
+	return terraform.HookActionContinue, nil
+}
+
+// stillApplying writes an apply_progress event for a resource that is
+// still being changed, and checks again later.
+func (h *JSONHook) stillApplying(id string) {
+	h.l.Lock()
+	start, ok := h.starts[id]
+	h.l.Unlock()
+
+	// If the resource is out of the map it means we're done with it
+	if !ok {
+		return
+	}
+
+	h.Ui.Event(&JSONEvent{
+		Type: "apply_progress",
+		Data: map[string]interface{}{
+			"resource":        id,
+			"elapsed_seconds": time.Since(start).Seconds(),
+		},
+	})
+
+	time.AfterFunc(periodicUiTimer, func() { h.stillApplying(id) })
+}
+
+func (h *JSONHook) PostApply(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState,
+	applyerr error) (terraform.HookAction, error) {
+	id := n.HumanId()
+	remaining := h.Progress.Complete(n, applyerr)
+
+	h.l.Lock()
+	start, ok := h.starts[id]
+	delete(h.starts, id)
+	h.l.Unlock()
+
+	data := map[string]interface{}{
+		"resource": id,
+	}
+	if ok {
+		data["elapsed_seconds"] = time.Since(start).Seconds()
+	}
+	if s != nil && s.ID != "" {
+		data["id"] = s.ID
+	}
+	if remaining >= 0 {
+		data["remaining"] = remaining
+	}
+
+	e := &JSONEvent{Type: "apply_complete", Data: data}
+	if applyerr != nil {
+		e.Type = "apply_errored"
+		e.Level = "error"
+		e.Message = applyerr.Error()
+	}
+	h.Ui.Event(e)
+
+	return terraform.HookActionContinue, nil
+}
+
+func (h *JSONHook) PostDiff(
+	n *terraform.InstanceInfo,
+	d *terraform.InstanceDiff) (terraform.HookAction, error) {
+	if d.Empty() {
+		return terraform.HookActionContinue, nil
+	}
+
+	var action string
+	switch d.ChangeType() {
+	case terraform.DiffCreate:
+		action = "create"
+	case terraform.DiffUpdate:
+		action = "modify"
+	case terraform.DiffDestroy:
+		action = "destroy"
+	case terraform.DiffDestroyCreate:
+		action = "replace"
+	default:
+		return terraform.HookActionContinue, nil
+	}
+
+	h.Ui.Event(&JSONEvent{
+		Type: "planned_change",
+		Data: map[string]interface{}{
+			"resource": n.HumanId(),
+			"action":   action,
+		},
+	})
+
+	return terraform.HookActionContinue, nil
+}
+
+func (h *JSONHook) PreProvision(
+	n *terraform.InstanceInfo,
+	provId string) (terraform.HookAction, error) {
+	h.Ui.Event(&JSONEvent{
+		Type: "provision_start",
+		Data: map[string]interface{}{
+			"resource":    n.HumanId(),
+			"provisioner": provId,
+		},
+	})
+
+	return terraform.HookActionContinue, nil
+}
+
+func (h *JSONHook) ProvisionOutput(
+	n *terraform.InstanceInfo,
+	provId string,
+	msg string) {
+	h.Ui.Event(&JSONEvent{
+		Type:    "provision_output",
+		Message: msg,
+		Data: map[string]interface{}{
+			"resource":    n.HumanId(),
+			"provisioner": provId,
+		},
+	})
+}
+
+func (h *JSONHook) PreRefresh(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState) (terraform.HookAction, error) {
+	data := map[string]interface{}{
+		"resource": n.HumanId(),
+	}
+	if s.ID != "" {
+		data["id"] = s.ID
+	}
+
+	h.Ui.Event(&JSONEvent{Type: "refresh_start", Data: data})
+	return terraform.HookActionContinue, nil
+}
+
+func (h *JSONHook) PostRefresh(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState) (terraform.HookAction, error) {
+	h.Ui.Event(&JSONEvent{
+		Type: "refresh_complete",
+		Data: map[string]interface{}{
+			"resource": n.HumanId(),
+		},
+	})
+
+	return terraform.HookActionContinue, nil
+}
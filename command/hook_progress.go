@@ -0,0 +1,269 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// applyProgress counts the resource changes made by an apply, so that the
+// UI hooks can tell how many are left. It's shared by the hooks of a
+// command, which sets the plan being applied with SetPlan before applying
+// it. Nothing is counted until then. Its methods can be called on a nil
+// *applyProgress, which counts nothing.
+//
+// If a history is set with SetHistory, how long the changes take is
+// recorded in it, and used to estimate how long the apply has left.
+type applyProgress struct {
+	l       sync.Mutex
+	start   time.Time
+	total   int
+	done    int
+	history *applyHistory
+	pending []applyChange
+	running map[string]applyRunning
+
+	// busy is the total time spent on the changes done, which is longer
+	// than the time since the start when changes are made concurrently.
+	busy time.Duration
+}
+
+// applyChange is a resource change of an apply. Action is "create",
+// "modify" or "destroy".
+type applyChange struct {
+	Action string
+	Id     string
+	Type   string
+}
+
+// applyRunning is a resource change in progress.
+type applyRunning struct {
+	applyChange
+	Start time.Time
+}
+
+// SetPlan starts counting the changes made to apply the given plan.
+func (p *applyProgress) SetPlan(plan *terraform.Plan) {
+	if p == nil {
+		return
+	}
+
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.start = time.Now()
+	p.pending = planChanges(plan)
+	p.total = len(p.pending)
+	p.done = 0
+	p.running = make(map[string]applyRunning)
+	p.busy = 0
+}
+
+// SetHistory sets the history to record the durations of the changes in
+// and to estimate the time left from.
+func (p *applyProgress) SetHistory(h *applyHistory) {
+	if p == nil {
+		return
+	}
+
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.history = h
+}
+
+// Start records that the given action on a resource started.
+func (p *applyProgress) Start(n *terraform.InstanceInfo, action string) {
+	if p == nil {
+		return
+	}
+
+	p.l.Lock()
+	defer p.l.Unlock()
+	if p.start.IsZero() {
+		return
+	}
+
+	id := n.HumanId()
+	p.running[id] = applyRunning{
+		applyChange: applyChange{Action: action, Id: id, Type: n.Type},
+		Start:       time.Now(),
+	}
+
+	// The planned change is no longer pending. The action is only a guess
+	// for planned changes, so a change of the resource with another
+	// action is taken if there's none with the same.
+	found := -1
+	for i, c := range p.pending {
+		if c.Id == id && (found < 0 || c.Action == action) {
+			found = i
+		}
+	}
+	if found >= 0 {
+		p.pending = append(p.pending[:found], p.pending[found+1:]...)
+	}
+}
+
+// Complete records that a change of the given resource was made, or
+// failed if err is set, and returns how many are left, or -1 if the plan
+// isn't known.
+func (p *applyProgress) Complete(n *terraform.InstanceInfo, err error) int {
+	if p == nil {
+		return -1
+	}
+
+	p.l.Lock()
+	defer p.l.Unlock()
+	if p.start.IsZero() {
+		return -1
+	}
+
+	id := n.HumanId()
+	if r, ok := p.running[id]; ok {
+		delete(p.running, id)
+		d := time.Since(r.Start)
+		p.busy += d
+		if err == nil && p.history != nil {
+			p.history.Record(r.Action, id, r.Type, d)
+		}
+	}
+
+	p.done++
+	if p.done > p.total {
+		p.total = p.done
+	}
+	return p.total - p.done
+}
+
+// Counts returns how many changes were made, how many there are in total,
+// and how long ago the apply started. The total is -1 if the plan isn't
+// known.
+func (p *applyProgress) Counts() (done, total int, elapsed time.Duration) {
+	if p == nil {
+		return 0, -1, 0
+	}
+
+	p.l.Lock()
+	defer p.l.Unlock()
+	if p.start.IsZero() {
+		return p.done, -1, 0
+	}
+	return p.done, p.total, time.Since(p.start)
+}
+
+// Estimate returns how much longer the apply is expected to take, from
+// the recorded durations of the changes left, and false if it can't be
+// told because nothing was recorded yet.
+func (p *applyProgress) Estimate() (time.Duration, bool) {
+	if p == nil {
+		return 0, false
+	}
+
+	p.l.Lock()
+	defer p.l.Unlock()
+	if p.start.IsZero() {
+		return 0, false
+	}
+	mean, ok := p.history.Mean()
+	if !ok {
+		return 0, false
+	}
+	estimate := func(c applyChange) time.Duration {
+		if d, ok := p.history.Estimate(c.Action, c.Id, c.Type); ok {
+			return d
+		}
+		return mean
+	}
+
+	now := time.Now()
+	var left time.Duration
+	for _, c := range p.pending {
+		left += estimate(c)
+	}
+	busy := p.busy
+	for _, r := range p.running {
+		elapsed := now.Sub(r.Start)
+		busy += elapsed
+		if d := estimate(r.applyChange) - elapsed; d > 0 {
+			left += d
+		}
+	}
+
+	// Changes are made concurrently, so the time left is divided by how
+	// many changes were made at once on average so far.
+	concurrency := 1.0
+	if elapsed := now.Sub(p.start); elapsed > 0 {
+		if c := busy.Seconds() / elapsed.Seconds(); c > concurrency {
+			concurrency = c
+		}
+	}
+
+	return time.Duration(float64(left) / concurrency), true
+}
+
+// applyAction returns the action, "create", "modify" or "destroy", of the
+// change of a resource with the given state and diff.
+func applyAction(s *terraform.InstanceState, d *terraform.InstanceDiff) string {
+	switch {
+	case d.Destroy:
+		return "destroy"
+	case s.ID == "":
+		return "create"
+	default:
+		return "modify"
+	}
+}
+
+// planChanges returns the resource changes made to apply the plan, as
+// seen by Hook.PreApply. Replacing a resource takes two changes, and
+// reading a data source takes none. The actions are a guess, since the
+// plan doesn't tell if a resource that is updated exists.
+func planChanges(plan *terraform.Plan) []applyChange {
+	if plan == nil || plan.Diff == nil {
+		return nil
+	}
+
+	var result []applyChange
+	for _, m := range plan.Diff.Modules {
+		prefix := ""
+		if len(m.Path) > 1 {
+			prefix = fmt.Sprintf("module.%s.", strings.Join(m.Path[1:], "."))
+		}
+
+		keys := make([]string, 0, len(m.Resources))
+		for k := range m.Resources {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, name := range keys {
+			d := m.Resources[name]
+			if d.Empty() || strings.HasPrefix(name, "data.") {
+				continue
+			}
+
+			c := applyChange{
+				Id:   prefix + name,
+				Type: strings.SplitN(name, ".", 2)[0],
+			}
+			switch d.ChangeType() {
+			case terraform.DiffDestroyCreate:
+				destroy := c
+				destroy.Action = "destroy"
+				result = append(result, destroy)
+				c.Action = "create"
+			case terraform.DiffDestroy:
+				c.Action = "destroy"
+			case terraform.DiffCreate:
+				c.Action = "create"
+			default:
+				c.Action = "modify"
+			}
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,265 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+	"github.com/mitchellh/colorstring"
+)
+
+func testProgressPlan() *terraform.Plan {
+	return &terraform.Plan{
+		Diff: &terraform.Diff{
+			Modules: []*terraform.ModuleDiff{
+				&terraform.ModuleDiff{
+					Path: []string{"root"},
+					Resources: map[string]*terraform.InstanceDiff{
+						"aws_instance.foo": &terraform.InstanceDiff{
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"ami": &terraform.ResourceAttrDiff{New: "bar"},
+							},
+						},
+						"aws_instance.bar": &terraform.InstanceDiff{
+							Destroy: true,
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"ami": &terraform.ResourceAttrDiff{
+									Old:         "foo",
+									New:         "bar",
+									RequiresNew: true,
+								},
+							},
+						},
+						"data.aws_ami.foo": &terraform.InstanceDiff{
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"id": &terraform.ResourceAttrDiff{NewComputed: true},
+							},
+						},
+						"aws_instance.baz": &terraform.InstanceDiff{},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPlanChanges(t *testing.T) {
+	actual := planChanges(testProgressPlan())
+	expected := []applyChange{
+		{Action: "destroy", Id: "aws_instance.bar", Type: "aws_instance"},
+		{Action: "create", Id: "aws_instance.bar", Type: "aws_instance"},
+		{Action: "modify", Id: "aws_instance.foo", Type: "aws_instance"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+	if actual := planChanges(nil); len(actual) != 0 {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestApplyProgress(t *testing.T) {
+	info := &terraform.InstanceInfo{Id: "aws_instance.foo", Type: "aws_instance"}
+
+	var nilProgress *applyProgress
+	if n := nilProgress.Complete(info, nil); n != -1 {
+		t.Fatalf("bad: %d", n)
+	}
+
+	p := new(applyProgress)
+	if n := p.Complete(info, nil); n != -1 {
+		t.Fatalf("bad: %d", n)
+	}
+
+	p.SetPlan(testProgressPlan())
+	for _, expected := range []int{2, 1, 0, 0} {
+		if n := p.Complete(info, nil); n != expected {
+			t.Fatalf("expected %d, got %d", expected, n)
+		}
+	}
+	if done, total, _ := p.Counts(); done != 4 || total != 4 {
+		t.Fatalf("bad: %d, %d", done, total)
+	}
+}
+
+func TestApplyProgress_estimate(t *testing.T) {
+	p := new(applyProgress)
+	p.SetPlan(testProgressPlan())
+	if _, ok := p.Estimate(); ok {
+		t.Fatal("should have no estimate without a history")
+	}
+
+	history := &applyHistory{}
+	p.SetHistory(history)
+	if _, ok := p.Estimate(); ok {
+		t.Fatal("should have no estimate with an empty history")
+	}
+
+	history.Durations = map[string]float64{
+		"destroy aws_instance.bar": 10,
+		"create aws_instance":      20,
+		"modify aws_instance.baz":  90,
+	}
+
+	// The change of aws_instance.foo has no recorded duration, so it's
+	// estimated with the mean of the resources
+	left, ok := p.Estimate()
+	if !ok {
+		t.Fatal("should have an estimate")
+	}
+	if left < 79*time.Second || left > 80*time.Second {
+		t.Fatalf("bad: %s", left)
+	}
+
+	// A change that completes is recorded in the history
+	info := &terraform.InstanceInfo{Id: "aws_instance.foo", Type: "aws_instance"}
+	p.Start(info, "modify")
+	p.Complete(info, nil)
+	if _, ok := history.Durations["modify aws_instance.foo"]; !ok {
+		t.Fatalf("bad: %#v", history.Durations)
+	}
+	if _, ok := history.Durations["modify aws_instance"]; !ok {
+		t.Fatalf("bad: %#v", history.Durations)
+	}
+
+	// A change that fails isn't
+	bar := &terraform.InstanceInfo{Id: "aws_instance.bar", Type: "aws_instance"}
+	p.Start(bar, "create")
+	p.Complete(bar, fmt.Errorf("failed"))
+	if _, ok := history.Durations["create aws_instance.bar"]; ok {
+		t.Fatalf("bad: %#v", history.Durations)
+	}
+}
+
+func TestApplyHistory(t *testing.T) {
+	td := tempDir(t)
+	if err := os.MkdirAll(td, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+	path := filepath.Join(td, ApplyHistoryFilename)
+
+	history, err := readApplyHistory(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := history.Mean(); ok {
+		t.Fatal("should have no mean")
+	}
+
+	history.Record("create", "aws_instance.foo", "aws_instance", 10*time.Second)
+	history.Record("create", "aws_instance.foo", "aws_instance", 20*time.Second)
+	history.Record("create", "aws_instance.bar", "aws_instance", 40*time.Second)
+	if err := writeApplyHistory(path, history); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	history, err = readApplyHistory(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	cases := []struct {
+		Id       string
+		Type     string
+		Expected time.Duration
+		Found    bool
+	}{
+		{"aws_instance.foo", "aws_instance", 15 * time.Second, true},
+		{"aws_instance.baz", "aws_instance", 27500 * time.Millisecond, true},
+		{"aws_eip.foo", "aws_eip", 0, false},
+	}
+	for _, tc := range cases {
+		actual, ok := history.Estimate("create", tc.Id, tc.Type)
+		if actual != tc.Expected || ok != tc.Found {
+			t.Fatalf("%s: bad: %s, %t", tc.Id, actual, ok)
+		}
+	}
+	if mean, _ := history.Mean(); mean != 27500*time.Millisecond {
+		t.Fatalf("bad: %s", mean)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	cases := []struct {
+		Done, Total int
+		Expected    string
+	}{
+		{0, 4, "[>                   ]"},
+		{1, 4, "[=====>              ]"},
+		{4, 4, "[====================]"},
+		{0, 0, "[====================]"},
+	}
+	for _, tc := range cases {
+		if actual := progressBar(tc.Done, tc.Total); actual != tc.Expected {
+			t.Fatalf("%d of %d: bad: %q", tc.Done, tc.Total, actual)
+		}
+	}
+}
+
+func TestUiHook_remaining(t *testing.T) {
+	ui := new(cli.MockUi)
+	progress := new(applyProgress)
+	progress.SetPlan(testProgressPlan())
+	h := &UiHook{
+		Colorize: &colorstring.Colorize{Colors: colorstring.DefaultColors, Disable: true},
+		Ui:       ui,
+		Progress: progress,
+	}
+
+	info := &terraform.InstanceInfo{Id: "aws_instance.foo", Type: "aws_instance"}
+	h.PreApply(info, new(terraform.InstanceState), new(terraform.InstanceDiff))
+	h.PostApply(info, &terraform.InstanceState{ID: "i-123"}, nil)
+
+	expected := "aws_instance.foo: Creation complete after 0s (2 remaining)"
+	if actual := ui.OutputWriter.String(); !strings.Contains(actual, expected) {
+		t.Fatalf("bad:\n\n%s", actual)
+	}
+}
+
+func TestUiHook_compact(t *testing.T) {
+	ui := new(cli.MockUi)
+	progress := new(applyProgress)
+	progress.SetPlan(testProgressPlan())
+	h := &UiHook{
+		Colorize: &colorstring.Colorize{Colors: colorstring.DefaultColors, Disable: true},
+		Ui:       ui,
+		Progress: progress,
+		Compact:  true,
+	}
+
+	foo := &terraform.InstanceInfo{Id: "aws_instance.foo", Type: "aws_instance"}
+	bar := &terraform.InstanceInfo{Id: "aws_instance.bar", Type: "aws_instance"}
+	h.PreRefresh(foo, new(terraform.InstanceState))
+	h.PreApply(foo, new(terraform.InstanceState), new(terraform.InstanceDiff))
+	h.PreApply(bar, new(terraform.InstanceState), new(terraform.InstanceDiff))
+	h.PostApply(foo, &terraform.InstanceState{ID: "i-123"}, nil)
+
+	// Without colors, only the completion is shown
+	actual := strings.TrimSpace(ui.OutputWriter.String())
+	expected := "Applying... 1 of 3 done, 1 in progress, 0s elapsed (aws_instance.bar: 0s)"
+	if actual != expected {
+		t.Fatalf("expected:\n\n%s\n\ngot:\n\n%s", expected, actual)
+	}
+}
+
+func TestJSONHook_remaining(t *testing.T) {
+	ui := new(cli.MockUi)
+	progress := new(applyProgress)
+	progress.SetPlan(testProgressPlan())
+	h := &JSONHook{Ui: &JSONUi{Ui: ui}, Progress: progress}
+
+	info := &terraform.InstanceInfo{Id: "aws_instance.foo", Type: "aws_instance"}
+	h.PreApply(info, new(terraform.InstanceState), new(terraform.InstanceDiff))
+	h.PostApply(info, &terraform.InstanceState{ID: "i-123"}, nil)
+
+	e := testJSONEvent(t, testJSONEvents(t, ui.OutputWriter.String()), "apply_complete")
+	if e.Data["remaining"] != float64(2) {
+		t.Fatalf("bad: %#v", e.Data)
+	}
+}
@@ -1,7 +1,9 @@
 package command
 
 import (
+	"log"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/terraform/state"
 	"github.com/hashicorp/terraform/terraform"
@@ -9,11 +11,18 @@ import (
 
 // StateHook is a hook that continuously updates the state by calling
 // WriteState on a state.State.
+//
+// If PersistInterval is set, the state is also persisted whenever it is
+// updated, but at most once per interval, so that a killed Terraform
+// process loses as little as possible when the state is remote.
 type StateHook struct {
 	terraform.NilHook
 	sync.Mutex
 
-	State state.State
+	State           state.State
+	PersistInterval time.Duration
+
+	lastPersist time.Time
 }
 
 func (h *StateHook) PostStateUpdate(
@@ -26,6 +35,16 @@ func (h *StateHook) PostStateUpdate(
 		if err := h.State.WriteState(s); err != nil {
 			return terraform.HookActionHalt, err
 		}
+
+		if h.PersistInterval > 0 && time.Since(h.lastPersist) >= h.PersistInterval {
+			h.lastPersist = time.Now()
+
+			// The state is persisted again when the operation completes,
+			// where a failure is reported, so don't stop for it here.
+			if err := h.State.PersistState(); err != nil {
+				log.Printf("[WARN] Failed to persist intermediate state: %s", err)
+			}
+		}
 	}
 
 	// Continue forth
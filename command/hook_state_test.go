@@ -2,6 +2,7 @@ package command
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/state"
 	"github.com/hashicorp/terraform/terraform"
@@ -27,3 +28,40 @@ func TestStateHook(t *testing.T) {
 		t.Fatalf("bad state: %#v", is.State())
 	}
 }
+
+func TestStateHook_persistInterval(t *testing.T) {
+	is := &testPersistState{}
+	hook := &StateHook{State: is, PersistInterval: time.Hour}
+
+	// The first update is persisted, the following ones within the
+	// interval aren't.
+	for i := 0; i < 3; i++ {
+		if _, err := hook.PostStateUpdate(state.TestStateInitial()); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	if is.Persisted != 1 {
+		t.Fatalf("bad: %d", is.Persisted)
+	}
+
+	hook.lastPersist = time.Now().Add(-2 * time.Hour)
+	if _, err := hook.PostStateUpdate(state.TestStateInitial()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if is.Persisted != 2 {
+		t.Fatalf("bad: %d", is.Persisted)
+	}
+}
+
+// testPersistState is an in-memory state that counts how often it is
+// persisted.
+type testPersistState struct {
+	state.InmemState
+
+	Persisted int
+}
+
+func (s *testPersistState) PersistState() error {
+	s.Persisted++
+	return nil
+}
@@ -0,0 +1,242 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// StatsHook is a hook that gathers statistics about an operation: the
+// resources walked, the number of calls made to each provider and the time
+// spent on each resource. These help to find slow providers and resources.
+type StatsHook struct {
+	terraform.NilHook
+
+	l         sync.Mutex
+	providers map[string]int
+	elapsed   map[string]time.Duration
+	starts    map[string]time.Time
+}
+
+// StatsResource is the time spent on a single resource.
+type StatsResource struct {
+	Resource string
+	Elapsed  time.Duration
+}
+
+func (h *StatsHook) PreRefresh(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState) (terraform.HookAction, error) {
+	h.start(n)
+	return terraform.HookActionContinue, nil
+}
+
+func (h *StatsHook) PostRefresh(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState) (terraform.HookAction, error) {
+	h.stop(n)
+	return terraform.HookActionContinue, nil
+}
+
+func (h *StatsHook) PreDiff(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState) (terraform.HookAction, error) {
+	h.start(n)
+	return terraform.HookActionContinue, nil
+}
+
+func (h *StatsHook) PostDiff(
+	n *terraform.InstanceInfo,
+	d *terraform.InstanceDiff) (terraform.HookAction, error) {
+	h.stop(n)
+	return terraform.HookActionContinue, nil
+}
+
+func (h *StatsHook) PreApply(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState,
+	d *terraform.InstanceDiff) (terraform.HookAction, error) {
+	h.start(n)
+	return terraform.HookActionContinue, nil
+}
+
+func (h *StatsHook) PostApply(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState,
+	e error) (terraform.HookAction, error) {
+	h.stop(n)
+	return terraform.HookActionContinue, nil
+}
+
+// start records the start of a call to the provider of a resource.
+func (h *StatsHook) start(n *terraform.InstanceInfo) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	if h.providers == nil {
+		h.providers = make(map[string]int)
+		h.elapsed = make(map[string]time.Duration)
+		h.starts = make(map[string]time.Time)
+	}
+
+	id := n.HumanId()
+	h.providers[statsProvider(n.Type)]++
+	h.starts[id] = time.Now()
+	if _, ok := h.elapsed[id]; !ok {
+		h.elapsed[id] = 0
+	}
+}
+
+// stop records the end of the call started with start.
+func (h *StatsHook) stop(n *terraform.InstanceInfo) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	id := n.HumanId()
+	if start, ok := h.starts[id]; ok {
+		delete(h.starts, id)
+		h.elapsed[id] += time.Since(start)
+	}
+}
+
+// Walked returns the number of resources walked.
+func (h *StatsHook) Walked() int {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	return len(h.elapsed)
+}
+
+// Providers returns the number of calls made to each provider.
+func (h *StatsHook) Providers() map[string]int {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	result := make(map[string]int, len(h.providers))
+	for k, v := range h.providers {
+		result[k] = v
+	}
+
+	return result
+}
+
+// Resources returns the time spent on each resource, slowest first.
+func (h *StatsHook) Resources() []StatsResource {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	result := make([]StatsResource, 0, len(h.elapsed))
+	for k, v := range h.elapsed {
+		result = append(result, StatsResource{Resource: k, Elapsed: v})
+	}
+	sort.Sort(statsResources(result))
+
+	return result
+}
+
+type statsResources []StatsResource
+
+func (s statsResources) Len() int      { return len(s) }
+func (s statsResources) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s statsResources) Less(i, j int) bool {
+	if s[i].Elapsed != s[j].Elapsed {
+		return s[i].Elapsed > s[j].Elapsed
+	}
+
+	return s[i].Resource < s[j].Resource
+}
+
+// statsProvider returns the name of the provider of a resource type, which
+// is the prefix of the type up to the first underscore.
+func statsProvider(t string) string {
+	return strings.SplitN(t, "_", 2)[0]
+}
+
+// statsSlowest is the number of resources listed in the statistics output,
+// slowest first.
+const statsSlowest = 5
+
+// outputStats outputs the statistics of an operation if asked to with the
+// -stats flag, and always as a JSON event when the output is JSON. The
+// state sizes are of the state before and after the operation.
+func (m *Meta) outputStats(show bool, h *StatsHook, before, after *terraform.State) {
+	beforeSize, afterSize := stateSize(before), stateSize(after)
+	providers := h.Providers()
+	resources := h.Resources()
+
+	resourcesData := make([]interface{}, 0, len(resources))
+	for _, r := range resources {
+		resourcesData = append(resourcesData, map[string]interface{}{
+			"resource":        r.Resource,
+			"elapsed_seconds": r.Elapsed.Seconds(),
+		})
+	}
+	providersData := make(map[string]interface{}, len(providers))
+	for k, v := range providers {
+		providersData[k] = v
+	}
+	m.jsonEvent("operation_stats", map[string]interface{}{
+		"resources_walked":  h.Walked(),
+		"provider_calls":    providersData,
+		"resources":         resourcesData,
+		"state_size_before": beforeSize,
+		"state_size_after":  afterSize,
+	})
+
+	if !show {
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("[reset][bold]Statistics:[reset]\n\n")
+	buf.WriteString(fmt.Sprintf("  Resources walked: %d\n", h.Walked()))
+	buf.WriteString(fmt.Sprintf(
+		"  State size:       %d bytes before, %d bytes after\n", beforeSize, afterSize))
+
+	if len(providers) > 0 {
+		names := make([]string, 0, len(providers))
+		for k := range providers {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+
+		buf.WriteString("\n  Provider calls:\n")
+		for _, k := range names {
+			buf.WriteString(fmt.Sprintf("    %s: %d\n", k, providers[k]))
+		}
+	}
+
+	if len(resources) > 0 {
+		if len(resources) > statsSlowest {
+			resources = resources[:statsSlowest]
+		}
+
+		buf.WriteString("\n  Slowest resources:\n")
+		for _, r := range resources {
+			elapsed := r.Elapsed - r.Elapsed%time.Millisecond
+			buf.WriteString(fmt.Sprintf("    %s: %s\n", r.Resource, elapsed))
+		}
+	}
+
+	m.Ui.Output(m.Colorize().Color(buf.String()))
+}
+
+// stateSize returns the size of the state when it's written, in bytes.
+func stateSize(s *terraform.State) int {
+	if s == nil {
+		return 0
+	}
+
+	// WriteState sorts and initializes the state, so write a copy
+	var buf bytes.Buffer
+	if err := terraform.WriteState(s.DeepCopy(), &buf); err != nil {
+		return 0
+	}
+
+	return buf.Len()
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestStatsHook_impl(t *testing.T) {
+	var _ terraform.Hook = new(StatsHook)
+}
+
+func TestStatsHook(t *testing.T) {
+	h := new(StatsHook)
+
+	foo := &terraform.InstanceInfo{Id: "aws_instance.foo", Type: "aws_instance"}
+	bar := &terraform.InstanceInfo{Id: "aws_instance.bar", Type: "aws_instance"}
+	dns := &terraform.InstanceInfo{Id: "dnsimple_record.www", Type: "dnsimple_record"}
+
+	h.PreRefresh(foo, nil)
+	h.PostRefresh(foo, nil)
+	h.PreDiff(foo, nil)
+	time.Sleep(10 * time.Millisecond)
+	h.PostDiff(foo, nil)
+	h.PreDiff(bar, nil)
+	h.PostDiff(bar, nil)
+	h.PreApply(dns, nil, nil)
+	h.PostApply(dns, nil, nil)
+
+	if h.Walked() != 3 {
+		t.Fatalf("bad: %d", h.Walked())
+	}
+
+	expected := map[string]int{"aws": 3, "dnsimple": 1}
+	if actual := h.Providers(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	resources := h.Resources()
+	if len(resources) != 3 || resources[0].Resource != "aws_instance.foo" {
+		t.Fatalf("bad: %#v", resources)
+	}
+	if resources[0].Elapsed < 10*time.Millisecond {
+		t.Fatalf("bad: %#v", resources[0])
+	}
+}
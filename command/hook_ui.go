@@ -23,10 +23,25 @@ type UiHook struct {
 	Colorize *colorstring.Colorize
 	Ui       cli.Ui
 
-	l         sync.Mutex
-	once      sync.Once
-	resources map[string]uiResourceState
-	ui        cli.Ui
+	// Progress, if set, counts the changes of an apply so that the
+	// messages can tell how many are left.
+	Progress *applyProgress
+
+	// Compact shows the progress of an apply as a single status line,
+	// updated in place, instead of a message for each resource. Without
+	// colors, which suggests the output isn't a terminal, the status is
+	// shown on a new line each time a resource is done.
+	Compact bool
+
+	// ProgressBar adds a progress bar and an estimate of the time left to
+	// the status line of a compact apply.
+	ProgressBar bool
+
+	l           sync.Mutex
+	once        sync.Once
+	resources   map[string]uiResourceState
+	ui          cli.Ui
+	statusShown bool
 }
 
 // uiResourceState tracks the state of a single resource
@@ -60,13 +75,25 @@ func (h *UiHook) PreApply(
 		op = uiResourceCreate
 	}
 
+	h.Progress.Start(n, applyAction(s, d))
+
 	h.l.Lock()
 	h.resources[id] = uiResourceState{
 		Op:    op,
 		Start: time.Now().Round(time.Second),
 	}
+	if h.Compact {
+		h.showStatus(false)
+	}
 	h.l.Unlock()
 
+	// Set a timer to show an operation is still happening
+	time.AfterFunc(periodicUiTimer, func() { h.stillApplying(id) })
+
+	if h.Compact {
+		return terraform.HookActionContinue, nil
+	}
+
 	var operation string
 	switch op {
 	case uiResourceModify:
@@ -134,9 +161,6 @@ func (h *UiHook) PreApply(
 		operation,
 		attrString)))
 
-	// Set a timer to show an operation is still happening
-	time.AfterFunc(periodicUiTimer, func() { h.stillApplying(id) })
-
 	return terraform.HookActionContinue, nil
 }
 
@@ -152,6 +176,12 @@ func (h *UiHook) stillApplying(id string) {
 		return
 	}
 
+	if h.Compact {
+		h.showStatus(false)
+		time.AfterFunc(periodicUiTimer, func() { h.stillApplying(id) })
+		return
+	}
+
 	var msg string
 	switch state.Op {
 	case uiResourceModify:
@@ -180,12 +210,20 @@ func (h *UiHook) PostApply(
 	s *terraform.InstanceState,
 	applyerr error) (terraform.HookAction, error) {
 	id := n.HumanId()
+	remaining := h.Progress.Complete(n, applyerr)
 
 	h.l.Lock()
 	state := h.resources[id]
 	delete(h.resources, id)
+	if h.Compact {
+		h.showStatus(true)
+	}
 	h.l.Unlock()
 
+	if h.Compact {
+		return terraform.HookActionContinue, nil
+	}
+
 	var msg string
 	switch state.Op {
 	case uiResourceModify:
@@ -203,6 +241,10 @@ func (h *UiHook) PostApply(
 		return terraform.HookActionContinue, nil
 	}
 
+	msg = fmt.Sprintf("%s after %s", msg, time.Now().Round(time.Second).Sub(state.Start))
+	if remaining >= 0 {
+		msg = fmt.Sprintf("%s (%d remaining)", msg, remaining)
+	}
 	h.ui.Output(h.Colorize.Color(fmt.Sprintf(
 		"[reset][bold]%s: %s[reset]",
 		id, msg)))
@@ -210,6 +252,82 @@ func (h *UiHook) PostApply(
 	return terraform.HookActionContinue, nil
 }
 
+// showStatus shows the status line of a compact apply, replacing the one
+// shown last if nothing else was shown since. Without colors, the status
+// is only shown when complete is set, when a resource is done. h.l must
+// be held.
+func (h *UiHook) showStatus(complete bool) {
+	if h.Colorize.Disable && !complete {
+		return
+	}
+
+	done, total, elapsed := h.Progress.Counts()
+	msg := fmt.Sprintf("%d done", done)
+	if total >= 0 {
+		msg = fmt.Sprintf("%d of %d done", done, total)
+		if h.ProgressBar {
+			msg = progressBar(done, total) + " " + msg
+		}
+	}
+	msg = fmt.Sprintf("Applying... %s, %d in progress, %s elapsed",
+		msg, len(h.resources), elapsed-elapsed%time.Second)
+	if h.ProgressBar {
+		if left, ok := h.Progress.Estimate(); ok {
+			msg = fmt.Sprintf("%s, about %s left", msg, left-left%time.Second)
+		}
+	}
+
+	// The resource that has been changing the longest is shown, since
+	// it's what the apply is most likely waiting for
+	var longest string
+	var longestStart time.Time
+	for id, state := range h.resources {
+		if longest == "" || state.Start.Before(longestStart) ||
+			state.Start.Equal(longestStart) && id < longest {
+			longest = id
+			longestStart = state.Start
+		}
+	}
+	if longest != "" {
+		msg = fmt.Sprintf("%s (%s: %s)", msg, longest,
+			time.Now().Round(time.Second).Sub(longestStart))
+	}
+
+	var prefix string
+	if h.statusShown && !h.Colorize.Disable {
+		// Move up to the last status line and clear it
+		prefix = "\x1b[1A\x1b[2K"
+	}
+	h.ui.Output(prefix + h.Colorize.Color("[reset][bold]"+msg+"[reset]"))
+	h.statusShown = true
+}
+
+// progressBarWidth is the number of characters in the bar of progressBar.
+const progressBarWidth = 20
+
+// progressBar returns a bar showing how many of the total changes are
+// done, such as "[=========>          ]".
+func progressBar(done, total int) string {
+	n := progressBarWidth
+	if done < total {
+		n = done * progressBarWidth / total
+	}
+
+	bar := strings.Repeat("=", n)
+	if n < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-n-1)
+	}
+	return "[" + bar + "]"
+}
+
+// otherOutput records that something other than the status line of a
+// compact apply is shown, so that it isn't replaced.
+func (h *UiHook) otherOutput() {
+	h.l.Lock()
+	h.statusShown = false
+	h.l.Unlock()
+}
+
 func (h *UiHook) PreDiff(
 	n *terraform.InstanceInfo,
 	s *terraform.InstanceState) (terraform.HookAction, error) {
@@ -220,6 +338,7 @@ func (h *UiHook) PreProvision(
 	n *terraform.InstanceInfo,
 	provId string) (terraform.HookAction, error) {
 	id := n.HumanId()
+	h.otherOutput()
 	h.ui.Output(h.Colorize.Color(fmt.Sprintf(
 		"[reset][bold]%s: Provisioning with '%s'...[reset]",
 		id, provId)))
@@ -231,6 +350,7 @@ func (h *UiHook) ProvisionOutput(
 	provId string,
 	msg string) {
 	id := n.HumanId()
+	h.otherOutput()
 	var buf bytes.Buffer
 	buf.WriteString(h.Colorize.Color("[reset]"))
 
@@ -252,6 +372,11 @@ func (h *UiHook) PreRefresh(
 	s *terraform.InstanceState) (terraform.HookAction, error) {
 	h.once.Do(h.init)
 
+	// A compact apply only shows the progress of the changes
+	if h.Compact {
+		return terraform.HookActionContinue, nil
+	}
+
 	id := n.HumanId()
 
 	var stateIdSuffix string
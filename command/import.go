@@ -1,9 +1,11 @@
 package command
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -24,11 +26,13 @@ func (c *ImportCommand) Run(args []string) int {
 	}
 
 	var configPath string
+	var autoApprove bool
 	args = c.Meta.process(args, true)
 
 	cmdFlags := c.Meta.flagSet("import")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
 	cmdFlags.IntVar(&c.Meta.parallelism, "parallelism", 0, "parallelism")
-	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
 	cmdFlags.StringVar(&c.Meta.stateOutPath, "state-out", "", "path")
 	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
 	cmdFlags.StringVar(&configPath, "config", pwd, "path")
@@ -56,6 +60,9 @@ func (c *ImportCommand) Run(args []string) int {
 		c.Ui.Error(err.Error())
 		return 1
 	}
+	if !c.confirmRemoteState(autoApprove) {
+		return 1
+	}
 
 	// Perform the import. Note that as you can see it is possible for this
 	// API to import more than one resource at once. For now, we only allow
@@ -81,6 +88,12 @@ func (c *ImportCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Show what was imported, so it's easier to write the configuration
+	if imported := formatImported(newState, args[0]); imported != "" {
+		c.Ui.Output(c.Colorize().Color(
+			"[reset][bold]\nImported attributes:\n\n[reset]" + imported))
+	}
+
 	c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
 		"[reset][green]\n" +
 			"Import success! The resources imported are shown above. These are\n" +
@@ -92,6 +105,39 @@ func (c *ImportCommand) Run(args []string) int {
 	return 0
 }
 
+// formatImported returns the attributes of the resources in the state at
+// the address the resources were imported to.
+func formatImported(s *terraform.State, addr string) string {
+	filter := &terraform.StateFilter{State: s}
+	results, err := filter.Filter(addr)
+	if err != nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	for _, r := range results {
+		is, ok := r.Value.(*terraform.InstanceState)
+		if !ok {
+			continue
+		}
+
+		keys := make([]string, 0, len(is.Attributes))
+		for k := range is.Attributes {
+			if k != "id" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		buf.WriteString(fmt.Sprintf("%s:\n  id = %s\n", r.Address, is.ID))
+		for _, k := range keys {
+			buf.WriteString(fmt.Sprintf("  %s = %s\n", k, is.Attributes[k]))
+		}
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
 func (c *ImportCommand) Help() string {
 	helpText := `
 Usage: terraform import [options] ADDR ID
@@ -121,6 +167,9 @@ Usage: terraform import [options] ADDR ID
 
 Options:
 
+  -auto-approve       Don't ask for the name of a remote state configured
+                      with confirm_required before changing it.
+
   -backup=path        Path to backup the existing state file before
                       modifying. Defaults to the "-state-out" path with
                       ".backup" extension. Set to "-" to disable backup.
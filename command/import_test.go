@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -46,6 +47,51 @@ func TestImport(t *testing.T) {
 	testStateOutput(t, statePath, testImportStr)
 }
 
+func TestImport_showAttributes(t *testing.T) {
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ImportCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	p.ImportStateFn = nil
+	p.ImportStateReturn = []*terraform.InstanceState{
+		&terraform.InstanceState{
+			ID: "yay",
+			Ephemeral: terraform.EphemeralState{
+				Type: "test_instance",
+			},
+		},
+	}
+	p.RefreshFn = nil
+	p.RefreshReturn = &terraform.InstanceState{
+		ID: "yay",
+		Attributes: map[string]string{
+			"id":  "yay",
+			"ami": "ami-123",
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"test_instance.foo",
+		"bar",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	expected := "test_instance.foo:\n  id = yay\n  ami = ami-123"
+	if actual := ui.OutputWriter.String(); !strings.Contains(actual, expected) {
+		t.Fatalf("expected:\n%s\n\ngot:\n%s", expected, actual)
+	}
+}
+
 func TestImport_providerConfig(t *testing.T) {
 	defer testChdir(t, testFixturePath("import-provider"))()
 
@@ -894,3 +940,47 @@ test_instance.foo:
   ID = yay
   provider = test.alias
 `
+
+func TestImport_confirmRequired(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	current := testState()
+	client, cleanup := testConfirmRequiredState(t, current)
+	defer cleanup()
+
+	p := testProvider()
+	p.ImportStateFn = nil
+	p.ImportStateReturn = []*terraform.InstanceState{
+		&terraform.InstanceState{
+			ID: "yay",
+			Ephemeral: terraform.EphemeralState{
+				Type: "test_instance",
+			},
+		},
+	}
+	ui := new(cli.MockUi)
+	c := &ImportCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// Answering anything but the name of the remote state doesn't change it
+	args := []string{
+		"-config", testFixturePath("import-provider"),
+		"test_instance.bar",
+		"yay",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "Cancelled") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+	if p.ImportStateCalled {
+		t.Fatal("import should not be called")
+	}
+	testConfirmRequiredUnchanged(t, client, current)
+}
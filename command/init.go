@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/go-getter"
 	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/state/remote"
 	"github.com/hashicorp/terraform/terraform"
 )
 
@@ -20,33 +21,101 @@ type InitCommand struct {
 }
 
 func (c *InitCommand) Run(args []string) int {
-	var remoteBackend string
+	var remoteBackend, fromModule string
+	var backendSelfTest, backendValidate, check, jsonOutput bool
+	var upgrade moduleUpgradeFlag
 	args = c.Meta.process(args, false)
 	remoteConfig := make(map[string]string)
 	cmdFlags := flag.NewFlagSet("init", flag.ContinueOnError)
 	cmdFlags.StringVar(&remoteBackend, "backend", "", "")
 	cmdFlags.Var((*FlagStringKV)(&remoteConfig), "backend-config", "config")
+	cmdFlags.BoolVar(&backendSelfTest, "backend-selftest", false, "")
+	cmdFlags.BoolVar(&backendValidate, "backend-validate", false, "")
+	cmdFlags.BoolVar(&check, "check", false, "check")
+	cmdFlags.StringVar(&fromModule, "from-module", "", "source")
+	cmdFlags.BoolVar(&c.Meta.input, "input", c.Meta.defaultInput(), "input")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.StringVar(&c.Meta.moduleMirror, "module-mirror", "", "mirror")
+	cmdFlags.Var((*FlagStringSlice)(&c.Meta.pluginDirs), "plugin-dir", "dir")
+	cmdFlags.Var(&upgrade, "upgrade", "upgrade")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
 
+	if jsonOutput {
+		c.Meta.enableJSONUi()
+	}
+
 	remoteBackend = strings.ToLower(remoteBackend)
 
+	// With -backend=false, remote state isn't set up or contacted at all,
+	// even if the other backend flags are given, so that the same flags can
+	// be used where the credentials aren't available.
+	if remoteBackend == "false" {
+		if backendSelfTest || backendValidate {
+			c.Ui.Error("The -backend-selftest and -backend-validate flags can't be\n" +
+				"used with -backend=false.\n")
+			return 1
+		}
+		remoteBackend = ""
+		remoteConfig = nil
+	}
+
+	if check && (backendSelfTest || backendValidate || upgrade.All || len(upgrade.Modules) > 0) {
+		c.Ui.Error("The -check flag can't be used with -backend-selftest,\n" +
+			"-backend-validate or -upgrade.\n")
+		return 1
+	}
+
+	// If we're only validating the backend, we don't need a source and
+	// we must not touch the module or any state.
+	if backendValidate {
+		if code := c.validateBackend(remoteBackend, remoteConfig); code != 0 {
+			return code
+		}
+		if backendSelfTest && !c.remoteSelfTest(remoteBackend, remoteConfig) {
+			return 1
+		}
+		return 0
+	}
+	// Make sure the remote storage can be used before anything is done
+	if backendSelfTest {
+		if remoteBackend == "" {
+			c.Ui.Error("The -backend-selftest flag requires -backend to be set.\n")
+			return 1
+		}
+		if !c.remoteSelfTest(remoteBackend, remoteConfig) {
+			return 1
+		}
+	}
+
 	var path string
 	args = cmdFlags.Args()
-	if len(args) > 2 {
-		c.Ui.Error("The init command expects at most two arguments.\n")
-		cmdFlags.Usage()
-		return 1
-	} else if len(args) < 1 {
-		c.Ui.Error("The init command expects at least one arguments.\n")
-		cmdFlags.Usage()
-		return 1
+	if fromModule == "" && len(args) > 0 {
+		// Before -from-module, the source was the first argument and the
+		// directory the optional second. Keep accepting that for now.
+		if len(args) > 2 {
+			c.Ui.Error("The init command expects at most two arguments.\n")
+			cmdFlags.Usage()
+			return 1
+		}
+
+		fromModule = args[0]
+		args = args[1:]
+		c.Ui.Warn(fmt.Sprintf(
+			"The SOURCE argument of init is deprecated and will be removed in a\n"+
+				"future version. Please use the -from-module flag instead:\n\n"+
+				"    terraform init -from-module=%s %s\n",
+			fromModule, strings.Join(args, " ")))
 	}
 
-	if len(args) == 2 {
-		path = args[1]
+	if len(args) > 1 {
+		c.Ui.Error("The init command expects at most one argument.\n")
+		cmdFlags.Usage()
+		return 1
+	} else if len(args) == 1 {
+		path = args[0]
 	} else {
 		var err error
 		path, err = os.Getwd()
@@ -57,44 +126,28 @@ func (c *InitCommand) Run(args []string) int {
 
 	// Set the state out path to be the path requested for the module
 	// to be copied. This ensures any remote states gets setup in the
-	// proper directory.
-	c.Meta.dataDir = filepath.Join(path, DefaultDataDir)
-
-	source := args[0]
-
-	// Get our pwd since we need it
-	pwd, err := os.Getwd()
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf(
-			"Error reading working directory: %s", err))
-		return 1
+	// proper directory, unless the data directory was set explicitly.
+	if os.Getenv(DataDirEnvVar) == "" && c.Meta.dataDir == "" {
+		c.Meta.dataDir = filepath.Join(path, DefaultDataDir)
 	}
 
-	// Verify the directory is empty
-	if empty, err := config.IsEmptyDir(path); err != nil {
-		c.Ui.Error(fmt.Sprintf(
-			"Error checking on destination path: %s", err))
-		return 1
-	} else if !empty {
-		c.Ui.Error(
-			"The destination path has Terraform configuration files. The\n" +
-				"init command can only be used on a directory without existing Terraform\n" +
-				"files.")
-		return 1
+	// Checking doesn't change anything, so no module can be copied into
+	// the directory first
+	if check {
+		if fromModule != "" {
+			c.Ui.Error("The -check flag can't be used with -from-module.\n")
+			return 1
+		}
+		return c.check(path, remoteBackend, remoteConfig)
 	}
 
-	// Detect
-	source, err = getter.Detect(source, pwd, getter.Detectors)
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf(
-			"Error with module source: %s", err))
-		return 1
+	if fromModule != "" {
+		if code := c.copyModule(fromModule, path); code != 0 {
+			return code
+		}
 	}
-
-	// Get it!
-	if err := module.GetCopy(path, source); err != nil {
-		c.Ui.Error(err.Error())
-		return 1
+	if code := c.getModules(path, remoteBackend != "", &upgrade); code != 0 {
+		return code
 	}
 
 	// Handle remote state if configured
@@ -133,32 +186,304 @@ func (c *InitCommand) Run(args []string) int {
 			Meta:       c.Meta,
 			remoteConf: &remoteConf,
 		}
-		return remoteCmd.initBlankState()
+		if code := remoteCmd.initBlankState(); code != 0 {
+			return code
+		}
+		c.jsonEvent("remote_state_configured", map[string]interface{}{
+			"backend": remoteBackend,
+		})
+	}
+	return 0
+}
+
+// copyModule copies the module given by source into path. If path
+// already has Terraform configuration files, the user must confirm that
+// they may be overwritten.
+func (c *InitCommand) copyModule(source, path string) int {
+	// Get our pwd since we need it
+	pwd, err := os.Getwd()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(
+			"Error reading working directory: %s", err))
+		return 1
+	}
+
+	// Verify the directory is empty
+	if empty, err := config.IsEmptyDir(path); err != nil {
+		c.Ui.Error(fmt.Sprintf(
+			"Error checking on destination path: %s", err))
+		return 1
+	} else if !empty {
+		if !c.input {
+			c.Ui.Error(
+				"The destination path has Terraform configuration files. The\n" +
+					"module can only be copied into it with confirmation, which\n" +
+					"isn't possible with -input=false.")
+			return 1
+		}
+
+		v, err := c.UIInput().Input(&terraform.InputOpts{
+			Id:    "copy",
+			Query: "Do you want to copy the module into a directory with configuration?",
+			Description: fmt.Sprintf(
+				"The destination path %s already has Terraform configuration\n"+
+					"files. Files in the module will overwrite files with the same\n"+
+					"name. Only 'yes' will be accepted to confirm.", path),
+		})
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error asking for confirmation: %s", err))
+			return 1
+		}
+		if v != "yes" {
+			c.Ui.Output("Init cancelled.")
+			return 1
+		}
+	}
+
+	// Detect. A module from a registry is copied from the source of its
+	// latest version.
+	source, subDir := getter.SourceDirSubdir(source)
+	if rs := module.ParseRegistrySource(source, pwd); rs != nil {
+		source, err = module.ResolveRegistrySource(rs, "")
+	} else {
+		source, err = getter.Detect(source, pwd, getter.Detectors)
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(
+			"Error with module source: %s", err))
+		return 1
+	}
+	if subDir != "" {
+		var subDir2 string
+		source, subDir2 = getter.SourceDirSubdir(source)
+		source = fmt.Sprintf("%s//%s", source, filepath.Join(subDir2, subDir))
+	}
+	if c.moduleMirror != "" {
+		source, err = mirrorModuleSource(c.moduleMirror, source)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+	}
+
+	// Get it!
+	if err := module.GetCopy(path, source); err != nil {
+		c.Ui.Error(err.Error())
+		return 1
 	}
+	c.jsonEvent("module_copied", map[string]interface{}{
+		"source": source,
+		"path":   path,
+	})
+
+	return 0
+}
+
+// getModules downloads the modules used by the configuration in path, from
+// the sources in the module lock file unless they're being upgraded, and
+// records them in the module lock file and the provider plugins it uses in
+// the plugin lock file. A path without configuration is only allowed if
+// remote state is being set up, since there's nothing else to do then.
+func (c *InitCommand) getModules(path string, remote bool, upgrade *moduleUpgradeFlag) int {
+	if empty, err := config.IsEmptyDir(path); err != nil {
+		c.Ui.Error(fmt.Sprintf(
+			"Error checking on destination path: %s", err))
+		return 1
+	} else if empty {
+		if remote {
+			return 0
+		}
+
+		c.Ui.Error(
+			"The init command found no Terraform configuration files to\n" +
+				"initialize. To copy a module into the directory first, use\n" +
+				"the -from-module flag.")
+		return 1
+	}
+
+	mod, err := module.NewTreeModule("", path)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(
+			"Error loading config: %s",
+			formatLoadError(path, err, c.Colorize())))
+		return 1
+	}
+
+	lockPath := filepath.Join(path, ModuleLockFilename)
+	lock, err := readModuleLock(lockPath)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	storage := &lockedModuleStorage{
+		Storage:    c.moduleStorage(c.DataDir()),
+		Lock:       lock,
+		UpgradeAll: upgrade.All,
+		Result:     &moduleLock{Modules: make(map[string]*moduleLockEntry)},
+	}
+	mode := module.GetModeGet
+	if upgrade.All {
+		mode = module.GetModeUpdate
+	}
+	if err := mod.Load(storage, mode); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error downloading modules: %s", err))
+		return 1
+	}
+
+	// The modules to upgrade are only known by their keys once the
+	// modules are loaded, so selected modules are upgraded by loading the
+	// modules again.
+	if !upgrade.All && len(upgrade.Modules) > 0 {
+		storage.Upgrade, err = moduleUpgradeKeys(mod, upgrade.Modules)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		storage.Result = &moduleLock{Modules: make(map[string]*moduleLockEntry)}
+		if err := mod.Load(storage, module.GetModeUpdate); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error upgrading modules: %s", err))
+			return 1
+		}
+	}
+
+	if err := writeModuleLock(lockPath, storage.Result); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing %s: %s", ModuleLockFilename, err))
+		return 1
+	}
+	if upgrade.All || len(upgrade.Modules) > 0 {
+		c.outputModuleUpgrades(mod, lock, storage)
+	}
+
+	if err := c.lockProviders(mod); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error choosing provider plugins: %s", err))
+		return 1
+	}
+
+	return 0
+}
+
+// validateBackend checks that the given remote backend configuration is
+// valid and that the backend can be reached. It doesn't download any
+// modules, write any state, or migrate anything.
+func (c *InitCommand) validateBackend(t string, conf map[string]string) int {
+	if t == "" {
+		c.Ui.Error("The -backend-validate flag requires -backend to be set.\n")
+		return 1
+	}
+
+	client, err := remote.NewClient(t, conf)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(
+			"Invalid configuration for the %q backend: %s\n\n"+
+				"Configuration options are set using the `-backend-config` flag.\n"+
+				"Example: -backend-config=\"name=foo\" to set the `name` configuration",
+			t, err))
+		return 1
+	}
+
+	// Reading the state is the one operation every client supports and
+	// it exercises both connectivity and read permissions. A missing state
+	// is not an error; clients return a nil payload in that case.
+	if _, err := client.Get(); err != nil {
+		c.Ui.Error(fmt.Sprintf(
+			"The %q backend configuration is valid, but reading the state\n"+
+				"failed. Please verify that the backend is reachable and that the\n"+
+				"configured credentials have read access.\n\n%s",
+			t, err))
+		return 1
+	}
+
+	c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
+		"[reset][bold][green]The %q backend configuration is valid and the backend is reachable.",
+		t)))
+	c.jsonEvent("backend_validated", map[string]interface{}{
+		"backend": t,
+	})
 	return 0
 }
 
 func (c *InitCommand) Help() string {
 	helpText := `
-Usage: terraform init [options] SOURCE [PATH]
+Usage: terraform init [options] [DIR]
+
+  Initializes the Terraform configuration in DIR, which defaults to
+  the working directory. The modules the configuration uses are
+  downloaded, the provider plugins it uses are chosen according to the
+  version constraints of its provider blocks and recorded in
+  .terraform/plugin.lock, and remote state is set up if -backend is given.
+
+  The sources and Git commits of the modules downloaded are recorded in
+  .terraform-modules.lock in DIR, and later runs of init download the
+  same modules until -upgrade is given.
 
-  Downloads the module given by SOURCE into the PATH. The PATH defaults
-  to the working directory. PATH must be empty of any Terraform files.
-  Any conflicting non-Terraform files will be overwritten.
+  With -from-module, the given module is first copied into DIR. If DIR
+  already has Terraform files, you're asked to confirm that they may be
+  overwritten. The module downloaded is a copy. If you're downloading
+  a module from Git, it will not preserve the Git history, it will only
+  copy the latest files.
 
-  The module downloaded is a copy. If you're downloading a module from
-  Git, it will not preserve the Git history, it will only copy the
-  latest files.
+  The older form "terraform init SOURCE [DIR]" is deprecated, but still
+  copies SOURCE into DIR.
 
 Options:
 
   -backend=atlas         Specifies the type of remote backend. If not
-                         specified, local storage will be used.
+                         specified, local storage will be used. With
+                         -backend=false, remote state isn't set up and the
+                         other backend flags are ignored, so that modules
+                         can be downloaded without the backend credentials.
 
   -backend-config="k=v"  Specifies configuration for the remote storage
                          backend. This can be specified multiple times.
 
-  -no-color           If specified, output won't contain any color.
+  -backend-selftest      Before setting up remote state, check that the state
+                         can be read and that an object next to it can be
+                         written and deleted, and show what failed. The
+                         state itself is never written. With
+                         -backend-validate, the check is made in addition.
+
+  -backend-validate      Only validate the backend configuration and check
+                         that the state can be read from it. No module is
+                         downloaded, no source is required, and no state is
+                         written or migrated.
+
+  -check                 Only check whether init needs to be run, because
+                         remote state with the -backend settings isn't set
+                         up, modules are missing, or provider plugins
+                         haven't been chosen. Nothing is changed. The exit
+                         code is 0 if init has nothing to do, 2 if it needs
+                         to be run, and 1 on errors.
+
+  -from-module=SOURCE    Copy the module given by SOURCE into DIR before
+                         initializing it.
+
+  -input=true            Ask for confirmation before copying a module into
+                         a directory with Terraform configuration files. If
+                         false, init fails in that case instead.
+
+  -json                  Write all output as newline delimited JSON events
+                         so that it can be parsed by other tools.
+
+  -module-mirror=MIRROR  Download modules from the given directory or
+                         HTTP(S) URL instead of from their sources, so that
+                         init doesn't need access to them. Local modules
+                         are still used from their paths.
+
+  -no-color              If specified, output won't contain any color.
+
+  -plugin-dir=PATH       Find provider plugins only in the given directory
+                         instead of the usual plugin locations. This can be
+                         specified multiple times, and is recorded in the
+                         plugin lock file for later commands.
+
+  -upgrade               Download the modules again from their sources and
+                         update the module lock file, instead of using the
+                         sources and commits recorded in it. To upgrade
+                         only some modules and the modules they use, give
+                         their addresses, such as -upgrade=module.vpc. This
+                         can be specified multiple times.
 
 `
 	return strings.TrimSpace(helpText)
@@ -167,3 +492,33 @@ Options:
 func (c *InitCommand) Synopsis() string {
 	return "Initializes Terraform configuration from a module"
 }
+
+// moduleUpgradeFlag is the -upgrade flag of init. Without a value, all the
+// modules are upgraded, and with module addresses as values, only those
+// modules and the modules they use.
+type moduleUpgradeFlag struct {
+	All     bool
+	Modules []string
+}
+
+func (f *moduleUpgradeFlag) String() string {
+	return strings.Join(f.Modules, ",")
+}
+
+func (f *moduleUpgradeFlag) Set(v string) error {
+	switch v {
+	case "true":
+		f.All = true
+	case "false":
+		f.All = false
+		f.Modules = nil
+	default:
+		f.Modules = append(f.Modules, v)
+	}
+
+	return nil
+}
+
+func (f *moduleUpgradeFlag) IsBoolFlag() bool {
+	return true
+}
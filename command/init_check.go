@@ -0,0 +1,112 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/state/remote"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// initCheckProblem is something init -check found that running init would
+// fix.
+type initCheckProblem struct {
+	// Kind is what needs to be initialized: "backend", "modules" or
+	// "providers".
+	Kind string `json:"kind"`
+
+	Message string `json:"message"`
+}
+
+// check is init -check. It reports whether running init on path with the
+// given remote backend configuration would change anything, without
+// changing anything itself. The exit code is 0 if nothing needs to be
+// initialized, 2 if something does, and 1 on errors.
+func (c *InitCommand) check(path, backend string, conf map[string]string) int {
+	var problems []*initCheckProblem
+	if backend != "" {
+		msg, err := c.checkBackend(backend, conf)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		if msg != "" {
+			problems = append(problems, &initCheckProblem{Kind: "backend", Message: msg})
+		}
+	}
+
+	if empty, err := config.IsEmptyDir(path); err != nil {
+		c.Ui.Error(fmt.Sprintf(
+			"Error checking on destination path: %s", err))
+		return 1
+	} else if empty && backend == "" {
+		c.Ui.Error(
+			"The init command found no Terraform configuration files to\n" +
+				"check.")
+		return 1
+	} else if !empty {
+		mod, err := module.NewTreeModule("", path)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf(
+				"Error loading config: %s",
+				formatLoadError(path, err, c.Colorize())))
+			return 1
+		}
+
+		// The provider constraints of modules can't be read before the
+		// modules are downloaded, so the plugin lock is only checked then
+		if err := mod.Load(c.moduleStorage(c.DataDir()), module.GetModeNone); err != nil {
+			problems = append(problems, &initCheckProblem{Kind: "modules", Message: err.Error()})
+		} else if err := c.checkProviderLock(mod); err != nil {
+			problems = append(problems, &initCheckProblem{Kind: "providers", Message: err.Error()})
+		}
+	}
+
+	if problems == nil {
+		problems = []*initCheckProblem{}
+	}
+	c.jsonEvent("init_check", map[string]interface{}{
+		"needed":   len(problems) > 0,
+		"problems": problems,
+	})
+
+	if len(problems) == 0 {
+		c.Ui.Output(c.Colorize().Color(
+			"[reset][bold][green]Terraform is initialized, there's nothing for init to do."))
+		return 0
+	}
+
+	msg := "[reset][bold][yellow]Terraform needs to be initialized:[reset]\n"
+	for _, p := range problems {
+		msg += fmt.Sprintf("\n  - %s: %s\n", p.Kind, p.Message)
+	}
+	msg += "\nRun \"terraform init\" to initialize it."
+	c.Ui.Output(c.Colorize().Color(msg))
+	return 2
+}
+
+// checkBackend returns why init would have to set up remote state with the
+// given configuration, or an empty string if it's already set up with it.
+func (c *InitCommand) checkBackend(backend string, conf map[string]string) (string, error) {
+	path := filepath.Join(c.DataDir(), DefaultStateFilename)
+	cache := remoteCacheState(path)
+	if err := cache.RefreshState(); err != nil {
+		return "", remoteCacheError(path, err)
+	}
+
+	current := cache.State()
+	if current == nil || current.Remote.Empty() {
+		return fmt.Sprintf("remote state with the %q backend isn't set up", backend), nil
+	}
+
+	wanted := &terraform.RemoteState{Type: backend, Config: conf}
+	if !remote.SameLocation(wanted, current.Remote) {
+		return fmt.Sprintf(
+			"remote state is set up with another %q backend configuration",
+			current.Remote.Type), nil
+	}
+
+	return "", nil
+}
@@ -1,10 +1,15 @@
 package command
 
 import (
+	"bytes"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config/module"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/cli"
 )
@@ -144,6 +149,110 @@ func TestInit_dstInSrc(t *testing.T) {
 	}
 }
 
+func TestInit_fromModule(t *testing.T) {
+	dir := tempDir(t)
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-from-module", testFixturePath("get"),
+		dir,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	if strings.Contains(ui.ErrorWriter.String(), "deprecated") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "main.tf")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The modules of the copied configuration are downloaded too
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "Get: file://") {
+		t.Fatalf("modules weren't downloaded: %s", output)
+	}
+}
+
+func TestInit_sourceDeprecated(t *testing.T) {
+	dir := tempDir(t)
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		testFixturePath("init"),
+		dir,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "-from-module") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+}
+
+func TestInit_fromModuleNotEmpty(t *testing.T) {
+	dir := tempDir(t)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "existing.tf"), nil, 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	// Without confirmation the module isn't copied
+	args := []string{
+		"-input=false",
+		"-from-module", testFixturePath("init"),
+		dir,
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: \n%s", ui.OutputWriter.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hello.tf")); err == nil {
+		t.Fatal("module should not be copied")
+	}
+
+	defaultInputReader = bytes.NewBufferString("yes\n")
+	defaultInputWriter = new(bytes.Buffer)
+
+	args = []string{
+		"-from-module", testFixturePath("init"),
+		dir,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hello.tf")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "existing.tf")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
 func TestInit_remoteState(t *testing.T) {
 	tmp, cwd := testCwd(t)
 	defer testFixCwd(t, tmp, cwd)
@@ -287,3 +396,349 @@ func TestInit_remoteStateWithRemote(t *testing.T) {
 		t.Fatalf("should have failed: \n%s", ui.OutputWriter.String())
 	}
 }
+
+func TestInit_backendFalse(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	// The backend isn't reachable, so it mustn't be contacted
+	args := []string{
+		"-backend", "http",
+		"-backend-config", "address=http://127.0.0.1:0",
+		"-backend=false",
+		"-from-module", testFixturePath("init"),
+		tmp,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	if _, err := os.Stat(filepath.Join(tmp, "hello.tf")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, DefaultDataDir, DefaultStateFilename)); !os.IsNotExist(err) {
+		t.Fatalf("remote state should not be set up: %s", err)
+	}
+}
+
+func TestInit_backendFalseValidate(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{"-backend=false", "-backend-validate"}); code != 1 {
+		t.Fatalf("bad: \n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestInit_backendValidate(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	conf, srv := testRemoteState(t, terraform.NewState(), 200)
+	defer srv.Close()
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-backend", "http",
+		"-backend-config", "address=" + conf.Config["address"],
+		"-backend-validate",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	// Nothing should be written
+	if _, err := os.Stat(filepath.Join(tmp, DefaultDataDir)); !os.IsNotExist(err) {
+		t.Fatalf("data dir should not exist: %s", err)
+	}
+}
+
+func TestInit_backendValidateInvalid(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-backend", "http",
+		"-backend-validate",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "Invalid configuration") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+}
+
+func TestInit_backendValidateUnreachable(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	// Start and immediately stop a server so we have an address that
+	// refuses connections.
+	conf, srv := testRemoteState(t, terraform.NewState(), 200)
+	srv.Close()
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-backend", "http",
+		"-backend-config", "address=" + conf.Config["address"],
+		"-backend-validate",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "reading the state") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+}
+
+func TestInit_backendSelfTest(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-backend", "local",
+		"-backend-config", "path=" + filepath.Join(tmp, "remote.tfstate"),
+		"-backend-selftest",
+		"-backend-validate",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	for _, step := range []string{"read", "write", "delete"} {
+		if !regexp.MustCompile(step + ` +ok`).MatchString(ui.OutputWriter.String()) {
+			t.Fatalf("%s should be ok:\n\n%s", step, ui.OutputWriter.String())
+		}
+	}
+}
+
+func TestInit_backendSelfTestFails(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-backend", "local",
+		"-backend-config", "path=" + filepath.Join(tmp, "missing", "remote.tfstate"),
+		"-backend-selftest",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "write   failed") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+
+	// Remote state isn't set up
+	if _, err := os.Stat(filepath.Join(tmp, DefaultDataDir)); !os.IsNotExist(err) {
+		t.Fatalf("data dir should not exist: %s", err)
+	}
+}
+
+func TestInit_backendValidateNoBackend(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{"-backend-validate"}); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+}
+
+func TestInit_json(t *testing.T) {
+	dir := tempDir(t)
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-json",
+		testFixturePath("init"),
+		dir,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	es := testJSONEvents(t, ui.OutputWriter.String())
+	if e := testJSONEvent(t, es, "module_copied"); e.Data["path"] != dir {
+		t.Fatalf("bad: %#v", e)
+	}
+}
+
+func TestInit_check(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	conf, srv := testRemoteState(t, terraform.NewState(), 200)
+	defer srv.Close()
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	// The module isn't downloaded and remote state isn't set up yet
+	if err := module.GetCopy(tmp, testFixturePath("get")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	backendArgs := []string{
+		"-backend", "http",
+		"-backend-config", "address=" + conf.Config["address"],
+	}
+	args := append([]string{"-check", "-json"}, backendArgs...)
+	if code := c.Run(args); code != 2 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	es := testJSONEvents(t, ui.OutputWriter.String())
+	e := testJSONEvent(t, es, "init_check")
+	problems, ok := e.Data["problems"].([]interface{})
+	if !ok || len(problems) != 2 {
+		t.Fatalf("bad: %#v", e)
+	}
+	for i, kind := range []string{"backend", "modules"} {
+		if p := problems[i].(map[string]interface{}); p["kind"] != kind {
+			t.Fatalf("bad: %#v", p)
+		}
+	}
+
+	// Checking doesn't change anything
+	if _, err := os.Stat(filepath.Join(tmp, DefaultDataDir)); !os.IsNotExist(err) {
+		t.Fatalf("data dir should not exist: %s", err)
+	}
+
+	// Once initialized, there's nothing to do
+	ui = new(cli.MockUi)
+	c.Meta = Meta{
+		ContextOpts: testCtxConfig(testProvider()),
+		Ui:          ui,
+	}
+	if code := c.Run(backendArgs); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	ui = new(cli.MockUi)
+	c.Meta = Meta{
+		ContextOpts: testCtxConfig(testProvider()),
+		Ui:          ui,
+	}
+	if code := c.Run(append([]string{"-check"}, backendArgs...)); code != 0 {
+		t.Fatalf("bad: %d\n\n%s\n\n%s", code, ui.OutputWriter.String(), ui.ErrorWriter.String())
+	}
+
+	// A different backend location needs init again
+	ui = new(cli.MockUi)
+	c.Meta = Meta{
+		ContextOpts: testCtxConfig(testProvider()),
+		Ui:          ui,
+	}
+	args = []string{"-check", "-backend", "http", "-backend-config", "address=http://example.com"}
+	if code := c.Run(args); code != 2 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "another \"http\" backend") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+}
+
+func TestInit_moduleMirror(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	config := `
+module "example" {
+  source = "github.com/hashicorp/example?ref=v1.0.0"
+}
+`
+	if err := ioutil.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	dir := filepath.Join(tmp, "mirror", "github.com", "hashicorp", "example@v1.0.0")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(`variable "foo" {}`), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{"-module-mirror", "mirror"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "(mirror: "+dir+")") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+}
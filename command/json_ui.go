@@ -0,0 +1,84 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+)
+
+// JSONUi is a Ui implementation that writes every message as a single
+// line JSON object so that the output of a command can be parsed reliably
+// by other tools. Commands can also write their own typed events with
+// Event.
+type JSONUi struct {
+	Ui cli.Ui
+}
+
+// JSONEvent is a single line of output written by JSONUi.
+//
+// Type is the kind of event. Plain Ui messages have the type "message",
+// anything else is a command specific event whose details are in Data.
+type JSONEvent struct {
+	Type      string                 `json:"type"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Timestamp string                 `json:"timestamp"`
+}
+
+func (u *JSONUi) Ask(query string) (string, error) {
+	return "", errors.New("input is not supported with JSON output")
+}
+
+func (u *JSONUi) AskSecret(query string) (string, error) {
+	return "", errors.New("input is not supported with JSON output")
+}
+
+func (u *JSONUi) Output(message string) {
+	u.Event(&JSONEvent{Type: "message", Level: "info", Message: message})
+}
+
+func (u *JSONUi) Info(message string) {
+	u.Event(&JSONEvent{Type: "message", Level: "info", Message: message})
+}
+
+func (u *JSONUi) Error(message string) {
+	u.Event(&JSONEvent{Type: "message", Level: "error", Message: message})
+}
+
+func (u *JSONUi) Warn(message string) {
+	u.Event(&JSONEvent{Type: "message", Level: "warn", Message: message})
+}
+
+// Event writes a single event. Events with the "error" level are written
+// to the error stream, everything else is written to the output stream.
+func (u *JSONUi) Event(e *JSONEvent) {
+	if e.Level == "" {
+		e.Level = "info"
+	}
+	if e.Timestamp == "" {
+		e.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	raw, err := json.Marshal(e)
+	if err != nil {
+		// This can only happen if Data has a value that can't be encoded,
+		// which is a bug in the caller. Still output something parseable.
+		raw, _ = json.Marshal(&JSONEvent{
+			Type:      "message",
+			Level:     "error",
+			Message:   fmt.Sprintf("error encoding %q event: %s", e.Type, err),
+			Timestamp: e.Timestamp,
+		})
+	}
+
+	if e.Level == "error" {
+		u.Ui.Error(string(raw))
+		return
+	}
+
+	u.Ui.Output(string(raw))
+}
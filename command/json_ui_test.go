@@ -0,0 +1,82 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestJSONUi_impl(t *testing.T) {
+	var _ cli.Ui = new(JSONUi)
+}
+
+func TestJSONUi(t *testing.T) {
+	mock := new(cli.MockUi)
+	ui := &JSONUi{Ui: mock}
+
+	ui.Output("hello")
+	ui.Error("bad")
+	ui.Event(&JSONEvent{
+		Type: "thing",
+		Data: map[string]interface{}{"key": "value"},
+	})
+
+	out := testJSONEvents(t, mock.OutputWriter.String())
+	if len(out) != 2 {
+		t.Fatalf("bad: %#v", out)
+	}
+	if out[0].Type != "message" || out[0].Message != "hello" || out[0].Level != "info" {
+		t.Fatalf("bad: %#v", out[0])
+	}
+	if out[1].Type != "thing" || out[1].Data["key"] != "value" {
+		t.Fatalf("bad: %#v", out[1])
+	}
+
+	var e JSONEvent
+	if err := json.Unmarshal(mock.ErrorWriter.Bytes(), &e); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if e.Level != "error" || e.Message != "bad" {
+		t.Fatalf("bad: %#v", e)
+	}
+}
+
+func TestJSONUi_ask(t *testing.T) {
+	ui := &JSONUi{Ui: new(cli.MockUi)}
+	if _, err := ui.Ask("foo"); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+// testJSONEvents parses the newline delimited events written by a JSONUi.
+func testJSONEvents(t *testing.T, out string) []*JSONEvent {
+	var result []*JSONEvent
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var e JSONEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("bad line %q: %s", line, err)
+		}
+		result = append(result, &e)
+	}
+
+	return result
+}
+
+// testJSONEvent returns the first event of the given type, failing the
+// test if there is none.
+func testJSONEvent(t *testing.T, es []*JSONEvent, typ string) *JSONEvent {
+	for _, e := range es {
+		if e.Type == typ {
+			return e
+		}
+	}
+
+	t.Fatalf("no %q event in %#v", typ, es)
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/errwrap"
@@ -20,6 +21,7 @@ import (
 	"github.com/hashicorp/terraform/helper/variables"
 	"github.com/hashicorp/terraform/helper/wrappedstreams"
 	"github.com/hashicorp/terraform/state"
+	"github.com/hashicorp/terraform/state/remote"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/cli"
 	"github.com/mitchellh/colorstring"
@@ -31,11 +33,27 @@ type Meta struct {
 	ContextOpts *terraform.ContextOpts
 	Ui          cli.Ui
 
+	// AuditLog, if enabled, gets a record of every change to the state
+	// saved by the command.
+	AuditLog *AuditLog
+
+	// ProviderPlugins are the provider plugins found on disk. The plugins
+	// recorded in the plugin lock file are used instead of the providers
+	// in ContextOpts.
+	ProviderPlugins *ProviderPlugins
+
 	// State read when calling `Context`. This is available after calling
 	// `Context`.
 	state       state.State
 	stateResult *StateResult
 
+	// stateReader is the read-only state loaded by StateReader, and
+	// stateKey is where state and stateReader were loaded from. They're
+	// loaded again if the Meta is changed to load the state from somewhere
+	// else, or after InvalidateState.
+	stateReader state.StateReader
+	stateKey    *stateKey
+
 	// This can be set by the command itself to provide extra hooks.
 	extraHooks []terraform.Hook
 
@@ -54,6 +72,34 @@ type Meta struct {
 	color bool
 	oldUi cli.Ui
 
+	// backendOverride is set with -backend-override to the path of a
+	// remote state configuration to use instead of the saved one.
+	backendOverride string
+
+	// stateFutureAllowed is set with -allow-future-state to operate on a
+	// state written by a newer version of Terraform anyway.
+	stateFutureAllowed bool
+
+	// pluginDirs are set with -plugin-dir to find provider plugins only in
+	// these directories.
+	pluginDirs []string
+
+	// moduleMirror is set with -module-mirror to download modules from a
+	// mirror instead of from their sources. See mirrorModuleSource.
+	moduleMirror string
+
+	// jsonUi is set when the command was asked for JSON output. See
+	// enableJSONUi.
+	jsonUi *JSONUi
+
+	// progress is set by commands that apply changes, to count them for
+	// the UI hooks, and compactOutput with -compact-output to show their
+	// progress on a single status line. progressBar is set with -progress
+	// to add a progress bar and an estimate of the time left to it.
+	progress      *applyProgress
+	compactOutput bool
+	progressBar   bool
+
 	// The fields below are expected to be set by the command via
 	// command line flags. See the Apply command for an example.
 	//
@@ -75,19 +121,94 @@ type Meta struct {
 	// shadow is used to enable/disable the shadow graph
 	//
 	// provider is to specify specific resource providers
+	//
+	// providerParallelism, if set, limits the number of concurrent
+	// refreshes against each provider
 	statePath    string
 	stateOutPath string
 	backupPath   string
 	parallelism  int
 	shadow       bool
 	provider     string
+
+	providerParallelism int
+
+	// defaultState and inputDisabled change the defaults of the -state and
+	// -input flags of the commands. See NewMeta.
+	defaultState  string
+	inputDisabled bool
+}
+
+// MetaOpts are the options of NewMeta.
+type MetaOpts struct {
+	// Ui is where the output of the commands goes and where input is
+	// asked for. Color enables colored output, unless -no-color is given.
+	Ui    cli.Ui
+	Color bool
+
+	// ContextOpts are the options of the Terraform contexts created by the
+	// commands, such as the providers and provisioners available.
+	ContextOpts *terraform.ContextOpts
+
+	// AuditLog and ProviderPlugins are optional. See the fields of Meta.
+	AuditLog        *AuditLog
+	ProviderPlugins *ProviderPlugins
+
+	// DataDir is the directory where local data, such as the cache of the
+	// remote state and the downloaded modules, is kept. It takes precedence
+	// over TF_DATA_DIR, and is DefaultDataDir if neither is set.
+	DataDir string
+
+	// StatePath is the state file used by commands that aren't given
+	// -state, instead of DefaultStateFilename.
+	StatePath string
+
+	// DisableInput makes the commands behave as if they were given
+	// -input=false, unless they're given -input=true.
+	DisableInput bool
+
+	// BackendOverride is the path of a remote state configuration to use
+	// instead of the saved one, as with -backend-override.
+	BackendOverride string
+}
+
+// NewMeta returns the Meta to use in the commands of a program that runs
+// them itself, rather than through the terraform binary. The options are
+// defaults that the flags given to a command still take precedence over.
+func NewMeta(opts *MetaOpts) Meta {
+	return Meta{
+		Color:           opts.Color,
+		ContextOpts:     opts.ContextOpts,
+		Ui:              opts.Ui,
+		AuditLog:        opts.AuditLog,
+		ProviderPlugins: opts.ProviderPlugins,
+
+		dataDir:         opts.DataDir,
+		defaultState:    opts.StatePath,
+		inputDisabled:   opts.DisableInput,
+		backendOverride: opts.BackendOverride,
+	}
+}
+
+// defaultStatePath returns the default of the -state flag of the commands.
+func (m *Meta) defaultStatePath() string {
+	if m.defaultState != "" {
+		return m.defaultState
+	}
+
+	return DefaultStateFilename
+}
+
+// defaultInput returns the default of the -input flag of the commands.
+func (m *Meta) defaultInput() bool {
+	return !m.inputDisabled
 }
 
 // initStatePaths is used to initialize the default values for
 // statePath, stateOutPath, and backupPath
 func (m *Meta) initStatePaths() {
 	if m.statePath == "" {
-		m.statePath = DefaultStateFilename
+		m.statePath = m.defaultStatePath()
 	}
 	if m.stateOutPath == "" {
 		m.stateOutPath = m.statePath
@@ -114,7 +235,10 @@ func (m *Meta) Colorize() *colorstring.Colorize {
 // Context returns a Terraform Context taking into account the context
 // options used to initialize this meta configuration.
 func (m *Meta) Context(copts contextOpts) (*terraform.Context, bool, error) {
-	opts := m.contextOpts()
+	opts, err := m.contextOpts()
+	if err != nil {
+		return nil, false, err
+	}
 
 	// First try to just read the plan directly from the path given.
 	f, err := os.Open(copts.Path)
@@ -122,6 +246,16 @@ func (m *Meta) Context(copts contextOpts) (*terraform.Context, bool, error) {
 		plan, err := terraform.ReadPlan(f)
 		f.Close()
 		if err == nil {
+			// Make sure the plan is applied to the state it was created
+			// against, if we were asked to. An explicit -backend-override
+			// takes precedence over the saved remote state, so there's
+			// nothing to compare the plan with then.
+			if copts.CheckPlanRemote && m.backendOverride == "" {
+				if err := m.checkPlanRemote(plan.State); err != nil {
+					return nil, false, err
+				}
+			}
+
 			// Setup our state, force it to use our plan's state
 			stateOpts := m.StateOpts()
 			if plan != nil {
@@ -135,7 +269,12 @@ func (m *Meta) Context(copts contextOpts) (*terraform.Context, bool, error) {
 			}
 
 			// Set our state
-			m.state = result.State
+			m.state, err = m.replicaState(m.auditState(result.State, result.StatePath))
+			if err != nil {
+				return nil, false, err
+			}
+			m.stateResult = result
+			m.stateKey = newStateKey(stateOpts)
 
 			// this is used for printing the saved location later
 			if m.stateOutPath == "" {
@@ -150,6 +289,10 @@ func (m *Meta) Context(copts contextOpts) (*terraform.Context, bool, error) {
 						"variable values, create a new plan file.")
 			}
 
+			if err := m.checkProviderLock(plan.Module); err != nil {
+				return nil, false, err
+			}
+
 			ctx, err := plan.Context(opts)
 			return ctx, true, err
 		}
@@ -164,7 +307,12 @@ func (m *Meta) Context(copts contextOpts) (*terraform.Context, bool, error) {
 	opts.Destroy = copts.Destroy
 
 	// Store the loaded state
-	state, err := m.State()
+	var state state.StateReader
+	if copts.StateReadOnly {
+		state, err = m.StateReader()
+	} else {
+		state, err = m.State()
+	}
 	if err != nil {
 		return nil, false, err
 	}
@@ -185,7 +333,9 @@ func (m *Meta) Context(copts contextOpts) (*terraform.Context, bool, error) {
 		}
 
 		if err != nil {
-			return nil, false, fmt.Errorf("Error loading config: %s", err)
+			return nil, false, fmt.Errorf(
+				"Error loading config: %s",
+				formatLoadError(copts.Path, err, m.Colorize()))
 		}
 	} else {
 		mod = module.NewEmptyTree()
@@ -201,6 +351,10 @@ func (m *Meta) Context(copts contextOpts) (*terraform.Context, bool, error) {
 		return nil, false, err
 	}
 
+	if err := m.checkProviderLock(mod); err != nil {
+		return nil, false, err
+	}
+
 	opts.Module = mod
 	opts.Parallelism = copts.Parallelism
 	opts.State = state.State()
@@ -208,9 +362,55 @@ func (m *Meta) Context(copts contextOpts) (*terraform.Context, bool, error) {
 	return ctx, false, err
 }
 
+// checkPlanRemote verifies that the remote state a plan was created with
+// matches the remote state configured in the data directory, so that a
+// plan can't be applied to the wrong state. Settings that don't change
+// where the state is stored, such as credentials, may differ, and the
+// configured ones replace those of the plan. If there is no remote state
+// configured in the data directory, the plan's remote state is used as-is.
+func (m *Meta) checkPlanRemote(planState *terraform.State) error {
+	path := filepath.Join(m.DataDir(), DefaultStateFilename)
+	cache := remoteCacheState(path)
+	if err := cache.RefreshState(); err != nil {
+		return remoteCacheError(path, err)
+	}
+
+	current := cache.State()
+	if current == nil || current.Remote.Empty() {
+		return nil
+	}
+
+	var planned *terraform.RemoteState
+	if planState != nil {
+		planned = planState.Remote
+	}
+
+	if planned.Empty() {
+		return fmt.Errorf(
+			strings.TrimSpace(errPlanRemoteLocal), current.Remote.Type)
+	}
+	if !remote.SameLocation(planned, current.Remote) {
+		return fmt.Errorf(
+			strings.TrimSpace(errPlanRemoteMismatch),
+			planned.Type, current.Remote.Type)
+	}
+	planState.Remote = current.Remote
+
+	return nil
+}
+
+const (
+	// DataDirEnvVar is the name of the environment variable that can be
+	// used to store the local data somewhere other than DefaultDataDir.
+	DataDirEnvVar = "TF_DATA_DIR"
+)
+
 // DataDir returns the directory where local data will be stored.
 func (m *Meta) DataDir() string {
 	dataDir := DefaultDataDir
+	if envVar := os.Getenv(DataDirEnvVar); envVar != "" {
+		dataDir = envVar
+	}
 	if m.dataDir != "" {
 		dataDir = m.dataDir
 	}
@@ -250,21 +450,71 @@ func (m *Meta) InputMode() terraform.InputMode {
 
 // State returns the state for this meta.
 func (m *Meta) State() (state.State, error) {
+	opts := m.StateOpts()
+	m.checkStateKey(opts)
 	if m.state != nil {
 		return m.state, nil
 	}
 
-	result, err := State(m.StateOpts())
+	result, err := State(opts)
 	if err != nil {
 		return nil, err
 	}
 
-	m.state = result.State
+	// Modifying a state written by a newer version of Terraform could
+	// silently drop anything this version doesn't know about.
+	if s := result.State; s != nil && !m.stateFutureAllowed {
+		if st := s.State(); st != nil && st.FromFutureTerraform() {
+			return nil, fmt.Errorf(
+				strings.TrimSpace(errStateFuture), st.TFVersion, terraform.Version)
+		}
+	}
+
+	m.state, err = m.replicaState(m.auditState(result.State, result.StatePath))
+	if err != nil {
+		return nil, err
+	}
 	m.stateOutPath = result.StatePath
 	m.stateResult = result
+	m.stateKey = newStateKey(opts)
 	return m.state, nil
 }
 
+// auditState wraps the state to record changes to it in the audit log,
+// if the audit log is enabled.
+func (m *Meta) auditState(s state.State, path string) state.State {
+	if !m.AuditLog.Enabled() {
+		return s
+	}
+
+	return newAuditState(s, m.AuditLog, path)
+}
+
+// StateReader returns the state for this meta for commands that only
+// inspect it. The remote state is refreshed but never written to, even if
+// the remote state cache is newer, and the result can't be persisted.
+func (m *Meta) StateReader() (state.StateReader, error) {
+	opts := m.StateOpts()
+	m.checkStateKey(opts)
+	if m.state != nil {
+		return m.state, nil
+	}
+	if m.stateReader != nil {
+		return m.stateReader, nil
+	}
+
+	opts.RemoteReadOnly = true
+	result, err := State(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	m.stateOutPath = result.StatePath
+	m.stateReader = result.State
+	m.stateKey = newStateKey(opts)
+	return result.State, nil
+}
+
 // StateRaw is used to setup the state manually.
 func (m *Meta) StateRaw(opts *StateOpts) (*StateResult, error) {
 	result, err := State(opts)
@@ -275,14 +525,52 @@ func (m *Meta) StateRaw(opts *StateOpts) (*StateResult, error) {
 	m.state = result.State
 	m.stateOutPath = result.StatePath
 	m.stateResult = result
+	m.stateKey = newStateKey(opts)
 	return result, nil
 }
 
+// InvalidateState forgets the state loaded by State, StateReader or
+// StateRaw, so that the next call loads it again. Programs that run
+// several commands with the same Meta call this when the state may have
+// been changed by something else in between.
+func (m *Meta) InvalidateState() {
+	m.state = nil
+	m.stateReader = nil
+	m.stateResult = nil
+	m.stateKey = nil
+}
+
+// checkStateKey invalidates the state loaded earlier if it was loaded from
+// somewhere other than where opts would load it from.
+func (m *Meta) checkStateKey(opts *StateOpts) {
+	if m.stateKey != nil && *m.stateKey != *newStateKey(opts) {
+		m.InvalidateState()
+	}
+}
+
+// stateKey is where a state is loaded from. The path the state is saved
+// to isn't part of it, since loading the state sets that path.
+type stateKey struct {
+	LocalPath          string
+	RemotePath         string
+	RemoteOverridePath string
+	BackupPath         string
+}
+
+func newStateKey(opts *StateOpts) *stateKey {
+	return &stateKey{
+		LocalPath:          opts.LocalPath,
+		RemotePath:         opts.RemotePath,
+		RemoteOverridePath: opts.RemoteOverridePath,
+		BackupPath:         opts.BackupPath,
+	}
+}
+
 // StateOpts returns the default state options
 func (m *Meta) StateOpts() *StateOpts {
 	localPath := m.statePath
 	if localPath == "" {
-		localPath = DefaultStateFilename
+		localPath = m.defaultStatePath()
 	}
 	remotePath := filepath.Join(m.DataDir(), DefaultStateFilename)
 
@@ -292,6 +580,8 @@ func (m *Meta) StateOpts() *StateOpts {
 		RemotePath:    remotePath,
 		RemoteRefresh: true,
 		BackupPath:    m.backupPath,
+
+		RemoteOverridePath: m.backendOverride,
 	}
 }
 
@@ -305,6 +595,11 @@ func (m *Meta) UIInput() terraform.UIInput {
 // PersistState is used to write out the state, handling backup of
 // the existing state file and respecting path configurations.
 func (m *Meta) PersistState(s *terraform.State) error {
+	// Record that this version of Terraform modified the state
+	if s != nil {
+		s.TFVersion = terraform.Version
+	}
+
 	if err := m.state.WriteState(s); err != nil {
 		return err
 	}
@@ -330,10 +625,15 @@ func (m *Meta) StdinPiped() bool {
 
 // contextOpts returns the options to use to initialize a Terraform
 // context with the settings from this Meta.
-func (m *Meta) contextOpts() *terraform.ContextOpts {
+func (m *Meta) contextOpts() (*terraform.ContextOpts, error) {
 	var opts terraform.ContextOpts = *m.ContextOpts
 
-	opts.Hooks = []terraform.Hook{m.uiHook(), &terraform.DebugHook{}}
+	var uiHook terraform.Hook = m.uiHook()
+	if m.jsonUi != nil {
+		uiHook = &JSONHook{Ui: m.jsonUi, Progress: m.progress}
+	}
+
+	opts.Hooks = []terraform.Hook{uiHook, &terraform.DebugHook{}}
 	opts.Hooks = append(opts.Hooks, m.ContextOpts.Hooks...)
 	opts.Hooks = append(opts.Hooks, m.extraHooks...)
 
@@ -351,14 +651,49 @@ func (m *Meta) contextOpts() *terraform.ContextOpts {
 	opts.Targets = m.targets
 	opts.UIInput = m.UIInput()
 	opts.Shadow = m.shadow
+	opts.StateFutureAllowed = m.stateFutureAllowed
+
+	providers, err := m.lockedProviders(opts.Providers)
+	if err != nil {
+		return nil, err
+	}
+	opts.Providers = providers
+
+	if m.providerParallelism > 0 {
+		opts.Providers = limitProviders(opts.Providers, m.providerParallelism)
+	}
+
+	backendValues, err := m.backendValues()
+	if err != nil {
+		return nil, err
+	}
+	opts.BackendValues = backendValues
+
+	return &opts, nil
+}
+
+// backendValues returns the settings the remote state configuration
+// exports for "${backend.NAME}" interpolations, or nil if remote state
+// isn't configured or doesn't export them.
+func (m *Meta) backendValues() (map[string]string, error) {
+	conf, _, err := m.remoteStateConfig()
+	if err != nil || conf == nil {
+		return nil, err
+	}
+
+	t := strings.ToLower(conf.Type)
+	result, err := remote.ExportedConfig(t, conf.Config)
+	if err != nil {
+		return nil, fmt.Errorf("Error in the %q remote state configuration: %s", t, err)
+	}
 
-	return &opts
+	return result, nil
 }
 
 // flags adds the meta flags to the given FlagSet.
 func (m *Meta) flagSet(n string) *flag.FlagSet {
 	f := flag.NewFlagSet(n, flag.ContinueOnError)
-	f.BoolVar(&m.input, "input", true, "input")
+	f.BoolVar(&m.input, "input", m.defaultInput(), "input")
 	f.Var((*variables.Flag)(&m.variables), "var", "variables")
 	f.Var((*variables.FlagFile)(&m.variables), "var-file", "variable file")
 	f.Var((*FlagStringSlice)(&m.targets), "target", "resource to target")
@@ -399,7 +734,8 @@ func (m *Meta) moduleStorage(root string) getter.Storage {
 		Storage: &getter.FolderStorage{
 			StorageDir: filepath.Join(root, "modules"),
 		},
-		Ui: m.Ui,
+		Ui:     m.Ui,
+		Mirror: m.moduleMirror,
 	}
 }
 
@@ -426,6 +762,31 @@ func (m *Meta) process(args []string, vars bool) []string {
 		}
 	}
 
+	// Allow operating on states from newer Terraform versions. This is
+	// accepted by every command that loads a state.
+	for i, v := range args {
+		if v == "-allow-future-state" {
+			m.stateFutureAllowed = true
+			args = append(args[:i], args[i+1:]...)
+			break
+		}
+	}
+
+	// Use another remote state configuration for this run. This is also
+	// accepted by every command that loads a state.
+	for i, v := range args {
+		if v == "-backend-override" && i+1 < len(args) {
+			m.backendOverride = args[i+1]
+			args = append(args[:i], args[i+2:]...)
+			break
+		}
+		if strings.HasPrefix(v, "-backend-override=") {
+			m.backendOverride = strings.TrimPrefix(v, "-backend-override=")
+			args = append(args[:i], args[i+1:]...)
+			break
+		}
+	}
+
 	// Set the UI
 	m.oldUi = m.Ui
 	m.Ui = &cli.ConcurrentUi{
@@ -461,11 +822,84 @@ func (m *Meta) process(args []string, vars bool) []string {
 	return args
 }
 
+// enableJSONUi replaces the Ui with a JSONUi so that all output of the
+// command is written as JSON events. This must be called after the flags
+// are parsed. Colors are disabled since they would only add codes to the
+// messages, and input is disabled since prompts can't be represented.
+func (m *Meta) enableJSONUi() *JSONUi {
+	m.color = false
+	m.Color = false
+	m.input = false
+
+	raw := m.oldUi
+	if raw == nil {
+		raw = m.Ui
+	}
+
+	m.jsonUi = &JSONUi{Ui: raw}
+	m.Ui = &cli.ConcurrentUi{Ui: m.jsonUi}
+	return m.jsonUi
+}
+
+// jsonEvent emits a structured event if JSON output was enabled. In the
+// normal human readable mode this does nothing, since commands show the
+// same information as messages.
+func (m *Meta) jsonEvent(t string, data map[string]interface{}) {
+	if m.jsonUi == nil {
+		return
+	}
+
+	m.jsonUi.Event(&JSONEvent{Type: t, Data: data})
+}
+
+// runInterruptible calls f, which runs an operation on ctx, in a goroutine
+// and waits for it to complete. If an interrupt is received on shutdownCh
+// while waiting, ctx is asked to stop gracefully: in-flight resource
+// operations are allowed to finish so that their results are kept, and
+// stopped is true. A second interrupt gives up waiting and returns with
+// aborted set, in which case the caller must exit without using anything
+// f produced since it may still be running.
+func (m *Meta) runInterruptible(
+	ctx *terraform.Context,
+	shutdownCh <-chan struct{},
+	f func()) (stopped, aborted bool) {
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		f()
+	}()
+
+	select {
+	case <-shutdownCh:
+		m.Ui.Output("Interrupt received. Gracefully shutting down...")
+		stopped = true
+
+		// Stop execution
+		go ctx.Stop()
+
+		// Still get the result, since there is still one
+		select {
+		case <-shutdownCh:
+			m.Ui.Error(
+				"Two interrupts received. Exiting immediately. Note that data\n" +
+					"loss may have occurred.")
+			return true, true
+		case <-doneCh:
+		}
+	case <-doneCh:
+	}
+
+	return stopped, false
+}
+
 // uiHook returns the UiHook to use with the context.
 func (m *Meta) uiHook() *UiHook {
 	return &UiHook{
-		Colorize: m.Colorize(),
-		Ui:       m.Ui,
+		Colorize:    m.Colorize(),
+		Ui:          m.Ui,
+		Progress:    m.progress,
+		Compact:     m.compactOutput || m.progressBar,
+		ProgressBar: m.progressBar,
 	}
 }
 
@@ -488,6 +922,13 @@ func (m *Meta) addModuleDepthFlag(flags *flag.FlagSet, moduleDepth *int) {
 	}
 }
 
+// addPlanDisplayFlags adds the -compact and -full flags, which set how
+// the changes to attributes holding JSON documents are shown in plans.
+func (m *Meta) addPlanDisplayFlags(flags *flag.FlagSet, opts *FormatPlanOpts) {
+	flags.BoolVar(&opts.Compact, "compact", false, "compact")
+	flags.BoolVar(&opts.Full, "full", false, "full")
+}
+
 // outputShadowError outputs the error from ctx.ShadowError. If the
 // error is nil then nothing happens. If output is false then it isn't
 // outputted to the user (you can define logic to guard against outputting).
@@ -554,4 +995,45 @@ type contextOpts struct {
 
 	// Number of concurrent operations allowed
 	Parallelism int
+
+	// StateReadOnly, if set, loads the state with StateReader so that
+	// the remote state is never written to. This should be set by
+	// commands that only inspect the state.
+	StateReadOnly bool
+
+	// CheckPlanRemote, if set and Path is a plan, verifies that the plan
+	// was created against the remote state configured for the current
+	// directory. This should be set by commands that modify state.
+	CheckPlanRemote bool
 }
+
+const errPlanRemoteLocal = `
+The plan was created with local state, but remote state (%s) is
+configured for this directory. Applying it would write the result to a
+local state file instead of the remote state.
+
+Please create a new plan with the current remote state configuration.
+`
+
+const errPlanRemoteMismatch = `
+The plan was created against a different remote state than the one
+configured for this directory. Applying it would modify a state other
+than the one this directory manages.
+
+  Plan remote state:    %s
+  Current remote state: %s
+
+The types shown can be the same if only the remote state settings differ.
+If the remote state was reconfigured since the plan was created, please
+create a new plan.
+`
+
+const errStateFuture = `
+The state was written by Terraform %s, which is newer than this version
+(%s). Modifying it could silently drop data that this version of
+Terraform doesn't understand.
+
+Please upgrade Terraform to at least the version that wrote the state.
+If you're sure this is safe, run the command again with the
+-allow-future-state flag.
+`
@@ -6,9 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
 )
 
 func TestMetaColorize(t *testing.T) {
@@ -221,6 +223,164 @@ func TestMeta_initStatePaths(t *testing.T) {
 	}
 }
 
+func TestMeta_dataDir(t *testing.T) {
+	old := os.Getenv(DataDirEnvVar)
+	defer os.Setenv(DataDirEnvVar, old)
+
+	cases := []struct {
+		EnvVar   string
+		DataDir  string
+		Expected string
+	}{
+		{"", "", DefaultDataDir},
+		{"/tmp/tf-data", "", "/tmp/tf-data"},
+		{"/tmp/tf-data", "foo", "foo"},
+		{"", "foo", "foo"},
+	}
+
+	for _, tc := range cases {
+		os.Setenv(DataDirEnvVar, tc.EnvVar)
+		m := &Meta{dataDir: tc.DataDir}
+		if actual := m.DataDir(); actual != tc.Expected {
+			t.Fatalf("%#v: bad: %s", tc, actual)
+		}
+	}
+}
+
+func TestMeta_dataDirRemoteState(t *testing.T) {
+	old := os.Getenv(DataDirEnvVar)
+	defer os.Setenv(DataDirEnvVar, old)
+	os.Setenv(DataDirEnvVar, "/tmp/tf-data")
+
+	m := new(Meta)
+	expected := filepath.Join("/tmp/tf-data", DefaultStateFilename)
+	if actual := m.StateOpts().RemotePath; actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestMeta_stateMemoized(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	first := testState()
+	first.Serial = 1
+	firstPath := testStateFile(t, first)
+	second := testState()
+	second.Serial = 2
+	secondPath := testStateFile(t, second)
+
+	m := &Meta{statePath: firstPath, stateOutPath: firstPath}
+	s, err := m.State()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if s.State().Serial != 1 {
+		t.Fatalf("bad: %#v", s.State())
+	}
+
+	// The same state is returned while it's loaded from the same place...
+	if again, err := m.State(); err != nil || again != s {
+		t.Fatalf("bad: %#v %s", again, err)
+	}
+	if r, err := m.StateReader(); err != nil || r != s {
+		t.Fatalf("bad: %#v %s", r, err)
+	}
+
+	// ...and it's loaded again from somewhere else
+	m.statePath = secondPath
+	m.stateOutPath = secondPath
+	s, err = m.State()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if s.State().Serial != 2 {
+		t.Fatalf("bad: %#v", s.State())
+	}
+}
+
+func TestMeta_InvalidateState(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	original := testState()
+	path := testStateFile(t, original)
+
+	m := &Meta{statePath: path, stateOutPath: path}
+	r, err := m.StateReader()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if again, err := m.StateReader(); err != nil || again != r {
+		t.Fatalf("bad: %#v %s", again, err)
+	}
+
+	// Changed by something else
+	changed := testState()
+	changed.Serial = original.Serial + 1
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := terraform.WriteState(changed, f); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	f.Close()
+
+	m.InvalidateState()
+	r, err = m.StateReader()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if r.State().Serial != changed.Serial {
+		t.Fatalf("bad: %#v", r.State())
+	}
+}
+
+func TestNewMeta(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	path := testStateFile(t, testState())
+	ui := new(cli.MockUi)
+	c := &StateListCommand{
+		Meta: NewMeta(&MetaOpts{
+			Ui:           ui,
+			ContextOpts:  testCtxConfig(testProvider()),
+			DataDir:      filepath.Join(tmp, "data"),
+			StatePath:    path,
+			DisableInput: true,
+		}),
+	}
+
+	// The state is read from StatePath without -state
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if actual := strings.TrimSpace(ui.OutputWriter.String()); actual != "test_instance.foo" {
+		t.Fatalf("bad: %s", actual)
+	}
+	if c.input {
+		t.Fatal("input should be disabled")
+	}
+	if actual := c.DataDir(); actual != filepath.Join(tmp, "data") {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// The flags still take precedence
+	empty := testStateFile(t, terraform.NewState())
+	ui.OutputWriter.Reset()
+	if code := c.Run([]string{"-input=true", "-state", empty}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if actual := ui.OutputWriter.String(); actual != "" {
+		t.Fatalf("bad: %s", actual)
+	}
+	if !c.input {
+		t.Fatal("input should be enabled")
+	}
+}
+
 func TestMeta_addModuleDepthFlag(t *testing.T) {
 	old := os.Getenv(ModuleDepthEnvVar)
 	defer os.Setenv(ModuleDepthEnvVar, old)
@@ -0,0 +1,414 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-getter"
+	"github.com/hashicorp/terraform/config/module"
+)
+
+// ModuleLockFilename is the name of the file in the configuration directory
+// that records the sources "terraform init" downloaded modules from. It's
+// meant to be kept with the configuration in version control, so that the
+// same modules are downloaded everywhere.
+const ModuleLockFilename = ".terraform-modules.lock"
+
+// moduleLock is the content of the module lock file. The modules are keyed
+// by their key in the module storage.
+type moduleLock struct {
+	Modules map[string]*moduleLockEntry `json:"modules"`
+}
+
+// moduleLockEntry is the source a module was downloaded from, after
+// resolving it in a registry if needed, the Git commit it was at and the
+// digest of its files. See moduleDigest.
+type moduleLockEntry struct {
+	Source string `json:"source"`
+	Commit string `json:"commit,omitempty"`
+	Digest string `json:"digest"`
+}
+
+// lockedModuleStorage is a module storage that pins modules to the sources
+// in a module lock file, and records the sources of the modules downloaded
+// in Result. A module that is in the lock file must still be at the commit
+// and have the digest recorded there. Modules being upgraded aren't pinned.
+type lockedModuleStorage struct {
+	getter.Storage
+
+	// Lock is the module lock file to pin modules to. If it's nil, no
+	// modules are pinned.
+	Lock *moduleLock
+
+	// UpgradeAll upgrades all modules, and Upgrade the modules with the
+	// given keys.
+	UpgradeAll bool
+	Upgrade    map[string]bool
+
+	Result *moduleLock
+}
+
+func (s *lockedModuleStorage) PinnedSource(key string) (string, bool) {
+	if s.upgrading(key) {
+		return "", false
+	}
+
+	entry := s.lockEntry(key)
+	if entry == nil {
+		return "", false
+	}
+	if entry.Commit == "" {
+		return entry.Source, true
+	}
+
+	source, err := pinGitSource(entry.Source, entry.Commit)
+	if err != nil {
+		return "", false
+	}
+	return source, true
+}
+
+func (s *lockedModuleStorage) Get(key string, source string, update bool) error {
+	// A module being upgraded is downloaded again instead of updated in
+	// place, since updating a Git repository doesn't update moved tags.
+	if update && s.upgrading(key) {
+		dir, found, err := s.Storage.Dir(key)
+		if err != nil {
+			return err
+		}
+		if found {
+			if err := os.RemoveAll(dir); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.Storage.Get(key, source, false); err != nil {
+		return err
+	}
+
+	// Local modules are part of the configuration already
+	if strings.HasPrefix(source, "file://") {
+		return nil
+	}
+
+	actual, err := s.entry(key, source)
+	if err != nil {
+		return err
+	}
+
+	entry := s.lockEntry(key)
+	if entry == nil || s.upgrading(key) {
+		s.Result.Modules[key] = actual
+		return nil
+	}
+
+	// A module downloaded before the lock file was changed is updated to
+	// the commit in the lock file.
+	if entry.Commit != "" && entry.Commit != actual.Commit {
+		if err := s.Storage.Get(key, source, true); err != nil {
+			return err
+		}
+		if actual, err = s.entry(key, source); err != nil {
+			return err
+		}
+	}
+	if entry.Commit != "" && entry.Commit != actual.Commit {
+		return fmt.Errorf(errModuleLockCommit, entry.Source, actual.Commit, entry.Commit)
+	}
+	if entry.Digest != "" && entry.Digest != actual.Digest {
+		return fmt.Errorf(errModuleLockDigest, entry.Source)
+	}
+
+	s.Result.Modules[key] = &moduleLockEntry{
+		Source: entry.Source,
+		Commit: entry.Commit,
+		Digest: actual.Digest,
+	}
+	return nil
+}
+
+// entry returns the lock file entry for the module with the given key as
+// it's downloaded now.
+func (s *lockedModuleStorage) entry(key, source string) (*moduleLockEntry, error) {
+	dir, _, err := s.Storage.Dir(key)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := gitCommit(dir)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := moduleDigest(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading module in %s: %s", dir, err)
+	}
+
+	return &moduleLockEntry{Source: source, Commit: commit, Digest: digest}, nil
+}
+
+func (s *lockedModuleStorage) upgrading(key string) bool {
+	return s.UpgradeAll || s.Upgrade[key]
+}
+
+func (s *lockedModuleStorage) lockEntry(key string) *moduleLockEntry {
+	if s.Lock == nil {
+		return nil
+	}
+
+	return s.Lock.Modules[key]
+}
+
+// outputModuleUpgrades outputs for each module upgraded whether its files
+// changed, by comparing its digest with the one in the module lock file from
+// before.
+func (m *Meta) outputModuleUpgrades(
+	mod *module.Tree,
+	before *moduleLock,
+	storage *lockedModuleStorage) {
+	addrs := moduleKeys(mod)
+	keys := make(map[string]string)
+	var upgraded []string
+	for key := range storage.Result.Modules {
+		if storage.upgrading(key) {
+			keys[addrs[key]] = key
+			upgraded = append(upgraded, addrs[key])
+		}
+	}
+	sort.Strings(upgraded)
+
+	for _, addr := range upgraded {
+		key := keys[addr]
+		entry := storage.Result.Modules[key]
+		var old *moduleLockEntry
+		if before != nil {
+			old = before.Modules[key]
+		}
+
+		switch {
+		case old == nil:
+			m.Ui.Output(fmt.Sprintf("- %s: downloaded, digest %s",
+				addr, shortDigest(entry.Digest)))
+		case old.Digest != entry.Digest:
+			m.Ui.Output(fmt.Sprintf("- %s: changed, digest %s -> %s",
+				addr, shortDigest(old.Digest), shortDigest(entry.Digest)))
+		default:
+			m.Ui.Output(fmt.Sprintf("- %s: unchanged", addr))
+		}
+
+		event := map[string]interface{}{
+			"module":  addr,
+			"source":  entry.Source,
+			"digest":  entry.Digest,
+			"changed": old == nil || old.Digest != entry.Digest,
+		}
+		if old != nil {
+			event["old_digest"] = old.Digest
+		}
+		m.jsonEvent("module_upgraded", event)
+	}
+}
+
+// moduleUpgradeKeys returns the storage keys of the modules with the given
+// addresses, such as "module.vpc", and of the modules they use.
+func moduleUpgradeKeys(mod *module.Tree, addrs []string) (map[string]bool, error) {
+	keys := moduleKeys(mod)
+	result := make(map[string]bool)
+	for _, addr := range addrs {
+		parts := strings.Split(addr, ".")
+		valid := len(parts)%2 == 0
+		for i := 0; valid && i < len(parts); i += 2 {
+			valid = parts[i] == "module" && parts[i+1] != ""
+		}
+		if !valid {
+			return nil, fmt.Errorf(
+				"Invalid module address %q. A module is given as module.NAME,\n"+
+					"or module.NAME.module.CHILD for a module used by another.", addr)
+		}
+
+		found := false
+		for key, a := range keys {
+			if a == addr || strings.HasPrefix(a, addr+".") {
+				result[key] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("The module %s isn't used by the configuration.", addr)
+		}
+	}
+
+	return result, nil
+}
+
+// moduleKeys returns the addresses of all the modules used by the
+// configuration, such as "module.vpc.module.subnets", by their storage keys.
+func moduleKeys(mod *module.Tree) map[string]string {
+	result := make(map[string]string)
+	var walk func(*module.Tree)
+	walk = func(t *module.Tree) {
+		children := t.Children()
+		for _, m := range t.Modules() {
+			path := make([]string, len(t.Path()), len(t.Path())+1)
+			copy(path, t.Path())
+			path = append(path, m.Name)
+
+			result[module.StorageKey(path, m)] = "module." + strings.Join(path, ".module.")
+			if child, ok := children[m.Name]; ok {
+				walk(child)
+			}
+		}
+	}
+	walk(mod)
+
+	return result
+}
+
+// shortDigest returns the start of a digest, for output.
+func shortDigest(digest string) string {
+	if len(digest) > 8 {
+		return digest[:8]
+	}
+
+	return digest
+}
+
+// gitCommit returns the commit checked out in the Git repository at dir, or
+// an empty string if dir isn't a Git repository.
+func gitCommit(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return "", nil
+	}
+
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Error reading the commit of module in %s: %s", dir, err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// moduleDigest returns the SHA256 digest of the files of the module in dir,
+// hex encoded. The digest covers the path and content of each file, but not
+// the Git repository of the module.
+func moduleDigest(dir string) (string, error) {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if info.Mode().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, path := range paths {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return "", err
+		}
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(h, "%s %s\n", sum, filepath.ToSlash(rel))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// pinGitSource returns the Git source with its ref set to the given commit.
+func pinGitSource(source, commit string) (string, error) {
+	forced, source := splitForcedGetter(source)
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Set("ref", commit)
+	u.RawQuery = q.Encode()
+
+	if forced != "" {
+		return forced + "::" + u.String(), nil
+	}
+	return u.String(), nil
+}
+
+// splitForcedGetter splits the forced getter, such as "git", from a detected
+// module source.
+func splitForcedGetter(source string) (string, string) {
+	if idx := strings.Index(source, "::"); idx >= 0 && !strings.Contains(source[:idx], "/") {
+		return source[:idx], source[idx+2:]
+	}
+
+	return "", source
+}
+
+// readModuleLock reads the module lock file at the given path. If there's
+// no lock file, nil is returned.
+func readModuleLock(path string) (*moduleLock, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lock moduleLock
+	if err := json.NewDecoder(f).Decode(&lock); err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", path, err)
+	}
+
+	return &lock, nil
+}
+
+// writeModuleLock writes the module lock file at the given path. Without
+// any modules to record, the lock file is removed instead.
+func writeModuleLock(path string, lock *moduleLock) error {
+	if len(lock.Modules) == 0 {
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return err
+	}
+
+	data, err := json.MarshalIndent(lock, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+const errModuleLockDigest = `The files of the module %s don't match the digest recorded in
+the module lock file, so the module changed at its source. Run
+"terraform init -upgrade" to accept the change and update the lock file.`
+
+const errModuleLockCommit = `The module %s is at commit %s, but the module lock file
+records commit %s. Run "terraform init -upgrade" to download the modules
+again and update the lock file.`
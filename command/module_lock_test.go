@@ -0,0 +1,328 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestPinGitSource(t *testing.T) {
+	cases := []struct {
+		Source   string
+		Expected string
+	}{
+		{
+			"git::https://example.com/vpc.git",
+			"git::https://example.com/vpc.git?ref=abc",
+		},
+		{
+			"git::https://example.com/vpc.git?ref=v1.0.0",
+			"git::https://example.com/vpc.git?ref=abc",
+		},
+		{
+			"https://example.com/vpc.git",
+			"https://example.com/vpc.git?ref=abc",
+		},
+	}
+
+	for _, tc := range cases {
+		actual, err := pinGitSource(tc.Source, "abc")
+		if err != nil {
+			t.Fatalf("%s: err: %s", tc.Source, err)
+		}
+		if actual != tc.Expected {
+			t.Fatalf("%s: bad: %s", tc.Source, actual)
+		}
+	}
+}
+
+func TestInit_moduleLock(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	repo := filepath.Join(tmp, "repo")
+	first := testGitCommit(t, repo, "v1")
+
+	config := fmt.Sprintf(`
+module "example" {
+  source = "git::file://%s?ref=v1"
+}
+`, filepath.ToSlash(repo))
+	if err := ioutil.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	lock, err := readModuleLock(ModuleLockFilename)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(lock.Modules) != 1 {
+		t.Fatalf("bad: %#v", lock.Modules)
+	}
+	for _, entry := range lock.Modules {
+		if entry.Commit != first {
+			t.Fatalf("bad: %#v", entry)
+		}
+	}
+
+	// Move the tag. A fresh init still gets the locked commit.
+	second := testGitCommit(t, repo, "v1")
+	if err := os.RemoveAll(DefaultDataDir); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui = new(cli.MockUi)
+	c = &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "ref="+first) {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+
+	// With -upgrade, the module is downloaded at the tag again
+	ui = new(cli.MockUi)
+	c = &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run([]string{"-upgrade"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	lock, err = readModuleLock(ModuleLockFilename)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, entry := range lock.Modules {
+		if entry.Commit != second {
+			t.Fatalf("bad: %#v", entry)
+		}
+	}
+}
+
+func TestInit_moduleLockLocal(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	if err := os.MkdirAll("child", 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join("child", "main.tf"), []byte(`variable "foo" { default = "bar" }`), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	config := `
+module "child" {
+  source = "./child"
+}
+`
+	if err := ioutil.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// Local modules aren't locked
+	if _, err := os.Stat(ModuleLockFilename); !os.IsNotExist(err) {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestInit_moduleUpgradeSelected(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	repoA := filepath.Join(tmp, "a")
+	repoB := filepath.Join(tmp, "b")
+	testGitCommit(t, repoA, "v1")
+	firstB := testGitCommit(t, repoB, "v1")
+
+	config := fmt.Sprintf(`
+module "a" {
+  source = "git::file://%s?ref=v1"
+}
+
+module "b" {
+  source = "git::file://%s?ref=v1"
+}
+`, filepath.ToSlash(repoA), filepath.ToSlash(repoB))
+	if err := ioutil.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	secondA := testGitCommit(t, repoA, "v1")
+	testGitCommit(t, repoB, "v1")
+
+	ui = new(cli.MockUi)
+	c = &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run([]string{"-upgrade=module.a"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "- module.a: changed, digest") {
+		t.Fatalf("bad:\n\n%s", output)
+	}
+	if strings.Contains(output, "module.b") {
+		t.Fatalf("module.b should not be upgraded:\n\n%s", output)
+	}
+
+	lock, err := readModuleLock(ModuleLockFilename)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	commits := make(map[string]bool)
+	for _, entry := range lock.Modules {
+		commits[entry.Commit] = true
+	}
+	if len(commits) != 2 || !commits[secondA] || !commits[firstB] {
+		t.Fatalf("bad: %#v", lock.Modules)
+	}
+}
+
+func TestInit_moduleUpgradeMissing(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	if err := ioutil.WriteFile("main.tf", []byte(`resource "test_instance" "foo" {}`), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, arg := range []string{"-upgrade=module.vpc", "-upgrade=vpc"} {
+		ui := new(cli.MockUi)
+		c := &InitCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(testProvider()),
+				Ui:          ui,
+			},
+		}
+		if code := c.Run([]string{arg}); code != 1 {
+			t.Fatalf("%s: bad: %d\n\n%s", arg, code, ui.OutputWriter.String())
+		}
+	}
+}
+
+func TestModuleDigest(t *testing.T) {
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte("# a"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	before, err := moduleDigest(dir)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The Git repository isn't part of the digest
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("x"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual, err := moduleDigest(dir); err != nil || actual != before {
+		t.Fatalf("bad: %s %s", actual, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte("# b"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual, err := moduleDigest(dir); err != nil || actual == before {
+		t.Fatalf("bad: %s %s", actual, err)
+	}
+}
+
+// testGitCommit makes a commit in the Git repository at dir, creating it if
+// needed, tags it with the given tag, and returns the commit.
+func testGitCommit(t *testing.T, dir, tag string) string {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %s\n\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		run("init", "-q", "-b", "master")
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, "main.tf"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	fmt.Fprintln(f, "# change")
+	f.Close()
+
+	run("add", "-A")
+	run("commit", "-q", "-m", "change")
+	run("tag", "-f", tag)
+	return run("rev-parse", "HEAD")
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-getter"
+)
+
+// mirrorModuleSource returns the source to download the module with the
+// given detected source from the mirror instead. The mirror is either a
+// directory or an HTTP(S) URL. A module is found in a mirror directory at
+// HOST/PATH, or HOST/PATH@REF for a module with a "ref" parameter, and at
+// an HTTP mirror as the same path with ".tar.gz" added. Local modules
+// aren't mirrored, so their source is returned unchanged.
+func mirrorModuleSource(mirror, source string) (string, error) {
+	source, subDir := getter.SourceDirSubdir(source)
+	key, err := moduleMirrorKey(source)
+	if err != nil {
+		return "", fmt.Errorf("Error mirroring module source %s: %s", source, err)
+	}
+
+	var result string
+	switch {
+	case key == "":
+		result = source
+	case strings.HasPrefix(mirror, "http://") || strings.HasPrefix(mirror, "https://"):
+		result = strings.TrimSuffix(mirror, "/") + "/" + key + ".tar.gz"
+	default:
+		dir, err := filepath.Abs(filepath.Join(mirror, filepath.FromSlash(key)))
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(dir); err != nil {
+			return "", fmt.Errorf(errModuleMirrorMissing, source, dir)
+		}
+
+		result = dir
+	}
+
+	if subDir != "" {
+		result += "//" + subDir
+	}
+
+	return result, nil
+}
+
+// moduleMirrorKey returns the path of a module in a mirror from its
+// detected source, such as "github.com/hashicorp/example@v1.0.0" for
+// "git::https://github.com/hashicorp/example.git?ref=v1.0.0". The key is
+// empty for a local module.
+func moduleMirrorKey(source string) (string, error) {
+	_, source = splitForcedGetter(source)
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "file" || u.Host == "" {
+		return "", nil
+	}
+
+	key := u.Host + "/" + strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if ref := u.Query().Get("ref"); ref != "" {
+		key += "@" + ref
+	}
+
+	return key, nil
+}
+
+const errModuleMirrorMissing = `The module %s isn't in the module mirror.
+It was expected at %s.`
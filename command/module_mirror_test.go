@@ -0,0 +1,87 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModuleMirrorKey(t *testing.T) {
+	cases := []struct {
+		Source string
+		Key    string
+	}{
+		{
+			"git::https://github.com/hashicorp/example.git",
+			"github.com/hashicorp/example",
+		},
+		{
+			"git::https://github.com/hashicorp/example.git?ref=v1.0.0",
+			"github.com/hashicorp/example@v1.0.0",
+		},
+		{
+			"git::ssh://git@example.com/modules/vpc.git",
+			"example.com/modules/vpc",
+		},
+		{
+			"https://example.com/modules/vpc",
+			"example.com/modules/vpc",
+		},
+		{
+			"file:///tmp/modules/vpc",
+			"",
+		},
+	}
+
+	for _, tc := range cases {
+		key, err := moduleMirrorKey(tc.Source)
+		if err != nil {
+			t.Fatalf("%s: err: %s", tc.Source, err)
+		}
+		if key != tc.Key {
+			t.Fatalf("%s: bad: %s", tc.Source, key)
+		}
+	}
+}
+
+func TestMirrorModuleSource(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	dir := filepath.Join(tmp, "mirror", "github.com", "hashicorp", "example@v1.0.0")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	source := "git::https://github.com/hashicorp/example.git?ref=v1.0.0"
+	actual, err := mirrorModuleSource("mirror", source+"//vpc")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != dir+"//vpc" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	actual, err = mirrorModuleSource("https://mirror.example.com/modules/", source)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := "https://mirror.example.com/modules/github.com/hashicorp/example@v1.0.0.tar.gz"
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// Local modules aren't mirrored
+	actual, err = mirrorModuleSource("mirror", "file:///tmp/vpc")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != "file:///tmp/vpc" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// A module missing from a mirror directory is an error
+	if _, err := mirrorModuleSource("mirror", "git::https://github.com/hashicorp/other.git"); err == nil {
+		t.Fatal("should error")
+	}
+}
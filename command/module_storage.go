@@ -8,10 +8,12 @@ import (
 )
 
 // uiModuleStorage implements module.Storage and is just a proxy to output
-// to the UI any Get operations.
+// to the UI any Get operations. If Mirror is set, modules are downloaded
+// from the mirror instead of from their sources.
 type uiModuleStorage struct {
 	Storage getter.Storage
 	Ui      cli.Ui
+	Mirror  string
 }
 
 func (s *uiModuleStorage) Dir(key string) (string, bool, error) {
@@ -24,6 +26,17 @@ func (s *uiModuleStorage) Get(key string, source string, update bool) error {
 		updateStr = " (update)"
 	}
 
+	if s.Mirror != "" {
+		mirrored, err := mirrorModuleSource(s.Mirror, source)
+		if err != nil {
+			return err
+		}
+		if mirrored != source {
+			s.Ui.Output(fmt.Sprintf("Get: %s%s (mirror: %s)", source, updateStr, mirrored))
+			return s.Storage.Get(key, mirrored, update)
+		}
+	}
+
 	s.Ui.Output(fmt.Sprintf("Get: %s%s", source, updateStr))
 	return s.Storage.Get(key, source, update)
 }
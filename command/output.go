@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/terraform"
 )
 
 // OutputCommand is a Command implementation that reads an output
@@ -19,11 +22,13 @@ func (c *OutputCommand) Run(args []string) int {
 	args = c.Meta.process(args, false)
 
 	var module string
-	var jsonOutput bool
+	var jsonOutput, rawOutput, showSensitive bool
 
 	cmdFlags := flag.NewFlagSet("output", flag.ContinueOnError)
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
-	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.BoolVar(&rawOutput, "raw", false, "raw")
+	cmdFlags.BoolVar(&showSensitive, "show-sensitive", false, "show-sensitive")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
 	cmdFlags.StringVar(&module, "module", "", "module")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 
@@ -45,7 +50,18 @@ func (c *OutputCommand) Run(args []string) int {
 		name = args[0]
 	}
 
-	stateStore, err := c.Meta.State()
+	if rawOutput && jsonOutput {
+		c.Ui.Error("The -raw and -json options are mutually exclusive.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+	if rawOutput && name == "" {
+		c.Ui.Error("The -raw option requires the name of a single output.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	stateStore, err := c.Meta.StateReader()
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Error reading state: %s", err))
 		return 1
@@ -78,9 +94,17 @@ func (c *OutputCommand) Run(args []string) int {
 		return 1
 	}
 
+	// When all the outputs are shown, the values of sensitive outputs
+	// are hidden unless asked for. Requesting a single output by name
+	// always shows its value.
 	if name == "" {
 		if jsonOutput {
-			jsonOutputs, err := json.MarshalIndent(mod.Outputs, "", "    ")
+			outputs := mod.Outputs
+			if !showSensitive {
+				outputs = redactSensitiveOutputs(outputs)
+			}
+
+			jsonOutputs, err := json.MarshalIndent(outputs, "", "    ")
 			if err != nil {
 				return 1
 			}
@@ -88,7 +112,12 @@ func (c *OutputCommand) Run(args []string) int {
 			c.Ui.Output(string(jsonOutputs))
 			return 0
 		} else {
-			c.Ui.Output(outputsAsString(state, modPath, nil, false))
+			var schema []*config.Output
+			if !showSensitive {
+				schema = sensitiveOutputSchema(mod.Outputs)
+			}
+
+			c.Ui.Output(outputsAsString(state, modPath, schema, false))
 			return 0
 		}
 	}
@@ -103,7 +132,17 @@ func (c *OutputCommand) Run(args []string) int {
 		return 1
 	}
 
-	if jsonOutput {
+	if rawOutput {
+		output, ok := v.Value.(string)
+		if !ok {
+			c.Ui.Error(fmt.Sprintf(
+				"The output %q is a %s. Only string outputs can be printed\n"+
+					"with -raw, use -json for lists and maps.", name, v.Type))
+			return 1
+		}
+
+		c.Ui.Output(output)
+	} else if jsonOutput {
 		jsonOutputs, err := json.MarshalIndent(v, "", "    ")
 		if err != nil {
 			return 1
@@ -130,6 +169,36 @@ func (c *OutputCommand) Run(args []string) int {
 	return 0
 }
 
+// redactSensitiveOutputs returns a copy of the given outputs with the values
+// of sensitive outputs removed.
+func redactSensitiveOutputs(
+	outputs map[string]*terraform.OutputState) map[string]*terraform.OutputState {
+	result := make(map[string]*terraform.OutputState, len(outputs))
+	for k, v := range outputs {
+		if v.Sensitive {
+			v = &terraform.OutputState{Sensitive: true, Type: v.Type}
+		}
+
+		result[k] = v
+	}
+
+	return result
+}
+
+// sensitiveOutputSchema returns the output configuration marking the
+// outputs that are sensitive in the state, so that outputsAsString hides
+// their values.
+func sensitiveOutputSchema(outputs map[string]*terraform.OutputState) []*config.Output {
+	var result []*config.Output
+	for k, v := range outputs {
+		if v.Sensitive {
+			result = append(result, &config.Output{Name: k, Sensitive: true})
+		}
+	}
+
+	return result
+}
+
 func formatNestedList(indent string, outputList []interface{}) string {
 	outputBuf := new(bytes.Buffer)
 	outputBuf.WriteString(fmt.Sprintf("%s[", indent))
@@ -264,6 +333,14 @@ Options:
   -json            If specified, machine readable output will be
                    printed in JSON format
 
+  -raw             If specified, the value of the single string
+                   output NAME is printed as-is, without any
+                   formatting. Useful for scripting.
+
+  -show-sensitive  If specified, the values of sensitive outputs
+                   are shown when printing all outputs. By default
+                   they are hidden.
+
 `
 	return strings.TrimSpace(helpText)
 }
@@ -236,6 +236,130 @@ func TestOutput_json(t *testing.T) {
 	}
 }
 
+func TestOutput_raw(t *testing.T) {
+	originalState := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			{
+				Path: []string{"root"},
+				Outputs: map[string]*terraform.OutputState{
+					"foo": {
+						Value: "bar",
+						Type:  "string",
+					},
+					"list": {
+						Value: []interface{}{"a", "b"},
+						Type:  "list",
+					},
+				},
+			},
+		},
+	}
+
+	statePath := testStateFile(t, originalState)
+
+	ui := new(cli.MockUi)
+	c := &OutputCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-raw",
+		"foo",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	actual := strings.TrimSpace(ui.OutputWriter.String())
+	if actual != "bar" {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// Only strings can be printed raw
+	ui = new(cli.MockUi)
+	c.Meta.Ui = ui
+	args = []string{
+		"-state", statePath,
+		"-raw",
+		"list",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: \n%s", ui.OutputWriter.String())
+	}
+
+	// A name is required
+	ui = new(cli.MockUi)
+	c.Meta.Ui = ui
+	args = []string{
+		"-state", statePath,
+		"-raw",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: \n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestOutput_sensitive(t *testing.T) {
+	originalState := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			{
+				Path: []string{"root"},
+				Outputs: map[string]*terraform.OutputState{
+					"foo": {
+						Value: "bar",
+						Type:  "string",
+					},
+					"secret": {
+						Sensitive: true,
+						Value:     "hunter2",
+						Type:      "string",
+					},
+				},
+			},
+		},
+	}
+
+	statePath := testStateFile(t, originalState)
+
+	cases := []struct {
+		Args     []string
+		Contains string
+	}{
+		{[]string{}, "secret = <sensitive>"},
+		{[]string{"-show-sensitive"}, "secret = hunter2"},
+		{[]string{"-json"}, `"value": null`},
+		{[]string{"-json", "-show-sensitive"}, `"value": "hunter2"`},
+		{[]string{"secret"}, "hunter2"},
+	}
+
+	for i, tc := range cases {
+		ui := new(cli.MockUi)
+		c := &OutputCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(testProvider()),
+				Ui:          ui,
+			},
+		}
+
+		args := append([]string{"-state", statePath}, tc.Args...)
+		if code := c.Run(args); code != 0 {
+			t.Fatalf("%d: bad: \n%s", i, ui.ErrorWriter.String())
+		}
+
+		actual := ui.OutputWriter.String()
+		if !strings.Contains(actual, tc.Contains) {
+			t.Fatalf("%d: expected %q in:\n%s", i, tc.Contains, actual)
+		}
+		if !strings.Contains(tc.Contains, "hunter2") && strings.Contains(actual, "hunter2") {
+			t.Fatalf("%d: sensitive value shown:\n%s", i, actual)
+		}
+	}
+}
+
 func TestMissingModuleOutput(t *testing.T) {
 	originalState := &terraform.State{
 		Modules: []*terraform.ModuleState{
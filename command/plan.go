@@ -14,28 +14,56 @@ import (
 // configuration to an actual infrastructure and shows the differences.
 type PlanCommand struct {
 	Meta
+
+	// When this channel is closed, the plan will be cancelled.
+	ShutdownCh <-chan struct{}
 }
 
 func (c *PlanCommand) Run(args []string) int {
-	var destroy, refresh, detailed bool
+	var destroy, refresh, refreshOnly, driftValues, detailed, jsonOutput, stats bool
 	var outPath string
 	var moduleDepth int
+	var display FormatPlanOpts
 
 	args = c.Meta.process(args, true)
 
 	cmdFlags := c.Meta.flagSet("plan")
 	cmdFlags.BoolVar(&destroy, "destroy", false, "destroy")
 	cmdFlags.BoolVar(&refresh, "refresh", true, "refresh")
+	cmdFlags.BoolVar(&refreshOnly, "refresh-only", false, "refresh-only")
+	cmdFlags.BoolVar(&driftValues, "drift-values", false, "drift-values")
 	c.addModuleDepthFlag(cmdFlags, &moduleDepth)
+	c.addPlanDisplayFlags(cmdFlags, &display)
 	cmdFlags.StringVar(&outPath, "out", "", "path")
 	cmdFlags.IntVar(
 		&c.Meta.parallelism, "parallelism", DefaultParallelism, "parallelism")
-	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
 	cmdFlags.BoolVar(&detailed, "detailed-exitcode", false, "detailed-exitcode")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.BoolVar(&stats, "stats", false, "stats")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
+	if display.Compact && display.Full {
+		c.Ui.Error("The -compact and -full flags can't be used together.")
+		return 1
+	}
+
+	if refreshOnly && (destroy || !refresh || outPath != "") {
+		c.Ui.Error("The -refresh-only flag can't be used with -destroy, -refresh=false or -out.")
+		cmdFlags.Usage()
+		return 1
+	}
+	if driftValues && !refreshOnly {
+		c.Ui.Error("The -drift-values flag can only be used with -refresh-only.")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	if jsonOutput {
+		c.Meta.enableJSONUi()
+	}
 
 	var path string
 	args = cmdFlags.Args()
@@ -56,7 +84,8 @@ func (c *PlanCommand) Run(args []string) int {
 	}
 
 	countHook := new(CountHook)
-	c.Meta.extraHooks = []terraform.Hook{countHook}
+	statsHook := new(StatsHook)
+	c.Meta.extraHooks = []terraform.Hook{countHook, statsHook}
 
 	// This is going to keep track of shadow errors
 	var shadowErr error
@@ -83,7 +112,7 @@ func (c *PlanCommand) Run(args []string) int {
 		refresh = false
 	}
 
-	err = terraform.SetDebugInfo(DefaultDataDir)
+	err = terraform.SetDebugInfo(c.DataDir())
 	if err != nil {
 		c.Ui.Error(err.Error())
 		return 1
@@ -104,21 +133,58 @@ func (c *PlanCommand) Run(args []string) int {
 		return 1
 	}
 
-	if refresh {
-		c.Ui.Output("Refreshing Terraform state in-memory prior to plan...")
-		c.Ui.Output("The refreshed state will be used to calculate this plan, but")
-		c.Ui.Output("will not be persisted to local or remote state storage.\n")
-		_, err := ctx.Refresh()
-		if err != nil {
-			c.Ui.Error(fmt.Sprintf("Error refreshing state: %s", err))
+	// The state before the refresh, for the statistics
+	var stateBefore *terraform.State
+	if c.state != nil {
+		stateBefore = c.state.State()
+	}
+
+	if !planned && !c.checkTargets(ctx.Module(), stateBefore) {
+		return 1
+	}
+
+	if refreshOnly {
+		if planned {
+			c.Ui.Error("The -refresh-only flag can't be used with a saved plan.")
 			return 1
 		}
-		c.Ui.Output("")
+
+		return c.runRefreshOnly(ctx, stateBefore, detailed, driftValues)
 	}
 
-	plan, err := ctx.Plan()
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf("Error running plan: %s", err))
+	// Refresh and plan so that we can be interrupted. Neither modifies
+	// any state, so an interrupted plan is simply discarded.
+	var plan *terraform.Plan
+	var opErr error
+	stopped, aborted := c.runInterruptible(ctx, c.ShutdownCh, func() {
+		if refresh {
+			c.Ui.Output("Refreshing Terraform state in-memory prior to plan...")
+			c.Ui.Output("The refreshed state will be used to calculate this plan, but")
+			c.Ui.Output("will not be persisted to local or remote state storage.\n")
+			if _, err := ctx.Refresh(); err != nil {
+				opErr = fmt.Errorf("Error refreshing state: %s", err)
+				return
+			}
+			c.Ui.Output("")
+		}
+
+		var err error
+		plan, err = ctx.Plan()
+		if err != nil {
+			opErr = fmt.Errorf("Error running plan: %s", err)
+		}
+	})
+	if aborted {
+		return 1
+	}
+	if opErr != nil {
+		c.Ui.Error(opErr.Error())
+		return 1
+	}
+	if stopped {
+		c.Ui.Error(
+			"Plan interrupted. The plan is incomplete, so it won't be shown\n" +
+				"or saved. Nothing was changed.")
 		return 1
 	}
 
@@ -140,7 +206,9 @@ func (c *PlanCommand) Run(args []string) int {
 			"No changes. Infrastructure is up-to-date. This means that Terraform\n" +
 				"could not detect any differences between your configuration and\n" +
 				"the real physical resources that exist. As a result, Terraform\n" +
-				"doesn't need to do anything.")
+				"doesn't need to do anything.\n")
+		c.outputStats(stats, statsHook, stateBefore, plan.State)
+		c.outputPlanSummary(0, 0, 0)
 		return 0
 	}
 
@@ -156,15 +224,10 @@ func (c *PlanCommand) Run(args []string) int {
 		Plan:        plan,
 		Color:       c.Colorize(),
 		ModuleDepth: moduleDepth,
+		Compact:     display.Compact,
+		Full:        display.Full,
 	}))
 
-	c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
-		"[reset][bold]Plan:[reset] "+
-			"%d to add, %d to change, %d to destroy.",
-		countHook.ToAdd+countHook.ToRemoveAndAdd,
-		countHook.ToChange,
-		countHook.ToRemove+countHook.ToRemoveAndAdd)))
-
 	// Record any shadow errors for later
 	if err := ctx.ShadowError(); err != nil {
 		shadowErr = multierror.Append(shadowErr, multierror.Prefix(
@@ -174,12 +237,122 @@ func (c *PlanCommand) Run(args []string) int {
 	// If we have an error in the shadow graph, let the user know.
 	c.outputShadowError(shadowErr, true)
 
+	c.outputStats(stats, statsHook, stateBefore, plan.State)
+
+	// The summary is always the last line of the output so that it can be
+	// found by scripts.
+	c.outputPlanSummary(
+		countHook.ToAdd+countHook.ToRemoveAndAdd,
+		countHook.ToChange,
+		countHook.ToRemove+countHook.ToRemoveAndAdd)
+
 	if detailed {
 		return 2
 	}
 	return 0
 }
 
+// runRefreshOnly refreshes the state in memory and reports how the real
+// resources drifted from the state, without planning any changes.
+func (c *PlanCommand) runRefreshOnly(
+	ctx *terraform.Context, stateBefore *terraform.State, detailed, values bool) int {
+	var stateAfter *terraform.State
+	var opErr error
+	stopped, aborted := c.runInterruptible(ctx, c.ShutdownCh, func() {
+		c.Ui.Output("Refreshing Terraform state in-memory to detect drift...")
+		c.Ui.Output("The refreshed state will not be persisted to local or")
+		c.Ui.Output("remote state storage.\n")
+
+		var err error
+		stateAfter, err = ctx.Refresh()
+		if err != nil {
+			opErr = fmt.Errorf("Error refreshing state: %s", err)
+		}
+	})
+	if aborted {
+		return 1
+	}
+	if opErr != nil {
+		c.Ui.Error(opErr.Error())
+		return 1
+	}
+	if stopped {
+		c.Ui.Error(
+			"Refresh interrupted. The drift is incomplete, so it won't be shown.\n" +
+				"Nothing was changed.")
+		return 1
+	}
+
+	drift := stateDrift(stateBefore, stateAfter)
+	var changed, deleted int
+	for _, d := range drift {
+		data := map[string]interface{}{
+			"address": d.Address,
+			"deleted": d.Deleted,
+		}
+		if d.Deleted {
+			deleted++
+		} else {
+			changed++
+			attrs := make(map[string]interface{})
+			for k, a := range d.Attributes {
+				attr := make(map[string]interface{})
+				if values {
+					attr["before"] = a.Before
+					if !a.Removed {
+						attr["after"] = a.After
+					}
+				} else {
+					attr["sensitive"] = true
+				}
+				if a.Removed {
+					attr["removed"] = true
+				}
+				attrs[k] = attr
+			}
+			data["attributes"] = attrs
+		}
+		c.jsonEvent("resource_drift", data)
+	}
+
+	if len(drift) == 0 {
+		c.Ui.Output(
+			"No drift. The real resources match the state, as far as the\n" +
+				"providers can tell.\n")
+	} else {
+		c.Ui.Output(strings.TrimSpace(planHeaderDrift) + "\n")
+		c.Ui.Output(FormatDrift(drift, c.Colorize(), values) + "\n")
+	}
+
+	// Like the plan summary, the drift summary is always the last line
+	c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
+		"[reset][bold]Drift:[reset] %d changed, %d deleted.", changed, deleted)))
+	c.jsonEvent("drift_summary", map[string]interface{}{
+		"changed": changed,
+		"deleted": deleted,
+	})
+
+	if detailed && len(drift) > 0 {
+		return 2
+	}
+	return 0
+}
+
+// outputPlanSummary outputs the number of planned changes, both as the
+// "Plan:" line and as a JSON event. The format of the line is documented
+// and must not change, since scripts parse it.
+func (c *PlanCommand) outputPlanSummary(add, change, destroy int) {
+	c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
+		"[reset][bold]Plan:[reset] "+
+			"%d to add, %d to change, %d to destroy.",
+		add, change, destroy)))
+	c.jsonEvent("plan_summary", map[string]interface{}{
+		"add":     add,
+		"change":  change,
+		"destroy": destroy,
+	})
+}
+
 func (c *PlanCommand) Help() string {
 	helpText := `
 Usage: terraform plan [options] [DIR-OR-PLAN]
@@ -196,6 +369,9 @@ Usage: terraform plan [options] [DIR-OR-PLAN]
 
 Options:
 
+  -compact            Show each changed value of an attribute holding a JSON
+                      document, such as a policy, on one line with its path.
+
   -destroy            If set, a plan will be generated to destroy all resources
                       managed by the given configuration and state.
 
@@ -205,8 +381,22 @@ Options:
                       1 - Errored
                       2 - Succeeded, there is a diff
 
+                      The last line of the output is always a summary of the
+                      form "Plan: 1 to add, 0 to change, 0 to destroy."
+
+  -drift-values       With -refresh-only, show the values of the attributes
+                      that drifted. By default only their names are shown,
+                      since they may be secrets such as passwords or keys.
+
+  -full               Show the unchanged values of attributes holding JSON
+                      documents as well as the changed ones.
+
   -input=true         Ask for input for variables if not directly set.
 
+  -json               Write all output as newline delimited JSON events,
+                      including an event for each planned resource change.
+                      Implies -input=false.
+
   -module-depth=n     Specifies the depth of modules to show in the output.
                       This does not affect the plan itself, only the output
                       shown. By default, this is -1, which will expand all.
@@ -220,10 +410,21 @@ Options:
 
   -refresh=true       Update state prior to checking for differences.
 
+  -refresh-only       Only refresh the state in memory and show how the real
+                      resources drifted from the state, instead of the
+                      changes needed to match the configuration. With
+                      -detailed-exitcode, the exit code is 2 if there is
+                      drift. The last line of the output is a summary of
+                      the form "Drift: 1 changed, 0 deleted."
+
   -state=statefile    Path to a Terraform state file to use to look
                       up Terraform-managed resources. By default it will
                       use the state "terraform.tfstate" if it exists.
 
+  -stats              Show statistics of the plan: the resources walked,
+                      the calls made to each provider, the slowest
+                      resources and the size of the state.
+
   -target=resource    Resource to target. Operation will be limited to this
                       resource and its dependencies. This flag can be used
                       multiple times.
@@ -266,3 +467,11 @@ plan.
 
 Path: %s
 `
+
+const planHeaderDrift = `
+The real resources below drifted from the state since it was last saved.
+Yellow resources were changed outside of Terraform, with the attributes
+that changed, and red resources were deleted. Run "terraform refresh" to
+update the state with these changes, or "terraform plan" to see the
+changes needed to match the configuration again.
+`
@@ -37,6 +37,78 @@ func TestPlan(t *testing.T) {
 	}
 }
 
+func TestPlan_json(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.Chdir(testFixturePath("plan")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Chdir(cwd)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"-json"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	es := testJSONEvents(t, ui.OutputWriter.String())
+	if e := testJSONEvent(t, es, "planned_change"); e.Data["action"] != "create" {
+		t.Fatalf("bad: %#v", e)
+	}
+	if e := testJSONEvent(t, es, "plan_summary"); e.Data["add"] != float64(1) {
+		t.Fatalf("bad: %#v", e)
+	}
+	if e := testJSONEvent(t, es, "operation_stats"); e.Data["resources_walked"] != float64(1) {
+		t.Fatalf("bad: %#v", e)
+	}
+}
+
+func TestPlan_stats(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.Chdir(testFixturePath("plan")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Chdir(cwd)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"-stats"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	for _, expected := range []string{
+		"Statistics:",
+		"Resources walked: 1",
+		"test_instance.foo: ",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("expected %q in output:\n\n%s", expected, output)
+		}
+	}
+}
+
 func TestPlan_plan(t *testing.T) {
 	tmp, cwd := testCwd(t)
 	defer testFixCwd(t, tmp, cwd)
@@ -262,6 +334,57 @@ func TestPlan_refresh(t *testing.T) {
 	}
 }
 
+func TestPlan_backendValues(t *testing.T) {
+	cases := []struct {
+		Export string
+		Code   int
+	}{
+		{"true", 0},
+		{"false", 1},
+	}
+
+	for i, tc := range cases {
+		func() {
+			tmp, cwd := testCwd(t)
+			defer testFixCwd(t, tmp, cwd)
+
+			current := terraform.NewState()
+			current.Remote = &terraform.RemoteState{
+				Type: "inmem",
+				Config: map[string]string{
+					"region":        "us-west-2",
+					"export_config": tc.Export,
+				},
+			}
+			testStateFileRemote(t, current)
+
+			p := testProvider()
+			ui := new(cli.MockUi)
+			c := &PlanCommand{
+				Meta: Meta{
+					ContextOpts: testCtxConfig(p),
+					Ui:          ui,
+				},
+			}
+
+			args := []string{testFixturePath("plan-backend-values")}
+			if code := c.Run(args); code != tc.Code {
+				t.Fatalf("%d: bad: %d\n\n%s", i, code, ui.ErrorWriter.String())
+			}
+			if tc.Code != 0 {
+				if !strings.Contains(ui.ErrorWriter.String(), "export_config") {
+					t.Fatalf("%d: bad: %s", i, ui.ErrorWriter.String())
+				}
+				return
+			}
+
+			if v, _ := p.ConfigureConfig.Get("value"); v != "us-west-2" {
+				t.Fatalf("%d: bad: %#v", i, v)
+			}
+		}()
+	}
+}
+
 func TestPlan_state(t *testing.T) {
 	// Write out some prior state
 	tf, err := ioutil.TempFile("", "tf")
@@ -652,6 +775,45 @@ func TestPlan_detailedExitcode_emptyDiff(t *testing.T) {
 	if code := c.Run(args); code != 0 {
 		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
 	}
+
+	output := strings.TrimSpace(ui.OutputWriter.String())
+	expected := "Plan: 0 to add, 0 to change, 0 to destroy."
+	if !strings.HasSuffix(output, expected) {
+		t.Fatalf("bad: %s", output)
+	}
+}
+
+func TestPlan_summary(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.Chdir(testFixturePath("plan")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Chdir(cwd)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"-no-color"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The summary must be the last line
+	output := strings.TrimSpace(ui.OutputWriter.String())
+	lines := strings.Split(output, "\n")
+	last := lines[len(lines)-1]
+	if last != "Plan: 1 to add, 0 to change, 0 to destroy." {
+		t.Fatalf("bad: %q", last)
+	}
 }
 
 const planVarFile = `
@@ -671,3 +833,222 @@ const testPlanStateDefaultStr = `
 ID = bar
 Tainted = false
 `
+
+func TestPlan_targetMissing(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.Chdir(testFixturePath("plan")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Chdir(cwd)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"-target", "test_instance.nope"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "test_instance.nope") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+	if p.DiffCalled {
+		t.Fatal("diff should not be called")
+	}
+}
+
+func TestPlan_refreshOnly(t *testing.T) {
+	originalState := testState()
+	originalState.Modules[0].Resources["test_instance.foo"].Primary.Attributes = map[string]string{
+		"ami": "bar",
+	}
+	statePath := testStateFile(t, originalState)
+
+	p := testProvider()
+	p.RefreshFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState) (*terraform.InstanceState, error) {
+		return &terraform.InstanceState{
+			ID:         s.ID,
+			Attributes: map[string]string{"ami": "baz"},
+		}, nil
+	}
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-refresh-only",
+		"-detailed-exitcode",
+		"-state", statePath,
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 2 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if p.DiffCalled {
+		t.Fatal("diff should not be called")
+	}
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "ami: <sensitive>") {
+		t.Fatalf("bad:\n\n%s", output)
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if last := lines[len(lines)-1]; last != "Drift: 1 changed, 0 deleted." {
+		t.Fatalf("bad: %q", last)
+	}
+
+	// The refreshed state isn't saved
+	actual := testReadState(t, statePath)
+	if !actual.Equal(originalState) {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestPlan_refreshOnlySecret(t *testing.T) {
+	originalState := testState()
+	originalState.Modules[0].Resources["test_instance.foo"].Primary.Attributes = map[string]string{
+		"password": "hunter2",
+	}
+	statePath := testStateFile(t, originalState)
+
+	p := testProvider()
+	p.RefreshFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState) (*terraform.InstanceState, error) {
+		return &terraform.InstanceState{
+			ID:         s.ID,
+			Attributes: map[string]string{"password": "correct-horse"},
+		}, nil
+	}
+
+	for _, jsonOutput := range []bool{false, true} {
+		ui := new(cli.MockUi)
+		c := &PlanCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		args := []string{
+			"-refresh-only",
+			"-state", statePath,
+		}
+		if jsonOutput {
+			args = append(args, "-json")
+		}
+		args = append(args, testFixturePath("plan"))
+		if code := c.Run(args); code != 0 {
+			t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+		}
+
+		// The attribute that changed is named, without its values
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, "password") {
+			t.Fatalf("bad:\n\n%s", output)
+		}
+		if strings.Contains(output, "hunter2") || strings.Contains(output, "correct-horse") {
+			t.Fatalf("values should be hidden:\n\n%s", output)
+		}
+	}
+
+	// With -drift-values, the values are shown
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+	args := []string{
+		"-refresh-only",
+		"-drift-values",
+		"-state", statePath,
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, `password: "hunter2" => "correct-horse"`) {
+		t.Fatalf("bad:\n\n%s", output)
+	}
+}
+
+func TestPlan_driftValuesWithoutRefreshOnly(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-drift-values",
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+}
+
+func TestPlan_refreshOnlyNoDrift(t *testing.T) {
+	statePath := testStateFile(t, testState())
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-refresh-only",
+		"-detailed-exitcode",
+		"-state", statePath,
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "Drift: 0 changed, 0 deleted.") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestPlan_refreshOnlyOut(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-refresh-only",
+		"-out", "foo.tfplan",
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+}
@@ -0,0 +1,530 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// PluginLockFilename is the name of the file in the data directory that
+// records the provider plugins chosen by "terraform init".
+const PluginLockFilename = "plugin.lock"
+
+// ProviderPlugin is a provider plugin binary found on disk. Plugins named
+// "terraform-provider-NAME_vVERSION" have a version, others don't.
+type ProviderPlugin struct {
+	Name    string
+	Version string
+	Path    string
+}
+
+// ProviderPlugins are the provider plugins found on disk, in increasing
+// order of precedence, and a function to make a provider factory for one
+// of them. The version constraints of providers are resolved against them.
+type ProviderPlugins struct {
+	Available []ProviderPlugin
+	Factory   func(path string) terraform.ResourceProviderFactory
+}
+
+// pluginLock is the content of the plugin lock file.
+type pluginLock struct {
+	Providers map[string]*pluginLockEntry `json:"providers"`
+
+	// PluginDirs are the directories given to "terraform init" with
+	// -plugin-dir. If set, provider plugins are only found in them.
+	PluginDirs []string `json:"plugin_dirs,omitempty"`
+}
+
+// pluginLockEntry is the provider plugin chosen for a provider. Version is
+// empty for a plugin without a version.
+type pluginLockEntry struct {
+	Version string `json:"version"`
+	SHA256  string `json:"sha256"`
+}
+
+// providerPlugins returns the provider plugins available. If plugin
+// directories are given, only the plugins in them are available, and the
+// plugins in later directories take precedence.
+func (m *Meta) providerPlugins(dirs []string) ([]ProviderPlugin, error) {
+	if len(dirs) == 0 {
+		if m.ProviderPlugins == nil {
+			return nil, nil
+		}
+
+		return m.ProviderPlugins.Available, nil
+	}
+
+	var result []ProviderPlugin
+	for _, dir := range dirs {
+		plugins, err := FindProviderPlugins(dir)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading plugin directory %s: %s", dir, err)
+		}
+
+		result = append(result, plugins...)
+	}
+
+	return result, nil
+}
+
+// pluginLockPath returns the path of the plugin lock file.
+func (m *Meta) pluginLockPath() string {
+	return filepath.Join(m.DataDir(), PluginLockFilename)
+}
+
+// lockProviders chooses the provider plugins for the providers used by the
+// configuration, which are the most preferred plugins that satisfy the
+// version constraints, and records them in the plugin lock file. Providers
+// that are built into Terraform and have no constraint aren't recorded.
+func (m *Meta) lockProviders(mod *module.Tree) error {
+	constraints, err := providerConstraints(mod)
+	if err != nil {
+		return err
+	}
+
+	var dirs []string
+	for _, dir := range m.pluginDirs {
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			return fmt.Errorf("Plugin directory %s doesn't exist", dir)
+		}
+
+		dirs = append(dirs, dir)
+	}
+
+	plugins, err := m.providerPlugins(dirs)
+	if err != nil {
+		return err
+	}
+
+	lock := &pluginLock{
+		Providers:  make(map[string]*pluginLockEntry),
+		PluginDirs: dirs,
+	}
+	for _, name := range configProviders(mod) {
+		p, err := resolveProvider(plugins, name, constraints[name])
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			continue
+		}
+
+		sum, err := fileSHA256(p.Path)
+		if err != nil {
+			return fmt.Errorf("Error reading provider plugin %s: %s", p.Path, err)
+		}
+
+		lock.Providers[name] = &pluginLockEntry{Version: p.Version, SHA256: sum}
+		if p.Version != "" {
+			m.Ui.Output(fmt.Sprintf("- provider.%s: version %s", name, p.Version))
+		} else {
+			m.Ui.Output(fmt.Sprintf("- provider.%s: %s", name, p.Path))
+		}
+		m.jsonEvent("provider_locked", map[string]interface{}{
+			"provider": name,
+			"version":  p.Version,
+			"sha256":   sum,
+		})
+	}
+
+	// Without plugins to record, a lock file from before is stale
+	if len(lock.Providers) == 0 && len(lock.PluginDirs) == 0 {
+		err := os.Remove(m.pluginLockPath())
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return err
+	}
+
+	return writePluginLock(m.pluginLockPath(), lock)
+}
+
+// checkProviderLock checks that the plugin lock file still satisfies the
+// version constraints of the configuration, so that a changed constraint
+// isn't ignored until "terraform init" is run again.
+func (m *Meta) checkProviderLock(mod *module.Tree) error {
+	if mod == nil {
+		return nil
+	}
+
+	constraints, err := providerConstraints(mod)
+	if err != nil {
+		return err
+	}
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	lock, err := readPluginLock(m.pluginLockPath())
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var entry *pluginLockEntry
+		if lock != nil {
+			entry = lock.Providers[name]
+		}
+		if entry == nil || entry.Version == "" {
+			return fmt.Errorf(errPluginLockMissing, name)
+		}
+
+		v, err := version.NewVersion(entry.Version)
+		if err != nil {
+			return fmt.Errorf("Invalid version of provider %s in %s: %s",
+				name, PluginLockFilename, err)
+		}
+		if !constraints[name].Check(v) {
+			return fmt.Errorf(errPluginLockStale, name, entry.Version, constraints[name])
+		}
+	}
+
+	return nil
+}
+
+// lockedProviders returns the provider factories with the providers in the
+// plugin lock file replaced by the plugins recorded there. The checksum of
+// a plugin is verified when the provider is started, so a plugin that was
+// changed since "terraform init" isn't used.
+func (m *Meta) lockedProviders(
+	providers map[string]terraform.ResourceProviderFactory) (map[string]terraform.ResourceProviderFactory, error) {
+	lock, err := readPluginLock(m.pluginLockPath())
+	if err != nil || lock == nil || m.ProviderPlugins == nil {
+		return providers, err
+	}
+
+	plugins, err := m.providerPlugins(lock.PluginDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]terraform.ResourceProviderFactory, len(providers))
+	for k, v := range providers {
+		result[k] = v
+	}
+
+	for name, entry := range lock.Providers {
+		var path string
+		for _, p := range plugins {
+			if p.Name == name && p.Version == entry.Version {
+				path = p.Path
+			}
+		}
+
+		result[name] = lockedProviderFactory(
+			name, entry, path, m.ProviderPlugins.Factory)
+	}
+
+	return result, nil
+}
+
+// lockedProviderFactory returns a factory for the plugin at the given path
+// that first verifies the plugin against its entry in the lock file.
+func lockedProviderFactory(
+	name string,
+	entry *pluginLockEntry,
+	path string,
+	f func(string) terraform.ResourceProviderFactory) terraform.ResourceProviderFactory {
+	return func() (terraform.ResourceProvider, error) {
+		if path == "" {
+			return nil, fmt.Errorf(errPluginLockNotFound, name, entry.Version)
+		}
+
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading provider plugin %s: %s", path, err)
+		}
+		if sum != entry.SHA256 {
+			return nil, fmt.Errorf(errPluginLockChecksum, name, path)
+		}
+
+		return f(path)()
+	}
+}
+
+// resolveProvider returns the most preferred plugin for the provider with
+// the given name that satisfies the constraints, if any. This is the plugin
+// with the highest version that satisfies them, or without constraints the
+// plugin that would be used without a lock file. A nil plugin without an
+// error means that the provider is built into Terraform.
+func resolveProvider(
+	plugins []ProviderPlugin,
+	name string,
+	constraints version.Constraints) (*ProviderPlugin, error) {
+	var result *ProviderPlugin
+	var resultVersion *version.Version
+	for i := range plugins {
+		p := &plugins[i]
+		if p.Name != name {
+			continue
+		}
+
+		if constraints == nil {
+			result = p
+			continue
+		}
+
+		if p.Version == "" {
+			continue
+		}
+		v, err := version.NewVersion(p.Version)
+		if err != nil || !constraints.Check(v) {
+			continue
+		}
+		if resultVersion == nil || !v.LessThan(resultVersion) {
+			result = p
+			resultVersion = v
+		}
+	}
+
+	if result == nil && constraints != nil {
+		var available []string
+		for _, p := range plugins {
+			if p.Name == name && p.Version != "" {
+				available = append(available, p.Version)
+			}
+		}
+		if len(available) == 0 {
+			available = append(available, "none")
+		}
+
+		return nil, fmt.Errorf(errPluginNoMatch,
+			name, constraints, strings.Join(available, ", "), name)
+	}
+
+	return result, nil
+}
+
+// providerConstraints returns the version constraints of each provider in
+// the configuration and its modules. A provider with several constraints,
+// such as in different modules, must satisfy all of them.
+func providerConstraints(mod *module.Tree) (map[string]version.Constraints, error) {
+	result := make(map[string]version.Constraints)
+	var walk func(*module.Tree) error
+	walk = func(t *module.Tree) error {
+		for _, pc := range t.Config().ProviderConfigs {
+			if pc.Version == "" {
+				continue
+			}
+
+			cs, err := version.NewConstraint(pc.Version)
+			if err != nil {
+				return fmt.Errorf(
+					"provider.%s: invalid version constraint: %s", pc.FullName(), err)
+			}
+			result[pc.Name] = append(result[pc.Name], cs...)
+		}
+
+		for _, child := range t.Children() {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return result, walk(mod)
+}
+
+// configProviders returns the names of the providers used by the
+// configuration and its modules, sorted.
+func configProviders(mod *module.Tree) []string {
+	set := make(map[string]struct{})
+	var walk func(*module.Tree)
+	walk = func(t *module.Tree) {
+		for _, pc := range t.Config().ProviderConfigs {
+			set[pc.Name] = struct{}{}
+		}
+		for _, r := range t.Config().Resources {
+			name := r.Provider
+			if name == "" {
+				name = r.Type
+				if idx := strings.Index(name, "_"); idx >= 0 {
+					name = name[:idx]
+				}
+			}
+			if idx := strings.Index(name, "."); idx >= 0 {
+				name = name[:idx]
+			}
+
+			set[name] = struct{}{}
+		}
+
+		for _, child := range t.Children() {
+			walk(child)
+		}
+	}
+	walk(mod)
+
+	result := make([]string, 0, len(set))
+	for name := range set {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// readPluginLock reads the plugin lock file at the given path. If there's
+// no lock file, nil is returned.
+func readPluginLock(path string) (*pluginLock, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lock pluginLock
+	if err := json.NewDecoder(f).Decode(&lock); err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", path, err)
+	}
+
+	return &lock, nil
+}
+
+// writePluginLock writes the plugin lock file at the given path.
+func writePluginLock(path string, lock *pluginLock) error {
+	data, err := json.MarshalIndent(lock, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// FindProviderPlugins returns the provider plugins in the given directory,
+// the plugins without a version first and then by increasing version, so
+// that the last plugin of each provider is the one to use by default.
+func FindProviderPlugins(dir string) ([]ProviderPlugin, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "terraform-provider-*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ProviderPlugin
+	for _, match := range matches {
+		name, v := pluginNameVersion(filepath.Base(match))
+		if name == "" {
+			continue
+		}
+
+		result = append(result, ProviderPlugin{
+			Name:    name,
+			Version: v,
+			Path:    match,
+		})
+	}
+
+	sort.Stable(providerPluginsByVersion(result))
+	return result, nil
+}
+
+// pluginNameVersion returns the name and version of a plugin from its
+// file name, such as "aws" and "1.2.0" for "terraform-provider-aws_v1.2.0".
+// The version is empty if the name has none, and the name is empty if the
+// file isn't named like a plugin.
+func pluginNameVersion(file string) (string, string) {
+	var v string
+	if idx := strings.LastIndex(file, "_v"); idx >= 0 {
+		raw := strings.TrimSuffix(file[idx+2:], ".exe")
+		if _, err := version.NewVersion(raw); err == nil {
+			v = raw
+			file = file[:idx]
+		}
+	}
+
+	// If the filename has a ".", trim up to there
+	if idx := strings.Index(file, "."); idx >= 0 {
+		file = file[:idx]
+	}
+
+	// Look for foo-bar-baz. The plugin name is "baz"
+	parts := strings.SplitN(file, "-", 3)
+	if len(parts) != 3 {
+		return "", ""
+	}
+
+	return parts[2], v
+}
+
+type providerPluginsByVersion []ProviderPlugin
+
+func (s providerPluginsByVersion) Len() int      { return len(s) }
+func (s providerPluginsByVersion) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s providerPluginsByVersion) Less(i, j int) bool {
+	if s[i].Version == "" || s[j].Version == "" {
+		return s[i].Version == "" && s[j].Version != ""
+	}
+
+	vi, _ := version.NewVersion(s[i].Version)
+	vj, _ := version.NewVersion(s[j].Version)
+	return vi.LessThan(vj)
+}
+
+// fileSHA256 returns the SHA256 checksum of the file at path, hex encoded.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+const errPluginNoMatch = `No provider plugin for %s matches the version constraint %q.
+Versions available: %s
+
+Provider plugins with a version are named terraform-provider-%s_vVERSION,
+and are found in the directory Terraform is run from, the directory of
+the Terraform binary, or ~/.terraform.d/plugins.`
+
+const errPluginLockMissing = `Provider %s has a version constraint, but no version of it is
+recorded in the plugin lock file. Please run "terraform init" to choose the
+provider plugin to use.`
+
+const errPluginLockStale = `The version %[2]s of provider %[1]s recorded in the plugin lock
+file doesn't satisfy the version constraint %[3]q of the configuration.
+Please run "terraform init" to choose a provider plugin that does.`
+
+const errPluginLockNotFound = `The provider plugin for %s with version %q recorded in the plugin
+lock file wasn't found. Please install it, or run "terraform init" to
+choose another plugin.`
+
+const errPluginLockChecksum = `The provider plugin for %s at %s doesn't match the checksum
+recorded in the plugin lock file, so it was changed since "terraform init"
+was run. If the change is expected, run "terraform init" again.`
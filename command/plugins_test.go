@@ -0,0 +1,363 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+func TestResolveProvider(t *testing.T) {
+	plugins := []ProviderPlugin{
+		ProviderPlugin{Name: "aws", Path: "aws"},
+		ProviderPlugin{Name: "aws", Version: "1.2.0", Path: "aws_v1.2.0"},
+		ProviderPlugin{Name: "aws", Version: "1.10.0", Path: "aws_v1.10.0"},
+		ProviderPlugin{Name: "aws", Version: "2.0.0", Path: "aws_v2.0.0"},
+		ProviderPlugin{Name: "aws", Version: "1.5.0", Path: "aws_v1.5.0"},
+		ProviderPlugin{Name: "null", Path: "null"},
+	}
+
+	cases := []struct {
+		Name       string
+		Constraint string
+		Path       string
+		Err        bool
+	}{
+		{"aws", "~> 1.2", "aws_v1.10.0", false},
+		{"aws", "< 1.6", "aws_v1.5.0", false},
+		{"aws", "", "aws_v1.5.0", false},
+		{"aws", "> 3.0", "", true},
+		{"null", "", "null", false},
+		{"null", "1.0.0", "", true},
+		{"template", "", "", false},
+	}
+
+	for _, tc := range cases {
+		var cs version.Constraints
+		if tc.Constraint != "" {
+			var err error
+			cs, err = version.NewConstraint(tc.Constraint)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+
+		p, err := resolveProvider(plugins, tc.Name, cs)
+		if (err != nil) != tc.Err {
+			t.Fatalf("%s %s: err: %s", tc.Name, tc.Constraint, err)
+		}
+
+		var path string
+		if p != nil {
+			path = p.Path
+		}
+		if path != tc.Path {
+			t.Fatalf("%s %s: bad: %s", tc.Name, tc.Constraint, path)
+		}
+	}
+}
+
+func TestInit_providerLock(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	config := `
+provider "test" {
+  version = "~> 1.0"
+}
+
+resource "test_instance" "foo" {}
+`
+	if err := ioutil.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	plugins := testProviderPlugins(t, tmp, "1.0.0", "1.4.0", "2.0.0")
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts:     testCtxConfig(testProvider()),
+			Ui:              ui,
+			ProviderPlugins: plugins,
+		},
+	}
+
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "provider.test: version 1.4.0") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+
+	lock, err := readPluginLock(filepath.Join(DefaultDataDir, PluginLockFilename))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	sum, err := fileSHA256(plugins.Available[1].Path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	entry := lock.Providers["test"]
+	if entry == nil || entry.Version != "1.4.0" || entry.SHA256 != sum {
+		t.Fatalf("bad: %#v", lock.Providers)
+	}
+}
+
+func TestInit_providerLockNoMatch(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	config := `
+provider "test" {
+  version = "~> 3.0"
+}
+`
+	if err := ioutil.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts:     testCtxConfig(testProvider()),
+			Ui:              ui,
+			ProviderPlugins: testProviderPlugins(t, tmp, "1.0.0", "2.0.0"),
+		},
+	}
+
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "Versions available: 1.0.0, 2.0.0") {
+		t.Fatalf("bad:\n\n%s", ui.ErrorWriter.String())
+	}
+}
+
+func TestInit_pluginDir(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	config := `
+provider "test" {
+  version = "~> 1.0"
+}
+
+resource "test_instance" "foo" {}
+`
+	if err := ioutil.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The plugins found as usual aren't used with -plugin-dir
+	dir := filepath.Join(tmp, "plugins")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	mirrored := testProviderPlugins(t, dir, "1.1.0")
+	plugins := testProviderPlugins(t, tmp, "1.4.0")
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts:     testCtxConfig(testProvider()),
+			Ui:              ui,
+			ProviderPlugins: plugins,
+		},
+	}
+
+	if code := c.Run([]string{"-plugin-dir", "plugins"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "provider.test: version 1.1.0") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+
+	lock, err := readPluginLock(filepath.Join(DefaultDataDir, PluginLockFilename))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(lock.PluginDirs, []string{dir}) {
+		t.Fatalf("bad: %#v", lock.PluginDirs)
+	}
+
+	// Later commands find the plugin in the directory as well
+	var started string
+	plugins.Factory = func(path string) terraform.ResourceProviderFactory {
+		started = path
+		return mirrored.Factory(path)
+	}
+	m := &Meta{ProviderPlugins: plugins}
+	providers, err := m.lockedProviders(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := providers["test"](); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if started != mirrored.Available[0].Path {
+		t.Fatalf("bad: %s", started)
+	}
+}
+
+func TestInit_pluginDirMissing(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	if err := ioutil.WriteFile("main.tf", []byte(`resource "test_instance" "foo" {}`), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{"-plugin-dir", "nope"}); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "doesn't exist") {
+		t.Fatalf("bad:\n\n%s", ui.ErrorWriter.String())
+	}
+}
+
+func TestMeta_lockedProviders(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	plugins := testProviderPlugins(t, tmp, "1.0.0")
+	path := plugins.Available[0].Path
+	sum, err := fileSHA256(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	lock := &pluginLock{
+		Providers: map[string]*pluginLockEntry{
+			"test": &pluginLockEntry{Version: "1.0.0", SHA256: sum},
+		},
+	}
+	if err := writePluginLock(filepath.Join(DefaultDataDir, PluginLockFilename), lock); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	m := &Meta{ProviderPlugins: plugins}
+	providers, err := m.lockedProviders(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := providers["test"](); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// A plugin changed since init isn't used
+	if err := ioutil.WriteFile(path, []byte("changed"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	_, err = providers["test"]()
+	if err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestPlan_providerLockStale(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	config := `
+provider "test" {
+  version = "~> 2.0"
+}
+
+resource "test_instance" "foo" {}
+`
+	if err := ioutil.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The lock was written for an older constraint
+	lock := &pluginLock{
+		Providers: map[string]*pluginLockEntry{
+			"test": &pluginLockEntry{Version: "1.0.0", SHA256: "abc"},
+		},
+	}
+	if err := writePluginLock(filepath.Join(DefaultDataDir, PluginLockFilename), lock); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "terraform init") {
+		t.Fatalf("bad:\n\n%s", ui.ErrorWriter.String())
+	}
+}
+
+func TestPluginNameVersion(t *testing.T) {
+	cases := []struct {
+		File    string
+		Name    string
+		Version string
+	}{
+		{"terraform-provider-aws", "aws", ""},
+		{"terraform-provider-aws_v1.2.0", "aws", "1.2.0"},
+		{"terraform-provider-aws_v1.2.0.exe", "aws", "1.2.0"},
+		{"terraform-provider-aws.exe", "aws", ""},
+		{"terraform-provider-my_vpc", "my_vpc", ""},
+		{"terraform", "", ""},
+	}
+
+	for _, tc := range cases {
+		name, v := pluginNameVersion(tc.File)
+		if name != tc.Name || v != tc.Version {
+			t.Fatalf("%s: bad: %q %q", tc.File, name, v)
+		}
+	}
+}
+
+// testProviderPlugins writes a plugin binary for each of the given versions
+// of the "test" provider in dir, and returns them. The factory of the
+// plugins returns a mock provider.
+func testProviderPlugins(t *testing.T, dir string, versions ...string) *ProviderPlugins {
+	result := &ProviderPlugins{
+		Factory: func(string) terraform.ResourceProviderFactory {
+			return func() (terraform.ResourceProvider, error) {
+				return testProvider(), nil
+			}
+		},
+	}
+
+	for _, v := range versions {
+		path := filepath.Join(dir, "terraform-provider-test_v"+v)
+		data := []byte(fmt.Sprintf("plugin %s", v))
+		if err := ioutil.WriteFile(path, data, 0755); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		result.Available = append(result.Available, ProviderPlugin{
+			Name:    "test",
+			Version: v,
+			Path:    path,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// policyCommand returns the policy command to run before applying, if any.
+// A command given on the command line takes precedence over the
+// policy_command setting in the terraform block of the root module.
+func policyCommand(flag string, mod *module.Tree) string {
+	if flag != "" {
+		return flag
+	}
+
+	if mod == nil || mod.Config() == nil || mod.Config().Terraform == nil {
+		return ""
+	}
+
+	return mod.Config().Terraform.PolicyCommand
+}
+
+// readPlanFile reads the plan file at the given path.
+func readPlanFile(path string) (*terraform.Plan, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading plan: %s", err)
+	}
+	defer f.Close()
+
+	plan, err := terraform.ReadPlan(f)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading plan: %s", err)
+	}
+
+	return plan, nil
+}
+
+// checkPolicy runs the given policy command with the plan as JSON, in the
+// format of "terraform show -json", on its standard input. The plan passes
+// the check if the command exits successfully. Anything the command writes
+// is shown to the user, so it can explain why a plan was rejected.
+func (m *Meta) checkPolicy(command string, plan *terraform.Plan) error {
+	input, err := FormatPlanJSON(plan)
+	if err != nil {
+		return err
+	}
+
+	var shell, flag string
+	if runtime.GOOS == "windows" {
+		shell = "cmd"
+		flag = "/C"
+	} else {
+		shell = "/bin/sh"
+		flag = "-c"
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(shell, flag, command)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Run()
+	if out := strings.TrimSpace(output.String()); out != "" {
+		m.Ui.Output(out)
+	}
+	if err != nil {
+		return fmt.Errorf(
+			"The plan was rejected by the policy command %q: %s\n\n"+
+				"Nothing was changed.", command, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package command
+
+import (
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// limitProviders wraps the given provider factories so that at most n
+// refreshes or data source reads run concurrently against each provider,
+// across all of its instances. This keeps a refresh of a large state from
+// tripping the API rate limits of a single provider when the graph is
+// walked with a high parallelism.
+func limitProviders(
+	providers map[string]terraform.ResourceProviderFactory,
+	n int) map[string]terraform.ResourceProviderFactory {
+	result := make(map[string]terraform.ResourceProviderFactory, len(providers))
+	for name, f := range providers {
+		// Shared by every instance of the provider
+		sem := make(chan struct{}, n)
+
+		// Copy the factory so the closure below doesn't capture the
+		// loop variable
+		f := f
+		result[name] = func() (terraform.ResourceProvider, error) {
+			p, err := f()
+			if err != nil {
+				return nil, err
+			}
+
+			return &limitedProvider{ResourceProvider: p, sem: sem}, nil
+		}
+	}
+
+	return result
+}
+
+// limitedProvider is a terraform.ResourceProvider that limits the number of
+// concurrent calls that read from the provider's API.
+type limitedProvider struct {
+	terraform.ResourceProvider
+
+	sem chan struct{}
+}
+
+func (p *limitedProvider) Refresh(
+	info *terraform.InstanceInfo,
+	s *terraform.InstanceState) (*terraform.InstanceState, error) {
+	p.sem <- struct{}{}
+	defer func() { <-p.sem }()
+
+	return p.ResourceProvider.Refresh(info, s)
+}
+
+func (p *limitedProvider) ReadDataApply(
+	info *terraform.InstanceInfo,
+	d *terraform.InstanceDiff) (*terraform.InstanceState, error) {
+	p.sem <- struct{}{}
+	defer func() { <-p.sem }()
+
+	return p.ResourceProvider.ReadDataApply(info, d)
+}
+
+// Close closes the wrapped provider if it can be, since the wrapper would
+// otherwise hide its ResourceProviderCloser implementation.
+func (p *limitedProvider) Close() error {
+	if c, ok := p.ResourceProvider.(terraform.ResourceProviderCloser); ok {
+		return c.Close()
+	}
+
+	return nil
+}
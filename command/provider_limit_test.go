@@ -0,0 +1,105 @@
+package command
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestLimitProviders(t *testing.T) {
+	var l sync.Mutex
+	var current, max int
+
+	// The mock provider serializes its calls, so use our own
+	p := &testRefreshProvider{
+		RefreshFn: func() {
+			l.Lock()
+			current++
+			if current > max {
+				max = current
+			}
+			l.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			l.Lock()
+			current--
+			l.Unlock()
+		},
+	}
+
+	providers := limitProviders(map[string]terraform.ResourceProviderFactory{
+		"test": terraform.ResourceProviderFactoryFixed(p),
+	}, 2)
+
+	// The limit is shared by every instance of the provider
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		rp, err := providers["test"]()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rp.Refresh(&terraform.InstanceInfo{}, &terraform.InstanceState{})
+		}()
+	}
+	wg.Wait()
+
+	if max != 2 {
+		t.Fatalf("bad: %d", max)
+	}
+}
+
+func TestLimitProviders_close(t *testing.T) {
+	p := new(terraform.MockResourceProvider)
+	providers := limitProviders(map[string]terraform.ResourceProviderFactory{
+		"test": terraform.ResourceProviderFactoryFixed(p),
+	}, 2)
+
+	rp, err := providers["test"]()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	closer, ok := rp.(terraform.ResourceProviderCloser)
+	if !ok {
+		t.Fatal("should be a ResourceProviderCloser")
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !p.CloseCalled {
+		t.Fatal("Close should be called")
+	}
+
+	// Providers that can't be closed are left alone
+	rp, err = limitProviders(map[string]terraform.ResourceProviderFactory{
+		"test": terraform.ResourceProviderFactoryFixed(&testRefreshProvider{}),
+	}, 2)["test"]()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := rp.(terraform.ResourceProviderCloser).Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+// testRefreshProvider is a terraform.ResourceProvider that only implements
+// Refresh, by calling RefreshFn.
+type testRefreshProvider struct {
+	terraform.ResourceProvider
+
+	RefreshFn func()
+}
+
+func (p *testRefreshProvider) Refresh(
+	*terraform.InstanceInfo,
+	*terraform.InstanceState) (*terraform.InstanceState, error) {
+	p.RefreshFn()
+	return nil, nil
+}
@@ -0,0 +1,222 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// ProvidersCommand is a Command implementation that prints out the
+// providers required by each module of a configuration and by the state.
+type ProvidersCommand struct {
+	Meta
+}
+
+func (c *ProvidersCommand) Run(args []string) int {
+	args = c.Meta.process(args, false)
+
+	cmdFlags := c.Meta.flagSet("providers")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		return 1
+	}
+
+	var path string
+	args = cmdFlags.Args()
+	if len(args) > 1 {
+		c.Ui.Error("The providers command expects at most one argument.")
+		cmdFlags.Usage()
+		return 1
+	} else if len(args) == 1 {
+		path = args[0]
+	} else {
+		var err error
+		path, err = os.Getwd()
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error getting pwd: %s", err))
+			return 1
+		}
+	}
+
+	// Load the configuration. An empty directory is fine, we'll then
+	// only show what the state requires.
+	mod, err := module.NewTreeModule("", path)
+	if errwrap.ContainsType(err, new(config.ErrNoConfigsFound)) {
+		err = nil
+		mod = module.NewEmptyTree()
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading config: %s", err))
+		return 1
+	}
+
+	// Modules aren't downloaded here, "terraform get" is responsible
+	// for that.
+	if err := mod.Load(c.moduleStorage(c.DataDir()), module.GetModeNone); err != nil {
+		c.Ui.Error(fmt.Sprintf(
+			"Error loading modules: %s\n\n"+
+				"Modules must be downloaded with \"terraform get\" before the\n"+
+				"providers they require can be listed.", err))
+		return 1
+	}
+
+	// Load the state
+	s, err := c.StateReader()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(".\n")
+	writeModuleProviders(&buf, mod, "")
+
+	if st := s.State(); st != nil && st.HasResources() {
+		source := fmt.Sprintf("local state: %s", c.Meta.StateOutPath())
+		if st.IsRemote() {
+			source = fmt.Sprintf("remote state: %s", st.Remote.Type)
+		}
+
+		buf.WriteString(fmt.Sprintf("\nProviders required by state (%s):\n\n", source))
+		for _, name := range stateProviders(st) {
+			buf.WriteString(fmt.Sprintf("    provider.%s\n", name))
+		}
+	}
+
+	c.Ui.Output(strings.TrimSpace(buf.String()))
+	return 0
+}
+
+// writeModuleProviders writes the providers used by the given module and,
+// recursively, its children as the branches of a tree.
+func writeModuleProviders(buf *bytes.Buffer, tree *module.Tree, prefix string) {
+	providers := moduleProviders(tree.Config())
+
+	children := tree.Children()
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	total := len(providers) + len(names)
+	i := 0
+	branch := func() (string, string) {
+		i++
+		if i == total {
+			return prefix + "└── ", prefix + "    "
+		}
+
+		return prefix + "├── ", prefix + "│   "
+	}
+
+	for _, p := range providers {
+		line, _ := branch()
+		buf.WriteString(fmt.Sprintf("%sprovider.%s\n", line, p))
+	}
+
+	for _, name := range names {
+		line, next := branch()
+		buf.WriteString(fmt.Sprintf("%smodule.%s\n", line, name))
+		writeModuleProviders(buf, children[name], next)
+	}
+}
+
+// moduleProviders returns the sorted names of the providers that a single
+// module configures or has resources for. Aliased providers are returned
+// with their alias, such as "aws.west".
+func moduleProviders(c *config.Config) []string {
+	if c == nil {
+		return nil
+	}
+
+	set := make(map[string]struct{})
+	for _, p := range c.ProviderConfigs {
+		name := p.Name
+		if p.Alias != "" {
+			name = fmt.Sprintf("%s.%s", p.Name, p.Alias)
+		}
+
+		set[name] = struct{}{}
+	}
+	for _, r := range c.Resources {
+		set[resourceProviderName(r.Type, r.Provider)] = struct{}{}
+	}
+
+	return sortedProviders(set)
+}
+
+// stateProviders returns the sorted names of the providers that the
+// resources in the state belong to, across all modules.
+func stateProviders(s *terraform.State) []string {
+	set := make(map[string]struct{})
+	for _, m := range s.Modules {
+		for _, r := range m.Resources {
+			set[resourceProviderName(r.Type, r.Provider)] = struct{}{}
+		}
+	}
+
+	return sortedProviders(set)
+}
+
+// resourceProviderName returns the name of the provider for a resource
+// type, taking into account an explicitly set provider alias.
+func resourceProviderName(t, alias string) string {
+	if alias != "" {
+		return alias
+	}
+
+	if idx := strings.IndexRune(t, '_'); idx != -1 {
+		return t[:idx]
+	}
+
+	return t
+}
+
+func sortedProviders(m map[string]struct{}) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func (c *ProvidersCommand) Help() string {
+	helpText := `
+Usage: terraform providers [options] [DIR]
+
+  Prints out a tree of modules in the referenced configuration annotated
+  with the providers each module requires, followed by the providers
+  required by the resources in the current state.
+
+  This provides an overview of all of the provider requirements across
+  all referenced modules, as an aid to understanding why particular
+  provider plugins are needed. Modules must already be downloaded with
+  "terraform get".
+
+Options:
+
+  -no-color           If specified, output won't contain any color.
+
+  -state=path         Path to a Terraform state file to use to look
+                      up the providers of managed resources. Defaults
+                      to "terraform.tfstate". Ignored when remote state
+                      is used.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ProvidersCommand) Synopsis() string {
+	return "Prints a tree of the providers used in the configuration"
+}
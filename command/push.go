@@ -30,7 +30,7 @@ func (c *PushCommand) Run(args []string) int {
 	args = c.Meta.process(args, true)
 	cmdFlags := c.Meta.flagSet("push")
 	cmdFlags.StringVar(&atlasAddress, "atlas-address", "", "")
-	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
 	cmdFlags.StringVar(&atlasToken, "token", "", "")
 	cmdFlags.BoolVar(&moduleUpload, "upload-modules", true, "")
 	cmdFlags.StringVar(&name, "name", "", "")
@@ -202,11 +202,15 @@ func (c *PushCommand) Run(args []string) int {
 
 	// Build the archiving options, which includes everything it can
 	// by default according to VCS rules but forcing the data directory.
+	// The checksum of the remote state cache is only meaningful locally.
 	archiveOpts := &archive.ArchiveOpts{
 		VCS: archiveVCS,
 		Extra: map[string]string{
 			DefaultDataDir: archive.ExtraEntryDir,
 		},
+		Exclude: []string{
+			filepath.Join(c.DataDir(), DefaultStateFilename+DefaultChecksumExtension),
+		},
 	}
 
 	// Always store the state file in here so we can find state
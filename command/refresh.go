@@ -14,21 +14,34 @@ import (
 // file.
 type RefreshCommand struct {
 	Meta
+
+	// When this channel is closed, the refresh will be cancelled.
+	ShutdownCh <-chan struct{}
 }
 
 func (c *RefreshCommand) Run(args []string) int {
+	var autoApprove, jsonOutput bool
 	args = c.Meta.process(args, true)
 
 	cmdFlags := c.Meta.flagSet("refresh")
-	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
-	cmdFlags.IntVar(&c.Meta.parallelism, "parallelism", 0, "parallelism")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
+	cmdFlags.IntVar(
+		&c.Meta.parallelism, "parallelism", DefaultParallelism, "parallelism")
+	cmdFlags.IntVar(
+		&c.Meta.providerParallelism, "provider-parallelism", 0, "provider-parallelism")
 	cmdFlags.StringVar(&c.Meta.stateOutPath, "state-out", "", "path")
 	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
 
+	if jsonOutput {
+		c.Meta.enableJSONUi()
+	}
+
 	var configPath string
 	args = cmdFlags.Args()
 	if len(args) > 1 {
@@ -108,10 +121,22 @@ func (c *RefreshCommand) Run(args []string) int {
 	if !validateContext(ctx, c.Ui) {
 		return 1
 	}
+	if !c.confirmRemoteState(autoApprove) {
+		return 1
+	}
 
-	newState, err := ctx.Refresh()
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf("Error refreshing state: %s", err))
+	// Run the refresh so that we can be interrupted. If it is stopped
+	// gracefully, the resources refreshed so far are still saved.
+	var newState *terraform.State
+	var refreshErr error
+	stopped, aborted := c.runInterruptible(ctx, c.ShutdownCh, func() {
+		newState, refreshErr = ctx.Refresh()
+	})
+	if aborted {
+		return 1
+	}
+	if refreshErr != nil {
+		c.Ui.Error(fmt.Sprintf("Error refreshing state: %s", refreshErr))
 		return 1
 	}
 
@@ -121,6 +146,13 @@ func (c *RefreshCommand) Run(args []string) int {
 		return 1
 	}
 
+	if stopped {
+		c.Ui.Error(
+			"Refresh interrupted. The resources that were refreshed before the\n" +
+				"interrupt have been saved to the state, the rest are unchanged.")
+		return 1
+	}
+
 	if outputs := outputsAsString(newState, terraform.RootModulePath, ctx.Module().Config().Outputs, true); outputs != "" {
 		c.Ui.Output(c.Colorize().Color(outputs))
 	}
@@ -150,14 +182,30 @@ Usage: terraform refresh [options] [dir]
 
 Options:
 
+  -auto-approve       Don't ask for the name of a remote state configured
+                      with confirm_required before changing it.
+
   -backup=path        Path to backup the existing state file before
                       modifying. Defaults to the "-state-out" path with
                       ".backup" extension. Set to "-" to disable backup.
 
   -input=true         Ask for input for variables if not directly set.
 
+  -json               Write all output as newline delimited JSON events,
+                      including an event for each resource that is
+                      refreshed. Implies -input=false.
+
   -no-color           If specified, output won't contain any color.
 
+  -parallelism=n      Limit the number of concurrent operations. Defaults
+                      to 10.
+
+  -provider-parallelism=n
+                      Limit the number of concurrent refreshes against
+                      each provider, to avoid hitting API rate limits
+                      when refreshing many resources. By default only
+                      -parallelism applies.
+
   -state=path         Path to read and save state (unless state-out
                       is specified). Defaults to "terraform.tfstate".
 
@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/cli"
@@ -59,6 +60,121 @@ func TestRefresh(t *testing.T) {
 	}
 }
 
+func TestRefresh_targeted(t *testing.T) {
+	state := testState()
+	state.RootModule().Resources["test_instance.bar"] = &terraform.ResourceState{
+		Type: "test_instance",
+		Primary: &terraform.InstanceState{
+			ID: "baz",
+		},
+	}
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &RefreshCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	var refreshed []string
+	p.RefreshFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState) (*terraform.InstanceState, error) {
+		refreshed = append(refreshed, info.Id)
+		return s, nil
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-target", "test_instance.foo",
+		testFixturePath("refresh-targeted"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if !reflect.DeepEqual(refreshed, []string{"test_instance.foo"}) {
+		t.Fatalf("bad: %#v", refreshed)
+	}
+}
+
+func TestRefresh_shutdown(t *testing.T) {
+	state := testState()
+	statePath := testStateFile(t, state)
+
+	stopped := false
+	stopCh := make(chan struct{})
+	stopReplyCh := make(chan struct{})
+
+	p := testProvider()
+	shutdownCh := make(chan struct{})
+	ui := new(cli.MockUi)
+	c := &RefreshCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+
+		ShutdownCh: shutdownCh,
+	}
+
+	p.RefreshFn = func(
+		*terraform.InstanceInfo,
+		*terraform.InstanceState) (*terraform.InstanceState, error) {
+		if !stopped {
+			stopped = true
+			close(stopCh)
+			<-stopReplyCh
+		}
+
+		return &terraform.InstanceState{ID: "yes"}, nil
+	}
+
+	go func() {
+		<-stopCh
+		shutdownCh <- struct{}{}
+
+		// This is really dirty, but we have no other way to assure that
+		// tf.Stop() has been called. This doesn't assure it either, but
+		// it makes it much more certain.
+		time.Sleep(50 * time.Millisecond)
+
+		close(stopReplyCh)
+	}()
+
+	args := []string{
+		"-state", statePath,
+		testFixturePath("refresh"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "Refresh interrupted") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+
+	// The refresh in progress when interrupted is discarded, so the
+	// state should still be intact.
+	f, err := os.Open(statePath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	newState, err := terraform.ReadState(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := newState.RootModule().Resources["test_instance.foo"].Primary.ID
+	if actual != "bar" {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
 func TestRefresh_badState(t *testing.T) {
 	p := testProvider()
 	ui := new(cli.MockUi)
@@ -769,3 +885,34 @@ const testRefreshCwdStr = `
 test_instance.foo:
   ID = yes
 `
+
+func TestRefresh_confirmRequired(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	current := testState()
+	client, cleanup := testConfirmRequiredState(t, current)
+	defer cleanup()
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &RefreshCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// Answering anything but the name of the remote state doesn't change it
+	args := []string{testFixturePath("refresh")}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "Cancelled") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+	if p.RefreshCalled {
+		t.Fatal("refresh should not be called")
+	}
+	testConfirmRequiredUnchanged(t, client, current)
+}
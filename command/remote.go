@@ -30,6 +30,9 @@ func (c *RemoteCommand) Run(argsRaw []string) int {
 	case "push":
 		cmd := &RemotePushCommand{Meta: c.Meta}
 		return cmd.Run(args[1:])
+	case "status":
+		cmd := &RemoteStatusCommand{Meta: c.Meta}
+		return cmd.Run(args[1:])
 	default:
 		c.Ui.Error(c.Help())
 		return 1
@@ -51,6 +54,7 @@ Available subcommands:
   config      Configure the remote storage settings.
   pull        Sync the remote storage by downloading to local storage.
   push        Sync the remote storage by uploading the local storage.
+  status      Show the remote storage settings and check them.
 
 `
 	return strings.TrimSpace(helpText)
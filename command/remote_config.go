@@ -1,10 +1,12 @@
 package command
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform/state"
@@ -40,10 +42,11 @@ func (c *RemoteConfigCommand) Run(args []string) int {
 	cmdFlags := flag.NewFlagSet("remote", flag.ContinueOnError)
 	cmdFlags.BoolVar(&c.conf.disableRemote, "disable", false, "")
 	cmdFlags.BoolVar(&c.conf.pullOnDisable, "pull", true, "")
-	cmdFlags.StringVar(&c.conf.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.StringVar(&c.conf.statePath, "state", c.Meta.defaultStatePath(), "path")
 	cmdFlags.StringVar(&c.conf.backupPath, "backup", "", "path")
 	cmdFlags.StringVar(&c.remoteConf.Type, "backend", "atlas", "")
 	cmdFlags.Var((*FlagStringKV)(&config), "backend-config", "config")
+	cmdFlags.BoolVar(&c.input, "input", c.Meta.defaultInput(), "input")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("\nError parsing CLI flags: %s", err))
@@ -97,8 +100,8 @@ func (c *RemoteConfigCommand) Run(args []string) int {
 	haveLocal := !localState.Empty()
 	switch {
 	case haveCache && haveLocal:
-		c.Ui.Error(fmt.Sprintf("Remote state is enabled, but non-managed state file '%s' is also present!",
-			c.conf.statePath))
+		c.Ui.Error(remoteAndLocalError(
+			remoteState.Remote, c.stateResult.RemotePath, c.conf.statePath).Error())
 		result = 1
 
 	case !haveCache && !haveLocal:
@@ -204,6 +207,11 @@ func (c *RemoteConfigCommand) disableRemoteState() int {
 		c.Ui.Error(fmt.Sprintf("Failed to remove the local state file: %v", err))
 		return 1
 	}
+	checksumPath := c.stateResult.RemotePath + DefaultChecksumExtension
+	if err := os.Remove(checksumPath); err != nil && !os.IsNotExist(err) {
+		c.Ui.Error(fmt.Sprintf("Failed to remove the state checksum file: %v", err))
+		return 1
+	}
 
 	return 0
 }
@@ -237,7 +245,7 @@ func (c *RemoteConfigCommand) initBlankState() int {
 	blank.Remote = c.remoteConf
 
 	// Persist the state
-	remote := &state.LocalState{Path: c.stateResult.RemotePath}
+	remote := remoteCacheState(c.stateResult.RemotePath)
 	if err := remote.WriteState(blank); err != nil {
 		c.Ui.Error(fmt.Sprintf("Failed to initialize state file: %v", err))
 		return 1
@@ -263,8 +271,15 @@ func (c *RemoteConfigCommand) updateRemoteConfig() int {
 	// Read in the local state, which is just the cache of the remote state
 	remote := c.stateResult.Remote.Cache
 
-	// Update the configuration
+	// Show what changed, so that an unintended change of the remote
+	// storage doesn't go unnoticed
 	state := remote.State()
+	if diff := remoteConfigDiff(state.Remote, c.remoteConf); diff != "" {
+		c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
+			"[reset][bold]Remote configuration changed:[reset]\n\n%s", diff)))
+	}
+
+	// Update the configuration
 	state.Remote = c.remoteConf
 	if err := remote.WriteState(state); err != nil {
 		c.Ui.Error(fmt.Sprintf("%s", err))
@@ -280,6 +295,69 @@ func (c *RemoteConfigCommand) updateRemoteConfig() int {
 	return 0
 }
 
+// remoteConfigDiff returns the changes from one remote configuration to
+// another, one per line, or an empty string if they're the same. Secrets
+// are redacted, so a changed secret only shows that it changed.
+func remoteConfigDiff(old, new *terraform.RemoteState) string {
+	if old == nil {
+		old = &terraform.RemoteState{}
+	}
+
+	var buf bytes.Buffer
+	if old.Type != new.Type {
+		buf.WriteString(fmt.Sprintf(
+			"  [yellow]~[reset] backend: %q => %q\n", old.Type, new.Type))
+	}
+
+	keys := make(map[string]struct{})
+	for k := range old.Config {
+		keys[k] = struct{}{}
+	}
+	for k := range new.Config {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		oldV, oldOk := old.Config[k]
+		newV, newOk := new.Config[k]
+
+		// A key that holds a secret for either type is redacted on both
+		// sides, so that changing the type doesn't reveal the secret.
+		oldR := remote.RedactValue(new.Type, k, remote.RedactValue(old.Type, k, oldV))
+		newR := remote.RedactValue(old.Type, k, remote.RedactValue(new.Type, k, newV))
+
+		switch {
+		case !oldOk:
+			buf.WriteString(fmt.Sprintf(
+				"  [green]+[reset] %s: %s\n", k, quoteRemoteValue(newR)))
+		case !newOk:
+			buf.WriteString(fmt.Sprintf(
+				"  [red]-[reset] %s: %s\n", k, quoteRemoteValue(oldR)))
+		case oldV != newV:
+			buf.WriteString(fmt.Sprintf(
+				"  [yellow]~[reset] %s: %s => %s\n",
+				k, quoteRemoteValue(oldR), quoteRemoteValue(newR)))
+		}
+	}
+
+	return buf.String()
+}
+
+// quoteRemoteValue quotes a configuration value for remoteConfigDiff,
+// unless it's a redacted secret.
+func quoteRemoteValue(v string) string {
+	if v == remote.SensitiveValue {
+		return v
+	}
+
+	return fmt.Sprintf("%q", v)
+}
+
 // enableRemoteState is used to enable remote state management
 // and to move a state file into place
 func (c *RemoteConfigCommand) enableRemoteState() int {
@@ -315,8 +393,32 @@ func (c *RemoteConfigCommand) enableRemoteState() int {
 		}
 	}
 
-	// Update the local configuration, move into place
+	// The remote storage may already have the state of other
+	// infrastructure. Which state to keep is up to the user. This is
+	// only checked if the remote state is pulled, since that's when the
+	// states would be reconciled.
 	state := local.State()
+	if c.conf.pullOnDisable {
+		existing, err := c.remoteStorageState()
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		if remoteStateConflicts(state, existing) {
+			switch c.resolveRemoteConflict(state, existing, backupPath) {
+			case "remote":
+				return c.keepRemoteState(existing)
+			case "local":
+				// The local state has to be newer than the remote state
+				// for the pull to replace the remote state with it.
+				state.Serial = existing.Serial + 1
+			default:
+				return 1
+			}
+		}
+	}
+
+	// Update the local configuration, move into place
 	state.Remote = c.remoteConf
 	remote := c.stateResult.Remote
 	if err := remote.WriteState(state); err != nil {
@@ -341,6 +443,135 @@ func (c *RemoteConfigCommand) enableRemoteState() int {
 	return 0
 }
 
+// remoteStorageState returns the state in the remote storage being
+// configured, or nil if it has none.
+func (c *RemoteConfigCommand) remoteStorageState() (*terraform.State, error) {
+	client, err := remote.NewClient(c.remoteConf.Type, c.remoteConf.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := client.Get()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the remote state: %s", err)
+	}
+	if payload == nil {
+		return nil, nil
+	}
+
+	s, err := terraform.ReadState(bytes.NewReader(payload.Data))
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the remote state: %s", err)
+	}
+
+	return s, nil
+}
+
+// remoteStateConflicts returns true if the local and the remote state both
+// have resources, and are the states of different infrastructure.
+func remoteStateConflicts(local, existing *terraform.State) bool {
+	if existing == nil || !existing.HasResources() || !local.HasResources() {
+		return false
+	}
+
+	return local.Lineage != "" && existing.Lineage != "" &&
+		local.Lineage != existing.Lineage
+}
+
+// resolveRemoteConflict shows how the local state differs from the state
+// already in the remote storage and asks which one to keep. It returns
+// "remote", "local", or an empty string if enabling remote state is
+// aborted, in which case the remote state is written next to the local
+// state for inspection.
+func (c *RemoteConfigCommand) resolveRemoteConflict(
+	local, existing *terraform.State, backupPath string) string {
+	diff := diffStateResources(existing, local).String()
+	if diff == "" {
+		diff = "  (the states have the same resources)\n"
+	}
+	c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
+		strings.TrimSpace(outputRemoteConfigConflict),
+		c.remoteConf.Type, existing.Lineage, existing.Serial, len(stateResourcesByAddr(existing)),
+		c.conf.statePath, local.Lineage, local.Serial, len(stateResourcesByAddr(local)),
+		diff)))
+
+	var choice string
+	if c.input {
+		v, err := c.UIInput().Input(&terraform.InputOpts{
+			Id:          "conflict",
+			Query:       "Which state do you want to keep?",
+			Description: remoteConflictDescription(backupPath),
+		})
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error asking which state to keep: %s", err))
+			return ""
+		}
+		choice = strings.TrimSpace(v)
+	}
+
+	if choice == "remote" || choice == "local" {
+		return choice
+	}
+
+	// Abort, leaving a copy of the remote state to compare
+	remotePath := c.conf.statePath + ".remote"
+	remoteCopy := &state.LocalState{Path: remotePath}
+	if err := remoteCopy.WriteState(existing); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing the remote state to %s: %s", remotePath, err))
+		return ""
+	}
+	if err := remoteCopy.PersistState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing the remote state to %s: %s", remotePath, err))
+		return ""
+	}
+
+	c.Ui.Error(fmt.Sprintf(
+		strings.TrimSpace(errRemoteConfigConflict),
+		remotePath, c.conf.statePath, remotePath, c.conf.statePath))
+	return ""
+}
+
+// remoteConflictDescription describes the choices of resolveRemoteConflict.
+func remoteConflictDescription(backupPath string) string {
+	keepRemote := "The local state is discarded."
+	if backupPath != "-" {
+		keepRemote = fmt.Sprintf("The local state is kept in %s.", backupPath)
+	}
+
+	return fmt.Sprintf(
+		"remote - Keep the remote state and use it from now on.\n"+
+			"         %s\n"+
+			"local  - Overwrite the remote state with the local state.\n"+
+			"abort  - Don't enable remote state, and write the remote state\n"+
+			"         next to the local state to inspect it.\n\n"+
+			"Enter \"remote\", \"local\" or \"abort\".", keepRemote)
+}
+
+// keepRemoteState enables remote state with the state already in the
+// remote storage, removing the local state, which was backed up.
+func (c *RemoteConfigCommand) keepRemoteState(existing *terraform.State) int {
+	existing.Remote = c.remoteConf
+	cache := remoteCacheState(c.stateResult.RemotePath)
+	if err := cache.WriteState(existing); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to initialize state file: %v", err))
+		return 1
+	}
+	if err := cache.PersistState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to initialize state file: %v", err))
+		return 1
+	}
+
+	log.Printf("[INFO] Removing state file: %s", c.conf.statePath)
+	if err := os.Remove(c.conf.statePath); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to remove state file '%s': %v",
+			c.conf.statePath, err))
+		return 1
+	}
+
+	c.Ui.Output("Remote state management enabled with the remote state")
+	return 0
+}
+
 func (c *RemoteConfigCommand) Help() string {
 	helpText := `
 Usage: terraform remote config [options]
@@ -366,10 +597,16 @@ Options:
   -disable               Disables remote state management and migrates the state
                          to the -state path.
 
+  -input=true            Ask which state to keep if the remote storage
+                         already has the state of other infrastructure.
+                         With -input=false, remote state isn't enabled then.
+
   -pull=true             If disabling, this controls if the remote state is
                          pulled before disabling. If enabling, this controls
                          if the remote state is pulled after enabling. This
-                         defaults to true.
+                         defaults to true. If the remote state is pulled and
+                         it's the state of other infrastructure than the
+                         local state, Terraform asks which one to keep.
 
   -state=path            Path to read state. Defaults to "terraform.tfstate"
                          unless remote state is enabled.
@@ -383,3 +620,24 @@ Options:
 func (c *RemoteConfigCommand) Synopsis() string {
 	return "Configures remote state management"
 }
+
+const outputRemoteConfigConflict = `
+[reset][bold][yellow]The remote storage already has the state of other infrastructure.[reset]
+
+  Remote state (%s): lineage %s, serial %d, %d resources
+  Local state (%s): lineage %s, serial %d, %d resources
+
+Resources only in the local state are marked with +, resources only in
+the remote state with -, and resources in both that differ with ~:
+
+%s
+`
+
+const errRemoteConfigConflict = `
+Remote state wasn't enabled. The remote state was written to %s,
+so that it can be compared with the local state in %s:
+
+    terraform state diff %s %s
+
+Run this command again to choose which state to keep.
+`
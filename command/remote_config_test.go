@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/state"
@@ -346,6 +347,121 @@ func TestRemoteConfig_updateRemote(t *testing.T) {
 	}
 }
 
+// Test that updating the remote config shows what changed
+func TestRemoteConfig_updateRemoteDiff(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	// Persist local remote state
+	s := terraform.NewState()
+	s.Serial = 5
+	s.Remote = &terraform.RemoteState{
+		Type: "atlas",
+		Config: map[string]string{
+			"name":         "foo/bar",
+			"access_token": "old-secret",
+		},
+	}
+
+	// Write the state
+	statePath := filepath.Join(tmp, DefaultDataDir, DefaultStateFilename)
+	ls := &state.LocalState{Path: statePath}
+	if err := ls.WriteState(s); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ls.PersistState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &RemoteConfigCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-backend=http",
+		"-backend-config", "address=http://example.com",
+		"-backend-config", "access_token=new-secret",
+		"-pull=false",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	for _, expected := range []string{
+		`~ backend: "atlas" => "http"`,
+		`~ access_token: <sensitive> => <sensitive>`,
+		`+ address: "http://example.com"`,
+		`- name: "foo/bar"`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("expected %q in output:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "secret\"") {
+		t.Fatalf("secret in output:\n%s", output)
+	}
+}
+
+func TestRemoteConfigDiff(t *testing.T) {
+	cases := []struct {
+		Old, New *terraform.RemoteState
+		Output   string
+	}{
+		{
+			&terraform.RemoteState{
+				Type:   "s3",
+				Config: map[string]string{"bucket": "foo"},
+			},
+			&terraform.RemoteState{
+				Type:   "s3",
+				Config: map[string]string{"bucket": "foo"},
+			},
+			"",
+		},
+
+		{
+			&terraform.RemoteState{
+				Type: "s3",
+				Config: map[string]string{
+					"bucket":     "foo",
+					"secret_key": "a",
+				},
+			},
+			&terraform.RemoteState{
+				Type: "s3",
+				Config: map[string]string{
+					"bucket":     "bar",
+					"secret_key": "b",
+				},
+			},
+			"  [yellow]~[reset] bucket: \"foo\" => \"bar\"\n" +
+				"  [yellow]~[reset] secret_key: <sensitive> => <sensitive>\n",
+		},
+
+		{
+			nil,
+			&terraform.RemoteState{
+				Type:   "s3",
+				Config: map[string]string{"bucket": "foo"},
+			},
+			"  [yellow]~[reset] backend: \"\" => \"s3\"\n" +
+				"  [green]+[reset] bucket: \"foo\"\n",
+		},
+	}
+
+	for i, tc := range cases {
+		actual := remoteConfigDiff(tc.Old, tc.New)
+		if actual != tc.Output {
+			t.Fatalf("%d: bad:\n%s", i, actual)
+		}
+	}
+}
+
 // Test enabling remote state
 func TestRemoteConfig_enableRemote(t *testing.T) {
 	tmp, cwd := testCwd(t)
@@ -447,3 +563,83 @@ func testRemoteLocalCache(t *testing.T, exists bool) {
 
 	t.Fatalf("bad: %#v", err)
 }
+
+func TestRemoteConfig_enableRemoteConflict(t *testing.T) {
+	cases := []struct {
+		Input   string
+		Code    int
+		Lineage string
+	}{
+		{"", 1, ""},
+		{"abort", 1, ""},
+		{"remote", 0, "remote"},
+		{"local", 0, "local"},
+	}
+
+	for _, tc := range cases {
+		tmp, cwd := testCwd(t)
+
+		remoteState := testState()
+		remoteState.Lineage = "remote"
+		conf, srv := testRemoteState(t, remoteState, 200)
+
+		s := testState()
+		s.Lineage = "local"
+		s.RootModule().Resources["test_instance.bar"] = &terraform.ResourceState{
+			Type:    "test_instance",
+			Primary: &terraform.InstanceState{ID: "baz"},
+		}
+		fh, err := os.Create(DefaultStateFilename)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		err = terraform.WriteState(s, fh)
+		fh.Close()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		args := []string{
+			"-backend=http",
+			"-backend-config", "address=" + conf.Config["address"],
+		}
+		if tc.Input == "" {
+			args = append(args, "-input=false")
+		}
+		defaultInputReader = bytes.NewBufferString(tc.Input + "\n")
+		defaultInputWriter = new(bytes.Buffer)
+
+		ui := new(cli.MockUi)
+		c := &RemoteConfigCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(testProvider()),
+				Ui:          ui,
+			},
+		}
+		if code := c.Run(args); code != tc.Code {
+			t.Fatalf("%q: bad: %d\n\n%s", tc.Input, code, ui.ErrorWriter.String())
+		}
+		if !strings.Contains(ui.OutputWriter.String(), "+[reset] test_instance.bar") &&
+			!strings.Contains(ui.OutputWriter.String(), "+ test_instance.bar") {
+			t.Fatalf("%q: bad:\n\n%s", tc.Input, ui.OutputWriter.String())
+		}
+
+		if tc.Code != 0 {
+			// Nothing changed, and the remote state can be inspected
+			testRemoteLocal(t, true)
+			if actual := testReadState(t, DefaultStateFilename+".remote"); actual.Lineage != "remote" {
+				t.Fatalf("%q: bad: %s", tc.Input, actual)
+			}
+		} else {
+			testRemoteLocal(t, false)
+			testRemoteLocalBackup(t, true)
+			remotePath := filepath.Join(DefaultDataDir, DefaultStateFilename)
+			if actual := testReadState(t, remotePath); actual.Lineage != tc.Lineage {
+				t.Fatalf("%q: bad: %s", tc.Input, actual)
+			}
+		}
+
+		srv.Close()
+		testFixCwd(t, tmp, cwd)
+	}
+}
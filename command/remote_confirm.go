@@ -0,0 +1,87 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform/state/remote"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// remoteStateConfig returns the remote state configuration that commands
+// use, and the path of the file it's configured in, or nil if remote
+// state isn't configured.
+func (m *Meta) remoteStateConfig() (*terraform.RemoteState, string, error) {
+	if m.backendOverride != "" {
+		conf, err := loadRemoteOverride(m.backendOverride)
+		return conf, m.backendOverride, err
+	}
+
+	path := filepath.Join(m.DataDir(), DefaultStateFilename)
+	cache := remoteCacheState(path)
+	if err := cache.RefreshState(); err != nil {
+		return nil, "", remoteCacheError(path, err)
+	}
+
+	s := cache.State()
+	if s == nil || s.Remote.Empty() {
+		return nil, "", nil
+	}
+
+	return s.Remote, path, nil
+}
+
+// confirmRemoteState asks for the name of the remote state to be typed
+// before the state is changed, if the remote state is configured with
+// confirm_required. With autoApprove, such as from the -auto-approve flag,
+// nothing is asked. It returns false if the state must not be changed,
+// after telling the user why.
+func (m *Meta) confirmRemoteState(autoApprove bool) bool {
+	conf, _, err := m.remoteStateConfig()
+	if err != nil {
+		m.Ui.Error(err.Error())
+		return false
+	}
+	if conf == nil {
+		return true
+	}
+
+	t := strings.ToLower(conf.Type)
+	name, err := remote.ConfirmName(t, conf.Config)
+	if err != nil {
+		m.Ui.Error(fmt.Sprintf("Error in the %q remote state configuration: %s", t, err))
+		return false
+	}
+	if name == "" || autoApprove {
+		return true
+	}
+
+	if m.InputMode() == 0 {
+		m.Ui.Error(fmt.Sprintf(strings.TrimSpace(errRemoteConfirmInput), t))
+		return false
+	}
+
+	v, err := m.UIInput().Input(&terraform.InputOpts{
+		Id:    "confirm_remote",
+		Query: fmt.Sprintf("Enter %q to confirm:", name),
+		Description: fmt.Sprintf(
+			"The %q remote state requires confirmation of changes to it.\n"+
+				"Only %q will be accepted to confirm.", t, name),
+	})
+	if err != nil {
+		m.Ui.Error(fmt.Sprintf("Error asking for confirmation: %s", err))
+		return false
+	}
+	if v != name {
+		m.Ui.Output("Cancelled, the state wasn't changed.")
+		return false
+	}
+
+	return true
+}
+
+const errRemoteConfirmInput = `
+The %q remote state requires confirmation of changes to it, but input
+is disabled. Use -auto-approve to change the state without confirmation.
+`
@@ -4,8 +4,6 @@ import (
 	"flag"
 	"fmt"
 	"strings"
-
-	"github.com/hashicorp/terraform/state"
 )
 
 type RemotePullCommand struct {
@@ -34,13 +32,9 @@ func (c *RemotePullCommand) Run(args []string) int {
 		return 1
 	}
 
-	// We need the CacheState structure in order to do anything
-	var cache *state.CacheState
-	if bs, ok := s.(*state.BackupState); ok {
-		if cs, ok := bs.Real.(*state.CacheState); ok {
-			cache = cs
-		}
-	}
+	// We need the CacheState structure in order to do anything. Pulling
+	// only updates the local cache, so it isn't audited or replicated.
+	cache, _ := findCacheState(s)
 	if cache == nil {
 		c.Ui.Error(fmt.Sprintf(
 			"Failed to extract internal CacheState from remote state.\n" +
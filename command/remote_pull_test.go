@@ -74,6 +74,50 @@ func TestRemotePull_local(t *testing.T) {
 	}
 }
 
+func TestRemotePull_replica(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	s := terraform.NewState()
+	s.Serial = 10
+	conf, srv := testRemoteState(t, s, 200)
+
+	s = terraform.NewState()
+	s.Serial = 5
+	s.Remote = conf
+	defer srv.Close()
+
+	// Store the local state, with the state replicated
+	statePath := filepath.Join(tmp, DefaultDataDir, DefaultStateFilename)
+	if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	f, err := os.Create(statePath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	err = terraform.WriteState(s, f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	testReplicaConfig(t,
+		filepath.Join(tmp, DefaultDataDir, ReplicaConfigFilename),
+		filepath.Join(tmp, "replica.tfstate"))
+
+	ui := new(cli.MockUi)
+	c := &RemotePullCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	args := []string{}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+}
+
 // testRemoteState is used to make a test HTTP server to
 // return a given state file
 func testRemoteState(t *testing.T, s *terraform.State, c int) (*terraform.RemoteState, *httptest.Server) {
@@ -1,11 +1,8 @@
 package command
 
 import (
-	"flag"
 	"fmt"
 	"strings"
-
-	"github.com/hashicorp/terraform/state"
 )
 
 type RemotePushCommand struct {
@@ -13,9 +10,10 @@ type RemotePushCommand struct {
 }
 
 func (c *RemotePushCommand) Run(args []string) int {
-	var force bool
+	var autoApprove, force bool
 	args = c.Meta.process(args, false)
-	cmdFlags := flag.NewFlagSet("push", flag.ContinueOnError)
+	cmdFlags := c.Meta.flagSet("remote push")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
 	cmdFlags.BoolVar(&force, "force", false, "")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
@@ -36,13 +34,11 @@ func (c *RemotePushCommand) Run(args []string) int {
 		return 1
 	}
 
-	// We need the CacheState structure in order to do anything
-	var cache *state.CacheState
-	if bs, ok := s.(*state.BackupState); ok {
-		if cs, ok := bs.Real.(*state.CacheState); ok {
-			cache = cs
-		}
-	}
+	// The push is recorded in the audit log here, since it bypasses
+	// the state the audit log wraps. The state pushed is already the
+	// saved state, so it isn't replicated again. We need the CacheState
+	// structure in order to do anything.
+	cache, audit := findCacheState(s)
 	if cache == nil {
 		c.Ui.Error(fmt.Sprintf(
 			"Failed to extract internal CacheState from remote state.\n" +
@@ -57,8 +53,16 @@ func (c *RemotePushCommand) Run(args []string) int {
 		return 1
 	}
 
+	if !c.confirmRemoteState(autoApprove || force) {
+		return 1
+	}
+
 	// Write it to the real storage
 	remote := cache.Durable
+	var serial *int64
+	if before := remote.State(); before != nil {
+		serial = &before.Serial
+	}
 	if err := remote.WriteState(cache.Cache.State()); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error writing state: %s", err))
 		return 1
@@ -67,6 +71,13 @@ func (c *RemotePushCommand) Run(args []string) int {
 		c.Ui.Error(fmt.Sprintf("Error saving state: %s", err))
 		return 1
 	}
+	if audit != nil {
+		if err := audit.Log.record(serial, remote.State(), ""); err != nil {
+			c.Ui.Error(fmt.Sprintf(
+				"The state was pushed, but writing the audit log failed: %s", err))
+			return 1
+		}
+	}
 
 	c.Ui.Output(c.Colorize().Color(
 		"[reset][bold][green]State successfully pushed!"))
@@ -81,6 +92,14 @@ Usage: terraform push [options]
 
 Options:
 
+  -auto-approve          Don't ask for the name of a remote state configured
+                         with confirm_required before changing it. -force
+                         doesn't ask either.
+
+  -input=true            Ask for the name of a remote state configured with
+                         confirm_required. With -input=false, -auto-approve
+                         or -force is required to push to such a state.
+
   -no-color              If specified, output won't contain any color.
 
   -force                 Forces the upload of the local state, ignoring any
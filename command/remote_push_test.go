@@ -3,6 +3,7 @@ package command
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -67,3 +68,31 @@ func TestRemotePush_local(t *testing.T) {
 		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
 	}
 }
+
+func TestRemotePush_confirmRequired(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	current := testState()
+	client, cleanup := testConfirmRequiredState(t, current)
+	defer cleanup()
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &RemotePushCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// Answering anything but the name of the remote state doesn't change it
+	args := []string{}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "Cancelled") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+	testConfirmRequiredUnchanged(t, client, current)
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hashicorp/terraform/state/remote"
+)
+
+// remoteSelfTest runs the self-test of the remote storage with the given
+// type and configuration, and shows what it can and can't do. It returns
+// false if any step of the self-test failed.
+func (m *Meta) remoteSelfTest(t string, conf map[string]string) bool {
+	steps := remote.SelfTest(t, conf)
+
+	ok := true
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("[reset][bold]Self-test of the %q backend:[reset]\n\n", t))
+	event := make(map[string]interface{})
+	for _, s := range steps {
+		switch {
+		case s.Err != nil:
+			ok = false
+			buf.WriteString(fmt.Sprintf("  %-7s [red]failed:[reset] %s\n", s.Name, s.Err))
+			event[s.Name] = fmt.Sprintf("failed: %s", s.Err)
+		case s.Skipped != "":
+			buf.WriteString(fmt.Sprintf("  %-7s [yellow]skipped:[reset] %s\n", s.Name, s.Skipped))
+			event[s.Name] = fmt.Sprintf("skipped: %s", s.Skipped)
+		default:
+			buf.WriteString(fmt.Sprintf("  %-7s [green]ok[reset]\n", s.Name))
+			event[s.Name] = "ok"
+		}
+	}
+
+	if ok {
+		m.Ui.Output(m.Colorize().Color(buf.String()))
+	} else {
+		m.Ui.Error(m.Colorize().Color(buf.String() + "\n" + errRemoteSelfTest))
+	}
+	event["backend"] = t
+	m.jsonEvent("backend_selftest", event)
+
+	return ok
+}
+
+const errRemoteSelfTest = `The backend self-test failed. Please verify that the configured
+credentials are allowed to read, write and delete objects next to the
+state. Nothing was changed.`
@@ -0,0 +1,180 @@
+package command
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/state/remote"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/ryanuber/columnize"
+)
+
+// RemoteStatusCommand is a Command implementation that shows the remote
+// state configuration and checks that the remote storage can be reached.
+type RemoteStatusCommand struct {
+	Meta
+}
+
+func (c *RemoteStatusCommand) Run(args []string) int {
+	var jsonOutput bool
+	args = c.Meta.process(args, false)
+	cmdFlags := flag.NewFlagSet("status", flag.ContinueOnError)
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		return 1
+	}
+
+	if jsonOutput {
+		c.Meta.enableJSONUi()
+	}
+
+	conf, source, err := c.remoteStateConfig()
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	if conf == nil {
+		c.jsonEvent("remote_status", map[string]interface{}{"configured": false})
+		c.Ui.Error(strings.TrimSpace(errRemoteStatusNotConfigured))
+		return 1
+	}
+
+	t := strings.ToLower(conf.Type)
+	event := map[string]interface{}{
+		"configured": true,
+		"backend":    t,
+		"source":     source,
+		"config":     remote.RedactConfig(t, conf.Config),
+	}
+	rows := []string{
+		fmt.Sprintf("Backend | %s", t),
+		fmt.Sprintf("Configured by | %s", source),
+	}
+	keys := make([]string, 0, len(conf.Config))
+	for k := range conf.Config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		rows = append(rows, fmt.Sprintf(
+			"  %s | %s", k, remote.RedactValue(t, k, conf.Config[k])))
+	}
+
+	// Reading the state is the probe, so nothing is written to the storage
+	probe, err := remoteProbe(t, conf)
+	if err != nil {
+		event["reachable"] = false
+		event["error"] = err.Error()
+		rows = append(rows, "Reachable | [red]no[reset]")
+	} else {
+		event["reachable"] = true
+		event["probe_duration"] = probe.Duration.String()
+		rows = append(rows, fmt.Sprintf("Reachable | [green]yes[reset] (%s)", probe.Duration))
+		if probe.State != nil {
+			event["serial"] = probe.State.Serial
+			event["lineage"] = probe.State.Lineage
+			rows = append(rows,
+				fmt.Sprintf("Serial | %d", probe.State.Serial),
+				fmt.Sprintf("Lineage | %s", summaryValue(probe.State.Lineage)))
+		} else {
+			rows = append(rows, "State | none stored yet")
+		}
+		if !probe.LastModified.IsZero() {
+			event["last_modified"] = probe.LastModified.UTC().Format(time.RFC3339)
+		}
+		rows = append(rows, fmt.Sprintf("Last modified | %s", summaryTime(probe.LastModified)))
+	}
+
+	c.jsonEvent("remote_status", event)
+	c.Ui.Output(c.Colorize().Color(columnize.SimpleFormat(rows)))
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("\nError reading the remote state: %s", err))
+		return 1
+	}
+
+	return 0
+}
+
+// remoteProbeResult is what reading the state from a remote storage found.
+type remoteProbeResult struct {
+	// Duration is how long reading the state took.
+	Duration time.Duration
+
+	// State is the state read, or nil if none is stored yet.
+	State *terraform.State
+
+	// LastModified is the time the state was last written, or the zero
+	// time if it isn't known.
+	LastModified time.Time
+}
+
+// remoteProbe reads the state from the remote storage configured by conf,
+// of the client type t.
+func remoteProbe(t string, conf *terraform.RemoteState) (*remoteProbeResult, error) {
+	client, err := remote.NewClient(t, conf.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	payload, err := client.Get()
+	if err != nil {
+		return nil, err
+	}
+	d := time.Since(start)
+	result := &remoteProbeResult{
+		Duration: d - d%time.Millisecond,
+	}
+
+	if payload != nil {
+		result.State, err = terraform.ReadState(bytes.NewReader(payload.Data))
+		if err != nil {
+			return nil, err
+		}
+	}
+	result.LastModified = replicaLastModified(client, conf)
+
+	return result, nil
+}
+
+func (c *RemoteStatusCommand) Help() string {
+	helpText := `
+Usage: terraform remote status [options]
+
+  Shows the remote state configuration, with secrets redacted, and reads
+  the state from the remote storage to check that it can be reached. The
+  serial and lineage of the stored state are shown, and when it was last
+  modified if the storage can tell.
+
+  Nothing is written to the remote storage or the local cache. The exit
+  code is 1 if remote state isn't configured or can't be read.
+
+Options:
+
+  -backend-override=path  Show the remote state configured in the given
+                          file instead of the one in .terraform.
+
+  -json                   Write the status as a newline delimited JSON
+                          event so that it can be parsed by other tools.
+
+  -no-color               If specified, output won't contain any color.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RemoteStatusCommand) Synopsis() string {
+	return "Shows the remote state configuration and checks it"
+}
+
+const errRemoteStatusNotConfigured = `
+Remote state isn't configured!
+
+Configure remote state with "terraform remote config" or "terraform init",
+or use -backend-override to check another remote state.
+`
@@ -0,0 +1,253 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform/state"
+	"github.com/hashicorp/terraform/state/remote"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+const (
+	// ReplicaConfigFilename is the name of the remote state configuration
+	// file in the data directory that every saved state is replicated to.
+	// See "terraform state replica".
+	ReplicaConfigFilename = "replica.hcl"
+
+	// ReplicaStatusFilename is the name of the file in the data directory
+	// that records the outcome of the last replication.
+	ReplicaStatusFilename = "replica-status.json"
+)
+
+// replicaWait tracks the replications in progress, so that Terraform can
+// wait for them before it exits.
+var replicaWait sync.WaitGroup
+
+// WaitForReplicas waits for the replications of the state in progress to
+// finish, for at most the given time. It returns false if they didn't.
+func WaitForReplicas(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		replicaWait.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// replicaStatus is the outcome of the last replication of the state.
+type replicaStatus struct {
+	Backend     string `json:"backend"`
+	LastAttempt string `json:"last_attempt"`
+	LastSuccess string `json:"last_success,omitempty"`
+	Lineage     string `json:"lineage,omitempty"`
+	Serial      int64  `json:"serial"`
+	Error       string `json:"error,omitempty"`
+}
+
+// replicaState wraps a State to copy the state to a replica remote storage
+// each time a changed state is persisted. The copy is made in the
+// background and a failure to make it doesn't fail persisting the state;
+// the outcome is recorded in the status file instead.
+type replicaState struct {
+	Real       state.State
+	Client     remote.Client
+	Backend    string
+	StatusPath string
+
+	// l protects next and running. Replications are made one at a time
+	// by a single goroutine, so that an older state never replaces a
+	// newer one; next is the copy of the state to replicate once the
+	// replication in progress is done, if it's running.
+	l       sync.Mutex
+	next    *terraform.State
+	running bool
+
+	// replicated is the serial of the state when it was last replicated.
+	replicated *int64
+}
+
+func (s *replicaState) unwrap() state.State {
+	return s.Real
+}
+
+func (s *replicaState) State() *terraform.State {
+	return s.Real.State()
+}
+
+func (s *replicaState) RefreshState() error {
+	return s.Real.RefreshState()
+}
+
+func (s *replicaState) WriteState(state *terraform.State) error {
+	return s.Real.WriteState(state)
+}
+
+func (s *replicaState) PersistState() error {
+	if err := s.Real.PersistState(); err != nil {
+		return err
+	}
+
+	current := s.Real.State()
+	if current == nil || (s.replicated != nil && current.Serial == *s.replicated) {
+		return nil
+	}
+	serial := current.Serial
+	s.replicated = &serial
+
+	// The state keeps being changed while it's replicated, so a copy is
+	// replicated. Persisting doesn't wait for a replication in progress:
+	// the copy replaces any waiting for it, since only the latest state
+	// needs to be replicated.
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.next = current.DeepCopy()
+	if !s.running {
+		s.running = true
+		replicaWait.Add(1)
+		go s.replicateNext()
+	}
+
+	return nil
+}
+
+// replicateNext replicates the waiting copies of the state until there
+// are none left.
+func (s *replicaState) replicateNext() {
+	defer replicaWait.Done()
+
+	for {
+		s.l.Lock()
+		next := s.next
+		s.next = nil
+		if next == nil {
+			s.running = false
+		}
+		s.l.Unlock()
+
+		if next == nil {
+			return
+		}
+		s.replicate(next)
+	}
+}
+
+// replicate writes the state to the replica and records the outcome.
+func (s *replicaState) replicate(current *terraform.State) {
+	status, err := readReplicaStatus(s.StatusPath)
+	if err != nil || status == nil {
+		status = new(replicaStatus)
+	}
+	status.Backend = s.Backend
+	status.LastAttempt = time.Now().UTC().Format(time.RFC3339)
+
+	var buf bytes.Buffer
+	err = terraform.WriteState(current, &buf)
+	if err == nil {
+		err = s.Client.Put(buf.Bytes())
+	}
+	if err != nil {
+		log.Printf("[WARN] Error replicating the state to %s: %s", s.Backend, err)
+		status.Error = err.Error()
+	} else {
+		status.LastSuccess = status.LastAttempt
+		status.Lineage = current.Lineage
+		status.Serial = current.Serial
+		status.Error = ""
+	}
+
+	if err := writeReplicaStatus(s.StatusPath, status); err != nil {
+		log.Printf("[WARN] Error writing the replica status: %s", err)
+	}
+}
+
+// replicaConfigPath returns the path of the replica configuration file.
+func (m *Meta) replicaConfigPath() string {
+	return filepath.Join(m.DataDir(), ReplicaConfigFilename)
+}
+
+// replicaStatusPath returns the path of the replica status file.
+func (m *Meta) replicaStatusPath() string {
+	return filepath.Join(m.DataDir(), ReplicaStatusFilename)
+}
+
+// replicaState wraps the state to replicate it, if a replica is configured
+// for the working directory.
+func (m *Meta) replicaState(s state.State) (state.State, error) {
+	path := m.replicaConfigPath()
+	if _, err := os.Stat(path); err != nil {
+		return s, nil
+	}
+
+	client, conf, err := replicaClient(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &replicaState{
+		Real:       s,
+		Client:     client,
+		Backend:    conf.Type,
+		StatusPath: m.replicaStatusPath(),
+	}, nil
+}
+
+// replicaClient returns the remote state client for the remote state
+// configuration file at path. See loadRemoteOverride for its format.
+func replicaClient(path string) (remote.Client, *terraform.RemoteState, error) {
+	conf, err := loadRemoteOverride(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := remote.NewClient(strings.ToLower(conf.Type), conf.Config)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"Error initializing remote driver '%s' from %s: %s", conf.Type, path, err)
+	}
+
+	return client, conf, nil
+}
+
+// readReplicaStatus reads the replica status file at path. If there's no
+// status file, nil is returned.
+func readReplicaStatus(path string) (*replicaStatus, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var status replicaStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", path, err)
+	}
+
+	return &status, nil
+}
+
+// writeReplicaStatus writes the replica status file at path.
+func writeReplicaStatus(path string, status *replicaStatus) error {
+	data, err := json.MarshalIndent(status, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
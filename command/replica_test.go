@@ -0,0 +1,187 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+func TestMetaReplicaState(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	testStateFileDefault(t, testState())
+	if err := os.MkdirAll(DefaultDataDir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	replicaPath := filepath.Join(tmp, "replica.tfstate")
+	testReplicaConfig(t, filepath.Join(DefaultDataDir, ReplicaConfigFilename), replicaPath)
+
+	m := new(Meta)
+	s, err := m.State()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := s.(*replicaState); !ok {
+		t.Fatalf("bad: %#v", s)
+	}
+
+	current := s.State()
+	current.Serial++
+	if err := s.WriteState(current); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := s.PersistState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !WaitForReplicas(5 * time.Second) {
+		t.Fatal("replication timed out")
+	}
+
+	actual := testReadState(t, replicaPath)
+	if !actual.Equal(current) {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	status, err := readReplicaStatus(filepath.Join(DefaultDataDir, ReplicaStatusFilename))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if status.Backend != "local" || status.Serial != current.Serial || status.Error != "" {
+		t.Fatalf("bad: %#v", status)
+	}
+	if status.LastSuccess == "" || status.LastSuccess != status.LastAttempt {
+		t.Fatalf("bad: %#v", status)
+	}
+}
+
+func TestMetaReplicaState_failure(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	testStateFileDefault(t, testState())
+	if err := os.MkdirAll(DefaultDataDir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	replicaPath := filepath.Join(tmp, "missing", "replica.tfstate")
+	testReplicaConfig(t, filepath.Join(DefaultDataDir, ReplicaConfigFilename), replicaPath)
+
+	m := new(Meta)
+	s, err := m.State()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	current := s.State()
+	current.Serial++
+	if err := s.WriteState(current); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// A failure to replicate doesn't fail saving the state
+	if err := s.PersistState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !WaitForReplicas(5 * time.Second) {
+		t.Fatal("replication timed out")
+	}
+
+	actual := testReadState(t, DefaultStateFilename)
+	if actual.Serial != current.Serial {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	status, err := readReplicaStatus(filepath.Join(DefaultDataDir, ReplicaStatusFilename))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if status.Error == "" || status.LastSuccess != "" {
+		t.Fatalf("bad: %#v", status)
+	}
+}
+
+func TestMetaReplicaState_copy(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	testStateFileDefault(t, testState())
+	if err := os.MkdirAll(DefaultDataDir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	replicaPath := filepath.Join(tmp, "replica.tfstate")
+	testReplicaConfig(t, filepath.Join(DefaultDataDir, ReplicaConfigFilename), replicaPath)
+
+	m := new(Meta)
+	s, err := m.State()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	current := s.State()
+	current.Serial++
+	if err := s.WriteState(current); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := s.PersistState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	persisted := s.State().DeepCopy()
+
+	// Changing the state while it's replicated doesn't change the replica
+	s.State().Lineage = "changed"
+	if !WaitForReplicas(5 * time.Second) {
+		t.Fatal("replication timed out")
+	}
+
+	actual := testReadState(t, replicaPath)
+	if !actual.Equal(persisted) || actual.Lineage == "changed" {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestMetaReplicaState_plan(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	if err := os.MkdirAll(DefaultDataDir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	replicaPath := filepath.Join(tmp, "replica.tfstate")
+	testReplicaConfig(t, filepath.Join(DefaultDataDir, ReplicaConfigFilename), replicaPath)
+
+	planPath := testPlanFile(t, &terraform.Plan{
+		Module: testModule(t, "apply"),
+	})
+	statePath := filepath.Join(tmp, "terraform.tfstate")
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// The state of an apply of a plan file is replicated too
+	args := []string{
+		"-state", statePath,
+		planPath,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !WaitForReplicas(5 * time.Second) {
+		t.Fatal("replication timed out")
+	}
+
+	expected := testReadState(t, statePath)
+	actual := testReadState(t, replicaPath)
+	if !actual.Equal(expected) {
+		t.Fatalf("bad: %s", actual)
+	}
+}
@@ -17,15 +17,23 @@ type ShowCommand struct {
 
 func (c *ShowCommand) Run(args []string) int {
 	var moduleDepth int
+	var jsonOutput bool
+	var display FormatPlanOpts
 
 	args = c.Meta.process(args, false)
 
 	cmdFlags := flag.NewFlagSet("show", flag.ContinueOnError)
 	c.addModuleDepthFlag(cmdFlags, &moduleDepth)
+	c.addPlanDisplayFlags(cmdFlags, &display)
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
+	if display.Compact && display.Full {
+		c.Ui.Error("The -compact and -full flags can't be used together.")
+		return 1
+	}
 
 	args = cmdFlags.Args()
 	if len(args) > 1 {
@@ -91,11 +99,29 @@ func (c *ShowCommand) Run(args []string) int {
 		return 1
 	}
 
+	if jsonOutput {
+		if plan == nil {
+			c.Ui.Error("The -json flag can only be used with a plan file.")
+			return 1
+		}
+
+		out, err := FormatPlanJSON(plan)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		c.Ui.Output(out)
+		return 0
+	}
+
 	if plan != nil {
 		c.Ui.Output(FormatPlan(&FormatPlanOpts{
 			Plan:        plan,
 			Color:       c.Colorize(),
 			ModuleDepth: moduleDepth,
+			Compact:     display.Compact,
+			Full:        display.Full,
 		}))
 		return 0
 	}
@@ -117,6 +143,16 @@ Usage: terraform show [options] [path]
 
 Options:
 
+  -compact            Show each changed value of an attribute holding a JSON
+                      document, such as a policy, on one line with its path.
+
+  -full               Show the unchanged values of attributes holding JSON
+                      documents as well as the changed ones.
+
+  -json               If specified, the plan file is shown as JSON for use
+                      by other tools. Sensitive values are not included.
+                      This can only be used with a plan file.
+
   -module-depth=n     Specifies the depth of modules to show in the output.
                       By default this is -1, which will expand all.
 
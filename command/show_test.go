@@ -1,6 +1,7 @@
 package command
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -125,6 +126,100 @@ func TestShow_plan(t *testing.T) {
 	}
 }
 
+func TestShow_planJSON(t *testing.T) {
+	planPath := testPlanFile(t, &terraform.Plan{
+		Module: new(module.Tree),
+		Diff: &terraform.Diff{
+			Modules: []*terraform.ModuleDiff{
+				&terraform.ModuleDiff{
+					Path: []string{"root"},
+					Resources: map[string]*terraform.InstanceDiff{
+						"test_instance.foo": &terraform.InstanceDiff{
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"ami": &terraform.ResourceAttrDiff{
+									Old: "bar",
+									New: "baz",
+								},
+								"password": &terraform.ResourceAttrDiff{
+									Old:       "secret",
+									New:       "hunter2",
+									Sensitive: true,
+								},
+							},
+						},
+						"test_instance.bar": &terraform.InstanceDiff{
+							Destroy: true,
+						},
+					},
+				},
+			},
+		},
+	})
+
+	ui := new(cli.MockUi)
+	c := &ShowCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-json",
+		planPath,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	var result planJSON
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := planJSONSummary{Change: 1, Destroy: 1}
+	if result.Summary != expected {
+		t.Fatalf("bad: %#v", result.Summary)
+	}
+	if len(result.Changes) != 2 {
+		t.Fatalf("bad: %#v", result.Changes)
+	}
+
+	bar, foo := result.Changes[0], result.Changes[1]
+	if bar.Address != "test_instance.bar" || bar.Action != "delete" {
+		t.Fatalf("bad: %#v", bar)
+	}
+	if foo.Action != "update" || foo.Provider != "test" || foo.Type != "test_instance" {
+		t.Fatalf("bad: %#v", foo)
+	}
+	if foo.Attributes["ami"].After != "baz" {
+		t.Fatalf("bad: %#v", foo.Attributes["ami"])
+	}
+	if strings.Contains(ui.OutputWriter.String(), "hunter2") {
+		t.Fatalf("sensitive value in output:\n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestShow_jsonState(t *testing.T) {
+	statePath := testStateFile(t, testState())
+
+	ui := new(cli.MockUi)
+	c := &ShowCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-json",
+		statePath,
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: \n%s", ui.OutputWriter.String())
+	}
+}
+
 func TestShow_noArgsRemoteState(t *testing.T) {
 	tmp, cwd := testCwd(t)
 	defer testFixCwd(t, tmp, cwd)
@@ -183,3 +278,45 @@ func TestShow_state(t *testing.T) {
 		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
 	}
 }
+
+func TestShow_stateSensitiveOutput(t *testing.T) {
+	originalState := testState()
+	originalState.RootModule().Outputs = map[string]*terraform.OutputState{
+		"foo": {
+			Value: "bar",
+			Type:  "string",
+		},
+		"secret": {
+			Sensitive: true,
+			Value:     "hunter2",
+			Type:      "string",
+		},
+	}
+	statePath := testStateFile(t, originalState)
+
+	ui := new(cli.MockUi)
+	c := &ShowCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		statePath,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	actual := ui.OutputWriter.String()
+	if !strings.Contains(actual, "foo = bar") {
+		t.Fatalf("bad: %s", actual)
+	}
+	if !strings.Contains(actual, "secret = <sensitive>") {
+		t.Fatalf("bad: %s", actual)
+	}
+	if strings.Contains(actual, "hunter2") {
+		t.Fatalf("sensitive value shown: %s", actual)
+	}
+}
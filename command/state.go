@@ -2,10 +2,13 @@ package command
 
 import (
 	"fmt"
+	"io/ioutil"
+	"log"
 	"os"
 	"strings"
 
 	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/hcl"
 	"github.com/hashicorp/terraform/state"
 	"github.com/hashicorp/terraform/state/remote"
 	"github.com/hashicorp/terraform/terraform"
@@ -28,6 +31,16 @@ type StateOpts struct {
 	RemoteCacheOnly bool
 	RemoteRefresh   bool
 
+	// RemoteReadOnly, if true, will never write to the remote state while
+	// loading it. Normally a local cache that is newer than the remote
+	// state is pushed to the remote state when it is refreshed.
+	RemoteReadOnly bool
+
+	// RemoteOverridePath is the path of a remote state configuration to
+	// use instead of the one saved at RemotePath. The override is only
+	// cached in memory, so nothing is written to RemotePath or LocalPath.
+	RemoteOverridePath string
+
 	// BackupPath is the path where the backup will be placed. If not set,
 	// it is assumed to be the path where the state is stored locally
 	// plus the DefaultBackupExtension.
@@ -65,6 +78,10 @@ type StateResult struct {
 // dataDir is the path to the local data directory where the remote state
 // cache would be stored.
 func State(opts *StateOpts) (*StateResult, error) {
+	if opts.RemoteOverridePath != "" {
+		return remoteOverrideState(opts)
+	}
+
 	result := new(StateResult)
 
 	// Get the remote state cache path
@@ -74,9 +91,9 @@ func State(opts *StateOpts) (*StateResult, error) {
 		var remote *state.CacheState
 		if opts.RemoteCacheOnly {
 			// Setup the in-memory state
-			ls := &state.LocalState{Path: opts.RemotePath}
+			ls := remoteCacheState(opts.RemotePath)
 			if err := ls.RefreshState(); err != nil {
-				return nil, err
+				return nil, remoteCacheError(opts.RemotePath, err)
 			}
 
 			// If we have a forced state, set it
@@ -105,7 +122,8 @@ func State(opts *StateOpts) (*StateResult, error) {
 				remote, err = remoteState(
 					opts.ForceState,
 					opts.RemotePath,
-					false)
+					false,
+					opts.RemoteReadOnly)
 				if err != nil {
 					return nil, err
 				}
@@ -116,7 +134,8 @@ func State(opts *StateOpts) (*StateResult, error) {
 					// We have a remote state, initialize that.
 					remote, err = remoteStateFromPath(
 						opts.RemotePath,
-						opts.RemoteRefresh)
+						opts.RemoteRefresh,
+						opts.RemoteReadOnly)
 					if err != nil {
 						return nil, err
 					}
@@ -165,9 +184,8 @@ func State(opts *StateOpts) (*StateResult, error) {
 				if result.State != nil && !result.State.State().Empty() {
 					if !local.State().Empty() {
 						// We already have a remote state... that is an error.
-						return nil, fmt.Errorf(
-							"Remote state found, but state file '%s' also present.",
-							opts.LocalPath)
+						return nil, remoteAndLocalError(
+							result.State.State().Remote, result.RemotePath, opts.LocalPath)
 					}
 
 					// Empty state
@@ -196,7 +214,15 @@ func State(opts *StateOpts) (*StateResult, error) {
 			backupPath = opts.BackupPath
 		}
 
-		if backupPath != "-" {
+		// A local state file is kept as it was, rather than written
+		// again from the state read from it. A forced state isn't what's
+		// in the file, so it's backed up like a remote state.
+		local, ok := result.State.(*state.LocalState)
+		switch {
+		case backupPath == "-":
+		case ok && opts.ForceState == nil:
+			local.BackupPath = backupPath
+		default:
 			result.State = &state.BackupState{
 				Real: result.State,
 				Path: backupPath,
@@ -210,14 +236,20 @@ func State(opts *StateOpts) (*StateResult, error) {
 
 func remoteState(
 	local *terraform.State,
-	localPath string, refresh bool) (*state.CacheState, error) {
+	localPath string, refresh, readOnly bool) (*state.CacheState, error) {
 	// If there is no remote settings, it is an error
 	if local.Remote == nil {
 		return nil, fmt.Errorf("Remote state cache has no remote info")
 	}
 
-	// Initialize the remote client based on the local state
-	client, err := remote.NewClient(strings.ToLower(local.Remote.Type), local.Remote.Config)
+	// Initialize the remote client based on the local state. The state
+	// is only read from a read replica if it won't be written, since the
+	// replica may lag behind.
+	config := local.Remote.Config
+	if !readOnly {
+		config = remote.PrimaryConfig(config)
+	}
+	client, err := remote.NewClient(strings.ToLower(local.Remote.Type), config)
 	if err != nil {
 		return nil, errwrap.Wrapf(fmt.Sprintf(
 			"Error initializing remote driver '%s': {{err}}",
@@ -229,13 +261,17 @@ func remoteState(
 
 	// Create the cached client
 	cache := &state.CacheState{
-		Cache:   &state.LocalState{Path: localPath},
+		Cache:   remoteCacheState(localPath),
 		Durable: durable,
 	}
 
 	if refresh {
 		// Refresh the cache
 		if err := cache.RefreshState(); err != nil {
+			if err == state.ErrChecksumMismatch {
+				return nil, remoteCacheError(localPath, err)
+			}
+
 			return nil, errwrap.Wrapf(
 				"Error reloading remote state: {{err}}", err)
 		}
@@ -254,6 +290,15 @@ func remoteState(
 		// want to explicitly sync the remote side with our local so that
 		// the remote gets the latest serial number.
 		case state.CacheRefreshLocalNewer:
+			// When we're only reading the state, leave the remote side
+			// alone. The next command that modifies the state syncs it.
+			if readOnly {
+				log.Printf(
+					"[INFO] Remote state cache is newer than the remote " +
+						"state, not syncing since the state is read-only")
+				break
+			}
+
 			// Write our local state out to the durable storage to start.
 			if err := cache.WriteState(local); err != nil {
 				return nil, errwrap.Wrapf(
@@ -263,22 +308,232 @@ func remoteState(
 				return nil, errwrap.Wrapf(
 					"Error preparing remote state: {{err}}", err)
 			}
+		// The cache and the remote state have the same serial but
+		// different contents, so neither can replace the other.
+		case state.CacheRefreshConflict:
+			return nil, fmt.Errorf(strings.TrimSpace(errRemoteStateConflict),
+				localPath, local.Remote.Type, local.Serial,
+				localPath, local.Remote.Type, localPath, local.Serial)
 		default:
 			return nil, fmt.Errorf(
-				"Unknown refresh result: %s", cache.RefreshResult())
+				"Unknown refresh result: %s. This is a bug in Terraform,\n"+
+					"please report it.", cache.RefreshResult())
 		}
 	}
 
 	return cache, nil
 }
 
-func remoteStateFromPath(path string, refresh bool) (*state.CacheState, error) {
+// remoteOverrideState returns the state for a remote state configuration
+// given with -backend-override. The local state and the remote state cache
+// are left alone, and the state isn't backed up locally.
+func remoteOverrideState(opts *StateOpts) (*StateResult, error) {
+	config, err := loadRemoteOverride(opts.RemoteOverridePath)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := remote.NewClient(strings.ToLower(config.Type), config.Config)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf(
+			"Error initializing remote driver '%s': {{err}}",
+			config.Type), err)
+	}
+
+	cache := &state.CacheState{
+		Cache:   &state.InmemState{},
+		Durable: &remote.State{Client: client},
+	}
+
+	// A plan brings its own state, so the remote state is only read
+	// when there is none.
+	if opts.ForceState != nil {
+		if err := cache.WriteState(opts.ForceState); err != nil {
+			return nil, err
+		}
+	} else if err := cache.RefreshState(); err != nil {
+		return nil, errwrap.Wrapf(
+			"Error loading remote state: {{err}}", err)
+	}
+
+	return &StateResult{State: cache, Remote: cache}, nil
+}
+
+// loadRemoteOverride loads a remote state configuration file, which sets
+// the backend type and its configuration like the flags of
+// "terraform remote config":
+//
+//     backend = "s3"
+//     config {
+//       bucket = "terraform-state-replica"
+//       key    = "network/terraform.tfstate"
+//     }
+func loadRemoteOverride(path string) (*terraform.RemoteState, error) {
+	d, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading backend override: %s", err)
+	}
+
+	var raw struct {
+		Backend string
+		Config  []map[string]string
+	}
+	if err := hcl.Decode(&raw, string(d)); err != nil {
+		return nil, fmt.Errorf("Error parsing backend override %s: %s", path, err)
+	}
+	if raw.Backend == "" {
+		return nil, fmt.Errorf(
+			"Backend override %s must set the backend type with \"backend\"", path)
+	}
+
+	result := &terraform.RemoteState{
+		Type:   raw.Backend,
+		Config: make(map[string]string),
+	}
+	for _, c := range raw.Config {
+		for k, v := range c {
+			result.Config[k] = v
+		}
+	}
+
+	return result, nil
+}
+
+func remoteStateFromPath(path string, refresh, readOnly bool) (*state.CacheState, error) {
 	// First create the local state for the path
-	local := &state.LocalState{Path: path}
+	local := remoteCacheState(path)
 	if err := local.RefreshState(); err != nil {
-		return nil, err
+		return nil, remoteCacheError(path, err)
 	}
 	localState := local.State()
 
-	return remoteState(localState, path, refresh)
+	return remoteState(localState, path, refresh, readOnly)
+}
+
+// remoteCacheState returns the LocalState used for the remote state cache
+// at the given path. A checksum is recorded next to the cache so that a
+// corrupted or hand edited cache can be detected when it is loaded.
+func remoteCacheState(path string) *state.LocalState {
+	return &state.LocalState{
+		Path:         path,
+		ChecksumPath: path + DefaultChecksumExtension,
+	}
+}
+
+// stateWrapper is implemented by the states in this package that wrap
+// another state.
+type stateWrapper interface {
+	unwrap() state.State
 }
+
+// unwrapState returns the state wrapped by s, or nil if s doesn't wrap
+// another state.
+func unwrapState(s state.State) state.State {
+	switch s := s.(type) {
+	case stateWrapper:
+		return s.unwrap()
+	case *state.BackupState:
+		return s.Real
+	}
+
+	return nil
+}
+
+// findCacheState looks through the states wrapping the remote state in s
+// for its CacheState. The auditState among them is returned too, or nil
+// if the audit log isn't enabled. The CacheState is nil if s isn't a
+// remote state.
+func findCacheState(s state.State) (*state.CacheState, *auditState) {
+	var audit *auditState
+	for s != nil {
+		switch ss := s.(type) {
+		case *state.CacheState:
+			return ss, audit
+		case *auditState:
+			audit = ss
+		}
+		s = unwrapState(s)
+	}
+
+	return nil, audit
+}
+
+// remoteCacheError turns an error loading the remote state cache into an
+// error explaining how to recover. The cache can always be rebuilt from
+// the remote state, so there's no reason to leave the user with just a
+// decoding error.
+func remoteCacheError(path string, err error) error {
+	return fmt.Errorf(
+		strings.TrimSpace(errRemoteCacheInvalid),
+		path, err, path, path+DefaultChecksumExtension)
+}
+
+const errRemoteCacheInvalid = `
+Error loading the remote state cache %q: %s
+
+This file is managed by Terraform and is either corrupted or was modified
+outside of Terraform. The state stored remotely is not affected by this.
+
+To recover, remove %q and %q, then configure remote state
+again with "terraform remote config" using the same -backend and
+-backend-config settings. This pulls the latest state from the remote
+server and rebuilds the cache.
+`
+
+// remoteAndLocalError returns the error for a local state file with
+// resources in it when remote state is enabled, explaining how to recover.
+func remoteAndLocalError(remote *terraform.RemoteState, remotePath, localPath string) error {
+	remoteType := "unknown"
+	if remote != nil {
+		remoteType = remote.Type
+	}
+
+	return fmt.Errorf(strings.TrimSpace(errStateRemoteAndLocal),
+		remoteType, remotePath, localPath,
+		localPath, localPath,
+		localPath, remoteType, localPath)
+}
+
+const errStateRemoteAndLocal = `
+Remote state is enabled, but there is also a local state file with
+resources in it, so Terraform can't tell which one is the state of this
+configuration:
+
+  Remote state (%s), cached in: %s
+  Local state file:             %s
+
+To recover, decide which state to keep:
+
+  - To keep the remote state, if the local state file is left over from
+    before remote state was enabled, move it out of the way and run the
+    command again:
+
+        mv %s %s.old
+
+  - To keep the local state, move it out of the way, run
+    "terraform remote config -disable" to stop using remote state, put
+    the local state file back at %s and enable remote state again with
+    "terraform remote config -backend=%s ...". This uploads the local
+    state, replacing the remote state. The remote state written out by
+    -disable is overwritten, so back it up first if you need it.
+
+The local state file is at: %s
+`
+
+const errRemoteStateConflict = `
+The state cached in %s and the remote state (%s) both
+have serial %d, but their contents differ. This usually means that two
+Terraform runs changed the state at the same time, from different
+machines or working directories.
+
+Terraform won't replace one with the other. To recover, decide which
+state to keep:
+
+  - To keep the remote state, move the cache %s out of the way
+    and configure the remote state again with
+    "terraform remote config -backend=%s ...", with the same settings.
+    This downloads the remote state.
+
+  - To keep the cached state, edit %s to set its "serial"
+    to a value higher than %d, then run "terraform remote push".
+`
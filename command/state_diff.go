@@ -0,0 +1,389 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/state"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+// StateDiffCommand is a Command implementation that compares the
+// resources of two states.
+type StateDiffCommand struct {
+	Meta
+	StateMeta
+}
+
+func (c *StateDiffCommand) Run(args []string) int {
+	var jsonOutput bool
+	args = c.Meta.process(args, true)
+
+	cmdFlags := c.Meta.flagSet("state diff")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+	args = cmdFlags.Args()
+	if len(args) < 1 || len(args) > 2 {
+		c.Ui.Error("The state diff command expects one or two arguments.")
+		return cli.RunResultHelp
+	}
+
+	oldState, err := c.readState(args[0])
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	// Without a second state, the state is compared with the current one
+	var newState *terraform.State
+	if len(args) == 2 {
+		newState, err = c.readState(args[1])
+	} else {
+		var current state.StateReader
+		current, err = c.StateReader()
+		if err == nil {
+			newState = current.State()
+		}
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+
+	diff := diffStateResources(oldState, newState)
+	if jsonOutput {
+		data, err := json.MarshalIndent(diff, "", "    ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to encode the state diff: %s", err))
+			return 1
+		}
+
+		c.Ui.Output(string(data))
+		return 0
+	}
+
+	if diff.Empty() {
+		c.Ui.Output("The states have the same resources.")
+		return 0
+	}
+
+	c.Ui.Output(c.Colorize().Color(strings.TrimRight(diff.format(true), "\n")))
+	return 0
+}
+
+// readState reads the state given by an argument of the state diff
+// command: a version of the remote state, the state in the remote storage
+// of a remote state configuration file, or a state file.
+func (c *StateDiffCommand) readState(source string) (*terraform.State, error) {
+	var data []byte
+	switch {
+	case strings.HasPrefix(source, "version:"):
+		id := strings.TrimPrefix(source, "version:")
+
+		opts := c.StateOpts()
+		opts.RemoteReadOnly = true
+		result, err := State(opts)
+		if err != nil {
+			return nil, fmt.Errorf(errStateLoadingState, err)
+		}
+		client, err := c.versionedClient(result)
+		if err != nil {
+			return nil, err
+		}
+
+		payload, err := client.GetVersion(id)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading version %s of the state: %s", id, err)
+		}
+		if payload == nil {
+			return nil, fmt.Errorf(
+				"Version %q of the remote state doesn't exist. Use \"terraform state versions\"\n"+
+					"to list the versions.", id)
+		}
+		data = payload.Data
+
+	case strings.HasPrefix(source, "backend:"):
+		path := strings.TrimPrefix(source, "backend:")
+
+		client, conf, err := replicaClient(path)
+		if err != nil {
+			return nil, err
+		}
+		payload, err := client.Get()
+		if err != nil {
+			return nil, fmt.Errorf("Error reading the state from the %q backend: %s", conf.Type, err)
+		}
+		if payload == nil {
+			return nil, fmt.Errorf("There is no state in the %q backend of %s.", conf.Type, path)
+		}
+		data = payload.Data
+
+	default:
+		f, err := os.Open(source)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("The state file %s doesn't exist.", source)
+			}
+			return nil, fmt.Errorf("Error reading state file %s: %s", source, err)
+		}
+		defer f.Close()
+
+		s, err := terraform.ReadState(f)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading state file %s: %s", source, err)
+		}
+		return s, nil
+	}
+
+	s, err := terraform.ReadState(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the state of %s: %s", source, err)
+	}
+	return s, nil
+}
+
+func (c *StateDiffCommand) Help() string {
+	helpText := `
+Usage: terraform state diff [options] OLD [NEW]
+
+  Compare the resources of two states.
+
+  The resources only in the NEW state are marked with +, the resources only
+  in the OLD state with -, and the resources in both that differ with ~,
+  followed by the attributes that differ. Without NEW, the OLD state is
+  compared with the current state.
+
+  Each state can be:
+
+    path                A state file.
+
+    backend:path        The state in the remote storage of a remote state
+                        configuration file, in the same format as for
+                        -backend-override.
+
+    version:ID          A version of the remote state, as listed by
+                        "terraform state versions".
+
+Options:
+
+  -json               Output the differences as JSON.
+
+  -state=statefile    Path to the current Terraform state, if NEW isn't
+                      given. By default it will use the state
+                      "terraform.tfstate" if it exists.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateDiffCommand) Synopsis() string {
+	return "Compare the resources of two states"
+}
+
+// stateResourceDiff is the difference between the resources of two states,
+// by resource address.
+type stateResourceDiff struct {
+	// Added are the resources only in the new state.
+	Added []string `json:"added"`
+
+	// Removed are the resources only in the old state.
+	Removed []string `json:"removed"`
+
+	// Changed are the resources in both states that differ.
+	Changed []*stateResourceChange `json:"changed"`
+}
+
+// stateResourceChange is a resource that differs between two states.
+type stateResourceChange struct {
+	Address    string                `json:"address"`
+	Attributes []*stateAttributeDiff `json:"attributes"`
+}
+
+// stateAttributeDiff is an attribute of a resource that differs between
+// two states. Old or New is nil if the attribute isn't in that state.
+// Besides the attributes of the primary instance, the ID of the instance,
+// whether it's tainted and the number of deposed instances are compared,
+// as the "id", "tainted" and "deposed" attributes.
+type stateAttributeDiff struct {
+	Name string  `json:"name"`
+	Old  *string `json:"old"`
+	New  *string `json:"new"`
+}
+
+// diffStateResources compares the resources of the old and the new state.
+// Either state may be nil.
+func diffStateResources(oldState, newState *terraform.State) *stateResourceDiff {
+	oldResources := stateResourcesByAddr(oldState)
+	newResources := stateResourcesByAddr(newState)
+
+	result := &stateResourceDiff{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []*stateResourceChange{},
+	}
+	for addr, r := range newResources {
+		oldR, ok := oldResources[addr]
+		switch {
+		case !ok:
+			result.Added = append(result.Added, addr)
+		case !oldR.Equal(r):
+			result.Changed = append(result.Changed, &stateResourceChange{
+				Address:    addr,
+				Attributes: diffResourceAttributes(oldR, r),
+			})
+		}
+	}
+	for addr := range oldResources {
+		if _, ok := newResources[addr]; !ok {
+			result.Removed = append(result.Removed, addr)
+		}
+	}
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Sort(stateResourceChanges(result.Changed))
+	return result
+}
+
+// Empty returns true if the states have the same resources.
+func (d *stateResourceDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String returns the difference one resource per line, in the colors of
+// a plan, for colorizing.
+func (d *stateResourceDiff) String() string {
+	return d.format(false)
+}
+
+// format returns the difference like String, with the attributes that
+// differ below each changed resource if attrs is true.
+func (d *stateResourceDiff) format(attrs bool) string {
+	var buf bytes.Buffer
+	for _, addr := range d.Added {
+		buf.WriteString(fmt.Sprintf("  [green]+[reset] %s\n", addr))
+	}
+	for _, addr := range d.Removed {
+		buf.WriteString(fmt.Sprintf("  [red]-[reset] %s\n", addr))
+	}
+	for _, c := range d.Changed {
+		buf.WriteString(fmt.Sprintf("  [yellow]~[reset] %s\n", c.Address))
+		if !attrs {
+			continue
+		}
+
+		width := 0
+		for _, a := range c.Attributes {
+			if len(a.Name) > width {
+				width = len(a.Name)
+			}
+		}
+		for _, a := range c.Attributes {
+			buf.WriteString(fmt.Sprintf(
+				"      %s:%s %s => %s\n",
+				a.Name, strings.Repeat(" ", width-len(a.Name)),
+				stateDiffValue(a.Old), stateDiffValue(a.New)))
+		}
+	}
+
+	return buf.String()
+}
+
+// diffResourceAttributes compares the primary instances of two resources.
+func diffResourceAttributes(oldR, newR *terraform.ResourceState) []*stateAttributeDiff {
+	oldAttrs := resourceDiffAttributes(oldR)
+	newAttrs := resourceDiffAttributes(newR)
+
+	names := make([]string, 0, len(oldAttrs)+len(newAttrs))
+	for k := range oldAttrs {
+		names = append(names, k)
+	}
+	for k := range newAttrs {
+		if _, ok := oldAttrs[k]; !ok {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	var result []*stateAttributeDiff
+	for _, k := range names {
+		oldV, oldOk := oldAttrs[k]
+		newV, newOk := newAttrs[k]
+		if oldOk && newOk && oldV == newV {
+			continue
+		}
+
+		a := &stateAttributeDiff{Name: k}
+		if oldOk {
+			a.Old = &oldV
+		}
+		if newOk {
+			a.New = &newV
+		}
+		result = append(result, a)
+	}
+
+	return result
+}
+
+// resourceDiffAttributes returns the attributes of a resource that are
+// compared by diffResourceAttributes.
+func resourceDiffAttributes(r *terraform.ResourceState) map[string]string {
+	result := make(map[string]string)
+	if r.Primary != nil {
+		for k, v := range r.Primary.Attributes {
+			result[k] = v
+		}
+		result["id"] = r.Primary.ID
+		result["tainted"] = fmt.Sprintf("%t", r.Primary.Tainted)
+	}
+	result["deposed"] = fmt.Sprintf("%d", len(r.Deposed))
+
+	return result
+}
+
+// stateDiffValue formats an attribute value for stateResourceDiff.
+func stateDiffValue(v *string) string {
+	if v == nil {
+		return "<none>"
+	}
+
+	return fmt.Sprintf("%q", *v)
+}
+
+// stateResourcesByAddr returns the resources of the state by their address,
+// such as "module.foo.aws_instance.bar".
+func stateResourcesByAddr(s *terraform.State) map[string]*terraform.ResourceState {
+	result := make(map[string]*terraform.ResourceState)
+	if s == nil {
+		return result
+	}
+
+	for _, m := range s.Modules {
+		prefix := ""
+		if len(m.Path) > 1 {
+			prefix = "module." + strings.Join(m.Path[1:], ".module.") + "."
+		}
+
+		for k, r := range m.Resources {
+			result[prefix+k] = r
+		}
+	}
+
+	return result
+}
+
+// stateResourceChanges sorts resource changes by address.
+type stateResourceChanges []*stateResourceChange
+
+func (s stateResourceChanges) Len() int           { return len(s) }
+func (s stateResourceChanges) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s stateResourceChanges) Less(i, j int) bool { return s[i].Address < s[j].Address }
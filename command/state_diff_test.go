@@ -0,0 +1,198 @@
+package command
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+func TestStateDiff(t *testing.T) {
+	oldPath := testStateFile(t, testStateDiffOld())
+	newPath := testStateFile(t, testStateDiffNew())
+
+	ui := new(cli.MockUi)
+	c := &StateDiffCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"-no-color", oldPath, newPath}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := strings.TrimSpace(ui.OutputWriter.String())
+	expected := strings.TrimSpace(testStateDiffOutput)
+	if actual != expected {
+		t.Fatalf("bad:\n\n%s\n\nexpected:\n\n%s", actual, expected)
+	}
+}
+
+func TestStateDiff_current(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	oldPath := testStateFile(t, testStateDiffOld())
+	testStateFileDefault(t, testStateDiffNew())
+
+	ui := new(cli.MockUi)
+	c := &StateDiffCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"-no-color", oldPath}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := strings.TrimSpace(ui.OutputWriter.String())
+	expected := strings.TrimSpace(testStateDiffOutput)
+	if actual != expected {
+		t.Fatalf("bad:\n\n%s\n\nexpected:\n\n%s", actual, expected)
+	}
+}
+
+func TestStateDiff_backend(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	oldPath := testStateFile(t, testStateDiffOld())
+	config := testReplicaConfig(t, "old.hcl", oldPath)
+	newPath := testStateFile(t, testStateDiffOld())
+
+	ui := new(cli.MockUi)
+	c := &StateDiffCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"backend:" + config, newPath}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "same resources") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestStateDiff_json(t *testing.T) {
+	oldPath := testStateFile(t, testStateDiffOld())
+	newPath := testStateFile(t, testStateDiffNew())
+
+	ui := new(cli.MockUi)
+	c := &StateDiffCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"-json", oldPath, newPath}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	var actual stateResourceDiff
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &actual); err != nil {
+		t.Fatalf("err: %s\n\n%s", err, ui.OutputWriter.String())
+	}
+	expected := diffStateResources(testStateDiffOld(), testStateDiffNew())
+	if !reflect.DeepEqual(&actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestStateDiff_missing(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &StateDiffCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"does-not-exist.tfstate", "also-missing.tfstate"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "doesn't exist") {
+		t.Fatalf("bad:\n\n%s", ui.ErrorWriter.String())
+	}
+}
+
+func TestDiffStateResources(t *testing.T) {
+	actual := diffStateResources(testStateDiffOld(), testStateDiffNew())
+
+	oldID, newID := "old", "new"
+	expected := &stateResourceDiff{
+		Added:   []string{"module.child.test_instance.added"},
+		Removed: []string{"test_instance.removed"},
+		Changed: []*stateResourceChange{
+			&stateResourceChange{
+				Address: "test_instance.changed",
+				Attributes: []*stateAttributeDiff{
+					&stateAttributeDiff{Name: "ami", Old: &oldID},
+					&stateAttributeDiff{Name: "id", Old: &oldID, New: &newID},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if !diffStateResources(testStateDiffOld(), testStateDiffOld()).Empty() {
+		t.Fatal("should be empty")
+	}
+}
+
+func testStateDiffOld() *terraform.State {
+	s := testState()
+	s.RootModule().Resources["test_instance.removed"] = &terraform.ResourceState{
+		Type:    "test_instance",
+		Primary: &terraform.InstanceState{ID: "removed"},
+	}
+	s.RootModule().Resources["test_instance.changed"] = &terraform.ResourceState{
+		Type: "test_instance",
+		Primary: &terraform.InstanceState{
+			ID:         "old",
+			Attributes: map[string]string{"ami": "old"},
+		},
+	}
+
+	return s
+}
+
+func testStateDiffNew() *terraform.State {
+	s := testState()
+	s.RootModule().Resources["test_instance.changed"] = &terraform.ResourceState{
+		Type:    "test_instance",
+		Primary: &terraform.InstanceState{ID: "new"},
+	}
+	child := s.AddModule([]string{"root", "child"})
+	child.Resources["test_instance.added"] = &terraform.ResourceState{
+		Type:    "test_instance",
+		Primary: &terraform.InstanceState{ID: "added"},
+	}
+
+	return s
+}
+
+const testStateDiffOutput = `
+  + module.child.test_instance.added
+  - test_instance.removed
+  ~ test_instance.changed
+      ami: "old" => <none>
+      id:  "old" => "new"
+`
@@ -0,0 +1,440 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/dag"
+	"github.com/hashicorp/terraform/state/remote"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+// StateGraphCommand is a Command implementation that shows which of a set
+// of configurations read the outputs of which others with the
+// terraform_remote_state data source, and the order to apply them in.
+type StateGraphCommand struct {
+	Meta
+}
+
+func (c *StateGraphCommand) Run(args []string) int {
+	var dot bool
+	args = c.Meta.process(args, false)
+
+	cmdFlags := c.Meta.flagSet("state graph")
+	cmdFlags.BoolVar(&dot, "dot", false, "dot")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+	dirs := cmdFlags.Args()
+	if len(dirs) == 0 {
+		c.Ui.Error("The state graph command expects at least one directory.")
+		return cli.RunResultHelp
+	}
+
+	g, err := c.stateGraph(dirs)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if dot {
+		c.Ui.Output(string(g.Dot(&dag.DotOpts{DrawCycles: true})))
+		return 0
+	}
+
+	order, err := stateGraphOrder(g)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	var buf bytes.Buffer
+	for _, n := range order {
+		buf.WriteString(n.Name() + "\n")
+
+		var deps []string
+		for _, raw := range g.DownEdges(n).List() {
+			dep := raw.(*stateGraphNode)
+			if dep.Dir == "" {
+				deps = append(deps, fmt.Sprintf("reads %s (not in the given directories)", dep.Name()))
+			} else {
+				deps = append(deps, fmt.Sprintf("reads %s", dep.Name()))
+			}
+		}
+		sort.Strings(deps)
+
+		for _, d := range deps {
+			buf.WriteString(fmt.Sprintf("  %s\n", d))
+		}
+	}
+
+	c.Ui.Output(strings.TrimSpace(buf.String()))
+	return 0
+}
+
+// stateGraph returns the graph of the states of the given directories,
+// with an edge from each one to the states it reads. States that are read
+// but that aren't of any of the directories are in the graph as well.
+func (c *StateGraphCommand) stateGraph(dirs []string) (*dag.AcyclicGraph, error) {
+	g := new(dag.AcyclicGraph)
+
+	var nodes []*stateGraphNode
+	for _, dir := range dirs {
+		ref, err := dirStateRef(dir)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading the state of %s: %s", dir, err)
+		}
+
+		n := &stateGraphNode{Dir: dir, Ref: ref}
+		nodes = append(nodes, n)
+		g.Add(n)
+	}
+
+	// Only the nodes of the directories are matched against, so that
+	// each state read from outside them is added once.
+	dirNodes := len(nodes)
+	for i := 0; i < dirNodes; i++ {
+		n := nodes[i]
+		refs, err := c.remoteStateRefs(n.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ref := range refs {
+			var target *stateGraphNode
+			for _, other := range nodes {
+				if other.Ref.matches(ref) {
+					target = other
+					break
+				}
+			}
+			if target == nil {
+				target = &stateGraphNode{Ref: ref}
+				nodes = append(nodes, target)
+				g.Add(target)
+			}
+
+			if target != n {
+				g.Connect(dag.BasicEdge(n, target))
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// remoteStateRefs returns the states read by the terraform_remote_state
+// data sources in the configuration of the given directory and the
+// modules it uses.
+func (c *StateGraphCommand) remoteStateRefs(dir string) ([]*stateRef, error) {
+	mod, err := module.NewTreeModule("", dir)
+	if errwrap.ContainsType(err, new(config.ErrNoConfigsFound)) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error loading config of %s: %s", dir, err)
+	}
+
+	// Modules aren't downloaded here, "terraform get" is responsible
+	// for that.
+	storage := c.moduleStorage(filepath.Join(dir, DefaultDataDir))
+	if err := mod.Load(storage, module.GetModeNone); err != nil {
+		return nil, fmt.Errorf(
+			"Error loading modules of %s: %s\n\n"+
+				"Modules must be downloaded with \"terraform get\" before the\n"+
+				"states they read can be found.", dir, err)
+	}
+
+	var result []*stateRef
+	var walk func(*module.Tree) error
+	walk = func(t *module.Tree) error {
+		for _, r := range t.Config().Resources {
+			if r.Mode != config.DataResourceMode || r.Type != "terraform_remote_state" {
+				continue
+			}
+
+			ref, err := remoteStateDataRef(dir, r)
+			if err != nil {
+				return fmt.Errorf("%s: %s: %s", dir, r.Id(), err)
+			}
+			result = append(result, ref)
+		}
+
+		for _, child := range t.Children() {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return result, walk(mod)
+}
+
+// stateGraphOrder returns the nodes of the graph in the order the states
+// can be applied in, each after the states it reads. Nodes that could go
+// in either order are sorted by name, so the order is always the same.
+func stateGraphOrder(g *dag.AcyclicGraph) ([]*stateGraphNode, error) {
+	if cycles := g.Cycles(); len(cycles) > 0 {
+		var names []string
+		for _, cycle := range cycles {
+			cycleStr := make([]string, len(cycle))
+			for i, v := range cycle {
+				cycleStr[i] = dag.VertexName(v)
+			}
+			sort.Strings(cycleStr)
+			names = append(names, "  "+strings.Join(cycleStr, ", "))
+		}
+
+		return nil, fmt.Errorf(
+			"The states read each other's outputs in a cycle, so there is no\n"+
+				"order to apply them in:\n\n%s", strings.Join(names, "\n"))
+	}
+
+	done := make(map[dag.Vertex]bool)
+	var result []*stateGraphNode
+	for len(result) < len(g.Vertices()) {
+		var next []*stateGraphNode
+		for _, v := range g.Vertices() {
+			if done[v] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range g.DownEdges(v).List() {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = append(next, v.(*stateGraphNode))
+			}
+		}
+		sort.Sort(stateGraphNodes(next))
+
+		for _, n := range next {
+			done[n] = true
+		}
+		result = append(result, next...)
+	}
+
+	return result, nil
+}
+
+// stateGraphNode is a state in the graph of the state graph command.
+type stateGraphNode struct {
+	// Dir is the directory of the configuration the state is for, or
+	// empty if the state is only read by the given directories.
+	Dir string
+
+	Ref *stateRef
+}
+
+func (n *stateGraphNode) Name() string {
+	if n.Dir != "" {
+		return n.Dir
+	}
+
+	return n.Ref.String()
+}
+
+// GraphNodeDotter impl.
+func (n *stateGraphNode) DotNode(name string, opts *dag.DotOpts) *dag.DotNode {
+	shape := "box"
+	if n.Dir == "" {
+		shape = "ellipse"
+	}
+
+	return &dag.DotNode{
+		Name: name,
+		Attrs: map[string]string{
+			"label": n.Name(),
+			"shape": shape,
+		},
+	}
+}
+
+type stateGraphNodes []*stateGraphNode
+
+func (s stateGraphNodes) Len() int           { return len(s) }
+func (s stateGraphNodes) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s stateGraphNodes) Less(i, j int) bool { return s[i].Name() < s[j].Name() }
+
+// stateRef identifies a state by its remote state type and configuration.
+// Local states have the "local" type and the absolute path of the state.
+type stateRef struct {
+	Type   string
+	Config map[string]string
+}
+
+// dirStateRef returns the state used by the configuration in the given
+// directory: its remote state if it has one configured, or else the local
+// state in the directory.
+func dirStateRef(dir string) (*stateRef, error) {
+	path := filepath.Join(dir, DefaultDataDir, DefaultStateFilename)
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		defer f.Close()
+
+		s, err := terraform.ReadState(f)
+		if err != nil {
+			return nil, err
+		}
+		if s.IsRemote() {
+			return &stateRef{Type: s.Remote.Type, Config: s.Remote.Config}, nil
+		}
+	}
+
+	abs, err := filepath.Abs(filepath.Join(dir, DefaultStateFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	return &stateRef{
+		Type:   "local",
+		Config: map[string]string{"path": abs},
+	}, nil
+}
+
+// remoteStateDataRef returns the state read by a terraform_remote_state
+// data source in the configuration of the given directory.
+func remoteStateDataRef(dir string, r *config.Resource) (*stateRef, error) {
+	raw := r.RawConfig.Raw
+	backend, ok := raw["backend"].(string)
+	if !ok || strings.Contains(backend, "${") {
+		return nil, fmt.Errorf("the backend must be set without interpolations")
+	}
+	if backend == "_local" {
+		backend = "local"
+	}
+
+	var maps []map[string]interface{}
+	switch v := raw["config"].(type) {
+	case map[string]interface{}:
+		maps = append(maps, v)
+	case []map[string]interface{}:
+		maps = v
+	}
+
+	conf := make(map[string]string)
+	for _, m := range maps {
+		for k, v := range m {
+			s := fmt.Sprintf("%v", v)
+			if strings.Contains(s, "${") {
+				return nil, fmt.Errorf(
+					"config.%s must be set without interpolations to find the state", k)
+			}
+			conf[k] = s
+		}
+	}
+
+	// The path of a local state is relative to where Terraform runs
+	if p, ok := conf["path"]; ok && backend == "local" {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		conf["path"] = abs
+	}
+
+	return &stateRef{Type: backend, Config: conf}, nil
+}
+
+// matches returns true if the two refer to the same state: they have the
+// same type, and the settings they both have, apart from credentials and
+// the settings that all types accept, are the same.
+func (r *stateRef) matches(other *stateRef) bool {
+	if r.Type != other.Type {
+		return false
+	}
+
+	shared := 0
+	for k, v := range r.Config {
+		if stateRefIgnored(r.Type, k) {
+			continue
+		}
+
+		ov, ok := other.Config[k]
+		if !ok {
+			continue
+		}
+		if ov != v {
+			return false
+		}
+
+		shared++
+	}
+
+	return shared > 0
+}
+
+func (r *stateRef) String() string {
+	var settings []string
+	for k, v := range r.Config {
+		if stateRefIgnored(r.Type, k) {
+			continue
+		}
+
+		settings = append(settings, fmt.Sprintf(
+			"%s=%s", k, remote.RedactValue(r.Type, k, v)))
+	}
+	sort.Strings(settings)
+
+	return fmt.Sprintf("%s (%s)", r.Type, strings.Join(settings, ", "))
+}
+
+// stateRefIgnored returns true if the setting doesn't tell which state
+// is used.
+func stateRefIgnored(t, k string) bool {
+	return remote.IsSensitive(t, k) ||
+		k == "compress" ||
+		k == "skip_cert_verification" ||
+		strings.HasPrefix(k, "retry_")
+}
+
+func (c *StateGraphCommand) Help() string {
+	helpText := `
+Usage: terraform state graph [options] DIR...
+
+  Shows which of the given configurations read the outputs of which others.
+
+  The states read by the terraform_remote_state data sources of each
+  configuration are matched against the states of the other configurations,
+  which are their remote states if they have one configured, or else the
+  terraform.tfstate file in their directory. The configurations are listed
+  in an order they can be applied in, each after the states it reads, with
+  the states it reads under it. States read from outside the given
+  directories are listed as well.
+
+  The backend and config arguments of the data sources must be set without
+  interpolations for the states to be found. Modules must have been
+  downloaded with "terraform get".
+
+Options:
+
+  -dot                Output the graph in DOT format instead, which can be
+                      read by GraphViz.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateGraphCommand) Synopsis() string {
+	return "Show which states read the outputs of others"
+}
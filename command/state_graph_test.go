@@ -0,0 +1,135 @@
+package command
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/state"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+func TestStateGraph(t *testing.T) {
+	defer testChdir(t, testFixturePath("state-graph"))()
+
+	ui := new(cli.MockUi)
+	c := &StateGraphCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{"app", "database", "network"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := strings.TrimSpace(ui.OutputWriter.String())
+	expected := strings.TrimSpace(testStateGraphStr)
+	if actual != expected {
+		t.Fatalf("bad:\n\n%s\n\nexpected:\n\n%s", actual, expected)
+	}
+}
+
+func TestStateGraph_dot(t *testing.T) {
+	defer testChdir(t, testFixturePath("state-graph"))()
+
+	ui := new(cli.MockUi)
+	c := &StateGraphCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{"-dot", "app", "database", "network"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, `"[root] app" -> "[root] network"`) {
+		t.Fatalf("bad:\n\n%s", output)
+	}
+	if strings.Contains(output, "hunter2") {
+		t.Fatalf("secret in output:\n\n%s", output)
+	}
+}
+
+func TestStateGraph_cycle(t *testing.T) {
+	defer testChdir(t, testFixturePath("state-graph-cycle"))()
+
+	ui := new(cli.MockUi)
+	c := &StateGraphCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{"a", "b"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "a, b") {
+		t.Fatalf("bad:\n\n%s", ui.ErrorWriter.String())
+	}
+}
+
+func TestStateGraph_remoteState(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	// The network configuration stores its state in S3
+	s := terraform.NewState()
+	s.Remote = &terraform.RemoteState{
+		Type: "s3",
+		Config: map[string]string{
+			"bucket": "shared",
+			"key":    "dns.tfstate",
+			"region": "us-east-1",
+		},
+	}
+	ls := &state.LocalState{
+		Path: filepath.Join(tmp, "network", DefaultDataDir, DefaultStateFilename),
+	}
+	if err := ls.WriteState(s); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ls.PersistState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ref, err := dirStateRef(filepath.Join(tmp, "network"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The region isn't set by the reader, and the credentials are ignored
+	other := &stateRef{
+		Type: "s3",
+		Config: map[string]string{
+			"bucket":     "shared",
+			"key":        "dns.tfstate",
+			"secret_key": "hunter2",
+		},
+	}
+	if !ref.matches(other) {
+		t.Fatalf("%s should match %s", ref, other)
+	}
+
+	other.Config["key"] = "other.tfstate"
+	if ref.matches(other) {
+		t.Fatalf("%s shouldn't match %s", ref, other)
+	}
+}
+
+const testStateGraphStr = `
+network
+s3 (bucket=shared, key=dns.tfstate)
+database
+  reads network
+app
+  reads database
+  reads network
+  reads s3 (bucket=shared, key=dns.tfstate) (not in the given directories)
+`
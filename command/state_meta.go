@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform/state"
+	"github.com/hashicorp/terraform/state/remote"
 	"github.com/hashicorp/terraform/terraform"
 )
 
@@ -15,8 +16,12 @@ type StateMeta struct{}
 // State returns the state for this meta. This is different then Meta.State
 // in the way that backups are done. This configures backups to be timestamped
 // rather than just the original state path plus a backup path.
+//
+// If m has a backup path, such as from the -backup flag, the backup is
+// written there instead.
 func (c *StateMeta) State(m *Meta) (state.State, error) {
 	// Disable backups since we wrap it manually below
+	backupPath := m.backupPath
 	m.backupPath = "-"
 
 	// Get the state (shouldn't be wrapped in a backup)
@@ -26,12 +31,15 @@ func (c *StateMeta) State(m *Meta) (state.State, error) {
 	}
 
 	// Determine the backup path. stateOutPath is set to the resulting
-	// file where state is written (cached in the case of remote state)
-	backupPath := fmt.Sprintf(
-		"%s.%d%s",
-		m.stateOutPath,
-		time.Now().UTC().Unix(),
-		DefaultBackupExtension)
+	// file where state is written (cached in the case of remote state).
+	// Backups are required, so "-" doesn't disable them here.
+	if backupPath == "" || backupPath == "-" {
+		backupPath = fmt.Sprintf(
+			"%s.%d%s",
+			m.stateOutPath,
+			time.Now().UTC().Unix(),
+			DefaultBackupExtension)
+	}
 
 	// Wrap it for backups
 	s = &state.BackupState{
@@ -60,8 +68,40 @@ func (c *StateMeta) filterInstance(rs []*terraform.StateFilterResult) (*terrafor
 	return result, nil
 }
 
+// versionedClient returns the client of the remote state in result, if
+// the remote storage keeps versions of the state.
+func (c *StateMeta) versionedClient(result *StateResult) (remote.VersionedClient, error) {
+	if result == nil || result.Remote == nil {
+		return nil, errors.New(errStateNotRemote)
+	}
+
+	durable, ok := result.Remote.Durable.(*remote.State)
+	if !ok {
+		return nil, errors.New(errStateNotRemote)
+	}
+
+	vc, ok := remote.Versioned(durable.Client)
+	if !ok {
+		return nil, errors.New(errStateNotVersioned)
+	}
+
+	return vc, nil
+}
+
 const errStateMultiple = `Multiple instances found for the given pattern!
 
 This command requires that the pattern match exactly one instance
 of a resource. To view the matched instances, use "terraform state list".
 Please modify the pattern to match only a single instance.`
+
+const errStateNotRemote = `Remote state isn't configured!
+
+Versions of the state are only kept by remote state storage. Configure
+remote state with "terraform remote config", or use -backend-override
+to point this command at a remote state.`
+
+const errStateNotVersioned = `The remote state storage doesn't keep versions of the state.
+
+Versions are available with the "s3" remote state in buckets that have
+versioning enabled, and the "gcs" remote state in buckets that have
+object versioning enabled.`
@@ -0,0 +1,174 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+	"github.com/ryanuber/columnize"
+)
+
+// StateMetaCommand is a Command implementation that shows the metadata of
+// a state, such as its lineage and serial, without its resources.
+type StateMetaCommand struct {
+	Meta
+}
+
+// stateMetadata is the output of the state meta command.
+type stateMetadata struct {
+	Lineage          string                 `json:"lineage"`
+	Serial           int64                  `json:"serial"`
+	Version          int                    `json:"version"`
+	TerraformVersion string                 `json:"terraform_version"`
+	Remote           *stateMetadataRemote   `json:"remote"`
+	Modules          []*stateMetadataModule `json:"modules"`
+}
+
+type stateMetadataRemote struct {
+	Type string `json:"type"`
+	Hash string `json:"hash"`
+}
+
+type stateMetadataModule struct {
+	Module    string `json:"module"`
+	Resources int    `json:"resources"`
+}
+
+func (c *StateMetaCommand) Run(args []string) int {
+	var jsonOutput bool
+	args = c.Meta.process(args, true)
+
+	cmdFlags := c.Meta.flagSet("state meta")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The state meta command expects no arguments.")
+		return cli.RunResultHelp
+	}
+
+	state, err := c.StateReader()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return cli.RunResultHelp
+	}
+
+	stateReal := state.State()
+	if stateReal == nil {
+		c.Ui.Error(fmt.Sprintf(errStateNotFound))
+		return 1
+	}
+
+	meta, err := newStateMetadata(stateReal)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading state metadata: %s", err))
+		return 1
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(meta, "", "    ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to encode state metadata: %s", err))
+			return 1
+		}
+
+		c.Ui.Output(string(data))
+		return 0
+	}
+
+	remote := "none"
+	if meta.Remote != nil {
+		remote = fmt.Sprintf("%s (config hash %s)", meta.Remote.Type, meta.Remote.Hash)
+	}
+
+	output := []string{
+		fmt.Sprintf("lineage | %s", meta.Lineage),
+		fmt.Sprintf("serial | %d", meta.Serial),
+		fmt.Sprintf("version | %d", meta.Version),
+		fmt.Sprintf("terraform_version | %s", meta.TerraformVersion),
+		fmt.Sprintf("remote | %s", remote),
+	}
+	for _, m := range meta.Modules {
+		output = append(output, fmt.Sprintf(
+			"resources.%s | %d", m.Module, m.Resources))
+	}
+
+	config := columnize.DefaultConfig()
+	config.Glue = " = "
+	c.Ui.Output(columnize.Format(output, config))
+	return 0
+}
+
+// newStateMetadata returns the metadata of the given state. The remote
+// configuration is only included as a hash, since it may have secrets.
+func newStateMetadata(s *terraform.State) (*stateMetadata, error) {
+	result := &stateMetadata{
+		Lineage:          s.Lineage,
+		Serial:           s.Serial,
+		Version:          s.Version,
+		TerraformVersion: s.TFVersion,
+	}
+
+	if s.Remote != nil && !s.Remote.Empty() {
+		// JSON encoding sorts the keys, so the same configuration
+		// always has the same hash.
+		data, err := json.Marshal(s.Remote.Config)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+
+		result.Remote = &stateMetadataRemote{
+			Type: s.Remote.Type,
+			Hash: hex.EncodeToString(sum[:]),
+		}
+	}
+
+	for _, m := range s.Modules {
+		addr := "root"
+		if len(m.Path) > 1 {
+			addr = "module." + strings.Join(m.Path[1:], ".module.")
+		}
+
+		result.Modules = append(result.Modules, &stateMetadataModule{
+			Module:    addr,
+			Resources: len(m.Resources),
+		})
+	}
+
+	return result, nil
+}
+
+func (c *StateMetaCommand) Help() string {
+	helpText := `
+Usage: terraform state meta [options]
+
+  Shows the metadata of the Terraform state.
+
+  This command shows the lineage, serial and format version of the state,
+  the version of Terraform that wrote it, the remote state type and the
+  number of resources in each module. The resources themselves and the
+  remote state configuration aren't shown, only a hash of the configuration
+  that can be compared between machines.
+
+Options:
+
+  -json               Output the metadata as JSON.
+
+  -state=statefile    Path to a Terraform state file to use to look
+                      up Terraform-managed resources. By default it will
+                      use the state "terraform.tfstate" if it exists.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateMetaCommand) Synopsis() string {
+	return "Show the metadata of the state"
+}
@@ -0,0 +1,163 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+func TestStateMeta(t *testing.T) {
+	state := testState()
+	state.Lineage = "foo"
+	state.Serial = 3
+	state.AddModuleState(&terraform.ModuleState{
+		Path: []string{"root", "child"},
+		Resources: map[string]*terraform.ResourceState{
+			"test_instance.foo": &terraform.ResourceState{
+				Type:    "test_instance",
+				Primary: &terraform.InstanceState{ID: "foo"},
+			},
+			"test_instance.bar": &terraform.ResourceState{
+				Type:    "test_instance",
+				Primary: &terraform.InstanceState{ID: "bar"},
+			},
+		},
+	})
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateMetaCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// Ignore the alignment of the columns
+	actual := strings.Join(strings.Fields(ui.OutputWriter.String()), " ")
+	for _, expected := range []string{
+		"lineage = foo",
+		"serial = 3",
+		"remote = none",
+		"resources.root = 1",
+		"resources.module.child = 2",
+	} {
+		if !strings.Contains(actual, expected) {
+			t.Fatalf("expected %q in:\n\n%s", expected, actual)
+		}
+	}
+	if strings.Contains(actual, "test_instance") {
+		t.Fatalf("resources shouldn't be shown:\n\n%s", actual)
+	}
+}
+
+func TestStateMeta_json(t *testing.T) {
+	state := testState()
+	state.Remote = &terraform.RemoteState{
+		Type:   "http",
+		Config: map[string]string{"address": "http://example.com/state"},
+	}
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateMetaCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-json",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	var actual stateMetadata
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &actual); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual.Remote == nil || actual.Remote.Type != "http" || actual.Remote.Hash == "" {
+		t.Fatalf("bad: %#v", actual.Remote)
+	}
+	if len(actual.Modules) != 1 || actual.Modules[0].Resources != 1 {
+		t.Fatalf("bad: %#v", actual.Modules)
+	}
+	if strings.Contains(ui.OutputWriter.String(), "example.com") {
+		t.Fatalf("remote config shouldn't be shown:\n\n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestStateMeta_noState(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateMetaCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+}
+
+func TestStateMeta_backendOverride(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	replica := testState()
+	replica.Lineage = "replica"
+	conf, srv := testRemoteState(t, replica, 200)
+	defer srv.Close()
+
+	override := filepath.Join(tmp, "override.tf")
+	err := ioutil.WriteFile(override, []byte(fmt.Sprintf(
+		"backend = %q\nconfig {\n  address = %q\n}\n",
+		conf.Type, conf.Config["address"])), 0644)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateMetaCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-backend-override=" + override,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := strings.Join(strings.Fields(ui.OutputWriter.String()), " ")
+	if !strings.Contains(actual, "lineage = replica") {
+		t.Fatalf("bad:\n\n%s", actual)
+	}
+}
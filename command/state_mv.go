@@ -15,14 +15,16 @@ type StateMvCommand struct {
 }
 
 func (c *StateMvCommand) Run(args []string) int {
+	var autoApprove bool
 	args = c.Meta.process(args, true)
 
 	// We create two metas to track the two states
 	var meta1, meta2 Meta
 	cmdFlags := c.Meta.flagSet("state mv")
-	cmdFlags.StringVar(&meta1.stateOutPath, "backup", "", "backup")
-	cmdFlags.StringVar(&meta1.statePath, "state", DefaultStateFilename, "path")
-	cmdFlags.StringVar(&meta2.stateOutPath, "backup-out", "", "backup")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
+	cmdFlags.StringVar(&meta1.backupPath, "backup", "", "backup")
+	cmdFlags.StringVar(&meta1.statePath, "state", c.Meta.defaultStatePath(), "path")
+	cmdFlags.StringVar(&meta2.backupPath, "backup-out", "", "backup")
 	cmdFlags.StringVar(&meta2.statePath, "state-out", "", "path")
 	if err := cmdFlags.Parse(args); err != nil {
 		return cli.RunResultHelp
@@ -93,7 +95,12 @@ func (c *StateMvCommand) Run(args []string) int {
 		return 1
 	}
 
+	if !c.confirmRemoteState(autoApprove) {
+		return 1
+	}
+
 	// Write the new state
+	stateToReal.TFVersion = terraform.Version
 	if err := stateTo.WriteState(stateToReal); err != nil {
 		c.Ui.Error(fmt.Sprintf(errStateMvPersist, err))
 		return 1
@@ -106,6 +113,7 @@ func (c *StateMvCommand) Run(args []string) int {
 
 	// Write the old state if it is different
 	if stateTo != stateFrom {
+		stateFromReal.TFVersion = terraform.Version
 		if err := stateFrom.WriteState(stateFromReal); err != nil {
 			c.Ui.Error(fmt.Sprintf(errStateMvPersist, err))
 			return 1
@@ -190,6 +198,9 @@ Usage: terraform state mv [options] ADDRESS ADDRESS
 
 Options:
 
+  -auto-approve       Don't ask for the name of a remote state configured
+                      with confirm_required before changing it.
+
   -backup=PATH        Path where Terraform should write the backup for the original
                       state. This can't be disabled. If not set, Terraform
                       will write it to the same path as the statefile with
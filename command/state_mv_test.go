@@ -1,7 +1,9 @@
 package command
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -71,6 +73,50 @@ func TestStateMv(t *testing.T) {
 	testStateOutput(t, backups[0], testStateMvOutputOriginal)
 }
 
+func TestStateMv_backup(t *testing.T) {
+	td := testTempDir(t)
+	statePath := testStateFile(t, testState())
+	stateOutPath := testStateFile(t, terraform.NewState())
+	backupPath := filepath.Join(td, "state.backup")
+	backupOutPath := filepath.Join(td, "out.backup")
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateMvCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-state-out", stateOutPath,
+		"-backup", backupPath,
+		"-backup-out", backupOutPath,
+		"test_instance.foo",
+		"test_instance.bar",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The backups are written to the given paths
+	backup := testReadState(t, backupPath)
+	if _, ok := backup.RootModule().Resources["test_instance.foo"]; !ok {
+		t.Fatalf("bad: %s", backup)
+	}
+	if _, err := os.Stat(backupOutPath); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The moved resource is in the output state
+	actual := testReadState(t, stateOutPath)
+	if _, ok := actual.RootModule().Resources["test_instance.bar"]; !ok {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
 func TestStateMv_stateOutNew(t *testing.T) {
 	state := &terraform.State{
 		Modules: []*terraform.ModuleState{
@@ -514,3 +560,31 @@ const testStateMvExisting_stateDstOriginal = `
 test_instance.qux:
   ID = bar
 `
+
+func TestStateMv_confirmRequired(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	current := testState()
+	client, cleanup := testConfirmRequiredState(t, current)
+	defer cleanup()
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateMvCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// Answering anything but the name of the remote state doesn't change it
+	args := []string{"test_instance.foo", "test_instance.bar"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "Cancelled") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+	testConfirmRequiredUnchanged(t, client, current)
+}
@@ -0,0 +1,291 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+// StatePruneCommand is a Command implementation that removes the entries
+// of the state that no longer mean anything, such as empty modules.
+type StatePruneCommand struct {
+	Meta
+	StateMeta
+}
+
+func (c *StatePruneCommand) Run(args []string) int {
+	var autoApprove, dryRun bool
+	args = c.Meta.process(args, true)
+
+	cmdFlags := c.Meta.flagSet("state prune")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
+	cmdFlags.BoolVar(&dryRun, "dry-run", false, "dry run")
+	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "backup")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The state prune command expects no arguments.")
+		return cli.RunResultHelp
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error getting pwd: %s", err))
+		return 1
+	}
+
+	// The configuration tells which modules are still used. Without one,
+	// modules that still have outputs are kept.
+	mod, err := module.NewTreeModule("", pwd)
+	if errwrap.ContainsType(err, new(config.ErrNoConfigsFound)) {
+		err = nil
+		mod = nil
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading config: %s", err))
+		return 1
+	}
+	if mod != nil {
+		if err := mod.Load(c.moduleStorage(c.DataDir()), module.GetModeNone); err != nil {
+			c.Ui.Error(fmt.Sprintf(
+				"Error loading modules: %s\n\n"+
+					"Modules must be downloaded with \"terraform get\" to find\n"+
+					"which modules are no longer used.", err))
+			return 1
+		}
+	}
+
+	if dryRun {
+		state, err := c.StateReader()
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+			return cli.RunResultHelp
+		}
+
+		stateReal := state.State()
+		if stateReal == nil {
+			c.Ui.Error(fmt.Sprintf(errStateNotFound))
+			return 1
+		}
+
+		pruned := pruneState(stateReal, mod)
+		for _, p := range pruned {
+			c.Ui.Output(p)
+		}
+		c.Ui.Output(fmt.Sprintf(
+			"\n%d entries would be pruned. The state was not modified.", len(pruned)))
+		return 0
+	}
+
+	state, err := c.StateMeta.State(&c.Meta)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return cli.RunResultHelp
+	}
+
+	stateReal := state.State()
+	if stateReal == nil {
+		c.Ui.Error(fmt.Sprintf(errStateNotFound))
+		return 1
+	}
+
+	pruned := pruneState(stateReal, mod)
+	if len(pruned) == 0 {
+		c.Ui.Output("Nothing to prune.")
+		return 0
+	}
+
+	for _, p := range pruned {
+		c.Ui.Output(p)
+	}
+	if !c.confirmRemoteState(autoApprove) {
+		return 1
+	}
+
+	stateReal.TFVersion = terraform.Version
+	if err := state.WriteState(stateReal); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStatePrunePersist, err))
+		return 1
+	}
+	if err := state.PersistState(); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStatePrunePersist, err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("\n%d entries pruned.", len(pruned)))
+	return 0
+}
+
+// pruneState removes the entries of the state that no longer mean anything
+// and returns a description of each entry removed, sorted.
+//
+// These are the modules other than the root module with no resources and
+// no child modules, unless they have outputs and are still in the
+// configuration, and the dependencies of resources on resources and
+// modules that aren't in the state. If there's no configuration, modules
+// with outputs are kept. Deposed instances with no ID are already removed
+// when a state is read.
+func pruneState(s *terraform.State, tree *module.Tree) []string {
+	var result []string
+
+	// Modules are removed first, so that dependencies on them are found
+	// to be stale as well.
+	var kept []*terraform.ModuleState
+	for _, m := range s.Modules {
+		if pruneModule(s, m, tree) {
+			result = append(result, fmt.Sprintf(
+				"%s: empty module", stateModuleAddr(m.Path)))
+			continue
+		}
+
+		kept = append(kept, m)
+	}
+	s.Modules = kept
+
+	for _, m := range s.Modules {
+		for k, r := range m.Resources {
+			var deps []string
+			for _, d := range r.Dependencies {
+				if stateDependencyExists(s, m, d) {
+					deps = append(deps, d)
+					continue
+				}
+
+				result = append(result, fmt.Sprintf(
+					"%s%s: stale dependency on %s",
+					stateModulePrefix(m.Path), k, d))
+			}
+			r.Dependencies = deps
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// pruneModule returns true if the module can be removed from the state.
+func pruneModule(s *terraform.State, m *terraform.ModuleState, tree *module.Tree) bool {
+	if len(m.Path) <= 1 || len(m.Resources) > 0 {
+		return false
+	}
+
+	// A parent of another module in the state is kept for it
+	for _, other := range s.Modules {
+		if len(other.Path) > len(m.Path) &&
+			strings.Join(other.Path[:len(m.Path)], ".") == strings.Join(m.Path, ".") {
+			return false
+		}
+	}
+
+	if len(m.Outputs) == 0 {
+		return true
+	}
+
+	if tree == nil {
+		return false
+	}
+
+	// The outputs of a module that's no longer used won't be updated
+	// again, so they're kept only for modules in the configuration.
+	for _, name := range m.Path[1:] {
+		child, ok := tree.Children()[name]
+		if !ok {
+			return true
+		}
+		tree = child
+	}
+
+	return false
+}
+
+// stateDependencyExists returns true if the dependency of a resource in
+// the given module refers to something in the state. Dependencies are
+// resource names, which may end in ".*" for all the instances of a
+// resource with count, or "module.NAME" for child modules.
+func stateDependencyExists(s *terraform.State, m *terraform.ModuleState, dep string) bool {
+	if strings.HasPrefix(dep, "module.") {
+		path := make([]string, len(m.Path), len(m.Path)+1)
+		copy(path, m.Path)
+		path = append(path, strings.TrimPrefix(dep, "module."))
+
+		return s.ModuleByPath(path) != nil
+	}
+
+	dep = strings.TrimSuffix(dep, ".*")
+	for k := range m.Resources {
+		if k == dep || strings.HasPrefix(k, dep+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stateModuleAddr returns the address of the module with the given path.
+func stateModuleAddr(path []string) string {
+	return strings.TrimSuffix(stateModulePrefix(path), ".")
+}
+
+// stateModulePrefix returns the prefix of the addresses of the resources
+// in the module with the given path, which is empty for the root module.
+func stateModulePrefix(path []string) string {
+	if len(path) <= 1 {
+		return ""
+	}
+
+	return "module." + strings.Join(path[1:], ".module.") + "."
+}
+
+func (c *StatePruneCommand) Help() string {
+	helpText := `
+Usage: terraform state prune [options]
+
+  Remove the entries of the Terraform state that no longer mean anything.
+
+  This command removes modules with no resources from the state, unless
+  they have outputs and are still in the configuration in the current
+  directory, and removes the dependencies of resources on resources and
+  modules that aren't in the state. No resources are removed. Each entry
+  removed is listed.
+
+  The lineage of the state is kept. This command creates a timestamped
+  backup of the state on every invocation.
+
+Options:
+
+  -auto-approve       Don't ask for the name of a remote state configured
+                      with confirm_required before changing it.
+
+  -backup=PATH        Path where Terraform should write the backup
+                      state. This can't be disabled. If not set, Terraform
+                      will write it to the same path as the statefile with
+                      a backup extension.
+
+  -dry-run            List the entries that would be removed without
+                      modifying the state.
+
+  -state=statefile    Path to a Terraform state file to use to look
+                      up Terraform-managed resources. By default it will
+                      use the state "terraform.tfstate" if it exists.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StatePruneCommand) Synopsis() string {
+	return "Remove entries from the state that are no longer used"
+}
+
+const errStatePrunePersist = `Error saving the state: %s
+
+The state was not saved. No entries were removed from the persisted
+state. Please resolve the issue above and try again.`
@@ -0,0 +1,185 @@
+package command
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+func TestStatePrune(t *testing.T) {
+	statePath := testStateFile(t, testStatePruneState())
+
+	ui := new(cli.MockUi)
+	c := &StatePruneCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{"-state", statePath}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	for _, expected := range []string{
+		"module.empty: empty module",
+		"test_instance.foo: stale dependency on module.empty",
+		"test_instance.foo: stale dependency on test_instance.gone",
+		"3 entries pruned.",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("expected %q in output:\n\n%s", expected, output)
+		}
+	}
+
+	actual := testReadState(t, statePath)
+	if actual.ModuleByPath([]string{"root", "empty"}) != nil {
+		t.Fatal("empty module should be removed")
+	}
+	if actual.ModuleByPath([]string{"root", "outputs"}) == nil {
+		t.Fatal("module with outputs should be kept without a configuration")
+	}
+
+	deps := actual.RootModule().Resources["test_instance.foo"].Dependencies
+	if !reflect.DeepEqual(deps, []string{"test_instance.bar.*"}) {
+		t.Fatalf("bad: %#v", deps)
+	}
+
+	if actual.Lineage != "prune" {
+		t.Fatalf("lineage should be kept: %s", actual.Lineage)
+	}
+
+	// Test we have backups
+	backups := testStateBackups(t, filepath.Dir(statePath))
+	if len(backups) != 1 {
+		t.Fatalf("bad: %#v", backups)
+	}
+}
+
+func TestStatePrune_dryRun(t *testing.T) {
+	statePath := testStateFile(t, testStatePruneState())
+
+	ui := new(cli.MockUi)
+	c := &StatePruneCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{"-dry-run", "-state", statePath}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "3 entries would be pruned") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+
+	actual := testReadState(t, statePath)
+	if actual.ModuleByPath([]string{"root", "empty"}) == nil {
+		t.Fatal("state should not be modified")
+	}
+
+	backups := testStateBackups(t, filepath.Dir(statePath))
+	if len(backups) != 0 {
+		t.Fatalf("bad: %#v", backups)
+	}
+}
+
+func TestPruneState_config(t *testing.T) {
+	s := testStatePruneState()
+	s.AddModule([]string{"root", "used"}).Outputs["foo"] = &terraform.OutputState{
+		Type:  "string",
+		Value: "bar",
+	}
+
+	actual := pruneState(s, testModule(t, "state-prune"))
+	expected := []string{
+		"module.empty: empty module",
+		"module.outputs: empty module",
+		"test_instance.foo: stale dependency on module.empty",
+		"test_instance.foo: stale dependency on test_instance.gone",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if s.ModuleByPath([]string{"root", "used"}) == nil {
+		t.Fatal("module in the configuration should be kept")
+	}
+}
+
+func TestPruneState_parent(t *testing.T) {
+	s := terraform.NewState()
+	s.Lineage = "prune"
+	s.AddModule([]string{"root", "parent"})
+	s.AddModule([]string{"root", "parent", "child"}).Resources["test_instance.foo"] = &terraform.ResourceState{
+		Type:    "test_instance",
+		Primary: &terraform.InstanceState{ID: "foo"},
+	}
+
+	if actual := pruneState(s, nil); len(actual) != 0 {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func testStatePruneState() *terraform.State {
+	s := terraform.NewState()
+	s.Lineage = "prune"
+
+	root := s.RootModule()
+	root.Resources["test_instance.foo"] = &terraform.ResourceState{
+		Type: "test_instance",
+		Dependencies: []string{
+			"module.empty",
+			"test_instance.bar.*",
+			"test_instance.gone",
+		},
+		Primary: &terraform.InstanceState{ID: "foo"},
+	}
+	root.Resources["test_instance.bar.0"] = &terraform.ResourceState{
+		Type:    "test_instance",
+		Primary: &terraform.InstanceState{ID: "bar"},
+	}
+
+	s.AddModule([]string{"root", "empty"})
+	s.AddModule([]string{"root", "outputs"}).Outputs["foo"] = &terraform.OutputState{
+		Type:  "string",
+		Value: "bar",
+	}
+
+	return s
+}
+
+func TestStatePrune_confirmRequired(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	current := testState()
+	current.AddModule([]string{"root", "child"})
+	client, cleanup := testConfirmRequiredState(t, current)
+	defer cleanup()
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StatePruneCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// Answering anything but the name of the remote state doesn't change it
+	args := []string{}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "Cancelled") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+	testConfirmRequiredUnchanged(t, client, current)
+}
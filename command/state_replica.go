@@ -0,0 +1,205 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+// StateReplicaCommand is a Command implementation that configures the
+// replica that the state of the working directory is copied to each time
+// it's saved, and shows the status of the replication.
+type StateReplicaCommand struct {
+	Meta
+}
+
+func (c *StateReplicaCommand) Run(args []string) int {
+	var configPath string
+	var disable bool
+
+	args = c.Meta.process(args, false)
+
+	cmdFlags := c.Meta.flagSet("state replica")
+	cmdFlags.StringVar(&configPath, "config", "", "path")
+	cmdFlags.BoolVar(&disable, "disable", false, "disable")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The state replica command expects no arguments.")
+		return cli.RunResultHelp
+	}
+
+	switch {
+	case configPath != "" && disable:
+		c.Ui.Error("Only one of -config and -disable can be set.")
+		return cli.RunResultHelp
+	case configPath != "":
+		return c.enable(configPath)
+	case disable:
+		return c.disable()
+	default:
+		return c.status()
+	}
+}
+
+// enable configures the replica and replicates the current state to it.
+func (c *StateReplicaCommand) enable(path string) int {
+	client, conf, err := replicaClient(path)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading %s: %s", path, err))
+		return 1
+	}
+	if err := os.MkdirAll(c.DataDir(), 0755); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error creating %s: %s", c.DataDir(), err))
+		return 1
+	}
+	if err := ioutil.WriteFile(c.replicaConfigPath(), data, 0644); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error saving the replica configuration: %s", err))
+		return 1
+	}
+	c.Ui.Output(fmt.Sprintf(
+		"The state will be replicated to the %q backend each time it's saved.", conf.Type))
+
+	// The state saved so far is replicated right away, so that the replica
+	// is up to date before the next change.
+	st, err := c.State()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading the state: %s", err))
+		return 1
+	}
+	current := st.State()
+	if current == nil {
+		return 0
+	}
+
+	var buf bytes.Buffer
+	if err := terraform.WriteState(current, &buf); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing the state: %s", err))
+		return 1
+	}
+
+	status := &replicaStatus{
+		Backend:     conf.Type,
+		LastAttempt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := client.Put(buf.Bytes()); err != nil {
+		status.Error = err.Error()
+		writeReplicaStatus(c.replicaStatusPath(), status)
+		c.Ui.Error(fmt.Sprintf(
+			"Error replicating the state to the %q backend: %s", conf.Type, err))
+		return 1
+	}
+	status.LastSuccess = status.LastAttempt
+	status.Lineage = current.Lineage
+	status.Serial = current.Serial
+	if err := writeReplicaStatus(c.replicaStatusPath(), status); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing the replica status: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Replicated the state with serial %d.", current.Serial))
+	return 0
+}
+
+// disable removes the replica configuration and status.
+func (c *StateReplicaCommand) disable() int {
+	for _, path := range []string{c.replicaConfigPath(), c.replicaStatusPath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			c.Ui.Error(fmt.Sprintf("Error removing %s: %s", path, err))
+			return 1
+		}
+	}
+
+	c.Ui.Output("The state is no longer replicated.")
+	return 0
+}
+
+// status shows the outcome of the last replication.
+func (c *StateReplicaCommand) status() int {
+	if _, err := os.Stat(c.replicaConfigPath()); err != nil {
+		c.Ui.Output("The state isn't replicated. Configure a replica with -config.")
+		return 0
+	}
+	_, conf, err := replicaClient(c.replicaConfigPath())
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	status, err := readReplicaStatus(c.replicaStatusPath())
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Replica:      %s", conf.Type))
+	if status == nil {
+		c.Ui.Output("Last attempt: never")
+		return 0
+	}
+	c.Ui.Output(fmt.Sprintf("Last attempt: %s", status.LastAttempt))
+	if status.LastSuccess != "" {
+		c.Ui.Output(fmt.Sprintf(
+			"Last success: %s (serial %d)", status.LastSuccess, status.Serial))
+	} else {
+		c.Ui.Output("Last success: never")
+	}
+	if status.Error != "" {
+		c.Ui.Error(fmt.Sprintf("Last error:   %s", status.Error))
+		return 1
+	}
+
+	return 0
+}
+
+func (c *StateReplicaCommand) Help() string {
+	helpText := `
+Usage: terraform state replica [options]
+
+  Configure a replica of the state, or show the status of the replication.
+
+  With a replica configured, each time Terraform saves the state of the
+  working directory, it also copies it to the replica, in the background.
+  A failure to copy the state doesn't fail the command that saved it; it's
+  logged and recorded, and shown by this command without options. The
+  next change to the state is copied again.
+
+  The replica is given by a remote state configuration file, in the same
+  format as for -backend-override:
+
+      backend = "gcs"
+      config {
+        bucket = "terraform-state-dr"
+        path   = "network/terraform.tfstate"
+      }
+
+  The configuration is saved in the .terraform directory.
+
+Options:
+
+  -config=path        Replicate the state to the remote storage configured
+                      in the given file. The current state is replicated
+                      right away.
+
+  -disable            Stop replicating the state.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateReplicaCommand) Synopsis() string {
+	return "Replicate the state to another remote storage"
+}
@@ -0,0 +1,108 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestStateReplica(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	state := testState()
+	testStateFileDefault(t, state)
+	replicaPath := filepath.Join(tmp, "replica.tfstate")
+	configPath := testReplicaConfig(t, "replica.hcl", replicaPath)
+
+	ui := new(cli.MockUi)
+	c := &StateReplicaCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run([]string{"-config", configPath}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The current state is replicated right away
+	actual := testReadState(t, replicaPath)
+	if !actual.Equal(state) {
+		t.Fatalf("bad: %s", actual)
+	}
+	if _, err := os.Stat(filepath.Join(DefaultDataDir, ReplicaConfigFilename)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The status shows the replication
+	ui = new(cli.MockUi)
+	c = &StateReplicaCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "Replica:      local") || strings.Contains(output, "Last success: never") {
+		t.Fatalf("bad:\n\n%s", output)
+	}
+
+	// Disabling removes the configuration
+	ui = new(cli.MockUi)
+	c = &StateReplicaCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run([]string{"-disable"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	for _, name := range []string{ReplicaConfigFilename, ReplicaStatusFilename} {
+		if _, err := os.Stat(filepath.Join(DefaultDataDir, name)); !os.IsNotExist(err) {
+			t.Fatalf("%s should be removed: %s", name, err)
+		}
+	}
+}
+
+func TestStateReplica_statusError(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	if err := os.MkdirAll(DefaultDataDir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	testReplicaConfig(t, filepath.Join(DefaultDataDir, ReplicaConfigFilename), "replica.tfstate")
+	err := writeReplicaStatus(filepath.Join(DefaultDataDir, ReplicaStatusFilename), &replicaStatus{
+		Backend:     "local",
+		LastAttempt: "2017-01-02T03:04:05Z",
+		Error:       "access denied",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &StateReplicaCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "access denied") {
+		t.Fatalf("bad:\n\n%s", ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "Last success: never") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+}
@@ -0,0 +1,254 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/state/remote"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+// StateReplicateCommand is a Command implementation that copies the state
+// from one remote storage to another, without using the remote state
+// configured for the working directory.
+type StateReplicateCommand struct {
+	Meta
+}
+
+func (c *StateReplicateCommand) Run(args []string) int {
+	var fromPath, toPath, backupPath string
+	var force bool
+
+	args = c.Meta.process(args, false)
+
+	cmdFlags := c.Meta.flagSet("state replicate")
+	cmdFlags.StringVar(&fromPath, "from-backend-config", "", "path")
+	cmdFlags.StringVar(&toPath, "to-backend-config", "", "path")
+	cmdFlags.StringVar(&backupPath, "backup", "", "path")
+	cmdFlags.BoolVar(&force, "force", false, "force")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The state replicate command expects no arguments.")
+		return cli.RunResultHelp
+	}
+	if fromPath == "" || toPath == "" {
+		c.Ui.Error("Both -from-backend-config and -to-backend-config must be set.")
+		return cli.RunResultHelp
+	}
+
+	from, fromConf, err := replicaClient(fromPath)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	to, toConf, err := replicaClient(toPath)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	payload, err := from.Get()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateReplicateRead, fromConf.Type, err))
+		return 1
+	}
+	if payload == nil {
+		c.Ui.Error(fmt.Sprintf("There is no state in the %q backend to replicate.", fromConf.Type))
+		return 1
+	}
+	source, err := terraform.ReadState(bytes.NewReader(payload.Data))
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateReplicateRead, fromConf.Type, err))
+		return 1
+	}
+
+	current, err := to.Get()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateReplicateRead, toConf.Type, err))
+		return 1
+	}
+	if current != nil {
+		dest, err := terraform.ReadState(bytes.NewReader(current.Data))
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf(errStateReplicateRead, toConf.Type, err))
+			return 1
+		}
+
+		if dest.Lineage == source.Lineage && dest.Serial == source.Serial && dest.Equal(source) {
+			c.Ui.Output(fmt.Sprintf(
+				"The state in the %q backend is already up to date.", toConf.Type))
+			return 0
+		}
+
+		// Show both states, so that it's clear what is overwritten
+		replaced := newStateSummary(fmt.Sprintf("%s (%s)", toConf.Type, toPath),
+			dest, replicaLastModified(to, toConf))
+		replacing := newStateSummary(fmt.Sprintf("%s (%s)", fromConf.Type, fromPath),
+			source, replicaLastModified(from, fromConf))
+		c.Ui.Output(c.Colorize().Color(formatStateSummaries(replaced, replacing)) + "\n")
+
+		// Replicating over another state, or over a newer copy of the same
+		// state, loses changes, so it must be confirmed or forced.
+		var reason string
+		switch {
+		case force:
+		case dest.Lineage != "" && dest.Lineage != source.Lineage:
+			reason = fmt.Sprintf(strings.TrimSpace(errStateReplicateLineage),
+				toConf.Type, dest.Lineage, source.Lineage)
+		case dest.Serial > source.Serial:
+			reason = fmt.Sprintf(strings.TrimSpace(errStateReplicateSerial),
+				toConf.Type, dest.Serial, source.Serial)
+		}
+		if reason != "" {
+			if !c.input {
+				c.Ui.Error(reason)
+				return 1
+			}
+
+			v, err := c.UIInput().Input(&terraform.InputOpts{
+				Id:          "replicate",
+				Query:       fmt.Sprintf("Do you want to replace the state in the %q backend?", toConf.Type),
+				Description: reason + "\nOnly 'yes' will be accepted to confirm.",
+			})
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf("Error asking for confirmation: %s", err))
+				return 1
+			}
+			if v != "yes" {
+				c.Ui.Output("State replication cancelled.")
+				return 1
+			}
+		}
+
+		// The state replaced is backed up locally
+		if backupPath == "" {
+			backupPath = fmt.Sprintf(
+				"%s.%d.replica%s",
+				DefaultStateFilename,
+				time.Now().UTC().Unix(),
+				DefaultBackupExtension)
+		}
+		if err := ioutil.WriteFile(backupPath, current.Data, 0644); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error writing backup of the replaced state: %s", err))
+			return 1
+		}
+	}
+
+	// The state is copied unchanged, so that the replica has the same
+	// serial and lineage as the original.
+	if err := to.Put(payload.Data); err != nil {
+		c.Ui.Error(fmt.Sprintf(
+			"Error writing the state to the %q backend: %s", toConf.Type, err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf(
+		"Replicated the state with serial %d from the %q backend to the %q backend.",
+		source.Serial, fromConf.Type, toConf.Type))
+	if current != nil {
+		c.Ui.Output(fmt.Sprintf("The replaced state was backed up to %s.", backupPath))
+	}
+	return 0
+}
+
+func (c *StateReplicateCommand) Help() string {
+	helpText := `
+Usage: terraform state replicate [options]
+
+  Copy the state from one remote storage to another.
+
+  The remote storages are given by remote state configuration files,
+  in the same format as for -backend-override:
+
+      backend = "s3"
+      config {
+        bucket = "terraform-state-replica"
+        key    = "network/terraform.tfstate"
+      }
+
+  The state is copied unchanged, with its serial and lineage, so the
+  copy can be used in place of the original. The remote state configured
+  for the working directory isn't used or changed.
+
+  If the destination already has a state, a summary of both states is
+  shown and the state replaced is backed up locally. Replacing a state
+  with another lineage, or with a higher serial than the state copied,
+  has to be confirmed, or forced with -force.
+
+Options:
+
+  -from-backend-config=path  Remote state configuration file of the remote
+                             storage to copy the state from.
+
+  -to-backend-config=path    Remote state configuration file of the remote
+                             storage to copy the state to.
+
+  -backup=path               Path to back up the state replaced in the
+                             destination to. Defaults to a timestamped
+                             file in the working directory.
+
+  -force                     Replace the state in the destination without
+                             confirmation even if it has another lineage or
+                             a higher serial.
+
+  -input=true                Ask for confirmation before replacing a state
+                             with another lineage or a higher serial. With
+                             -input=false, such a state is only replaced
+                             with -force.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateReplicateCommand) Synopsis() string {
+	return "Copy the state between remote storages"
+}
+
+// replicaLastModified returns the time the state in the given remote
+// storage was last written, or the zero time if it can't be told.
+func replicaLastModified(client remote.Client, conf *terraform.RemoteState) time.Time {
+	if strings.ToLower(conf.Type) == "local" {
+		if fi, err := os.Stat(conf.Config["path"]); err == nil {
+			return fi.ModTime()
+		}
+		return time.Time{}
+	}
+
+	versioned, ok := remote.Versioned(client)
+	if !ok {
+		return time.Time{}
+	}
+	versions, err := versioned.ListVersions()
+	if err != nil {
+		return time.Time{}
+	}
+	for _, v := range versions {
+		if v.Latest {
+			return v.LastModified
+		}
+	}
+
+	return time.Time{}
+}
+
+const errStateReplicateRead = `Error reading the state from the %q backend: %s`
+
+const errStateReplicateLineage = `
+The state in the %q backend has the lineage %q, but the state to
+replicate has the lineage %q. It's another state, and replacing it
+loses it, apart from the local backup.
+`
+
+const errStateReplicateSerial = `
+The state in the %q backend has the serial %d, which is higher than
+the serial %d of the state to replicate, so it may have changes that
+the original doesn't. Replacing it loses them, apart from the local
+backup.
+`
@@ -0,0 +1,230 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestStateReplicate(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	source := testState()
+	source.Lineage = "foo"
+	source.Serial = 3
+	fromPath := testStateFile(t, source)
+	toPath := filepath.Join(tmp, "to.tfstate")
+
+	ui := new(cli.MockUi)
+	c := &StateReplicateCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-from-backend-config", testReplicaConfig(t, "from.hcl", fromPath),
+		"-to-backend-config", testReplicaConfig(t, "to.hcl", toPath),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := testReadState(t, toPath)
+	if actual.Lineage != "foo" || actual.Serial != 3 || !actual.Equal(source) {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// Replicating again does nothing
+	ui = new(cli.MockUi)
+	c = &StateReplicateCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "already up to date") {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestStateReplicate_backup(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	source := testState()
+	source.Lineage = "foo"
+	source.Serial = 3
+	fromPath := testStateFile(t, source)
+
+	dest := testState()
+	dest.Lineage = "foo"
+	dest.Serial = 2
+	toPath := testStateFile(t, dest)
+
+	ui := new(cli.MockUi)
+	c := &StateReplicateCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-from-backend-config", testReplicaConfig(t, "from.hcl", fromPath),
+		"-to-backend-config", testReplicaConfig(t, "to.hcl", toPath),
+		"-backup", "replaced.tfstate",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if actual := testReadState(t, toPath); actual.Serial != 3 {
+		t.Fatalf("bad: %s", actual)
+	}
+	if backup := testReadState(t, "replaced.tfstate"); backup.Serial != 2 {
+		t.Fatalf("bad: %s", backup)
+	}
+}
+
+func TestStateReplicate_conflict(t *testing.T) {
+	cases := []struct {
+		Lineage string
+		Serial  int64
+		Error   string
+	}{
+		{"bar", 1, "lineage"},
+		{"foo", 4, "higher"},
+	}
+
+	for _, tc := range cases {
+		tmp, cwd := testCwd(t)
+
+		source := testState()
+		source.Lineage = "foo"
+		source.Serial = 3
+		fromPath := testStateFile(t, source)
+
+		dest := testState()
+		dest.Lineage = tc.Lineage
+		dest.Serial = tc.Serial
+		toPath := testStateFile(t, dest)
+
+		args := []string{
+			"-from-backend-config", testReplicaConfig(t, "from.hcl", fromPath),
+			"-to-backend-config", testReplicaConfig(t, "to.hcl", toPath),
+		}
+
+		ui := new(cli.MockUi)
+		c := &StateReplicateCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(testProvider()),
+				Ui:          ui,
+			},
+		}
+		if code := c.Run(append(args, "-input=false")); code != 1 {
+			t.Fatalf("%s: bad: %d\n\n%s", tc.Error, code, ui.OutputWriter.String())
+		}
+		if !strings.Contains(ui.ErrorWriter.String(), tc.Error) {
+			t.Fatalf("%s: bad:\n\n%s", tc.Error, ui.ErrorWriter.String())
+		}
+
+		// With -force, the state is replaced anyway
+		ui = new(cli.MockUi)
+		c = &StateReplicateCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(testProvider()),
+				Ui:          ui,
+			},
+		}
+		if code := c.Run(append(args, "-force")); code != 0 {
+			t.Fatalf("%s: bad: %d\n\n%s", tc.Error, code, ui.ErrorWriter.String())
+		}
+		if actual := testReadState(t, toPath); actual.Lineage != "foo" || actual.Serial != 3 {
+			t.Fatalf("%s: bad: %s", tc.Error, actual)
+		}
+
+		testFixCwd(t, tmp, cwd)
+	}
+}
+
+func TestStateReplicate_confirm(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	source := testState()
+	source.Lineage = "foo"
+	source.Serial = 3
+	fromPath := testStateFile(t, source)
+
+	dest := testState()
+	dest.Lineage = "foo"
+	dest.Serial = 4
+	toPath := testStateFile(t, dest)
+
+	args := []string{
+		"-from-backend-config", testReplicaConfig(t, "from.hcl", fromPath),
+		"-to-backend-config", testReplicaConfig(t, "to.hcl", toPath),
+	}
+
+	defaultInputReader = bytes.NewBufferString("no\n")
+	defaultInputWriter = new(bytes.Buffer)
+
+	ui := new(cli.MockUi)
+	c := &StateReplicateCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	output := ui.OutputWriter.String()
+	for _, expected := range []string{"Last modified", "higher serial", "cancelled"} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("expected %q in output:\n\n%s", expected, output)
+		}
+	}
+	if actual := testReadState(t, toPath); actual.Serial != 4 {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	defaultInputReader = bytes.NewBufferString("yes\n")
+	defaultInputWriter = new(bytes.Buffer)
+
+	ui = new(cli.MockUi)
+	c = &StateReplicateCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if actual := testReadState(t, toPath); actual.Serial != 3 {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+// testReplicaConfig writes a remote state configuration file for the
+// "local" remote storage with the state at statePath, and returns its path.
+func testReplicaConfig(t *testing.T, path, statePath string) string {
+	config := fmt.Sprintf("backend = \"local\"\nconfig {\n  path = %q\n}\n", statePath)
+	if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return path
+}
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+// StateRestoreCommand is a Command implementation that restores a version
+// of the remote state kept by the remote storage.
+type StateRestoreCommand struct {
+	Meta
+	StateMeta
+}
+
+func (c *StateRestoreCommand) Run(args []string) int {
+	var autoApprove bool
+	args = c.Meta.process(args, true)
+
+	cmdFlags := c.Meta.flagSet("state restore")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("The state restore command expects exactly one argument.")
+		return cli.RunResultHelp
+	}
+	id := args[0]
+
+	state, err := c.StateMeta.State(&c.Meta)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+
+	client, err := c.versionedClient(c.stateResult)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	payload, err := client.GetVersion(id)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateRestore, err))
+		return 1
+	}
+	if payload == nil {
+		c.Ui.Error(fmt.Sprintf(
+			"Version %q of the remote state doesn't exist. Use \"terraform state versions\"\n"+
+				"to list the versions.", id))
+		return 1
+	}
+
+	restored, err := terraform.ReadState(bytes.NewReader(payload.Data))
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateRestore, err))
+		return 1
+	}
+
+	// The restored state is written as a new version. Its serial has to
+	// be higher than the current one, or the state cached locally would
+	// be considered newer and written over it again.
+	if current := state.State(); current != nil {
+		if current.Lineage != "" && restored.Lineage != current.Lineage {
+			c.Ui.Error(fmt.Sprintf(
+				strings.TrimSpace(errStateRestoreLineage),
+				id, restored.Lineage, current.Lineage))
+			return 1
+		}
+
+		restored.Serial = current.Serial + 1
+		restored.Remote = current.Remote
+	}
+
+	if !c.confirmRemoteState(autoApprove) {
+		return 1
+	}
+
+	restored.TFVersion = terraform.Version
+	if err := state.WriteState(restored); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateRestore, err))
+		return 1
+	}
+	if err := state.PersistState(); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateRestore, err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf(
+		"Restored version %s of the state as serial %d.", id, restored.Serial))
+	return 0
+}
+
+func (c *StateRestoreCommand) Help() string {
+	helpText := `
+Usage: terraform state restore [options] VERSION
+
+  Restore a version of the remote state.
+
+  This command replaces the remote state with the given version of it,
+  as listed by "terraform state versions". The restored state is written
+  as a new version, so the current state can be restored again later.
+  A timestamped backup of the current state is written locally as well.
+
+  The version must be of the same state, with the same lineage, as the
+  current state.
+
+Options:
+
+  -auto-approve       Don't ask for the name of a remote state configured
+                      with confirm_required before changing it.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateRestoreCommand) Synopsis() string {
+	return "Restore a version of the remote state"
+}
+
+const errStateRestore = `Error restoring the state version: %s`
+
+const errStateRestoreLineage = `
+Version %s of the state has the lineage %q, but the current state has
+the lineage %q. The version belongs to another state and can't be
+restored.
+`
@@ -0,0 +1,160 @@
+package command
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+func TestStateRestore(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	old := testState()
+	old.Lineage = "foo"
+	old.Serial = 1
+	current := testState()
+	current.Lineage = "foo"
+	current.Serial = 2
+	current.RootModule().Resources["test_instance.bar"] = &terraform.ResourceState{
+		Type:    "test_instance",
+		Primary: &terraform.InstanceState{ID: "baz"},
+	}
+
+	client, cleanup := testVersionedRemoteState(t, old, current)
+	defer cleanup()
+
+	ui := new(cli.MockUi)
+	c := &StateRestoreCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{"1"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The restored state is the latest version, with a higher serial
+	actual, err := terraform.ReadState(bytes.NewReader(client.Data))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual.Serial <= current.Serial {
+		t.Fatalf("bad: %d", actual.Serial)
+	}
+	if _, ok := actual.RootModule().Resources["test_instance.bar"]; ok {
+		t.Fatalf("bad: %s", actual)
+	}
+	if _, ok := actual.RootModule().Resources["test_instance.foo"]; !ok {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	versions, err := client.ListVersions()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("bad: %#v", versions)
+	}
+}
+
+func TestStateRestore_lineage(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	other := testState()
+	other.Lineage = "bar"
+	current := testState()
+	current.Lineage = "foo"
+
+	client, cleanup := testVersionedRemoteState(t, other, current)
+	defer cleanup()
+
+	ui := new(cli.MockUi)
+	c := &StateRestoreCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{"1"}); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "belongs to another state") {
+		t.Fatalf("bad:\n\n%s", ui.ErrorWriter.String())
+	}
+
+	versions, err := client.ListVersions()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("state should not be written: %#v", versions)
+	}
+}
+
+func TestStateRestore_notFound(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	_, cleanup := testVersionedRemoteState(t, testState())
+	defer cleanup()
+
+	ui := new(cli.MockUi)
+	c := &StateRestoreCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run([]string{"42"}); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "doesn't exist") {
+		t.Fatalf("bad:\n\n%s", ui.ErrorWriter.String())
+	}
+}
+
+func TestStateRestore_confirmRequired(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	old := testState()
+	old.Lineage = "foo"
+	old.Serial = 1
+	current := testState()
+	current.Lineage = "foo"
+	current.Serial = 2
+	current.RootModule().Resources["test_instance.bar"] = &terraform.ResourceState{
+		Type:    "test_instance",
+		Primary: &terraform.InstanceState{ID: "baz"},
+	}
+	client, cleanup := testConfirmRequiredState(t, old, current)
+	defer cleanup()
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateRestoreCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// Answering anything but the name of the remote state doesn't change it
+	args := []string{"1"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "Cancelled") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+	testConfirmRequiredUnchanged(t, client, current)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/cli"
 )
 
@@ -14,12 +15,13 @@ type StateRmCommand struct {
 }
 
 func (c *StateRmCommand) Run(args []string) int {
+	var autoApprove bool
 	args = c.Meta.process(args, true)
 
-	var backupPath string
-	cmdFlags := c.Meta.flagSet("state show")
-	cmdFlags.StringVar(&backupPath, "backup", "", "backup")
-	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags := c.Meta.flagSet("state rm")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
+	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "backup")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
 	if err := cmdFlags.Parse(args); err != nil {
 		return cli.RunResultHelp
 	}
@@ -42,6 +44,11 @@ func (c *StateRmCommand) Run(args []string) int {
 		return 1
 	}
 
+	if !c.confirmRemoteState(autoApprove) {
+		return 1
+	}
+
+	stateReal.TFVersion = terraform.Version
 	if err := state.WriteState(stateReal); err != nil {
 		c.Ui.Error(fmt.Sprintf(errStateRmPersist, err))
 		return 1
@@ -72,6 +79,9 @@ Usage: terraform state rm [options] ADDRESS...
 
 Options:
 
+  -auto-approve       Don't ask for the name of a remote state configured
+                      with confirm_required before changing it.
+
   -backup=PATH        Path where Terraform should write the backup
                       state. This can't be disabled. If not set, Terraform
                       will write it to the same path as the statefile with
@@ -2,6 +2,7 @@ package command
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -70,6 +71,43 @@ func TestStateRm(t *testing.T) {
 	testStateOutput(t, backups[0], testStateRmOutputOriginal)
 }
 
+func TestStateRm_backup(t *testing.T) {
+	statePath := testStateFile(t, testState())
+	backupPath := filepath.Join(filepath.Dir(statePath), "custom.backup")
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateRmCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-backup", backupPath,
+		"test_instance.foo",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The backup is only written to the given path
+	backup := testReadState(t, backupPath)
+	if _, ok := backup.RootModule().Resources["test_instance.foo"]; !ok {
+		t.Fatalf("bad: %s", backup)
+	}
+	if backups := testStateBackups(t, filepath.Dir(statePath)); len(backups) != 1 {
+		t.Fatalf("bad: %#v", backups)
+	}
+
+	state := testReadState(t, statePath)
+	if _, ok := state.RootModule().Resources["test_instance.foo"]; ok {
+		t.Fatalf("bad: %s", state)
+	}
+}
+
 func TestStateRm_noState(t *testing.T) {
 	tmp, cwd := testCwd(t)
 	defer testFixCwd(t, tmp, cwd)
@@ -106,3 +144,31 @@ test_instance.bar:
   bar = value
   foo = value
 `
+
+func TestStateRm_confirmRequired(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	current := testState()
+	client, cleanup := testConfirmRequiredState(t, current)
+	defer cleanup()
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateRmCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// Answering anything but the name of the remote state doesn't change it
+	args := []string{"test_instance.foo"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.OutputWriter.String(), "Cancelled") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+	testConfirmRequiredUnchanged(t, client, current)
+}
@@ -0,0 +1,93 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/ryanuber/columnize"
+)
+
+// stateSummary describes a state before it's replaced with another, so
+// that the user can tell which of the two states is newer.
+type stateSummary struct {
+	// Name says where the state is, such as `the "s3" backend`.
+	Name string
+
+	Lineage   string
+	Serial    int64
+	Resources int
+
+	// LastModified is the time the state was last written, or the zero
+	// time if it isn't known.
+	LastModified time.Time
+}
+
+// newStateSummary returns the summary of the given state.
+func newStateSummary(name string, s *terraform.State, modified time.Time) *stateSummary {
+	result := &stateSummary{
+		Name:         name,
+		Lineage:      s.Lineage,
+		Serial:       s.Serial,
+		LastModified: modified,
+	}
+	for _, m := range s.Modules {
+		result.Resources += len(m.Resources)
+	}
+
+	return result
+}
+
+// formatStateSummaries returns the summaries of the state replaced and the
+// state replacing it side by side, followed by what will be overwritten.
+// A warning is added if the state replaced looks newer.
+func formatStateSummaries(replaced, replacing *stateSummary) string {
+	rows := []string{
+		" | Replaced | Replaced with",
+		fmt.Sprintf("State | %s | %s", replaced.Name, replacing.Name),
+		fmt.Sprintf("Resources | %d | %d", replaced.Resources, replacing.Resources),
+		fmt.Sprintf("Lineage | %s | %s", summaryValue(replaced.Lineage), summaryValue(replacing.Lineage)),
+		fmt.Sprintf("Serial | %d | %d", replaced.Serial, replacing.Serial),
+		fmt.Sprintf("Last modified | %s | %s",
+			summaryTime(replaced.LastModified), summaryTime(replacing.LastModified)),
+	}
+	result := columnize.SimpleFormat(rows)
+
+	result += fmt.Sprintf(
+		"\n\nThe state in %s, with %d resources, will be overwritten with the\n"+
+			"state from %s, with %d resources.",
+		replaced.Name, replaced.Resources, replacing.Name, replacing.Resources)
+
+	switch {
+	case replaced.Lineage != "" && replaced.Lineage != replacing.Lineage:
+		result += "\n\n[yellow]The states have different lineages, so they're the states of\n" +
+			"different infrastructure.[reset]"
+	case replaced.Serial > replacing.Serial:
+		result += "\n\n[yellow]The state overwritten has a higher serial, so it's newer than the\n" +
+			"state replacing it and its changes will be lost.[reset]"
+	case !replacing.LastModified.IsZero() && replaced.LastModified.After(replacing.LastModified):
+		result += "\n\n[yellow]The state overwritten was modified more recently than the state\n" +
+			"replacing it.[reset]"
+	}
+
+	return result
+}
+
+// summaryValue returns v, or "none" if it's empty.
+func summaryValue(v string) string {
+	if v == "" {
+		return "none"
+	}
+
+	return v
+}
+
+// summaryTime returns t formatted for a state summary, or "unknown" if
+// it's the zero time.
+func summaryTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
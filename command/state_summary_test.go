@@ -0,0 +1,58 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatStateSummaries(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		Replaced, Replacing *stateSummary
+		Warning             string
+	}{
+		{
+			&stateSummary{Name: "a", Lineage: "foo", Serial: 1},
+			&stateSummary{Name: "b", Lineage: "foo", Serial: 2},
+			"",
+		},
+		{
+			&stateSummary{Name: "a", Lineage: "foo", Serial: 1},
+			&stateSummary{Name: "b", Lineage: "bar", Serial: 2},
+			"different lineages",
+		},
+		{
+			&stateSummary{Name: "a", Lineage: "foo", Serial: 3},
+			&stateSummary{Name: "b", Lineage: "foo", Serial: 2},
+			"higher serial",
+		},
+		{
+			&stateSummary{Name: "a", Lineage: "foo", Serial: 2, LastModified: now},
+			&stateSummary{Name: "b", Lineage: "foo", Serial: 2, LastModified: now.Add(-time.Hour)},
+			"modified more recently",
+		},
+		{
+			&stateSummary{Name: "a", Lineage: "foo", Serial: 2, LastModified: now},
+			&stateSummary{Name: "b", Lineage: "foo", Serial: 2},
+			"",
+		},
+	}
+
+	for i, tc := range cases {
+		actual := formatStateSummaries(tc.Replaced, tc.Replacing)
+		if !strings.Contains(actual, "The state in a, with 0 resources, will be overwritten") {
+			t.Fatalf("%d: bad:\n\n%s", i, actual)
+		}
+		if tc.Warning == "" {
+			if strings.Contains(actual, "[yellow]") {
+				t.Fatalf("%d: unexpected warning:\n\n%s", i, actual)
+			}
+			continue
+		}
+		if !strings.Contains(actual, tc.Warning) {
+			t.Fatalf("%d: expected %q:\n\n%s", i, tc.Warning, actual)
+		}
+	}
+}
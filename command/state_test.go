@@ -1,12 +1,20 @@
 package command
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/state"
+	"github.com/hashicorp/terraform/terraform"
 )
 
 // testStateBackups returns the list of backups in order of creation
@@ -36,3 +44,276 @@ func TestStateDefaultBackupExtension(t *testing.T) {
 		t.Fatal("Bad backup path:", backupPath)
 	}
 }
+
+func TestState_remoteCacheChecksum(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	conf, srv := testRemoteState(t, testState(), 200)
+	defer srv.Close()
+
+	s := terraform.NewState()
+	s.Remote = conf
+	path := testStateFileRemote(t, s)
+
+	// Write a checksum that can't match the cache
+	if err := ioutil.WriteFile(path+DefaultChecksumExtension, []byte("bad\n"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	opts := &StateOpts{RemotePath: path, RemoteRefresh: true}
+	_, err := State(opts)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "terraform remote config") {
+		t.Fatalf("should explain how to recover: %s", err)
+	}
+
+	// Loading works again once the checksum is removed
+	if err := os.Remove(path + DefaultChecksumExtension); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := State(opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestState_remoteConflict(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	remoteState := testState()
+	remoteState.Serial = 2
+	conf, srv := testRemoteState(t, remoteState, 200)
+	defer srv.Close()
+
+	// Same serial as the remote state, different contents. Reading the
+	// remote state normalizes it, which bumps its serial to 3.
+	s := testState()
+	s.Serial = 3
+	s.RootModule().Resources["test_instance.foo"].Primary.ID = "baz"
+	s.Remote = conf
+	path := testStateFileRemote(t, s)
+
+	_, err := State(&StateOpts{RemotePath: path, RemoteRefresh: true})
+	if err == nil {
+		t.Fatal("should error")
+	}
+	for _, expected := range []string{"both\nhave serial", "terraform remote push"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q in error: %s", expected, err)
+		}
+	}
+
+	// The cache is left alone
+	if actual := testReadState(t, path).RootModule().Resources["test_instance.foo"].Primary.ID; actual != "baz" {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestState_remoteAndLocal(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	conf, srv := testRemoteState(t, testState(), 200)
+	defer srv.Close()
+
+	s := testState()
+	s.Remote = conf
+	path := testStateFileRemote(t, s)
+	localPath := testStateFileDefault(t, testState())
+
+	_, err := State(&StateOpts{
+		LocalPath:     localPath,
+		RemotePath:    path,
+		RemoteRefresh: true,
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+	for _, expected := range []string{"Remote state (http)", localPath, "remote config -disable"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q in error: %s", expected, err)
+		}
+	}
+}
+
+func TestState_remoteReadReplica(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	// Serve the given state, with the remote configuration of the cache
+	conf := &terraform.RemoteState{Type: "http", Config: map[string]string{}}
+	serve := func(serial int64) *httptest.Server {
+		s := testState()
+		s.Serial = serial
+		s.Remote = conf
+
+		return httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			if req.Method == "GET" {
+				terraform.WriteState(s, resp)
+			}
+		}))
+	}
+	primarySrv := serve(5)
+	defer primarySrv.Close()
+	replicaSrv := serve(3)
+	defer replicaSrv.Close()
+	conf.Config["address"] = primarySrv.URL
+	conf.Config["read_address"] = replicaSrv.URL
+
+	// The cache has no resources, so the remote state read replaces it
+	s := terraform.NewState()
+	s.Remote = conf
+	path := testStateFileRemote(t, s)
+
+	// Read-only commands read the state from the replica
+	opts := &StateOpts{RemotePath: path, RemoteRefresh: true, RemoteReadOnly: true}
+	result, err := State(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := result.State.State().Serial; actual != 3 {
+		t.Fatalf("bad: %d", actual)
+	}
+
+	// Commands that write the state read it from where it's written
+	opts = &StateOpts{RemotePath: path, RemoteRefresh: true}
+	result, err = State(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := result.State.State().Serial; actual != 5 {
+		t.Fatalf("bad: %d", actual)
+	}
+}
+
+func TestState_remoteReadOnly(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	// Serve an older remote state and record any attempt to write it
+	var buf bytes.Buffer
+	if err := terraform.WriteState(testState(), &buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	written := false
+	srv := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method != "GET" {
+			written = true
+			return
+		}
+
+		resp.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	s := testState()
+	s.Serial = 100
+	s.Remote = &terraform.RemoteState{
+		Type:   "http",
+		Config: map[string]string{"address": srv.URL},
+	}
+	path := testStateFileRemote(t, s)
+
+	opts := &StateOpts{RemotePath: path, RemoteRefresh: true, RemoteReadOnly: true}
+	result, err := State(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if written {
+		t.Fatal("remote state should not be written")
+	}
+	if actual := result.State.State().Serial; actual != 100 {
+		t.Fatalf("bad: %d", actual)
+	}
+
+	// Without read-only, the newer cache is synced to the remote state
+	opts.RemoteReadOnly = false
+	if _, err := State(opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !written {
+		t.Fatal("remote state should be written")
+	}
+}
+
+func TestState_remoteOverride(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	// The saved remote state
+	saved, srv := testRemoteState(t, testState(), 200)
+	defer srv.Close()
+
+	s := terraform.NewState()
+	s.Remote = saved
+	path := testStateFileRemote(t, s)
+	before, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The remote state the override points to
+	replica := testState()
+	replica.Lineage = "replica"
+	conf, replicaSrv := testRemoteState(t, replica, 200)
+	defer replicaSrv.Close()
+
+	override := filepath.Join(tmp, "override.tf")
+	err = ioutil.WriteFile(override, []byte(fmt.Sprintf(
+		"backend = %q\nconfig {\n  address = %q\n}\n",
+		conf.Type, conf.Config["address"])), 0644)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	opts := &StateOpts{
+		LocalPath:          DefaultStateFilename,
+		RemotePath:         path,
+		RemoteRefresh:      true,
+		RemoteOverridePath: override,
+	}
+	result, err := State(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := result.State.State().Lineage; actual != "replica" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// Nothing is written locally
+	if err := result.State.WriteState(result.State.State()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := result.State.PersistState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	after, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("remote state cache should not be changed")
+	}
+	if _, err := os.Stat(DefaultStateFilename); !os.IsNotExist(err) {
+		t.Fatalf("local state should not be written: %s", err)
+	}
+}
+
+func TestState_remoteOverrideInvalid(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	override := filepath.Join(tmp, "override.tf")
+	err := ioutil.WriteFile(override, []byte("config {\n  address = \"foo\"\n}\n"), 0644)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	_, err = State(&StateOpts{RemoteOverridePath: override})
+	if err == nil || !strings.Contains(err.Error(), "must set the backend type") {
+		t.Fatalf("bad: %v", err)
+	}
+}
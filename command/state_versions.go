@@ -0,0 +1,94 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/ryanuber/columnize"
+)
+
+// StateVersionsCommand is a Command implementation that lists the versions
+// of the remote state kept by the remote storage.
+type StateVersionsCommand struct {
+	Meta
+	StateMeta
+}
+
+func (c *StateVersionsCommand) Run(args []string) int {
+	args = c.Meta.process(args, true)
+
+	cmdFlags := c.Meta.flagSet("state versions")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The state versions command expects no arguments.")
+		return cli.RunResultHelp
+	}
+
+	// The state is only read to find the remote storage
+	opts := c.StateOpts()
+	opts.RemoteReadOnly = true
+	result, err := State(opts)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+
+	client, err := c.versionedClient(result)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	versions, err := client.ListVersions()
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if len(versions) == 0 {
+		c.Ui.Output("The remote state has no versions.")
+		return 0
+	}
+
+	output := make([]string, 0, len(versions))
+	for _, v := range versions {
+		var latest string
+		if v.Latest {
+			latest = "(current)"
+		}
+
+		output = append(output, fmt.Sprintf(
+			"%s | %s | %d bytes | %s",
+			v.ID, v.LastModified.UTC().Format(time.RFC3339), v.Size, latest))
+	}
+
+	c.Ui.Output(columnize.SimpleFormat(output))
+	return 0
+}
+
+func (c *StateVersionsCommand) Help() string {
+	helpText := `
+Usage: terraform state versions [options]
+
+  List the versions of the remote state.
+
+  This command lists the versions of the state kept by the remote state
+  storage, newest first, with the ID of each version, the time it was
+  written and its size. A version can be restored with
+  "terraform state restore".
+
+  This is only supported by remote state storage that keeps versions:
+  "s3" buckets with versioning enabled and "gcs" buckets with object
+  versioning enabled.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateVersionsCommand) Synopsis() string {
+	return "List the versions of the remote state"
+}
@@ -0,0 +1,126 @@
+package command
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/state/remote"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+// testVersionedRemoteState configures a remote state in the cwd that is
+// stored in the returned in-memory client, which keeps every version of
+// the state written to it.
+func testVersionedRemoteState(t *testing.T, states ...*terraform.State) (*remote.InmemClient, func()) {
+	client := new(remote.InmemClient)
+	old := remote.BuiltinClients["inmem"]
+	remote.BuiltinClients["inmem"] = func(map[string]string) (remote.Client, error) {
+		return client, nil
+	}
+
+	conf := &terraform.RemoteState{
+		Type:   "inmem",
+		Config: map[string]string{},
+	}
+	for _, s := range states {
+		s.Remote = conf
+
+		var buf bytes.Buffer
+		if err := terraform.WriteState(s, &buf); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := client.Put(buf.Bytes()); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	cache := terraform.NewState()
+	cache.Remote = conf
+	testStateFileRemote(t, cache)
+
+	return client, func() {
+		remote.BuiltinClients["inmem"] = old
+	}
+}
+
+func TestStateVersions(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	_, cleanup := testVersionedRemoteState(t, testState(), testState())
+	defer cleanup()
+
+	ui := new(cli.MockUi)
+	c := &StateVersionsCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(ui.OutputWriter.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("bad:\n\n%s", ui.OutputWriter.String())
+	}
+	if !strings.HasPrefix(lines[0], "2 ") || !strings.HasSuffix(lines[0], "(current)") {
+		t.Fatalf("bad: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "1 ") || strings.Contains(lines[1], "(current)") {
+		t.Fatalf("bad: %q", lines[1])
+	}
+}
+
+func TestStateVersions_notVersioned(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	conf, srv := testRemoteState(t, testState(), 200)
+	defer srv.Close()
+
+	s := terraform.NewState()
+	s.Remote = conf
+	testStateFileRemote(t, s)
+
+	ui := new(cli.MockUi)
+	c := &StateVersionsCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "doesn't keep versions") {
+		t.Fatalf("bad:\n\n%s", ui.ErrorWriter.String())
+	}
+}
+
+func TestStateVersions_local(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	testStateFileDefault(t, testState())
+
+	ui := new(cli.MockUi)
+	c := &StateVersionsCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "Remote state isn't configured") {
+		t.Fatalf("bad:\n\n%s", ui.ErrorWriter.String())
+	}
+}
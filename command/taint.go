@@ -17,12 +17,13 @@ type TaintCommand struct {
 func (c *TaintCommand) Run(args []string) int {
 	args = c.Meta.process(args, false)
 
-	var allowMissing bool
+	var allowMissing, autoApprove bool
 	var module string
 	cmdFlags := c.Meta.flagSet("taint")
 	cmdFlags.BoolVar(&allowMissing, "allow-missing", false, "module")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
 	cmdFlags.StringVar(&module, "module", "", "module")
-	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
 	cmdFlags.StringVar(&c.Meta.stateOutPath, "state-out", "", "path")
 	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
@@ -39,13 +40,7 @@ func (c *TaintCommand) Run(args []string) int {
 	}
 
 	name := args[0]
-	if module == "" {
-		module = "root"
-	} else {
-		module = "root." + module
-	}
-
-	rsk, err := terraform.ParseResourceStateKey(name)
+	module, rsk, err := parseTaintAddress(name, module)
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Failed to parse resource name: %s", err))
 		return 1
@@ -105,7 +100,7 @@ func (c *TaintCommand) Run(args []string) int {
 	}
 
 	// Get the resource we're looking for
-	rs, ok := mod.Resources[name]
+	rs, ok := mod.Resources[rsk.String()]
 	if !ok {
 		if allowMissing {
 			return c.allowMissingExit(name, module)
@@ -118,6 +113,10 @@ func (c *TaintCommand) Run(args []string) int {
 		return 1
 	}
 
+	if !c.confirmRemoteState(autoApprove) {
+		return 1
+	}
+
 	// Taint the resource
 	rs.Taint()
 
@@ -146,11 +145,18 @@ Usage: terraform taint [options] name
   its own will not modify infrastructure. This command can be undone by
   reverting the state backup file that is created.
 
+  The resource is given by its name, such as "aws_instance.foo", in the
+  module given with -module, or by an address that includes the module and
+  the index, such as "module.foo.aws_instance.bar[1]".
+
 Options:
 
   -allow-missing      If specified, the command will succeed (exit code 0)
                       even if the resource is missing.
 
+  -auto-approve       Don't ask for the name of a remote state configured
+                      with confirm_required before changing it.
+
   -backup=path        Path to backup the existing state file before
                       modifying. Defaults to the "-state-out" path with
                       ".backup" extension. Set to "-" to disable backup.
@@ -182,3 +188,45 @@ func (c *TaintCommand) allowMissingExit(name, module string) int {
 		name, module))
 	return 0
 }
+
+// parseTaintAddress returns the module and the state key of the resource
+// given to taint or untaint. The resource is either an address such as
+// "module.foo.aws_instance.bar[1]", or a key in the state such as
+// "aws_instance.bar.1" of the resource in the module given with -module.
+// The module is returned as a path such as "root.foo".
+func parseTaintAddress(name, module string) (string, *terraform.ResourceStateKey, error) {
+	if !strings.HasPrefix(name, "module.") && !strings.Contains(name, "[") {
+		rsk, err := terraform.ParseResourceStateKey(name)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if module == "" {
+			return "root", rsk, nil
+		}
+		return "root." + module, rsk, nil
+	}
+
+	if module != "" {
+		return "", nil, fmt.Errorf(
+			"The -module flag can't be used with a resource address that " +
+				"includes the module.")
+	}
+
+	addr, err := terraform.ParseResourceAddress(name)
+	if err != nil {
+		return "", nil, err
+	}
+	if addr.Type == "" {
+		return "", nil, fmt.Errorf("%s is a module, not a resource", name)
+	}
+
+	rsk := &terraform.ResourceStateKey{
+		Mode:  addr.Mode,
+		Type:  addr.Type,
+		Name:  addr.Name,
+		Index: addr.Index,
+	}
+	path := append([]string{"root"}, addr.Path...)
+	return strings.Join(path, "."), rsk, nil
+}
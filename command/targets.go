@@ -0,0 +1,201 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// targetExpansion is a -target address and the resources it includes.
+type targetExpansion struct {
+	Target    string
+	Resources []string
+}
+
+// checkTargets checks the -target addresses before an operation starts, so
+// that a mistyped address is an error instead of an operation that does
+// nothing. For each address that includes other resources than itself, such
+// as a module or all the instances of a resource with count, the resources
+// it includes are output. This returns false if the operation can't go on.
+func (m *Meta) checkTargets(mod *module.Tree, s *terraform.State) bool {
+	if len(m.targets) == 0 {
+		return true
+	}
+
+	expansions, err := expandTargets(m.targets, mod, s)
+	if err != nil {
+		m.Ui.Error(err.Error())
+		return false
+	}
+
+	var buf bytes.Buffer
+	for _, e := range expansions {
+		if len(e.Resources) == 1 && e.Resources[0] == e.Target {
+			continue
+		}
+
+		resources := make([]interface{}, len(e.Resources))
+		for i, r := range e.Resources {
+			resources[i] = r
+		}
+		m.jsonEvent("target_expanded", map[string]interface{}{
+			"target":    e.Target,
+			"resources": resources,
+		})
+
+		buf.WriteString(fmt.Sprintf("Target %s includes:\n", e.Target))
+		for _, r := range e.Resources {
+			buf.WriteString(fmt.Sprintf("  %s\n", r))
+		}
+	}
+	if buf.Len() > 0 {
+		m.Ui.Output(buf.String())
+	}
+
+	return true
+}
+
+// expandTargets matches each of the target addresses against the resources
+// in the configuration and the state, and returns the resources each one
+// includes. Resources in the state are listed by instance, and resources
+// that are only in the configuration by name. It's an error for an address
+// to be invalid or to match no resources.
+func expandTargets(
+	targets []string,
+	mod *module.Tree,
+	s *terraform.State) ([]*targetExpansion, error) {
+	// The instances in the state come first, so that a resource with
+	// instances isn't listed by name as well.
+	var addrs []*terraform.ResourceAddress
+	if s != nil {
+		for _, ms := range s.Modules {
+			for k := range ms.Resources {
+				addr, err := stateKeyAddress(ms.Path, k)
+				if err != nil {
+					return nil, err
+				}
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	if mod != nil {
+		var walk func(*module.Tree)
+		walk = func(t *module.Tree) {
+			for _, r := range t.Config().Resources {
+				addrs = append(addrs, &terraform.ResourceAddress{
+					Path:         t.Path(),
+					Index:        -1,
+					InstanceType: terraform.TypePrimary,
+					Name:         r.Name,
+					Type:         r.Type,
+					Mode:         r.Mode,
+				})
+			}
+			for _, child := range t.Children() {
+				walk(child)
+			}
+		}
+		walk(mod)
+	}
+
+	var result []*targetExpansion
+	var missing []string
+	for _, target := range targets {
+		targetAddr, err := terraform.ParseResourceAddress(target)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid target address %q: %s", target, err)
+		}
+
+		listed := make(map[string]bool)
+		var resources []string
+		for _, addr := range addrs {
+			if !targetAddr.Equals(addr) {
+				continue
+			}
+
+			// An instance of a resource that isn't in the state yet
+			if addr.Index < 0 && targetAddr.Index >= 0 {
+				addr = addr.Copy()
+				addr.Index = targetAddr.Index
+			}
+			if listed[addr.String()] {
+				continue
+			}
+			listed[addr.String()] = true
+
+			// Skip the name of a resource if an instance of it is listed
+			name := addr.Copy()
+			name.Index = -1
+			if addr.Index < 0 && listed[name.String()+"[*]"] {
+				continue
+			}
+			if addr.Index >= 0 {
+				listed[name.String()+"[*]"] = true
+			}
+
+			resources = append(resources, addr.String())
+		}
+
+		if len(resources) == 0 {
+			missing = append(missing, target)
+			continue
+		}
+
+		sort.Strings(resources)
+		result = append(result, &targetExpansion{
+			Target:    target,
+			Resources: resources,
+		})
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf(
+			"The following targets don't match any resource in the configuration\n"+
+				"or the state:\n\n  %s\n\n"+
+				"Please check the addresses and try again.",
+			strings.Join(missing, "\n  "))
+	}
+
+	return result, nil
+}
+
+// stateKeyAddress returns the address of a resource in the state of the
+// module with the given path, from its key such as "aws_instance.web.1".
+func stateKeyAddress(path []string, k string) (*terraform.ResourceAddress, error) {
+	addr := &terraform.ResourceAddress{
+		Index:        -1,
+		InstanceType: terraform.TypePrimary,
+		Mode:         config.ManagedResourceMode,
+	}
+	if len(path) > 1 {
+		addr.Path = path[1:]
+	}
+
+	parts := strings.Split(k, ".")
+	if len(parts) > 2 && parts[0] == "data" {
+		addr.Mode = config.DataResourceMode
+		parts = parts[1:]
+	}
+
+	switch len(parts) {
+	case 3:
+		idx, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid resource in state: %s", k)
+		}
+		addr.Index = idx
+	case 2:
+	default:
+		return nil, fmt.Errorf("Invalid resource in state: %s", k)
+	}
+
+	addr.Type = parts[0]
+	addr.Name = parts[1]
+	return addr, nil
+}
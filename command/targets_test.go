@@ -0,0 +1,112 @@
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+func TestExpandTargets(t *testing.T) {
+	mod := testModule(t, "targets")
+
+	s := terraform.NewState()
+	s.RootModule().Resources = map[string]*terraform.ResourceState{
+		"test_instance.web.0": &terraform.ResourceState{Type: "test_instance"},
+		"test_instance.web.1": &terraform.ResourceState{Type: "test_instance"},
+	}
+
+	cases := []struct {
+		Target    string
+		Resources []string
+	}{
+		{
+			"test_instance.web",
+			[]string{"test_instance.web[0]", "test_instance.web[1]"},
+		},
+		{
+			"test_instance.web[*]",
+			[]string{"test_instance.web[0]", "test_instance.web[1]"},
+		},
+		{
+			"test_instance.web[1]",
+			[]string{"test_instance.web[1]"},
+		},
+		{
+			"module.child",
+			[]string{"module.child.test_instance.foo"},
+		},
+		{
+			"module.child.test_instance.foo",
+			[]string{"module.child.test_instance.foo"},
+		},
+	}
+
+	for _, tc := range cases {
+		actual, err := expandTargets([]string{tc.Target}, mod, s)
+		if err != nil {
+			t.Fatalf("%s: err: %s", tc.Target, err)
+		}
+
+		expected := []*targetExpansion{
+			&targetExpansion{Target: tc.Target, Resources: tc.Resources},
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("%s: bad: %#v", tc.Target, actual[0])
+		}
+	}
+}
+
+func TestExpandTargets_noState(t *testing.T) {
+	mod := testModule(t, "targets")
+
+	actual, err := expandTargets([]string{"test_instance.web[*]"}, mod, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{"test_instance.web"}
+	if !reflect.DeepEqual(actual[0].Resources, expected) {
+		t.Fatalf("bad: %#v", actual[0].Resources)
+	}
+}
+
+func TestExpandTargets_invalid(t *testing.T) {
+	mod := testModule(t, "targets")
+
+	_, err := expandTargets([]string{"test_instance.web[x]"}, mod, nil)
+	if err == nil || !strings.Contains(err.Error(), "Invalid target address") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	_, err = expandTargets(
+		[]string{"test_instance.nope", "module.nope", "test_instance.web"}, mod, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "test_instance.nope\n  module.nope\n") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestMeta_checkTargets(t *testing.T) {
+	ui := new(cli.MockUi)
+	m := &Meta{
+		Ui:      ui,
+		targets: []string{"module.child", "test_instance.web[0]"},
+	}
+
+	if !m.checkTargets(testModule(t, "targets"), nil) {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+
+	// Only the module includes other resources than itself. Resources
+	// that aren't in the state yet are matched by name.
+	expected := "Target module.child includes:\n  module.child.test_instance.foo\n"
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, expected) || strings.Contains(output, "web") {
+		t.Fatalf("bad:\n\n%s", output)
+	}
+}
@@ -0,0 +1,7 @@
+terraform {
+    policy_command = "grep -q test_instance.foo"
+}
+
+resource "test_instance" "foo" {
+    ami = "bar"
+}
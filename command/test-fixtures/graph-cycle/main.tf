@@ -0,0 +1,7 @@
+resource "test_instance" "a" {
+    ami = "${test_instance.b.id}"
+}
+
+resource "test_instance" "b" {
+    ami = "${test_instance.a.id}"
+}
@@ -0,0 +1,7 @@
+provider "test" {
+    value = "${backend.region}"
+}
+
+resource "test_instance" "foo" {
+    ami = "bar"
+}
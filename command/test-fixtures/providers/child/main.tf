@@ -0,0 +1 @@
+resource "template_file" "foo" {}
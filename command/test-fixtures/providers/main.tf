@@ -0,0 +1,15 @@
+provider "aws" {
+    alias = "west"
+}
+
+resource "aws_instance" "foo" {}
+
+resource "aws_instance" "bar" {
+    provider = "aws.west"
+}
+
+resource "null_resource" "baz" {}
+
+module "child" {
+    source = "./child"
+}
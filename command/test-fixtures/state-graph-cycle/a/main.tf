@@ -0,0 +1,6 @@
+data "terraform_remote_state" "b" {
+  backend = "local"
+  config {
+    path = "../b/terraform.tfstate"
+  }
+}
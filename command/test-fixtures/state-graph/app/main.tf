@@ -0,0 +1,22 @@
+data "terraform_remote_state" "network" {
+  backend = "local"
+  config {
+    path = "../network/terraform.tfstate"
+  }
+}
+
+data "terraform_remote_state" "database" {
+  backend = "local"
+  config {
+    path = "../database/terraform.tfstate"
+  }
+}
+
+data "terraform_remote_state" "dns" {
+  backend = "s3"
+  config {
+    bucket     = "shared"
+    key        = "dns.tfstate"
+    secret_key = "hunter2"
+  }
+}
@@ -0,0 +1,3 @@
+module "used" {
+  source = "./child"
+}
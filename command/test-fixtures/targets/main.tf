@@ -0,0 +1,7 @@
+resource "test_instance" "web" {
+  count = 2
+}
+
+module "child" {
+  source = "./child"
+}
@@ -0,0 +1,9 @@
+variable "foo" {}
+
+variable "bar" {
+    default = "baz"
+}
+
+resource "test_instance" "foo" {
+    ami = "${var.foo}-${var.bar}"
+}
@@ -20,6 +20,86 @@ import (
 var defaultInputReader io.Reader
 var defaultInputWriter io.Writer
 
+// stdin is the line reader of the standard input, shared by every UIInput
+// so that the input read ahead for one answer is kept for the next one, as
+// when several answers are piped to Terraform.
+var stdin = newLineReader(os.Stdin)
+
+// lineReader reads lines from a reader one at a time, in a goroutine so
+// that waiting for a line can be interrupted. A read that is still in
+// progress when the ask is interrupted is carried on by the next ask, so
+// that reads never run concurrently and the line isn't lost.
+type lineReader struct {
+	r     *bufio.Reader
+	lines chan lineResult
+
+	// l makes sure a single line is asked for at once. reading is set
+	// while a line that hasn't been returned is being read, and err is
+	// the error that ended the input, after which nothing is read.
+	l       sync.Mutex
+	reading bool
+	err     error
+}
+
+// lineResult is a line read by a lineReader, and the error reading it.
+type lineResult struct {
+	line string
+	err  error
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	// A buffered reader is used as it is, so that nothing it has read
+	// ahead is lost
+	buf, ok := r.(*bufio.Reader)
+	if !ok {
+		buf = bufio.NewReader(r)
+	}
+
+	// The line read for an interrupted ask is buffered, so that the
+	// goroutine reading it doesn't wait for the next ask to end
+	return &lineReader{
+		r:     buf,
+		lines: make(chan lineResult, 1),
+	}
+}
+
+// ReadLine returns the next line, without the trailing space, or false
+// if a value is received on cancel first. Once the input has ended, the
+// line is empty.
+func (lr *lineReader) ReadLine(cancel <-chan os.Signal) (string, bool) {
+	lr.l.Lock()
+	defer lr.l.Unlock()
+
+	if lr.err != nil {
+		return "", true
+	}
+	if !lr.reading {
+		lr.reading = true
+		go lr.read()
+	}
+
+	select {
+	case result := <-lr.lines:
+		lr.reading = false
+		lr.err = result.err
+		return result.line, true
+	case <-cancel:
+		return "", false
+	}
+}
+
+func (lr *lineReader) read() {
+	line, err := lr.r.ReadString('\n')
+	if err != nil {
+		log.Printf("[ERR] UIInput scan err: %s", err)
+	}
+
+	lr.lines <- lineResult{
+		line: strings.TrimRightFunc(line, unicode.IsSpace),
+		err:  err,
+	}
+}
+
 // UIInput is an implementation of terraform.UIInput that asks the CLI
 // for input stdin.
 type UIInput struct {
@@ -34,22 +114,19 @@ type UIInput struct {
 	interrupted bool
 	l           sync.Mutex
 	once        sync.Once
+
+	// reader is the line reader of Reader, or of defaultInputReader,
+	// created when input is first asked for.
+	reader *lineReader
 }
 
 func (i *UIInput) Input(opts *terraform.InputOpts) (string, error) {
 	i.once.Do(i.init)
 
-	r := i.Reader
 	w := i.Writer
-	if r == nil {
-		r = defaultInputReader
-	}
 	if w == nil {
 		w = defaultInputWriter
 	}
-	if r == nil {
-		r = os.Stdin
-	}
 	if w == nil {
 		w = os.Stdout
 	}
@@ -59,6 +136,18 @@ func (i *UIInput) Input(opts *terraform.InputOpts) (string, error) {
 	i.l.Lock()
 	defer i.l.Unlock()
 
+	if i.reader == nil {
+		r := i.Reader
+		if r == nil {
+			r = defaultInputReader
+		}
+		if r == nil {
+			i.reader = stdin
+		} else {
+			i.reader = newLineReader(r)
+		}
+	}
+
 	// If we're interrupted, then don't ask for input
 	if i.interrupted {
 		return "", errors.New("interrupted")
@@ -92,29 +181,10 @@ func (i *UIInput) Input(opts *terraform.InputOpts) (string, error) {
 		return "", err
 	}
 
-	// Listen for the input in a goroutine. This will allow us to
-	// interrupt this if we are interrupted (SIGINT)
-	result := make(chan string, 1)
-	go func() {
-		buf := bufio.NewReader(r)
-		line, err := buf.ReadString('\n')
-		if err != nil {
-			log.Printf("[ERR] UIInput scan err: %s", err)
-		}
-
-		result <- strings.TrimRightFunc(line, unicode.IsSpace)
-	}()
-
-	select {
-	case line := <-result:
-		fmt.Fprint(w, "\n")
-
-		if line == "" {
-			line = opts.Default
-		}
-
-		return line, nil
-	case <-sigCh:
+	// The input is read in a goroutine. This will allow us to stop
+	// waiting for it if we are interrupted (SIGINT)
+	line, ok := i.reader.ReadLine(sigCh)
+	if !ok {
 		// Print a newline so that any further output starts properly
 		// on a new line.
 		fmt.Fprintln(w)
@@ -124,6 +194,14 @@ func (i *UIInput) Input(opts *terraform.InputOpts) (string, error) {
 
 		return "", errors.New("interrupted")
 	}
+
+	fmt.Fprint(w, "\n")
+
+	if line == "" {
+		line = opts.Default
+	}
+
+	return line, nil
 }
 
 func (i *UIInput) init() {
@@ -1,7 +1,10 @@
 package command
 
 import (
+	"bufio"
 	"bytes"
+	"io"
+	"os"
 	"testing"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -42,3 +45,92 @@ func TestUIInputInput_spaces(t *testing.T) {
 		t.Fatalf("bad: %#v", v)
 	}
 }
+
+func TestUIInputInput_buffered(t *testing.T) {
+	i := &UIInput{
+		Reader: bufio.NewReader(bytes.NewBufferString("foo\nbar\n")),
+		Writer: bytes.NewBuffer(nil),
+	}
+
+	for _, expected := range []string{"foo", "bar"} {
+		v, err := i.Input(&terraform.InputOpts{})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if v != expected {
+			t.Fatalf("bad: %#v", v)
+		}
+	}
+}
+
+func TestLineReader_cancel(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	lr := newLineReader(r)
+
+	// Nothing is written, so the read is still in progress when the
+	// line is asked for again after it's canceled
+	cancel := make(chan os.Signal, 1)
+	cancel <- os.Interrupt
+	if _, ok := lr.ReadLine(cancel); ok {
+		t.Fatal("should be canceled")
+	}
+
+	go w.Write([]byte("foo\nbar\n"))
+	for _, expected := range []string{"foo", "bar"} {
+		v, ok := lr.ReadLine(make(chan os.Signal))
+		if !ok {
+			t.Fatal("should read a line")
+		}
+		if v != expected {
+			t.Fatalf("bad: %#v", v)
+		}
+	}
+}
+
+func TestLineReader_eof(t *testing.T) {
+	lr := newLineReader(bytes.NewBufferString("foo\nbar"))
+
+	for _, expected := range []string{"foo", "bar", "", ""} {
+		v, ok := lr.ReadLine(make(chan os.Signal))
+		if !ok {
+			t.Fatal("should read a line")
+		}
+		if v != expected {
+			t.Fatalf("bad: %#v", v)
+		}
+	}
+
+	// Nothing is read once the input has ended
+	if lr.reading || lr.err != io.EOF {
+		t.Fatalf("bad: %v %v", lr.reading, lr.err)
+	}
+}
+
+func TestUIInputInput_interrupted(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	i := &UIInput{
+		Reader: r,
+		Writer: bytes.NewBuffer(nil),
+	}
+	i.once.Do(i.init)
+
+	// The reader is kept by the UIInput, so the read carried on by the
+	// next ask is the one started before
+	i.reader = newLineReader(r)
+	cancel := make(chan os.Signal, 1)
+	cancel <- os.Interrupt
+	if _, ok := i.reader.ReadLine(cancel); ok {
+		t.Fatal("should be canceled")
+	}
+
+	go w.Write([]byte("foo\n"))
+	v, err := i.Input(&terraform.InputOpts{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if v != "foo" {
+		t.Fatalf("bad: %#v", v)
+	}
+}
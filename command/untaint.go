@@ -15,12 +15,13 @@ type UntaintCommand struct {
 func (c *UntaintCommand) Run(args []string) int {
 	args = c.Meta.process(args, false)
 
-	var allowMissing bool
+	var allowMissing, autoApprove bool
 	var module string
 	cmdFlags := c.Meta.flagSet("untaint")
 	cmdFlags.BoolVar(&allowMissing, "allow-missing", false, "module")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
 	cmdFlags.StringVar(&module, "module", "", "module")
-	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", c.Meta.defaultStatePath(), "path")
 	cmdFlags.StringVar(&c.Meta.stateOutPath, "state-out", "", "path")
 	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
@@ -37,10 +38,10 @@ func (c *UntaintCommand) Run(args []string) int {
 	}
 
 	name := args[0]
-	if module == "" {
-		module = "root"
-	} else {
-		module = "root." + module
+	module, rsk, err := parseTaintAddress(name, module)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to parse resource name: %s", err))
+		return 1
 	}
 
 	// Get the state that we'll be modifying
@@ -92,7 +93,7 @@ func (c *UntaintCommand) Run(args []string) int {
 	}
 
 	// Get the resource we're looking for
-	rs, ok := mod.Resources[name]
+	rs, ok := mod.Resources[rsk.String()]
 	if !ok {
 		if allowMissing {
 			return c.allowMissingExit(name, module)
@@ -105,6 +106,10 @@ func (c *UntaintCommand) Run(args []string) int {
 		return 1
 	}
 
+	if !c.confirmRemoteState(autoApprove) {
+		return 1
+	}
+
 	// Untaint the resource
 	rs.Untaint()
 
@@ -133,11 +138,18 @@ Usage: terraform untaint [options] name
   reverting the state backup file that is created, or by running
   'terraform taint' on the resource.
 
+  The resource is given by its name, such as "aws_instance.foo", in the
+  module given with -module, or by an address that includes the module and
+  the index, such as "module.foo.aws_instance.bar[1]".
+
 Options:
 
   -allow-missing      If specified, the command will succeed (exit code 0)
                       even if the resource is missing.
 
+  -auto-approve       Don't ask for the name of a remote state configured
+                      with confirm_required before changing it.
+
   -backup=path        Path to backup the existing state file before
                       modifying. Defaults to the "-state-out" path with
                       ".backup" extension. Set to "-" to disable backup.
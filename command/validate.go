@@ -1,12 +1,15 @@
 package command
 
 import (
-	"flag"
+	"encoding/json"
 	"fmt"
-	"path/filepath"
+	"os"
 	"strings"
 
 	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/state/remote"
+	"github.com/hashicorp/terraform/terraform"
 )
 
 // ValidateCommand is a Command implementation that validates the terraform files
@@ -17,29 +20,62 @@ type ValidateCommand struct {
 const defaultPath = "."
 
 func (c *ValidateCommand) Run(args []string) int {
-	args = c.Meta.process(args, false)
-	var dirPath string
+	var checkVars, jsonOutput bool
+	args = c.Meta.process(args, true)
 
-	cmdFlags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	cmdFlags := c.Meta.flagSet("validate")
+	cmdFlags.BoolVar(&checkVars, "check-variables", true, "check-variables")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
 
-	if len(args) == 1 {
+	dirPath := defaultPath
+	args = cmdFlags.Args()
+	if len(args) > 1 {
+		c.Ui.Error("The validate command expects at most one argument.")
+		cmdFlags.Usage()
+		return 1
+	} else if len(args) == 1 {
 		dirPath = args[0]
-	} else {
-		dirPath = "."
 	}
-	dir, err := filepath.Abs(dirPath)
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf(
-			"Unable to locate directory %v\n", err.Error()))
+
+	diags := c.validate(dirPath, checkVars)
+
+	var errCount int
+	for _, d := range diags {
+		if d.Severity == diagError {
+			errCount++
+		}
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"valid":       errCount == 0,
+			"diagnostics": diags,
+		}, "", "    ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to encode diagnostics: %s", err))
+			return 1
+		}
+
+		c.Ui.Output(string(data))
+	} else {
+		for _, d := range diags {
+			if d.Severity == diagError {
+				c.Ui.Error(formatDiagnostic(d, c.Colorize()))
+			} else {
+				c.Ui.Warn(formatDiagnostic(d, c.Colorize()))
+			}
+		}
 	}
 
-	rtnCode := c.validate(dir)
+	if errCount > 0 {
+		return 1
+	}
 
-	return rtnCode
+	return 0
 }
 
 func (c *ValidateCommand) Synopsis() string {
@@ -53,30 +89,134 @@ Usage: terraform validate [options] [path]
   Reads the Terraform files in the given path (directory) and
   validates their syntax and basic semantics.
 
+  The modules used by the configuration are validated too if they've
+  been downloaded with "terraform get", and so are the settings of the
+  remote state if it is configured.
+
   This is not a full validation that is normally done with
   a plan or apply operation, but can be used to verify the basic
   syntax and usage of Terraform configurations is correct.
 
 Options:
 
-  -no-color           If specified, output won't contain any color.
+  -check-variables=true  If set to true (default), check that all required
+                         variables of the configuration have a value.
+
+  -json                  Output the problems found as JSON, with the file
+                         and line of each syntax error.
+
+  -no-color              If specified, output won't contain any color.
+
+  -var 'foo=bar'         Set a variable in the Terraform configuration. This
+                         flag can be set multiple times.
+
+  -var-file=foo          Set variables in the Terraform configuration from
+                         a file. If "terraform.tfvars" is present, it will be
+                         automatically loaded if this flag is not specified.
 
 `
 	return strings.TrimSpace(helpText)
 }
 
-func (c *ValidateCommand) validate(dir string) int {
+// validate returns the problems found in the configuration in the given
+// directory. The checks stop at the first step that finds errors, since
+// later steps need a valid configuration.
+func (c *ValidateCommand) validate(dir string, checkVars bool) []*diagnostic {
 	cfg, err := config.LoadDir(dir)
 	if err != nil {
-		c.Ui.Error(fmt.Sprintf(
-			"Error loading files %v\n", err.Error()))
-		return 1
+		return loadDiagnostics(dir, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return errorDiagnostics(err)
 	}
-	err = cfg.Validate()
+
+	var diags []*diagnostic
+
+	// Modules are only validated if they've already been downloaded,
+	// since validate shouldn't fetch anything.
+	mod := module.NewTree("", cfg)
+	if err := mod.Load(c.moduleStorage(c.DataDir()), module.GetModeNone); err != nil {
+		diags = append(diags, &diagnostic{
+			Severity: diagWarning,
+			Summary:  fmt.Sprintf("Modules were not validated: %s", err),
+		})
+	} else if err := mod.Validate(); err != nil {
+		return append(diags, errorDiagnostics(err)...)
+	}
+
+	if checkVars {
+		diags = append(diags, c.validateVariables(mod)...)
+	}
+
+	diags = append(diags, c.validateRemoteState()...)
+	return diags
+}
+
+// validateVariables checks that every required variable of the root
+// module has a value from the command line, a variable file or the
+// environment.
+func (c *ValidateCommand) validateVariables(mod *module.Tree) []*diagnostic {
+	override := make(map[string]interface{})
+	for k, v := range c.autoVariables {
+		override[k] = v
+	}
+	for k, v := range c.variables {
+		override[k] = v
+	}
+
+	vs, err := terraform.Variables(mod, override)
 	if err != nil {
-		c.Ui.Error(fmt.Sprintf(
-			"Error validating: %v\n", err.Error()))
-		return 1
+		return errorDiagnostics(err)
 	}
-	return 0
+
+	var diags []*diagnostic
+	for _, v := range mod.Config().Variables {
+		if _, ok := vs[v.Name]; ok || !v.Required() {
+			continue
+		}
+
+		diags = append(diags, &diagnostic{
+			Severity: diagError,
+			Summary:  fmt.Sprintf("Required variable not set: %s", v.Name),
+			Detail: fmt.Sprintf(
+				"Set it with -var, -var-file or the %s%s environment variable.",
+				terraform.VarEnvPrefix, v.Name),
+		})
+	}
+
+	return diags
+}
+
+// validateRemoteState checks the settings of the remote state configured
+// in the data directory, if any, without contacting the remote storage.
+func (c *ValidateCommand) validateRemoteState() []*diagnostic {
+	f, err := os.Open(c.StateOpts().RemotePath)
+	if err != nil {
+		// No remote state is configured
+		return nil
+	}
+	defer f.Close()
+
+	s, err := terraform.ReadState(f)
+	if err != nil {
+		return []*diagnostic{&diagnostic{
+			Severity: diagError,
+			Summary:  fmt.Sprintf("Error reading remote state configuration: %s", err),
+			File:     f.Name(),
+		}}
+	}
+	if s.Remote == nil || s.Remote.Empty() {
+		return nil
+	}
+
+	_, err = remote.NewClient(strings.ToLower(s.Remote.Type), s.Remote.Config)
+	if err != nil {
+		return []*diagnostic{&diagnostic{
+			Severity: diagError,
+			Summary: fmt.Sprintf(
+				"Invalid remote state configuration for %q: %s", s.Remote.Type, err),
+		}}
+	}
+
+	return nil
 }
@@ -1,6 +1,8 @@
 package command
 
 import (
+	"encoding/json"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -40,7 +42,7 @@ func TestValidateFailingCommandMissingQuote(t *testing.T) {
 	if code != 1 {
 		t.Fatalf("Should have failed: %d\n\n%s", code, ui.ErrorWriter.String())
 	}
-	if !strings.HasSuffix(strings.TrimSpace(ui.ErrorWriter.String()), "IDENT test") {
+	if !strings.Contains(ui.ErrorWriter.String(), "IDENT test") {
 		t.Fatalf("Should have failed: %d\n\n'%s'", code, ui.ErrorWriter.String())
 	}
 }
@@ -122,3 +124,75 @@ func TestWronglyUsedInterpolationShouldFail(t *testing.T) {
 		t.Fatalf("Should have failed: %d\n\n'%s'", code, ui.ErrorWriter.String())
 	}
 }
+
+func TestValidate_requiredVariable(t *testing.T) {
+	ui, code := setupTest("validate-variables")
+	if code != 1 {
+		t.Fatalf("Should have failed: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "Required variable not set: foo") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+	if strings.Contains(ui.ErrorWriter.String(), "bar") {
+		t.Fatalf("bar has a default: %s", ui.ErrorWriter.String())
+	}
+}
+
+func TestValidate_requiredVariableSet(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &ValidateCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{
+		"-var", "foo=bar",
+		testFixturePath("validate-variables"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// Variables aren't checked at all with -check-variables=false
+	args = []string{
+		"-check-variables=false",
+		testFixturePath("validate-variables"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+}
+
+func TestValidate_json(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &ValidateCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{
+		"-json",
+		testFixturePath("validate-invalid/missing_quote"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("Should have failed: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+
+	var result struct {
+		Valid       bool
+		Diagnostics []*diagnostic
+	}
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if result.Valid || len(result.Diagnostics) != 1 {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	d := result.Diagnostics[0]
+	if filepath.Base(d.File) != "main.tf" || d.Line != 6 || d.Severity != "error" {
+		t.Fatalf("bad: %#v", d)
+	}
+}
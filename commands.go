@@ -15,6 +15,15 @@ var PlumbingCommands map[string]struct{}
 // Ui is the cli.Ui used for communicating to the outside world.
 var Ui cli.Ui
 
+// AuditLog is the audit log of state changes used by the commands. It is
+// set up from the CLI configuration before the command is run.
+var AuditLog command.AuditLog
+
+// ProviderPlugins are the provider plugins found on disk, used to resolve
+// the version constraints of providers. It is set up after the plugins are
+// discovered, before the command is run.
+var ProviderPlugins command.ProviderPlugins
+
 const (
 	ErrorPrefix  = "e:"
 	OutputPrefix = "o:"
@@ -29,11 +38,14 @@ func init() {
 		Ui:           &cli.BasicUi{Writer: os.Stdout},
 	}
 
-	meta := command.Meta{
+	meta := command.NewMeta(&command.MetaOpts{
+		Ui:          Ui,
 		Color:       true,
 		ContextOpts: &ContextOpts,
-		Ui:          Ui,
-	}
+		AuditLog:    &AuditLog,
+
+		ProviderPlugins: &ProviderPlugins,
+	})
 
 	// The command list is included in the terraform -help
 	// output, which is in turn included in the docs at
@@ -42,8 +54,9 @@ func init() {
 	// that to match.
 
 	PlumbingCommands = map[string]struct{}{
-		"state": struct{}{}, // includes all subcommands
-		"debug": struct{}{}, // includes all subcommands
+		"state":       struct{}{}, // includes all subcommands
+		"debug":       struct{}{}, // includes all subcommands
+		"credentials": struct{}{}, // includes all subcommands
 	}
 
 	Commands = map[string]cli.CommandFactory{
@@ -69,6 +82,12 @@ func init() {
 			}, nil
 		},
 
+		"discover": func() (cli.Command, error) {
+			return &command.DiscoverCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"fmt": func() (cli.Command, error) {
 			return &command.FmtCommand{
 				Meta: meta,
@@ -113,6 +132,13 @@ func init() {
 
 		"plan": func() (cli.Command, error) {
 			return &command.PlanCommand{
+				Meta:       meta,
+				ShutdownCh: makeShutdownCh(),
+			}, nil
+		},
+
+		"providers": func() (cli.Command, error) {
+			return &command.ProvidersCommand{
 				Meta: meta,
 			}, nil
 		},
@@ -125,7 +151,8 @@ func init() {
 
 		"refresh": func() (cli.Command, error) {
 			return &command.RefreshCommand{
-				Meta: meta,
+				Meta:       meta,
+				ShutdownCh: makeShutdownCh(),
 			}, nil
 		},
 
@@ -173,6 +200,19 @@ func init() {
 		// Plumbing
 		//-----------------------------------------------------------
 
+		"credentials": func() (cli.Command, error) {
+			return &command.CredentialsCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"credentials store": func() (cli.Command, error) {
+			return &command.CredentialsStoreCommand{
+				Meta:      meta,
+				StoreFunc: osStoreCredentials,
+			}, nil
+		},
+
 		"debug": func() (cli.Command, error) {
 			return &command.DebugCommand{
 				Meta: meta,
@@ -191,12 +231,30 @@ func init() {
 			}, nil
 		},
 
+		"state graph": func() (cli.Command, error) {
+			return &command.StateGraphCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state diff": func() (cli.Command, error) {
+			return &command.StateDiffCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"state list": func() (cli.Command, error) {
 			return &command.StateListCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"state meta": func() (cli.Command, error) {
+			return &command.StateMetaCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"state rm": func() (cli.Command, error) {
 			return &command.StateRmCommand{
 				Meta: meta,
@@ -209,11 +267,41 @@ func init() {
 			}, nil
 		},
 
+		"state prune": func() (cli.Command, error) {
+			return &command.StatePruneCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state replica": func() (cli.Command, error) {
+			return &command.StateReplicaCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state replicate": func() (cli.Command, error) {
+			return &command.StateReplicateCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state restore": func() (cli.Command, error) {
+			return &command.StateRestoreCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"state show": func() (cli.Command, error) {
 			return &command.StateShowCommand{
 				Meta: meta,
 			}, nil
 		},
+
+		"state versions": func() (cli.Command, error) {
+			return &command.StateVersionsCommand{
+				Meta: meta,
+			}, nil
+		},
 	}
 }
 
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/go-plugin"
@@ -27,8 +28,36 @@ type Config struct {
 	Providers    map[string]string
 	Provisioners map[string]string
 
+	// ProviderPlugins are all the provider plugins found, in increasing
+	// order of precedence, including the ones with a version in their name.
+	// Providers only has the one used for each provider by default.
+	ProviderPlugins []command.ProviderPlugin `hcl:"-"`
+
 	DisableCheckpoint          bool `hcl:"disable_checkpoint"`
 	DisableCheckpointSignature bool `hcl:"disable_checkpoint_signature"`
+
+	// Credentials are the settings, such as a token, used to authenticate
+	// to each host. CredentialsHelpers can have at most one helper program
+	// that is asked for credentials of hosts that aren't listed here.
+	Credentials        map[string]map[string]interface{}   `hcl:"credentials"`
+	CredentialsHelpers map[string]*ConfigCredentialsHelper `hcl:"credentials_helper"`
+
+	// AuditLog is the path of the file that changes to the state are
+	// recorded in. Nothing is recorded if it is empty.
+	AuditLog string `hcl:"audit_log"`
+
+	// StatsdAddress is the address of the statsd server that metrics of
+	// the remote state, such as how long writing it takes, are sent to.
+	// No metrics are sent if it is empty. StatsdPrefix is prepended to
+	// the name of every metric, "terraform" by default.
+	StatsdAddress string `hcl:"statsd_address"`
+	StatsdPrefix  string `hcl:"statsd_prefix"`
+}
+
+// ConfigCredentialsHelper is the configuration of a credentials helper
+// program.
+type ConfigCredentialsHelper struct {
+	Args []string `hcl:"args"`
 }
 
 // BuiltinConfig is the built-in defaults for the configuration. These
@@ -174,6 +203,20 @@ func (c1 *Config) Merge(c2 *Config) *Config {
 		}
 		result.Providers[k] = v
 	}
+	result.ProviderPlugins = append(result.ProviderPlugins, c1.ProviderPlugins...)
+	result.ProviderPlugins = append(result.ProviderPlugins, c2.ProviderPlugins...)
+
+	// A provider set in the configuration file takes precedence over the
+	// plugins found, and has no version
+	overrides := make([]string, 0, len(c2.Providers))
+	for k := range c2.Providers {
+		overrides = append(overrides, k)
+	}
+	sort.Strings(overrides)
+	for _, k := range overrides {
+		result.ProviderPlugins = append(result.ProviderPlugins,
+			command.ProviderPlugin{Name: k, Path: c2.Providers[k]})
+	}
 	for k, v := range c1.Provisioners {
 		result.Provisioners[k] = v
 	}
@@ -186,6 +229,37 @@ func (c1 *Config) Merge(c2 *Config) *Config {
 	result.DisableCheckpoint = c1.DisableCheckpoint || c2.DisableCheckpoint
 	result.DisableCheckpointSignature = c1.DisableCheckpointSignature || c2.DisableCheckpointSignature
 
+	if len(c1.Credentials) > 0 || len(c2.Credentials) > 0 {
+		result.Credentials = make(map[string]map[string]interface{})
+		for host, creds := range c1.Credentials {
+			result.Credentials[host] = creds
+		}
+		for host, creds := range c2.Credentials {
+			result.Credentials[host] = creds
+		}
+	}
+
+	// Only one credentials helper can be used, so a helper in c2 replaces
+	// the one in c1 rather than being merged with it.
+	result.CredentialsHelpers = c1.CredentialsHelpers
+	if len(c2.CredentialsHelpers) > 0 {
+		result.CredentialsHelpers = c2.CredentialsHelpers
+	}
+
+	result.AuditLog = c1.AuditLog
+	if c2.AuditLog != "" {
+		result.AuditLog = c2.AuditLog
+	}
+
+	result.StatsdAddress = c1.StatsdAddress
+	if c2.StatsdAddress != "" {
+		result.StatsdAddress = c2.StatsdAddress
+	}
+	result.StatsdPrefix = c1.StatsdPrefix
+	if c2.StatsdPrefix != "" {
+		result.StatsdPrefix = c2.StatsdPrefix
+	}
+
 	return &result
 }
 
@@ -199,8 +273,7 @@ func (c *Config) discover(path string) error {
 		}
 	}
 
-	err = c.discoverSingle(
-		filepath.Join(path, "terraform-provider-*"), &c.Providers)
+	err = c.discoverProviders(path)
 	if err != nil {
 		return err
 	}
@@ -245,6 +318,29 @@ func (c *Config) discoverSingle(glob string, m *map[string]string) error {
 	return nil
 }
 
+// discoverProviders is like discoverSingle for provider plugins, which may
+// have a version in their name, such as "terraform-provider-aws_v1.2.0".
+// All the plugins found are recorded in ProviderPlugins, and the one with
+// the highest version of each provider is used by default.
+func (c *Config) discoverProviders(dir string) error {
+	found, err := command.FindProviderPlugins(dir)
+	if err != nil {
+		return err
+	}
+
+	if c.Providers == nil {
+		c.Providers = make(map[string]string)
+	}
+
+	for _, p := range found {
+		log.Printf("[DEBUG] Discovered plugin: %s = %s", p.Name, p.Path)
+		c.Providers[p.Name] = p.Path
+	}
+	c.ProviderPlugins = append(c.ProviderPlugins, found...)
+
+	return nil
+}
+
 // ProviderFactories returns the mapping of prefixes to
 // ResourceProviderFactory that can be used to instantiate a
 // binary-based plugin.
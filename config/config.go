@@ -45,6 +45,7 @@ type Config struct {
 // in configuration files for configuring Terraform itself.
 type Terraform struct {
 	RequiredVersion string `hcl:"required_version"` // Required Terraform version (constraint)
+	PolicyCommand   string `hcl:"policy_command"`   // Command that must approve a plan before apply
 }
 
 // AtlasConfig is the configuration for building in HashiCorp's Atlas.
@@ -59,8 +60,13 @@ type AtlasConfig struct {
 // This does not represent a module itself, this represents a module
 // call-site within an existing configuration.
 type Module struct {
-	Name      string
-	Source    string
+	Name   string
+	Source string
+
+	// Version is the constraint on the versions of a module from a module
+	// registry, such as "~> 1.0". It's empty for other modules.
+	Version string
+
 	RawConfig *RawConfig
 }
 
@@ -72,6 +78,11 @@ type ProviderConfig struct {
 	Name      string
 	Alias     string
 	RawConfig *RawConfig
+
+	// Version is the constraint on the versions of the provider plugin
+	// that can be used, such as "~> 1.2". It's resolved by "terraform
+	// init" against the provider plugins available.
+	Version string
 }
 
 // A resource represents a single Terraform resource in the configuration.
@@ -268,6 +279,19 @@ func (c *Config) Validate() error {
 				}
 			}
 		}
+
+		if raw := tf.PolicyCommand; raw != "" {
+			rc, err := NewRawConfig(map[string]interface{}{
+				"root": raw,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf(
+					"terraform.policy_command: %s", err))
+			} else if len(rc.Interpolations) > 0 {
+				errs = append(errs, fmt.Errorf(
+					"terraform.policy_command: cannot contain interpolations"))
+			}
+		}
 	}
 
 	vars := c.InterpolatedVariables()
@@ -352,6 +376,15 @@ func (c *Config) Validate() error {
 						source,
 						v.FullKey()))
 				}
+			case *BackendVariable:
+				// The settings of the remote state are usually
+				// credentials, which mustn't end up in the state
+				if !strings.HasPrefix(source, "provider config ") {
+					errs = append(errs, fmt.Errorf(
+						"%s: backend variables can only be used in provider configurations: %s",
+						source,
+						v.FullKey()))
+				}
 			}
 		}
 	}
@@ -368,6 +401,13 @@ func (c *Config) Validate() error {
 		}
 
 		providerSet[name] = struct{}{}
+
+		if p.Version != "" {
+			if _, err := version.NewConstraint(p.Version); err != nil {
+				errs = append(errs, fmt.Errorf(
+					"provider.%s: invalid version constraint: %s", name, err))
+			}
+		}
 	}
 
 	// Check that all references to modules are valid
@@ -404,6 +444,13 @@ func (c *Config) Validate() error {
 				m.Id()))
 		}
 
+		if m.Version != "" {
+			if _, err := version.NewConstraint(m.Version); err != nil {
+				errs = append(errs, fmt.Errorf(
+					"%s: invalid version constraint: %s", m.Id(), err))
+			}
+		}
+
 		// Check that the name matches our regexp
 		if !NameRegexp.Match([]byte(m.Name)) {
 			errs = append(errs, fmt.Errorf(
@@ -893,6 +940,9 @@ func (m *Module) mergerMerge(other merger) merger {
 	if m2.Source != "" {
 		result.Source = m2.Source
 	}
+	if m2.Version != "" {
+		result.Version = m2.Version
+	}
 
 	return &result
 }
@@ -940,6 +990,9 @@ func (c *ProviderConfig) mergerMerge(m merger) merger {
 	if c2.Alias != "" {
 		result.Alias = c2.Alias
 	}
+	if c2.Version != "" {
+		result.Version = c2.Version
+	}
 
 	return &result
 }
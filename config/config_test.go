@@ -210,6 +210,13 @@ func TestConfigValidate_tfVersionInterpolations(t *testing.T) {
 	}
 }
 
+func TestConfigValidate_tfPolicyInterpolations(t *testing.T) {
+	c := testConfig(t, "validate-tf-policy-interp")
+	if err := c.Validate(); err == nil {
+		t.Fatal("should not be valid")
+	}
+}
+
 func TestConfigValidate_badDependsOn(t *testing.T) {
 	c := testConfig(t, "validate-bad-depends-on")
 	if err := c.Validate(); err == nil {
@@ -421,6 +428,20 @@ func TestConfigValidate_outputDuplicate(t *testing.T) {
 	}
 }
 
+func TestConfigValidate_backendVar(t *testing.T) {
+	c := testConfig(t, "validate-backend-var")
+	if err := c.Validate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestConfigValidate_backendVarResource(t *testing.T) {
+	c := testConfig(t, "validate-backend-var-resource")
+	if err := c.Validate(); err == nil {
+		t.Fatal("should not be valid")
+	}
+}
+
 func TestConfigValidate_pathVar(t *testing.T) {
 	c := testConfig(t, "validate-path-var")
 	if err := c.Validate(); err != nil {
@@ -456,6 +477,50 @@ func TestConfigValidate_providerMultiRefGood(t *testing.T) {
 	}
 }
 
+func TestConfigValidate_providerVersion(t *testing.T) {
+	c := testConfig(t, "validate-provider-version")
+	if err := c.Validate(); err != nil {
+		t.Fatalf("should be valid: %s", err)
+	}
+
+	pc := c.ProviderConfigs[0]
+	if pc.Version != "~> 1.2" {
+		t.Fatalf("bad: %#v", pc)
+	}
+	if _, ok := pc.RawConfig.Raw["version"]; ok {
+		t.Fatalf("version should not be in the provider config: %#v", pc.RawConfig.Raw)
+	}
+}
+
+func TestConfigValidate_providerVersionBad(t *testing.T) {
+	c := testConfig(t, "validate-provider-version-bad")
+	if err := c.Validate(); err == nil {
+		t.Fatal("should not be valid")
+	}
+}
+
+func TestConfigValidate_moduleVersion(t *testing.T) {
+	c := testConfig(t, "validate-module-version")
+	if err := c.Validate(); err != nil {
+		t.Fatalf("should be valid: %s", err)
+	}
+
+	m := c.Modules[0]
+	if m.Version != "~> 0.1" {
+		t.Fatalf("bad: %#v", m)
+	}
+	if _, ok := m.RawConfig.Raw["version"]; ok {
+		t.Fatalf("version should not be a module variable: %#v", m.RawConfig.Raw)
+	}
+}
+
+func TestConfigValidate_moduleVersionBad(t *testing.T) {
+	c := testConfig(t, "validate-module-version-bad")
+	if err := c.Validate(); err == nil {
+		t.Fatal("should not be valid")
+	}
+}
+
 func TestConfigValidate_provConnSplatOther(t *testing.T) {
 	c := testConfig(t, "validate-prov-conn-splat-other")
 	if err := c.Validate(); err != nil {
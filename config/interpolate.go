@@ -16,6 +16,15 @@ type InterpolatedVariable interface {
 	FullKey() string
 }
 
+// A BackendVariable is a variable that is referencing a setting of the
+// remote state configuration, such as "${backend.region}". Only remote
+// states configured to export their settings have any.
+type BackendVariable struct {
+	Field string
+
+	key string
+}
+
 // CountVariable is a variable for referencing information about
 // the count.
 type CountVariable struct {
@@ -105,6 +114,8 @@ func NewInterpolatedVariable(v string) (InterpolatedVariable, error) {
 		return NewUserVariable(v)
 	} else if strings.HasPrefix(v, "module.") {
 		return NewModuleVariable(v)
+	} else if strings.HasPrefix(v, "backend.") {
+		return NewBackendVariable(v)
 	} else if !strings.ContainsRune(v, '.') {
 		return NewSimpleVariable(v)
 	} else {
@@ -112,6 +123,27 @@ func NewInterpolatedVariable(v string) (InterpolatedVariable, error) {
 	}
 }
 
+func NewBackendVariable(key string) (*BackendVariable, error) {
+	field := key[len("backend."):]
+	if field == "" || strings.ContainsRune(field, '.') {
+		return nil, fmt.Errorf(
+			"%s: backend variables must be two parts: backend.setting", key)
+	}
+
+	return &BackendVariable{
+		Field: field,
+		key:   key,
+	}, nil
+}
+
+func (v *BackendVariable) FullKey() string {
+	return v.key
+}
+
+func (v *BackendVariable) GoString() string {
+	return fmt.Sprintf("*%#v", *v)
+}
+
 func NewCountVariable(key string) (*CountVariable, error) {
 	var fieldType CountValueType
 	parts := strings.SplitN(key, ".", 2)
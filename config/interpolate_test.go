@@ -63,6 +63,19 @@ func TestNewInterpolatedVariable(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"backend.region",
+			&BackendVariable{
+				Field: "region",
+				key:   "backend.region",
+			},
+			false,
+		},
+		{
+			"backend.region.foo",
+			(*BackendVariable)(nil),
+			true,
+		},
 	}
 
 	for i, tc := range cases {
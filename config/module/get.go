@@ -1,8 +1,10 @@
 package module
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/hashicorp/go-getter"
 )
@@ -58,6 +60,31 @@ func GetCopy(dst, src string) error {
 	return copyDir(dst, tmpDir)
 }
 
+// PinnedStorage is a Storage that knows the sources some modules must be
+// downloaded from, such as from a lock file. The source of a module with a
+// pinned source isn't resolved again, so a registry isn't asked for its
+// versions. The pinned source is the source given to Get, without the
+// subdirectory of the module.
+type PinnedStorage interface {
+	getter.Storage
+
+	// PinnedSource returns the source pinned for the module with the
+	// given key, if any.
+	PinnedSource(key string) (string, bool)
+}
+
+// StorageKey returns the key in the storage of the module m imported at the
+// given path, which ends with the name of m. A changed version constraint
+// changes the key, so that the module is downloaded again.
+func StorageKey(path []string, m *Module) string {
+	key := fmt.Sprintf("root.%s-%s", strings.Join(path, "."), m.Source)
+	if m.Version != "" {
+		key = fmt.Sprintf("%s@%s", key, m.Version)
+	}
+
+	return key
+}
+
 func getStorage(s getter.Storage, key string, src string, mode GetMode) (string, bool, error) {
 	// Get the module with the level specified if we were told to.
 	if mode > GetModeNone {
@@ -0,0 +1,201 @@
+package module
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-version"
+)
+
+// DefaultRegistryHost is the module registry used for registry sources
+// without a host, such as "hashicorp/consul/aws".
+const DefaultRegistryHost = "registry.terraform.io"
+
+// registryClient is the HTTP client used to talk to module registries.
+// Tests replace it.
+var registryClient = cleanhttp.DefaultClient()
+
+// registrySourceRe matches a module registry source, which is
+// "NAMESPACE/NAME/PROVIDER" with an optional registry host in front.
+var registrySourceRe = regexp.MustCompile(
+	`^(?:([^/]+\.[^/]+)/)?([0-9A-Za-z][0-9A-Za-z_-]*)/([0-9A-Za-z][0-9A-Za-z_-]*)/([0-9a-z]+)$`)
+
+// RegistrySource is a module source in a module registry.
+type RegistrySource struct {
+	Host      string
+	Namespace string
+	Name      string
+	Provider  string
+}
+
+func (s *RegistrySource) String() string {
+	return fmt.Sprintf("%s/%s/%s/%s", s.Host, s.Namespace, s.Name, s.Provider)
+}
+
+// ParseRegistrySource parses a module source, without a subdirectory, as a
+// module registry source. It returns nil if the source isn't one. Since a
+// registry source looks like a relative path, a source that is a directory
+// relative to pwd is never a registry source, so existing configurations
+// keep working.
+func ParseRegistrySource(src, pwd string) *RegistrySource {
+	match := registrySourceRe.FindStringSubmatch(src)
+	if match == nil {
+		return nil
+	}
+
+	// These hosts are handled by the go-getter detectors
+	switch match[1] {
+	case "github.com", "bitbucket.org":
+		return nil
+	}
+
+	if pwd != "" {
+		if fi, err := os.Stat(filepath.Join(pwd, src)); err == nil && fi.IsDir() {
+			return nil
+		}
+	}
+
+	host := match[1]
+	if host == "" {
+		host = DefaultRegistryHost
+	}
+
+	return &RegistrySource{
+		Host:      host,
+		Namespace: match[2],
+		Name:      match[3],
+		Provider:  match[4],
+	}
+}
+
+// ResolveRegistrySource asks the registry for the highest version of the
+// module that satisfies the version constraint, which may be empty, and
+// returns the go-getter source to download that version from.
+func ResolveRegistrySource(s *RegistrySource, constraint string) (string, error) {
+	var cs version.Constraints
+	if constraint != "" {
+		var err error
+		cs, err = version.NewConstraint(constraint)
+		if err != nil {
+			return "", fmt.Errorf("invalid version constraint: %s", err)
+		}
+	}
+
+	base := &url.URL{
+		Scheme: "https",
+		Host:   s.Host,
+		Path: fmt.Sprintf("/v1/modules/%s/%s/%s/",
+			s.Namespace, s.Name, s.Provider),
+	}
+
+	v, err := registryVersion(s, base, cs)
+	if err != nil {
+		return "", err
+	}
+
+	// The download endpoint answers with the source in a header
+	u := base.ResolveReference(&url.URL{Path: v.String() + "/download"})
+	resp, err := registryClient.Get(u.String())
+	if err != nil {
+		return "", fmt.Errorf("error reaching module registry: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return "", fmt.Errorf(
+			"module registry returned %s for version %s of %s", resp.Status, v, s)
+	}
+
+	source := resp.Header.Get("X-Terraform-Get")
+	if source == "" {
+		return "", fmt.Errorf(
+			"module registry returned no source for version %s of %s", v, s)
+	}
+
+	// A relative source is relative to the download URL
+	if strings.HasPrefix(source, "/") || strings.HasPrefix(source, "./") ||
+		strings.HasPrefix(source, "../") {
+		ref, err := url.Parse(source)
+		if err != nil {
+			return "", fmt.Errorf("module registry returned an invalid source: %s", err)
+		}
+		source = u.ResolveReference(ref).String()
+	}
+
+	return source, nil
+}
+
+// registryVersions is the response of the versions endpoint of a module
+// registry.
+type registryVersions struct {
+	Modules []struct {
+		Versions []struct {
+			Version string `json:"version"`
+		} `json:"versions"`
+	} `json:"modules"`
+}
+
+// registryVersion returns the highest version of the module in the
+// registry that satisfies the constraints.
+func registryVersion(
+	s *RegistrySource,
+	base *url.URL,
+	cs version.Constraints) (*version.Version, error) {
+	u := base.ResolveReference(&url.URL{Path: "versions"})
+	resp, err := registryClient.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("error reaching module registry: %s", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("module %s not found in the registry", s)
+	default:
+		return nil, fmt.Errorf(
+			"module registry returned %s for the versions of %s", resp.Status, s)
+	}
+
+	var body registryVersions
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("error reading versions of %s: %s", s, err)
+	}
+
+	var versions version.Collection
+	for _, m := range body.Modules {
+		for _, raw := range m.Versions {
+			v, err := version.NewVersion(raw.Version)
+			if err != nil {
+				continue
+			}
+			versions = append(versions, v)
+		}
+	}
+	sort.Sort(versions)
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if cs == nil || cs.Check(versions[i]) {
+			return versions[i], nil
+		}
+	}
+
+	var available []string
+	for _, v := range versions {
+		available = append(available, v.String())
+	}
+	if len(available) == 0 {
+		available = append(available, "none")
+	}
+
+	return nil, fmt.Errorf(
+		"no version of module %s matches the version constraint %q. "+
+			"Versions available: %s", s, cs, strings.Join(available, ", "))
+}
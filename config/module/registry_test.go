@@ -0,0 +1,236 @@
+package module
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-getter"
+	"github.com/hashicorp/terraform/config"
+)
+
+func TestParseRegistrySource(t *testing.T) {
+	cases := []struct {
+		Source   string
+		Expected *RegistrySource
+	}{
+		{
+			"hashicorp/consul/aws",
+			&RegistrySource{
+				Host:      DefaultRegistryHost,
+				Namespace: "hashicorp",
+				Name:      "consul",
+				Provider:  "aws",
+			},
+		},
+		{
+			"registry.example.com/infra/vpc/aws",
+			&RegistrySource{
+				Host:      "registry.example.com",
+				Namespace: "infra",
+				Name:      "vpc",
+				Provider:  "aws",
+			},
+		},
+		{"./hashicorp/consul/aws", nil},
+		{"hashicorp/consul", nil},
+		{"github.com/hashicorp/consul/aws", nil},
+		{"git::https://example.com/consul.git", nil},
+		// A directory with the name of a registry source is a local module
+		{"basic/foo/bar", nil},
+	}
+
+	pwd := tempDir(t)
+	if err := os.MkdirAll(filepath.Join(pwd, "basic", "foo", "bar"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(pwd)
+
+	for _, tc := range cases {
+		actual := ParseRegistrySource(tc.Source, pwd)
+		if !reflect.DeepEqual(actual, tc.Expected) {
+			t.Fatalf("%s: bad: %#v", tc.Source, actual)
+		}
+	}
+}
+
+func TestResolveRegistrySource(t *testing.T) {
+	rs, closeFn := testRegistry(t, "./archive.tar.gz")
+	defer closeFn()
+
+	source, err := ResolveRegistrySource(rs, "~> 1.0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := fmt.Sprintf(
+		"https://%s/v1/modules/hashicorp/example/aws/1.2.0/archive.tar.gz", rs.Host)
+	if source != expected {
+		t.Fatalf("bad: %s", source)
+	}
+
+	// Without a constraint, the highest version is used
+	source, err = ResolveRegistrySource(rs, "")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if source != fmt.Sprintf(
+		"https://%s/v1/modules/hashicorp/example/aws/2.0.0/archive.tar.gz", rs.Host) {
+		t.Fatalf("bad: %s", source)
+	}
+
+	if _, err := ResolveRegistrySource(rs, "> 3.0"); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestTreeLoad_registry(t *testing.T) {
+	moduleDir, err := filepath.Abs(filepath.Join(fixtureDir, "basic"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	rs, closeFn := testRegistry(t, "file://"+moduleDir)
+	defer closeFn()
+
+	dir := tempDir(t)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	main := fmt.Sprintf(`
+module "example" {
+  source  = "%s/hashicorp/example/aws"
+  version = "~> 1.0"
+}
+`, rs.Host)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(main), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	c, err := config.LoadDir(dir)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	storage := testStorage(t)
+	tree := NewTree("", c)
+	if err := tree.Load(storage, GetModeGet); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if tree.Children()["example"].Children()["foo"] == nil {
+		t.Fatal("module should be loaded")
+	}
+
+	// Loading again doesn't need the registry
+	registryClient = &http.Client{Transport: failingTransport{}}
+	tree = NewTree("", c)
+	if err := tree.Load(storage, GetModeNone); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestTreeLoad_versionNotRegistry(t *testing.T) {
+	c := testConfig(t, "basic-parent")
+	c.Modules[0].Version = "1.0.0"
+
+	tree := NewTree("", c)
+	if err := tree.Load(testStorage(t), GetModeGet); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+// testRegistry starts a module registry with the versions 1.0.0, 1.2.0 and
+// 2.0.0 of the module "hashicorp/example/aws", which answers downloads with
+// the given source. The returned function closes the registry and restores
+// the registry client.
+func testRegistry(t *testing.T, source string) (*RegistrySource, func()) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/modules/hashicorp/example/aws/versions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"modules":[{"versions":[`+
+			`{"version":"1.0.0"},{"version":"2.0.0"},{"version":"1.2.0"}]}]}`)
+	})
+	mux.HandleFunc("/v1/modules/hashicorp/example/aws/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Terraform-Get", source)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	server := httptest.NewTLSServer(mux)
+	old := registryClient
+	registryClient = server.Client()
+	closeFn := func() {
+		registryClient = old
+		server.Close()
+	}
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		closeFn()
+		t.Fatalf("err: %s", err)
+	}
+
+	return &RegistrySource{
+		Host:      u.Host,
+		Namespace: "hashicorp",
+		Name:      "example",
+		Provider:  "aws",
+	}, closeFn
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("registry should not be used")
+}
+
+func TestTreeLoad_registryPinned(t *testing.T) {
+	moduleDir, err := filepath.Abs(filepath.Join(fixtureDir, "basic"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	old := registryClient
+	registryClient = &http.Client{Transport: failingTransport{}}
+	defer func() { registryClient = old }()
+
+	c := &config.Config{
+		Modules: []*config.Module{
+			&config.Module{
+				Name:    "example",
+				Source:  "hashicorp/example/aws",
+				Version: "~> 1.0",
+			},
+		},
+	}
+
+	// The registry isn't asked for a pinned module
+	storage := &testPinnedStorage{
+		Storage: testStorage(t),
+		Sources: map[string]string{
+			"root.example-hashicorp/example/aws@~> 1.0": "file://" + moduleDir,
+		},
+	}
+	tree := NewTree("", c)
+	if err := tree.Load(storage, GetModeGet); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if tree.Children()["example"].Children()["foo"] == nil {
+		t.Fatal("module should be loaded")
+	}
+}
+
+type testPinnedStorage struct {
+	getter.Storage
+	Sources map[string]string
+}
+
+func (s *testPinnedStorage) PinnedSource(key string) (string, bool) {
+	source, ok := s.Sources[key]
+	return source, ok
+}
@@ -107,8 +107,9 @@ func (t *Tree) Modules() []*Module {
 	result := make([]*Module, len(t.config.Modules))
 	for i, m := range t.config.Modules {
 		result[i] = &Module{
-			Name:   m.Name,
-			Source: m.Source,
+			Name:    m.Name,
+			Source:  m.Source,
+			Version: m.Version,
 		}
 	}
 
@@ -158,23 +159,55 @@ func (t *Tree) Load(s getter.Storage, mode GetMode) error {
 		copy(path, t.path)
 		path = append(path, m.Name)
 
+		key := StorageKey(path, m)
+
+		// A pinned source is used instead of resolving the source again
+		var pinned string
+		if ps, ok := s.(PinnedStorage); ok && mode > GetModeNone {
+			pinned, _ = ps.PinnedSource(key)
+		}
+
 		// Split out the subdir if we have one
 		source, subDir := getter.SourceDirSubdir(m.Source)
 
-		source, err := getter.Detect(source, t.config.Dir, getter.Detectors)
-		if err != nil {
-			return fmt.Errorf("module %s: %s", m.Name, err)
+		// A module from a registry is downloaded from the source that the
+		// registry gives for the chosen version, which is only asked for
+		// when downloading. A subdir in that source is left to go-getter,
+		// so that the module can be found later without the registry.
+		if rs := ParseRegistrySource(source, t.config.Dir); rs != nil {
+			if mode > GetModeNone && pinned == "" {
+				var err error
+				source, err = ResolveRegistrySource(rs, m.Version)
+				if err != nil {
+					return fmt.Errorf("module %s: %s", m.Name, err)
+				}
+			}
+		} else {
+			if m.Version != "" {
+				return fmt.Errorf(
+					"module %s: version can only be set for a module from a module registry",
+					m.Name)
+			}
+
+			var err error
+			source, err = getter.Detect(source, t.config.Dir, getter.Detectors)
+			if err != nil {
+				return fmt.Errorf("module %s: %s", m.Name, err)
+			}
+
+			// Check if the detector introduced something new.
+			var subDir2 string
+			source, subDir2 = getter.SourceDirSubdir(source)
+			if subDir2 != "" {
+				subDir = filepath.Join(subDir2, subDir)
+			}
 		}
 
-		// Check if the detector introduced something new.
-		source, subDir2 := getter.SourceDirSubdir(source)
-		if subDir2 != "" {
-			subDir = filepath.Join(subDir2, subDir)
+		if pinned != "" {
+			source = pinned
 		}
 
 		// Get the directory where this module is so we can load it
-		key := strings.Join(path, ".")
-		key = fmt.Sprintf("root.%s-%s", key, m.Source)
 		dir, ok, err := getStorage(s, key, source, mode)
 		if err != nil {
 			return err
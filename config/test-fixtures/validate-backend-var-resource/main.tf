@@ -0,0 +1,5 @@
+resource "aws_instance" "web" {
+    tags {
+        Name = "${backend.access_key}"
+    }
+}
@@ -0,0 +1,6 @@
+provider "aws" {
+    region     = "${backend.region}"
+    access_key = "${backend.access_key}"
+}
+
+resource "aws_instance" "web" {}
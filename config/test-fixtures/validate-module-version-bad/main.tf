@@ -0,0 +1,4 @@
+module "consul" {
+  source  = "hashicorp/consul/aws"
+  version = "not a version"
+}
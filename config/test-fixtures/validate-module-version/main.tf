@@ -0,0 +1,5 @@
+module "consul" {
+  source  = "hashicorp/consul/aws"
+  version = "~> 0.1"
+  servers = 3
+}
@@ -0,0 +1,3 @@
+provider "aws" {
+  version = "not a version"
+}
@@ -0,0 +1,3 @@
+terraform {
+    policy_command = "check ${var.foo}"
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/hashicorp/terraform/command"
 )
 
 // This is the directory where our test fixtures are.
@@ -27,6 +31,30 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_credentials(t *testing.T) {
+	c, err := LoadConfig(filepath.Join(fixtureDir, "config-credentials"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := &Config{
+		Credentials: map[string]map[string]interface{}{
+			"example.com": map[string]interface{}{
+				"token": "foo",
+			},
+		},
+		CredentialsHelpers: map[string]*ConfigCredentialsHelper{
+			"test": &ConfigCredentialsHelper{
+				Args: []string{"--bar"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(c, expected) {
+		t.Fatalf("bad: %#v", c)
+	}
+}
+
 func TestConfig_Merge(t *testing.T) {
 	c1 := &Config{
 		Providers: map[string]string{
@@ -59,6 +87,12 @@ func TestConfig_Merge(t *testing.T) {
 			"local":  "local",
 			"remote": "remote",
 		},
+
+		// The providers set in c2 take precedence over plugins found
+		ProviderPlugins: []command.ProviderPlugin{
+			command.ProviderPlugin{Name: "bar", Path: "baz"},
+			command.ProviderPlugin{Name: "baz", Path: "what"},
+		},
 	}
 
 	actual := c1.Merge(c2)
@@ -104,3 +138,109 @@ func TestConfig_Merge_disableCheckpointSignature(t *testing.T) {
 		t.Fatalf("bad: %#v", actual)
 	}
 }
+
+func TestConfig_Merge_auditLog(t *testing.T) {
+	c1 := &Config{
+		AuditLog: "/var/log/terraform.log",
+	}
+
+	c2 := &Config{
+		AuditLog: "~/terraform.log",
+	}
+
+	expected := &Config{
+		Providers:    map[string]string{},
+		Provisioners: map[string]string{},
+		AuditLog:     "~/terraform.log",
+	}
+
+	actual := c1.Merge(c2)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	actual = c1.Merge(&Config{})
+	if actual.AuditLog != c1.AuditLog {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestConfig_Merge_statsd(t *testing.T) {
+	c1 := &Config{
+		StatsdAddress: "127.0.0.1:8125",
+		StatsdPrefix:  "tf",
+	}
+
+	c2 := &Config{
+		StatsdAddress: "statsd.example.com:8125",
+	}
+
+	expected := &Config{
+		Providers:     map[string]string{},
+		Provisioners:  map[string]string{},
+		StatsdAddress: "statsd.example.com:8125",
+		StatsdPrefix:  "tf",
+	}
+
+	actual := c1.Merge(c2)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestConfig_discoverProviders(t *testing.T) {
+	td, err := ioutil.TempDir("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	for _, name := range []string{
+		"terraform-provider-aws_v1.10.0",
+		"terraform-provider-aws_v1.2.0",
+		"terraform-provider-aws",
+		"terraform-provider-null.exe",
+		"terraform-provisioner-local",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(td, name), nil, 0755); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	var c Config
+	if err := c.discover(td); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []command.ProviderPlugin{
+		command.ProviderPlugin{
+			Name: "aws",
+			Path: filepath.Join(td, "terraform-provider-aws"),
+		},
+		command.ProviderPlugin{
+			Name: "null",
+			Path: filepath.Join(td, "terraform-provider-null.exe"),
+		},
+		command.ProviderPlugin{
+			Name:    "aws",
+			Version: "1.2.0",
+			Path:    filepath.Join(td, "terraform-provider-aws_v1.2.0"),
+		},
+		command.ProviderPlugin{
+			Name:    "aws",
+			Version: "1.10.0",
+			Path:    filepath.Join(td, "terraform-provider-aws_v1.10.0"),
+		},
+	}
+	if !reflect.DeepEqual(c.ProviderPlugins, expected) {
+		t.Fatalf("bad: %#v", c.ProviderPlugins)
+	}
+
+	// The highest version is used by default
+	if c.Providers["aws"] != filepath.Join(td, "terraform-provider-aws_v1.10.0") {
+		t.Fatalf("bad: %#v", c.Providers)
+	}
+	if _, ok := c.Provisioners["local"]; !ok {
+		t.Fatalf("bad: %#v", c.Provisioners)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform/state/remote"
+)
+
+// credentialsHelperPrefix is the prefix of the name of credentials helper
+// programs. The helper named "foo" in the configuration is the program
+// "terraform-credentials-foo".
+const credentialsHelperPrefix = "terraform-credentials-"
+
+// osCredentialsHelperName is the name of the built-in credentials helper,
+// which reads tokens from the credential store of the operating system.
+// Tokens are stored with the service (or target prefix) osCredentialsService
+// and the host name as the account.
+const (
+	osCredentialsHelperName = "os"
+	osCredentialsService    = "terraform"
+)
+
+// CredentialsFunc returns the function used by remote state clients to
+// look up the token for a host.
+//
+// Tokens set in credentials blocks are used first. For other hosts the
+// credentials helper, if one is configured, is run as
+//
+//     terraform-credentials-NAME [ARGS...] get HOST
+//
+// and must write a JSON object to stdout. The "token" property of the
+// object is the token; an empty object means there are no credentials
+// for the host. The helper named "os" is built in, see osCredentials.
+func (c *Config) CredentialsFunc() (remote.CredentialsFunc, error) {
+	if len(c.CredentialsHelpers) > 1 {
+		return nil, fmt.Errorf("only one credentials_helper block is allowed")
+	}
+
+	var helper remote.CredentialsFunc
+	for name, h := range c.CredentialsHelpers {
+		if name == osCredentialsHelperName {
+			helper = osCredentials
+			continue
+		}
+
+		path, err := credentialsHelperPath(credentialsHelperPrefix + name)
+		if err != nil {
+			return nil, fmt.Errorf("credentials helper %q: %s", name, err)
+		}
+
+		command := append([]string{path}, h.Args...)
+		helper = func(host string) (string, error) {
+			return credentialsFromHelper(command, host)
+		}
+	}
+
+	return func(host string) (string, error) {
+		if creds, ok := c.Credentials[host]; ok {
+			token, _ := creds["token"].(string)
+			return token, nil
+		}
+
+		if helper == nil {
+			return "", nil
+		}
+
+		return helper(host)
+	}, nil
+}
+
+// credentialsHelperPath finds the credentials helper program with the
+// given name, looking in the plugins directory before the PATH.
+func credentialsHelperPath(name string) (string, error) {
+	if dir, err := ConfigDir(); err == nil {
+		path := filepath.Join(dir, "plugins", name)
+		if _, err := exec.LookPath(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return exec.LookPath(name)
+}
+
+func credentialsFromHelper(helper []string, host string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	args := make([]string, 0, len(helper)+1)
+	args = append(args, helper[1:]...)
+	args = append(args, "get", host)
+	cmd := exec.Command(helper[0], args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf(
+			"credentials helper failed: %s\n\n%s",
+			err, strings.TrimSpace(stderr.String()))
+	}
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return "", fmt.Errorf("credentials helper returned invalid JSON: %s", err)
+	}
+
+	return result.Token, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// osCredentials reads the token for a host from the macOS Keychain. The
+// token is the password of the generic password item with the service
+// "terraform" and the host as the account, which "terraform credentials
+// store" adds with osStoreCredentials.
+func osCredentials(host string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(
+		"security", "find-generic-password",
+		"-s", osCredentialsService, "-a", host, "-w")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// security exits with errSecItemNotFound (44) if there's no item
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.ExitStatus() == 44 {
+				return "", nil
+			}
+		}
+
+		return "", fmt.Errorf(
+			"reading the keychain failed: %s\n\n%s",
+			err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// osStoreCredentials stores the token for a host in the macOS Keychain,
+// replacing the one stored before. security only takes the password as an
+// argument or from a terminal, so it's given in the commands read from
+// stdin in interactive mode, to keep it out of the process list.
+func osStoreCredentials(host, token string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf(
+		"add-generic-password -U -s %s -a %s -w %s\n",
+		securityQuote(osCredentialsService), securityQuote(host), securityQuote(token)))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf(
+			"writing the keychain failed: %s\n\n%s",
+			err, strings.TrimSpace(stderr.String()))
+	}
+
+	// security doesn't fail in interactive mode when a command fails
+	if stderr.Len() > 0 {
+		return fmt.Errorf(
+			"writing the keychain failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// securityQuote quotes an argument of a command of security's interactive
+// mode.
+func securityQuote(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "\"", "\\\"", -1)
+	return "\"" + s + "\""
+}
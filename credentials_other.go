@@ -0,0 +1,52 @@
+// +build !darwin,!windows
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// osCredentials reads the token for a host from the Secret Service (such
+// as GNOME Keyring or KWallet) using libsecret's secret-tool. The token is
+// the secret with the attributes service=terraform and host=HOST, which
+// "terraform credentials store" stores with osStoreCredentials.
+func osCredentials(host string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(
+		"secret-tool", "lookup", "service", osCredentialsService, "host", host)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// secret-tool fails without output if there is no such secret
+		if _, ok := err.(*exec.ExitError); ok && stdout.Len() == 0 && stderr.Len() == 0 {
+			return "", nil
+		}
+
+		return "", fmt.Errorf(
+			"reading the secret service failed: %s\n\n%s",
+			err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// osStoreCredentials stores the token for a host in the Secret Service,
+// replacing the one stored before. secret-tool reads the secret from stdin.
+func osStoreCredentials(host, token string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(
+		"secret-tool", "store", "--label=Terraform "+host,
+		"service", osCredentialsService, "host", host)
+	cmd.Stdin = strings.NewReader(token)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf(
+			"writing the secret service failed: %s\n\n%s",
+			err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestConfigCredentialsFunc(t *testing.T) {
+	c := &Config{
+		Credentials: map[string]map[string]interface{}{
+			"example.com": map[string]interface{}{
+				"token": "foo",
+			},
+		},
+	}
+
+	f, err := c.CredentialsFunc()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if token, err := f("example.com"); err != nil || token != "foo" {
+		t.Fatalf("bad: %q %s", token, err)
+	}
+	if token, err := f("other.com"); err != nil || token != "" {
+		t.Fatalf("bad: %q %s", token, err)
+	}
+}
+
+func TestConfigCredentialsFunc_helper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test helper is a shell script")
+	}
+
+	td, err := ioutil.TempDir("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	// The helper echoes its arguments back as the token
+	script := "#!/bin/sh\necho \"{\\\"token\\\": \\\"$*\\\"}\"\n"
+	path := filepath.Join(td, credentialsHelperPrefix+"test")
+	if err := ioutil.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", td)
+
+	c := &Config{
+		Credentials: map[string]map[string]interface{}{
+			"example.com": map[string]interface{}{
+				"token": "foo",
+			},
+		},
+		CredentialsHelpers: map[string]*ConfigCredentialsHelper{
+			"test": &ConfigCredentialsHelper{Args: []string{"--bar"}},
+		},
+	}
+
+	f, err := c.CredentialsFunc()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Credentials blocks take precedence over the helper
+	if token, err := f("example.com"); err != nil || token != "foo" {
+		t.Fatalf("bad: %q %s", token, err)
+	}
+	if token, err := f("other.com"); err != nil || token != "--bar get other.com" {
+		t.Fatalf("bad: %q %s", token, err)
+	}
+}
+
+func TestConfigCredentialsFunc_helperMissing(t *testing.T) {
+	c := &Config{
+		CredentialsHelpers: map[string]*ConfigCredentialsHelper{
+			"does-not-exist": &ConfigCredentialsHelper{},
+		},
+	}
+
+	if _, err := c.CredentialsFunc(); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestConfigCredentialsFunc_osHelper(t *testing.T) {
+	c := &Config{
+		Credentials: map[string]map[string]interface{}{
+			"example.com": map[string]interface{}{
+				"token": "foo",
+			},
+		},
+		CredentialsHelpers: map[string]*ConfigCredentialsHelper{
+			osCredentialsHelperName: &ConfigCredentialsHelper{},
+		},
+	}
+
+	// The built-in helper doesn't need a program
+	f, err := c.CredentialsFunc()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Credentials blocks still take precedence, without asking the
+	// credential store
+	if token, err := f("example.com"); err != nil || token != "foo" {
+		t.Fatalf("bad: %q %s", token, err)
+	}
+}
@@ -0,0 +1,106 @@
+// +build windows
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+)
+
+var (
+	modadvapi32    = syscall.NewLazyDLL("advapi32.dll")
+	procCredReadW  = modadvapi32.NewProc("CredReadW")
+	procCredWriteW = modadvapi32.NewProc("CredWriteW")
+	procCredFree   = modadvapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = 1168
+)
+
+// winCredential is the CREDENTIALW structure.
+type winCredential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// osCredentials reads the token for a host from the Windows Credential
+// Manager. The token is the password of the generic credential with the
+// target "terraform:HOST", which "terraform credentials store" adds with
+// osStoreCredentials.
+func osCredentials(host string) (string, error) {
+	target, err := syscall.UTF16PtrFromString(osCredentialsService + ":" + host)
+	if err != nil {
+		return "", err
+	}
+
+	var cred *winCredential
+	r, _, err := procCredReadW.Call(
+		uintptr(unsafe.Pointer(target)),
+		credTypeGeneric,
+		0,
+		uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if errno, ok := err.(syscall.Errno); ok && errno == errorNotFound {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("reading the credential manager failed: %s", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	// Passwords stored by cmdkey and the control panel are UTF-16
+	n := int(cred.CredentialBlobSize) / 2
+	if n == 0 {
+		return "", nil
+	}
+	blob := (*[1 << 20]uint16)(unsafe.Pointer(cred.CredentialBlob))[:n:n]
+
+	return string(utf16.Decode(blob)), nil
+}
+
+// osStoreCredentials stores the token for a host in the Windows Credential
+// Manager, replacing the one stored before. The token is stored as UTF-16,
+// like the passwords stored by cmdkey and the control panel.
+func osStoreCredentials(host, token string) error {
+	target, err := syscall.UTF16PtrFromString(osCredentialsService + ":" + host)
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(osCredentialsService)
+	if err != nil {
+		return err
+	}
+
+	blob := utf16.Encode([]rune(token))
+	cred := winCredential{
+		Type:       credTypeGeneric,
+		TargetName: target,
+		Persist:    credPersistLocalMachine,
+		UserName:   user,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlobSize = uint32(len(blob) * 2)
+		cred.CredentialBlob = (*byte)(unsafe.Pointer(&blob[0]))
+	}
+	r, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if r == 0 {
+		return fmt.Errorf("writing the credential manager failed: %s", err)
+	}
+
+	return nil
+}
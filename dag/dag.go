@@ -138,7 +138,9 @@ func (g *AcyclicGraph) Validate() error {
 			}
 
 			err = multierror.Append(err, fmt.Errorf(
-				"Cycle: %s", strings.Join(cycleStr, ", ")))
+				"Cycle: %s\n\n  Edges involved in the cycle:\n%s",
+				strings.Join(cycleStr, ", "),
+				strings.Join(g.cycleEdges(cycle), "\n")))
 		}
 	}
 
@@ -153,6 +155,30 @@ func (g *AcyclicGraph) Validate() error {
 	return err
 }
 
+// cycleEdges returns the sorted edges between the vertices of a cycle, in
+// the form "source -> target", to explain how the cycle is formed.
+func (g *AcyclicGraph) cycleEdges(cycle []Vertex) []string {
+	members := make(map[interface{}]struct{}, len(cycle))
+	for _, v := range cycle {
+		members[hashcode(v)] = struct{}{}
+	}
+
+	var result []string
+	for _, v := range cycle {
+		for _, raw := range g.DownEdges(v).List() {
+			if _, ok := members[hashcode(raw)]; !ok {
+				continue
+			}
+
+			result = append(result, fmt.Sprintf(
+				"    %s -> %s", VertexName(v), VertexName(raw)))
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
 func (g *AcyclicGraph) Cycles() [][]Vertex {
 	var cycles [][]Vertex
 	for _, cycle := range StronglyConnected(&g.Graph) {
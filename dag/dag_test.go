@@ -129,9 +129,19 @@ func TestAcyclicGraphValidate_cycle(t *testing.T) {
 	g.Connect(BasicEdge(1, 2))
 	g.Connect(BasicEdge(2, 1))
 
-	if err := g.Validate(); err == nil {
+	err := g.Validate()
+	if err == nil {
 		t.Fatal("should error")
 	}
+
+	// The edges forming the cycle are listed, but not the others
+	if !strings.Contains(err.Error(), "1 -> 2") ||
+		!strings.Contains(err.Error(), "2 -> 1") {
+		t.Fatalf("edges should be listed: %s", err)
+	}
+	if strings.Contains(err.Error(), "3 ->") {
+		t.Fatalf("only cycle edges should be listed: %s", err)
+	}
 }
 
 func TestAcyclicGraphValidate_cycleSelf(t *testing.T) {
@@ -0,0 +1,162 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/logutils"
+)
+
+// EnvLogComponentPrefix is the prefix of the environmental variables that
+// set the log level of a single component, such as TF_LOG_REMOTE=debug.
+// The log lines of a component are written if they are at the level of
+// the component or above, whatever the level set by TF_LOG.
+const EnvLogComponentPrefix = "TF_LOG_"
+
+// Logger writes leveled log lines tagged with a component, in the form
+// "[DEBUG] remote: message", so that they can be filtered by component.
+type Logger struct {
+	Component string
+}
+
+// NewLogger returns a Logger for the given component.
+func NewLogger(component string) *Logger {
+	return &Logger{Component: component}
+}
+
+func (l *Logger) Trace(format string, v ...interface{}) { l.printf("TRACE", format, v...) }
+func (l *Logger) Debug(format string, v ...interface{}) { l.printf("DEBUG", format, v...) }
+func (l *Logger) Info(format string, v ...interface{})  { l.printf("INFO", format, v...) }
+func (l *Logger) Warn(format string, v ...interface{})  { l.printf("WARN", format, v...) }
+func (l *Logger) Error(format string, v ...interface{}) { l.printf("ERROR", format, v...) }
+
+func (l *Logger) printf(level, format string, v ...interface{}) {
+	log.Printf("[%s] %s: %s", level, l.Component, fmt.Sprintf(format, v...))
+}
+
+// ComponentLevels returns the log levels of the components set with
+// TF_LOG_<COMPONENT>, keyed by the lower case component name.
+func ComponentLevels() map[string]logutils.LogLevel {
+	result := make(map[string]logutils.LogLevel)
+	for _, kv := range os.Environ() {
+		idx := strings.Index(kv, "=")
+		if idx == -1 || !strings.HasPrefix(kv, EnvLogComponentPrefix) {
+			continue
+		}
+
+		name, value := kv[:idx], kv[idx+1:]
+		if name == EnvLogFile || value == "" {
+			continue
+		}
+		if !isValidLogLevel(value) {
+			log.Printf("[WARN] Invalid log level for %s: %q. Valid levels are: %+v",
+				name, value, validLevels)
+			continue
+		}
+
+		component := strings.ToLower(strings.TrimPrefix(name, EnvLogComponentPrefix))
+		result[component] = logutils.LogLevel(strings.ToUpper(value))
+	}
+
+	return result
+}
+
+// componentFilter is an io.Writer that filters log lines by their level,
+// using the level of their component if one is set, and optionally writes
+// them as JSON objects.
+type componentFilter struct {
+	// MinLevel is the level of the lines of any component. If it's empty,
+	// only the lines of the components in Components are written.
+	MinLevel   logutils.LogLevel
+	Components map[string]logutils.LogLevel
+
+	// JSON writes each line as a JSON object with the keys "@timestamp",
+	// "@level", "@component" and "@message".
+	JSON bool
+
+	Writer io.Writer
+}
+
+// logLinePrefix matches the timestamp written by the standard logger, the
+// level and the component of a log line.
+var logLinePrefix = regexp.MustCompile(
+	`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)? )?(?:\[([A-Z]+)\] )?(?:([a-z0-9-]+): )?`)
+
+func (f *componentFilter) Write(p []byte) (int, error) {
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		m := logLinePrefix.FindSubmatch(line)
+		timestamp, level, component := string(m[1]), string(m[2]), string(m[3])
+		if !f.check(logutils.LogLevel(level), component) {
+			continue
+		}
+
+		if !f.JSON {
+			buf.Write(line)
+			continue
+		}
+
+		t, err := time.ParseInLocation("2006/01/02 15:04:05 ", timestamp, time.Local)
+		if err != nil {
+			t = time.Now()
+		}
+		data, err := json.Marshal(map[string]string{
+			"@timestamp": t.Format(time.RFC3339),
+			"@level":     strings.ToLower(level),
+			"@component": component,
+			"@message":   strings.TrimRight(string(line[len(m[0]):]), "\n"),
+		})
+		if err != nil {
+			return 0, err
+		}
+		buf.Write(data)
+		buf.WriteString("\n")
+	}
+
+	if buf.Len() > 0 {
+		if _, err := f.Writer.Write(buf.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// check returns whether a line with the given level and component is
+// written. Lines without a level are written as long as a minimum level
+// is set, like with logutils.LevelFilter.
+func (f *componentFilter) check(level logutils.LogLevel, component string) bool {
+	min, ok := f.Components[component]
+	if !ok {
+		min = f.MinLevel
+	}
+	if min == "" {
+		return false
+	}
+	if level == "" {
+		return true
+	}
+
+	return levelIndex(level) >= levelIndex(min)
+}
+
+func levelIndex(level logutils.LogLevel) int {
+	for i, l := range validLevels {
+		if l == level {
+			return i
+		}
+	}
+
+	return -1
+}
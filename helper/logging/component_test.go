@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/logutils"
+)
+
+func TestComponentFilter(t *testing.T) {
+	var buf bytes.Buffer
+	f := &componentFilter{
+		MinLevel: "WARN",
+		Components: map[string]logutils.LogLevel{
+			"remote": "DEBUG",
+		},
+		Writer: &buf,
+	}
+
+	input := strings.Join([]string{
+		"2017/01/02 03:04:05 [DEBUG] remote: kept",
+		"2017/01/02 03:04:05 [TRACE] remote: dropped",
+		"2017/01/02 03:04:05 [DEBUG] plugin: dropped",
+		"2017/01/02 03:04:05 [WARN] plugin: kept",
+		"2017/01/02 03:04:05 [INFO] dropped",
+		"no level is kept",
+		"",
+	}, "\n")
+	if _, err := f.Write([]byte(input)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := strings.Join([]string{
+		"2017/01/02 03:04:05 [DEBUG] remote: kept",
+		"2017/01/02 03:04:05 [WARN] plugin: kept",
+		"no level is kept",
+		"",
+	}, "\n")
+	if actual := buf.String(); actual != expected {
+		t.Fatalf("bad:\n\n%s", actual)
+	}
+}
+
+func TestComponentFilter_componentOnly(t *testing.T) {
+	var buf bytes.Buffer
+	f := &componentFilter{
+		Components: map[string]logutils.LogLevel{
+			"remote": "INFO",
+		},
+		Writer: &buf,
+	}
+
+	input := "[INFO] remote: kept\n[ERROR] plugin: dropped\nno level is dropped\n"
+	if _, err := f.Write([]byte(input)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if actual := buf.String(); actual != "[INFO] remote: kept\n" {
+		t.Fatalf("bad: %q", actual)
+	}
+}
+
+func TestComponentFilter_json(t *testing.T) {
+	var buf bytes.Buffer
+	f := &componentFilter{
+		MinLevel: "TRACE",
+		JSON:     true,
+		Writer:   &buf,
+	}
+
+	input := "2017/01/02 03:04:05 [DEBUG] remote: Uploading state\n"
+	if _, err := f.Write([]byte(input)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var actual map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &actual); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.HasPrefix(actual["@timestamp"], "2017-01-02T03:04:05") {
+		t.Fatalf("bad: %#v", actual)
+	}
+	delete(actual, "@timestamp")
+
+	expected := map[string]string{
+		"@level":     "debug",
+		"@component": "remote",
+		"@message":   "Uploading state",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestComponentLevels(t *testing.T) {
+	defer os.Setenv("TF_LOG_REMOTE", os.Getenv("TF_LOG_REMOTE"))
+	defer os.Setenv("TF_LOG_PATH", os.Getenv("TF_LOG_PATH"))
+	os.Setenv("TF_LOG_REMOTE", "debug")
+	os.Setenv("TF_LOG_PATH", "terraform.log")
+
+	actual := ComponentLevels()
+	if actual["remote"] != "DEBUG" {
+		t.Fatalf("bad: %#v", actual)
+	}
+	if _, ok := actual["path"]; ok {
+		t.Fatalf("TF_LOG_PATH isn't a component: %#v", actual)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger("remote")
+
+	defer setLogOutput(&buf)()
+	l.Warn("retrying in %s", "1s")
+
+	if !strings.HasSuffix(buf.String(), "[WARN] remote: retrying in 1s\n") {
+		t.Fatalf("bad: %q", buf.String())
+	}
+}
+
+// setLogOutput sets the output of the standard logger and returns a
+// function that restores it.
+func setLogOutput(w *bytes.Buffer) func() {
+	log.SetOutput(w)
+	return func() { log.SetOutput(os.Stderr) }
+}
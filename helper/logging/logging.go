@@ -18,6 +18,10 @@ const (
 	EnvLogFile = "TF_LOG_PATH" // Set to a file
 )
 
+// LogJSON is the value of TF_LOG that writes all the log lines as JSON
+// objects, for log processing tools.
+const LogJSON = "json"
+
 var validLevels = []logutils.LogLevel{"TRACE", "DEBUG", "INFO", "WARN", "ERROR"}
 
 // LogOutput determines where we should send logs (if anywhere) and the log level.
@@ -25,7 +29,8 @@ func LogOutput() (logOutput io.Writer, err error) {
 	logOutput = ioutil.Discard
 
 	logLevel := LogLevel()
-	if logLevel == "" {
+	components := ComponentLevels()
+	if logLevel == "" && len(components) == 0 {
 		return
 	}
 
@@ -39,10 +44,20 @@ func LogOutput() (logOutput io.Writer, err error) {
 	}
 
 	// This was the default since the beginning
-	logOutput = &logutils.LevelFilter{
-		Levels:   validLevels,
-		MinLevel: logutils.LogLevel(logLevel),
-		Writer:   logOutput,
+	if len(components) == 0 && !IsJSON() {
+		logOutput = &logutils.LevelFilter{
+			Levels:   validLevels,
+			MinLevel: logutils.LogLevel(logLevel),
+			Writer:   logOutput,
+		}
+		return
+	}
+
+	logOutput = &componentFilter{
+		MinLevel:   logutils.LogLevel(logLevel),
+		Components: components,
+		JSON:       IsJSON(),
+		Writer:     logOutput,
 	}
 
 	return
@@ -72,7 +87,9 @@ func LogLevel() string {
 	}
 
 	logLevel := "TRACE"
-	if isValidLogLevel(envLevel) {
+	if strings.ToLower(envLevel) == LogJSON {
+		// All the log lines are written as JSON
+	} else if isValidLogLevel(envLevel) {
 		// allow following for better ux: info, Info or INFO
 		logLevel = strings.ToUpper(envLevel)
 	} else {
@@ -83,6 +100,11 @@ func LogLevel() string {
 	return logLevel
 }
 
+// IsJSON returns whether the log lines are written as JSON.
+func IsJSON() bool {
+	return strings.ToLower(os.Getenv(EnvLog)) == LogJSON
+}
+
 // IsDebugOrHigher returns whether or not the current log level is debug or trace
 func IsDebugOrHigher() bool {
 	level := string(LogLevel())
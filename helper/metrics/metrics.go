@@ -0,0 +1,123 @@
+// Package metrics records counters, timings and sizes of Terraform's
+// operations, such as reading and writing the remote state, and sends them
+// to a statsd server so that Terraform can be monitored across machines.
+//
+// Nothing is recorded until a sink is set with SetSink.
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPrefix is the prefix of the names of the metrics if no prefix is
+// configured.
+const DefaultPrefix = "terraform"
+
+// Sink receives the metrics recorded.
+type Sink interface {
+	// IncrCounter adds v to the counter with the given name.
+	IncrCounter(name string, v int64)
+
+	// AddTiming records the duration of an operation.
+	AddTiming(name string, d time.Duration)
+
+	// SetGauge sets the gauge with the given name, such as the size of
+	// the last state written, to v.
+	SetGauge(name string, v int64)
+}
+
+var (
+	sink     Sink
+	sinkLock sync.RWMutex
+)
+
+// SetSink sets the sink that the metrics are sent to. A nil sink disables
+// recording metrics.
+func SetSink(s Sink) {
+	sinkLock.Lock()
+	defer sinkLock.Unlock()
+	sink = s
+}
+
+// Enabled returns true if a sink is set, so that callers can avoid the
+// cost of measuring when nothing is recorded.
+func Enabled() bool {
+	return currentSink() != nil
+}
+
+// IncrCounter adds v to the counter with the given name.
+func IncrCounter(name string, v int64) {
+	if s := currentSink(); s != nil {
+		s.IncrCounter(name, v)
+	}
+}
+
+// MeasureSince records the time since start as the duration of the
+// operation with the given name.
+func MeasureSince(name string, start time.Time) {
+	if s := currentSink(); s != nil {
+		s.AddTiming(name, time.Since(start))
+	}
+}
+
+// SetGauge sets the gauge with the given name to v.
+func SetGauge(name string, v int64) {
+	if s := currentSink(); s != nil {
+		s.SetGauge(name, v)
+	}
+}
+
+func currentSink() Sink {
+	sinkLock.RLock()
+	defer sinkLock.RUnlock()
+	return sink
+}
+
+// StatsdSink is a Sink that sends the metrics to a statsd server over UDP,
+// one packet per metric. Sending is best effort: a metric that can't be
+// sent is dropped, so that monitoring never fails a command.
+type StatsdSink struct {
+	conn   net.Conn
+	prefix string
+}
+
+// NewStatsdSink returns a StatsdSink that sends to the statsd server at
+// addr, such as "127.0.0.1:8125", with the names of the metrics prefixed
+// with prefix and a dot.
+func NewStatsdSink(addr, prefix string) (*StatsdSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting to statsd at %s: %s", addr, err)
+	}
+
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	return &StatsdSink{conn: conn, prefix: strings.TrimSuffix(prefix, ".")}, nil
+}
+
+func (s *StatsdSink) IncrCounter(name string, v int64) {
+	s.send(name, fmt.Sprintf("%d|c", v))
+}
+
+func (s *StatsdSink) AddTiming(name string, d time.Duration) {
+	s.send(name, fmt.Sprintf("%d|ms", int64(d/time.Millisecond)))
+}
+
+func (s *StatsdSink) SetGauge(name string, v int64) {
+	s.send(name, fmt.Sprintf("%d|g", v))
+}
+
+// Close closes the connection to the statsd server.
+func (s *StatsdSink) Close() error {
+	return s.conn.Close()
+}
+
+func (s *StatsdSink) send(name, value string) {
+	s.conn.Write([]byte(fmt.Sprintf("%s.%s:%s", s.prefix, name, value)))
+}
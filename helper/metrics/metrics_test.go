@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsdSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer conn.Close()
+
+	s, err := NewStatsdSink(conn.LocalAddr().String(), "")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer s.Close()
+
+	SetSink(s)
+	defer SetSink(nil)
+
+	IncrCounter("remote.s3.get.errors", 1)
+	MeasureSince("remote.s3.get", time.Now().Add(-1500*time.Millisecond))
+	SetGauge("remote.s3.put.bytes", 1024)
+
+	var actual []string
+	buf := make([]byte, 512)
+	for i := 0; i < 3; i++ {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		actual = append(actual, string(buf[:n]))
+	}
+
+	// The timing may be a few milliseconds longer
+	timing := actual[1]
+	if !strings.HasPrefix(timing, "terraform.remote.s3.get:15") || !strings.HasSuffix(timing, "|ms") {
+		t.Fatalf("bad: %s", timing)
+	}
+
+	expected := []string{
+		"terraform.remote.s3.get.errors:1|c",
+		timing,
+		"terraform.remote.s3.put.bytes:1024|g",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	SetSink(nil)
+	if Enabled() {
+		t.Fatal("should be disabled")
+	}
+
+	// Recording without a sink does nothing
+	IncrCounter("foo", 1)
+	MeasureSince("foo", time.Now())
+	SetGauge("foo", 1)
+}
@@ -258,6 +258,7 @@ func (p *Provider) Resources() []terraform.ResourceType {
 		result = append(result, terraform.ResourceType{
 			Name:       k,
 			Importable: resource.Importer != nil,
+			Listable:   resource.List != nil,
 		})
 	}
 
@@ -313,6 +314,34 @@ func (p *Provider) ImportState(
 	return states, nil
 }
 
+// ListResources implementation of terraform.ResourceProvider interface.
+func (p *Provider) ListResources(
+	info *terraform.InstanceInfo) ([]*terraform.InstanceState, error) {
+	r, ok := p.ResourcesMap[info.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown resource type: %s", info.Type)
+	}
+
+	if r.List == nil {
+		return nil, fmt.Errorf("resource %s doesn't support listing", info.Type)
+	}
+
+	ids, err := r.List(p.meta)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]*terraform.InstanceState, len(ids))
+	for i, id := range ids {
+		states[i] = &terraform.InstanceState{
+			ID:        id,
+			Ephemeral: terraform.EphemeralState{Type: info.Type},
+		}
+	}
+
+	return states, nil
+}
+
 // ValidateDataSource implementation of terraform.ResourceProvider interface.
 func (p *Provider) ValidateDataSource(
 	t string, c *terraform.ResourceConfig) ([]string, []error) {
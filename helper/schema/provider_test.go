@@ -123,6 +123,19 @@ func TestProviderResources(t *testing.T) {
 				terraform.ResourceType{Name: "foo"},
 			},
 		},
+
+		{
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"foo": &Resource{
+						List: func(interface{}) ([]string, error) { return nil, nil },
+					},
+				},
+			},
+			Result: []terraform.ResourceType{
+				terraform.ResourceType{Name: "foo", Listable: true},
+			},
+		},
 	}
 
 	for i, tc := range cases {
@@ -317,6 +330,47 @@ func TestProviderImportState_setsType(t *testing.T) {
 	}
 }
 
+func TestProviderListResources(t *testing.T) {
+	var metaVal interface{}
+	p := &Provider{
+		ResourcesMap: map[string]*Resource{
+			"foo": &Resource{
+				List: func(meta interface{}) ([]string, error) {
+					metaVal = meta
+					return []string{"a", "b"}, nil
+				},
+			},
+		},
+	}
+	p.SetMeta(42)
+
+	states, err := p.ListResources(&terraform.InstanceInfo{Type: "foo"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if metaVal != 42 {
+		t.Fatalf("bad: %#v", metaVal)
+	}
+	if len(states) != 2 || states[0].ID != "a" || states[1].ID != "b" {
+		t.Fatalf("bad: %#v", states)
+	}
+	if states[0].Ephemeral.Type != "foo" {
+		t.Fatal("should set type")
+	}
+}
+
+func TestProviderListResources_unsupported(t *testing.T) {
+	p := &Provider{
+		ResourcesMap: map[string]*Resource{
+			"foo": &Resource{},
+		},
+	}
+
+	if _, err := p.ListResources(&terraform.InstanceInfo{Type: "foo"}); err == nil {
+		t.Fatal("should error")
+	}
+}
+
 func TestProviderMeta(t *testing.T) {
 	p := new(Provider)
 	if v := p.Meta(); v != nil {
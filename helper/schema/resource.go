@@ -90,6 +90,11 @@ type Resource struct {
 	// by InternalValidate on Resource.
 	Importer *ResourceImporter
 
+	// List is called to list the IDs of all the resources of this type
+	// that the provider can see, to find the ones that aren't managed by
+	// Terraform. If this is nil, the resources can't be listed.
+	List ListFunc
+
 	// If non-empty, this string is emitted as a warning during Validate.
 	// This is a private interface for now, for use by DataSourceResourceShim,
 	// and not for general use. (But maybe later...)
@@ -111,6 +116,9 @@ type DeleteFunc func(*ResourceData, interface{}) error
 // See Resource documentation.
 type ExistsFunc func(*ResourceData, interface{}) (bool, error)
 
+// See Resource documentation.
+type ListFunc func(interface{}) ([]string, error)
+
 // See Resource documentation.
 type StateMigrateFunc func(
 	int, *terraform.InstanceState, interface{}) (*terraform.InstanceState, error)
@@ -8,16 +8,25 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/terraform/command"
 	"github.com/hashicorp/terraform/helper/logging"
+	"github.com/hashicorp/terraform/helper/metrics"
+	"github.com/hashicorp/terraform/state/remote"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mattn/go-colorable"
 	"github.com/mitchellh/cli"
+	"github.com/mitchellh/go-homedir"
 	"github.com/mitchellh/panicwrap"
 	"github.com/mitchellh/prefixedio"
 )
 
+// replicaTimeout is how long Terraform waits for the state to be copied
+// to its replica before it exits.
+const replicaTimeout = 30 * time.Second
+
 func main() {
 	// Override global prefix set by go-dynect during init()
 	log.SetPrefix("")
@@ -123,6 +132,13 @@ func wrappedMain() int {
 		config = *config.Merge(usrcfg)
 	}
 
+	// Remote state clients look up credentials using the CLI configuration
+	remote.Credentials, err = config.CredentialsFunc()
+	if err != nil {
+		Ui.Error(fmt.Sprintf("Error loading CLI configuration: \n\n%s", err))
+		return 1
+	}
+
 	// Run checkpoint
 	go runCheckpoint(&config)
 
@@ -152,6 +168,29 @@ func wrappedMain() int {
 	// Initialize the TFConfig settings for the commands...
 	ContextOpts.Providers = config.ProviderFactories()
 	ContextOpts.Provisioners = config.ProvisionerFactories()
+	ProviderPlugins.Available = config.ProviderPlugins
+	ProviderPlugins.Factory = config.providerFactory
+
+	// Record changes to the state in the audit log, if there is one
+	if config.AuditLog != "" {
+		AuditLog.Path, err = homedir.Expand(config.AuditLog)
+		if err != nil {
+			Ui.Error(fmt.Sprintf("Error loading CLI configuration: \n\n%s", err))
+			return 1
+		}
+		AuditLog.Command = cli.Subcommand()
+	}
+
+	// Send the metrics of the remote state to statsd, if it's configured
+	if config.StatsdAddress != "" {
+		sink, err := metrics.NewStatsdSink(config.StatsdAddress, config.StatsdPrefix)
+		if err != nil {
+			Ui.Error(fmt.Sprintf("Error loading CLI configuration: \n\n%s", err))
+			return 1
+		}
+		defer sink.Close()
+		metrics.SetSink(sink)
+	}
 
 	exitCode, err := cli.Run()
 	if err != nil {
@@ -159,6 +198,12 @@ func wrappedMain() int {
 		return 1
 	}
 
+	// Wait for the state to be copied to its replica, if it's replicated
+	if !command.WaitForReplicas(replicaTimeout) {
+		Ui.Warn("Timed out waiting for the state to be replicated. Check the\n" +
+			"replica with \"terraform state replica\".")
+	}
+
 	return exitCode
 }
 
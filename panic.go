@@ -4,20 +4,31 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/mitchellh/panicwrap"
 )
 
+// crashLogFilename is the name of the file in the working directory that
+// the logs are written to if a panic happens.
+const crashLogFilename = "crash.log"
+
 // This output is shown if a panic happens.
 const panicOutput = `
 
 !!!!!!!!!!!!!!!!!!!!!!!!!!! TERRAFORM CRASH !!!!!!!!!!!!!!!!!!!!!!!!!!!!
 
 Terraform crashed! This is always indicative of a bug within Terraform.
-A crash log has been placed at "crash.log" relative to your current
-working directory. It would be immensely helpful if you could please
-report the crash with Terraform[1] so that we can fix this.
+A crash log has been placed at:
+
+    %s
+
+It would be immensely helpful if you could please report the crash with
+Terraform[1] so that we can fix this. The crash log contains the debug
+logs of the run. Secrets in the remote state configuration are redacted
+and the state itself isn't included, but please review it before
+sharing it.
 
 When reporting bugs, please include your terraform version. That
 information is available on the first line of crash.log. You can also
@@ -39,7 +50,11 @@ func panicHandler(logF *os.File) panicwrap.HandlerFunc {
 		fmt.Fprintf(os.Stderr, fmt.Sprintf("%s\n", m))
 
 		// Create the crash log file where we'll write the logs
-		f, err := os.Create("crash.log")
+		path, err := filepath.Abs(crashLogFilename)
+		if err != nil {
+			path = crashLogFilename
+		}
+		f, err := os.Create(path)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to create crash log file: %s", err)
 			return
@@ -62,6 +77,6 @@ func panicHandler(logF *os.File) panicwrap.HandlerFunc {
 		// Tell the user a crash occurred in some helpful way that
 		// they'll hopefully notice.
 		fmt.Printf("\n\n")
-		fmt.Println(strings.TrimSpace(panicOutput))
+		fmt.Println(fmt.Sprintf(strings.TrimSpace(panicOutput), path))
 	}
 }
@@ -233,6 +233,24 @@ func (p *ResourceProvider) ImportState(
 	return resp.State, err
 }
 
+func (p *ResourceProvider) ListResources(
+	info *terraform.InstanceInfo) ([]*terraform.InstanceState, error) {
+	var resp ResourceProviderListResourcesResponse
+	args := &ResourceProviderListResourcesArgs{
+		Info: info,
+	}
+
+	err := p.Client.Call("Plugin.ListResources", args, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		err = resp.Error
+	}
+
+	return resp.State, err
+}
+
 func (p *ResourceProvider) Resources() []terraform.ResourceType {
 	var result []terraform.ResourceType
 
@@ -368,6 +386,15 @@ type ResourceProviderImportStateResponse struct {
 	Error *plugin.BasicError
 }
 
+type ResourceProviderListResourcesArgs struct {
+	Info *terraform.InstanceInfo
+}
+
+type ResourceProviderListResourcesResponse struct {
+	State []*terraform.InstanceState
+	Error *plugin.BasicError
+}
+
 type ResourceProviderReadDataApplyArgs struct {
 	Info *terraform.InstanceInfo
 	Diff *terraform.InstanceDiff
@@ -526,6 +553,17 @@ func (s *ResourceProviderServer) ImportState(
 	return nil
 }
 
+func (s *ResourceProviderServer) ListResources(
+	args *ResourceProviderListResourcesArgs,
+	result *ResourceProviderListResourcesResponse) error {
+	states, err := s.Provider.ListResources(args.Info)
+	*result = ResourceProviderListResourcesResponse{
+		State: states,
+		Error: plugin.NewBasicError(err),
+	}
+	return nil
+}
+
 func (s *ResourceProviderServer) Resources(
 	nothing interface{},
 	result *[]terraform.ResourceType) error {
@@ -411,6 +411,45 @@ func TestResourceProvider_importState(t *testing.T) {
 	}
 }
 
+func TestResourceProvider_listResources(t *testing.T) {
+	p := new(terraform.MockResourceProvider)
+
+	// Create a mock provider
+	client, _ := plugin.TestPluginRPCConn(t, pluginMap(&ServeOpts{
+		ProviderFunc: testProviderFixed(p),
+	}))
+	defer client.Close()
+
+	// Request the provider
+	raw, err := client.Dispense(ProviderPluginName)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	provider := raw.(terraform.ResourceProvider)
+
+	p.ListResourcesReturn = []*terraform.InstanceState{
+		&terraform.InstanceState{
+			ID: "bob",
+		},
+	}
+
+	// ListResources
+	info := &terraform.InstanceInfo{Type: "foo"}
+	states, err := provider.ListResources(info)
+	if !p.ListResourcesCalled {
+		t.Fatal("ListResources should be called")
+	}
+	if !reflect.DeepEqual(p.ListResourcesInfo, info) {
+		t.Fatalf("bad: %#v", p.ListResourcesInfo)
+	}
+	if err != nil {
+		t.Fatalf("bad: %#v", err)
+	}
+	if !reflect.DeepEqual(p.ListResourcesReturn, states) {
+		t.Fatalf("bad: %#v", states)
+	}
+}
+
 func TestResourceProvider_resources(t *testing.T) {
 	p := new(terraform.MockResourceProvider)
 
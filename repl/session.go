@@ -37,8 +37,11 @@ func (s *Session) Handle(line string) (string, error) {
 }
 
 func (s *Session) handleEval(line string) (string, error) {
-	// Wrap the line to make it an interpolation.
-	line = fmt.Sprintf("${%s}", line)
+	// Wrap the line to make it an interpolation, unless it already
+	// contains interpolations as it would be written in the configuration.
+	if !strings.Contains(line, "${") {
+		line = fmt.Sprintf("${%s}", line)
+	}
 
 	// Parse the line
 	raw, err := config.NewRawConfig(map[string]interface{}{
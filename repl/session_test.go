@@ -67,6 +67,30 @@ func TestSession_basicState(t *testing.T) {
 		})
 	})
 
+	t.Run("wrapped interpolation", func(t *testing.T) {
+		testSession(t, testSessionTest{
+			State: state,
+			Inputs: []testSessionInput{
+				{
+					Input:  "${test_instance.foo.id}",
+					Output: "bar",
+				},
+			},
+		})
+	})
+
+	t.Run("template", func(t *testing.T) {
+		testSession(t, testSessionTest{
+			State: state,
+			Inputs: []testSessionInput{
+				{
+					Input:  "id: ${test_instance.foo.id}",
+					Output: "id: bar",
+				},
+			},
+		})
+	})
+
 	t.Run("missing resource", func(t *testing.T) {
 		testSession(t, testSessionTest{
 			State: state,
@@ -96,7 +96,11 @@ func (s *CacheState) RefreshState() error {
 		// Return early so we don't updtae the state
 		return nil
 	default:
-		panic("unhandled cache refresh state")
+		// Every combination is handled above, so this is a bug
+		return fmt.Errorf(
+			"Unable to compare the cached state (serial %d) with the durable\n"+
+				"state (serial %d). This is a bug in Terraform, please report it.",
+			cached.Serial, durable.Serial)
 	}
 
 	if s.refreshResult == CacheRefreshUpdateLocal {
@@ -1,12 +1,24 @@
 package state
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/terraform/terraform"
 )
 
+// ErrChecksumMismatch is returned by LocalState.RefreshState when the
+// state file doesn't match the checksum recorded when it was written,
+// which means it was modified outside of Terraform or is corrupted.
+var ErrChecksumMismatch = errors.New(
+	"state file doesn't match the checksum recorded when it was written")
+
 // LocalState manages a state storage that is local to the filesystem.
 type LocalState struct {
 	// Path is the path to read the state from. PathOut is the path to
@@ -15,9 +27,21 @@ type LocalState struct {
 	Path    string
 	PathOut string
 
+	// ChecksumPath, if set, is the path where a SHA256 checksum of the
+	// state is stored whenever it is written. RefreshState verifies the
+	// file against it if it exists, returning ErrChecksumMismatch if the
+	// file was changed by anything other than LocalState.
+	ChecksumPath string
+
+	// BackupPath, if set, is the path where the state file at Path is
+	// kept, unchanged, when the state is first written. If there's no
+	// state file yet, any file at BackupPath is removed instead.
+	BackupPath string
+
 	state     *terraform.State
 	readState *terraform.State
 	written   bool
+	backedUp  bool
 }
 
 // SetState will force a specific state in-memory for this local state.
@@ -42,8 +66,18 @@ func (s *LocalState) WriteState(state *terraform.State) error {
 		path = s.Path
 	}
 
+	if err := s.backup(); err != nil {
+		return err
+	}
+
 	// If we don't have any state, we actually delete the file if it exists
 	if state == nil {
+		if s.ChecksumPath != "" {
+			if err := os.Remove(s.ChecksumPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
 		err := os.Remove(path)
 		if err != nil && os.IsNotExist(err) {
 			return nil
@@ -57,18 +91,26 @@ func (s *LocalState) WriteState(state *terraform.State) error {
 		return err
 	}
 
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
 	s.state.IncrementSerialMaybe(s.readState)
 	s.readState = s.state
 
-	if err := terraform.WriteState(s.state, f); err != nil {
+	var buf bytes.Buffer
+	if err := terraform.WriteState(s.state, &buf); err != nil {
 		return err
 	}
+	if err := writeFileAtomic(path, buf.Bytes(), fileMode(path)); err != nil {
+		return err
+	}
+
+	if s.ChecksumPath != "" {
+		sum := sha256.Sum256(buf.Bytes())
+		err := writeFileAtomic(
+			s.ChecksumPath, []byte(hex.EncodeToString(sum[:])+"\n"),
+			fileMode(s.ChecksumPath))
+		if err != nil {
+			return err
+		}
+	}
 
 	s.written = true
 	return nil
@@ -103,7 +145,16 @@ func (s *LocalState) RefreshState() error {
 	var state *terraform.State
 	if f != nil {
 		defer f.Close()
-		state, err = terraform.ReadState(f)
+		raw, err := ioutil.ReadAll(f)
+		if err != nil {
+			return err
+		}
+
+		if err := s.verifyChecksum(raw); err != nil {
+			return err
+		}
+
+		state, err = terraform.ReadState(bytes.NewReader(raw))
 		if err != nil {
 			return err
 		}
@@ -113,3 +164,109 @@ func (s *LocalState) RefreshState() error {
 	s.readState = state
 	return nil
 }
+
+// backup keeps the state file at BackupPath the first time it's called.
+func (s *LocalState) backup() error {
+	if s.BackupPath == "" || s.backedUp {
+		return nil
+	}
+
+	raw, err := ioutil.ReadFile(s.Path)
+	switch {
+	case os.IsNotExist(err):
+		err = os.Remove(s.BackupPath)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	case err == nil:
+		err = writeFileAtomic(s.BackupPath, raw, fileMode(s.Path))
+	}
+	if err != nil {
+		return err
+	}
+
+	s.backedUp = true
+	return nil
+}
+
+// verifyChecksum checks the raw contents of the state file against the
+// checksum at ChecksumPath. A missing checksum file isn't an error, since
+// the state may have been written before checksums were recorded.
+func (s *LocalState) verifyChecksum(raw []byte) error {
+	if s.ChecksumPath == "" {
+		return nil
+	}
+
+	expected, err := ioutil.ReadFile(s.ChecksumPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	sum := sha256.Sum256(raw)
+	if strings.TrimSpace(string(expected)) != hex.EncodeToString(sum[:]) {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
+
+// fileMode returns the permissions of the file at path, to keep them when
+// it's replaced, or 0600 if it doesn't exist, since state files hold
+// secrets.
+func fileMode(path string) os.FileMode {
+	if fi, err := os.Stat(path); err == nil {
+		return fi.Mode().Perm()
+	}
+
+	return 0600
+}
+
+// writeFileAtomic writes data to the file at path by writing and syncing a
+// temporary file next to it and renaming it into place, with the given
+// permissions. The file is never left partially written if Terraform is
+// killed, the machine loses power or the disk is full; it either has the
+// old or the new contents.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	// Write through symlinks rather than replacing them
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		path = p
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Sync the directory so that the rename itself survives a power loss.
+	// Not every platform supports syncing a directory, so this is only
+	// done on a best effort basis.
+	if d, err := os.Open(filepath.Dir(path)); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
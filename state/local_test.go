@@ -3,6 +3,7 @@ package state
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -66,3 +67,166 @@ func testLocalState(t *testing.T) *LocalState {
 
 	return ls
 }
+
+func TestLocalState_checksum(t *testing.T) {
+	ls := testLocalState(t)
+	defer os.Remove(ls.Path)
+	ls.ChecksumPath = ls.Path + ".sha256"
+	defer os.Remove(ls.ChecksumPath)
+
+	// Without a checksum file nothing is verified
+	if err := ls.RefreshState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Writing records the checksum, so reading again works
+	if err := ls.WriteState(ls.State()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := os.Stat(ls.ChecksumPath); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ls.RefreshState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Modify the file behind the state's back
+	f, err := os.OpenFile(ls.Path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	f.WriteString("\n")
+	f.Close()
+
+	if err := ls.RefreshState(); err != ErrChecksumMismatch {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	// Writing a nil state removes the checksum too
+	if err := ls.WriteState(nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := os.Stat(ls.ChecksumPath); !os.IsNotExist(err) {
+		t.Fatalf("checksum should be removed: %s", err)
+	}
+}
+
+func TestLocalState_writeAtomic(t *testing.T) {
+	td, err := ioutil.TempDir("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	// Write through a symlink, which should be kept
+	real := filepath.Join(td, "real.tfstate")
+	link := filepath.Join(td, "terraform.tfstate")
+	if err := ioutil.WriteFile(real, nil, 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ls := &LocalState{Path: link}
+	if err := ls.WriteState(TestStateInitial()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("symlink should be kept: %v", err)
+	}
+
+	// No temporary files should be left behind
+	entries, err := ioutil.ReadDir(td)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("bad: %#v", entries)
+	}
+
+	ls = &LocalState{Path: real}
+	if err := ls.RefreshState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !ls.State().Equal(TestStateInitial()) {
+		t.Fatalf("bad: %#v", ls.State())
+	}
+}
+
+func TestLocalState_writeMode(t *testing.T) {
+	td, err := ioutil.TempDir("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	// A new state file is only readable by its owner
+	path := filepath.Join(td, "terraform.tfstate")
+	ls := &LocalState{Path: path}
+	if err := ls.WriteState(TestStateInitial()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Fatalf("bad: %v %v", fi.Mode(), err)
+	}
+
+	// The permissions of an existing state file are kept
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ls.WriteState(TestStateInitial()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0640 {
+		t.Fatalf("bad: %v %v", fi.Mode(), err)
+	}
+}
+
+func TestLocalState_backup(t *testing.T) {
+	td, err := ioutil.TempDir("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	path := filepath.Join(td, "terraform.tfstate")
+	backupPath := path + ".backup"
+	previous := []byte("previous state\n")
+	if err := ioutil.WriteFile(path, previous, 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ls := &LocalState{Path: path, BackupPath: backupPath}
+	for i := 0; i < 2; i++ {
+		if err := ls.WriteState(TestStateInitial()); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	// The backup is the previous file as it was, not the state written
+	// first
+	actual, err := ioutil.ReadFile(backupPath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(actual) != string(previous) {
+		t.Fatalf("bad: %q", actual)
+	}
+	if fi, err := os.Stat(backupPath); err != nil || fi.Mode().Perm() != 0600 {
+		t.Fatalf("bad: %v %v", fi.Mode(), err)
+	}
+
+	// Without a previous file, a stale backup is removed
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	ls = &LocalState{Path: path, BackupPath: backupPath}
+	if err := ls.WriteState(TestStateInitial()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := os.Stat(backupPath); !os.IsNotExist(err) {
+		t.Fatalf("backup should be removed: %v", err)
+	}
+}
@@ -20,14 +20,6 @@ func artifactoryFactory(conf map[string]string) (Client, error) {
 				"missing 'username' configuration or ARTIFACTORY_USERNAME environment variable")
 		}
 	}
-	password, ok := conf["password"]
-	if !ok {
-		password = os.Getenv("ARTIFACTORY_PASSWORD")
-		if password == "" {
-			return nil, fmt.Errorf(
-				"missing 'password' configuration or ARTIFACTORY_PASSWORD environment variable")
-		}
-	}
 	url, ok := conf["url"]
 	if !ok {
 		url = os.Getenv("ARTIFACTORY_URL")
@@ -36,6 +28,22 @@ func artifactoryFactory(conf map[string]string) (Client, error) {
 				"missing 'url' configuration or ARTIFACTORY_URL environment variable")
 		}
 	}
+	password, ok := conf["password"]
+	if !ok {
+		password = os.Getenv("ARTIFACTORY_PASSWORD")
+	}
+	if password == "" {
+		// An API key or access token can be used instead of a password
+		var err error
+		if password, err = credentialsToken(url); err != nil {
+			return nil, err
+		}
+	}
+	if password == "" {
+		return nil, fmt.Errorf(
+			"missing 'password' configuration, ARTIFACTORY_PASSWORD environment\n" +
+				"variable or credentials for the url in the CLI configuration")
+	}
 	repo, ok := conf["repo"]
 	if !ok {
 		return nil, fmt.Errorf(
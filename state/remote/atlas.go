@@ -8,7 +8,6 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -45,9 +44,15 @@ func atlasFactory(conf map[string]string) (Client, error) {
 		token = os.Getenv("ATLAS_TOKEN")
 		ok = true
 	}
+	if token == "" {
+		if token, err = credentialsToken(server); err != nil {
+			return nil, err
+		}
+	}
 	if !ok || token == "" {
 		return nil, fmt.Errorf(
-			"missing 'access_token' configuration or ATLAS_TOKEN environmental variable")
+			"missing 'access_token' configuration, ATLAS_TOKEN environmental variable\n" +
+				"or credentials for the server in the CLI configuration")
 	}
 
 	name, ok := conf["name"]
@@ -315,13 +320,13 @@ func (c *AtlasClient) http() (*retryablehttp.Client, error) {
 // In other words, in this situation Terraform can override Atlas's detected
 // conflict by asserting that the state it is pushing is indeed correct.
 func (c *AtlasClient) handleConflict(msg string, state []byte) error {
-	log.Printf("[DEBUG] Handling Atlas conflict response: %s", msg)
+	logger.Debug("Handling Atlas conflict response: %s", msg)
 
 	if c.conflictHandlingAttempted {
-		log.Printf("[DEBUG] Already attempted conflict resolution; returning conflict.")
+		logger.Debug("Already attempted conflict resolution; returning conflict.")
 	} else {
 		c.conflictHandlingAttempted = true
-		log.Printf("[DEBUG] Atlas reported conflict, checking for equivalent states.")
+		logger.Debug("Atlas reported conflict, checking for equivalent states.")
 
 		payload, err := c.Get()
 		if err != nil {
@@ -339,7 +344,7 @@ func (c *AtlasClient) handleConflict(msg string, state []byte) error {
 		}
 
 		if statesAreEquivalent(currentState, proposedState) {
-			log.Printf("[DEBUG] States are equivalent, incrementing serial and retrying.")
+			logger.Debug("States are equivalent, incrementing serial and retrying.")
 			proposedState.Serial++
 			var buf bytes.Buffer
 			if err := terraform.WriteState(proposedState, &buf); err != nil {
@@ -348,7 +353,7 @@ func (c *AtlasClient) handleConflict(msg string, state []byte) error {
 			}
 			return c.Put(buf.Bytes())
 		} else {
-			log.Printf("[DEBUG] States are not equivalent, returning conflict.")
+			logger.Debug("States are not equivalent, returning conflict.")
 		}
 	}
 
@@ -37,7 +37,7 @@ func TestAtlasClient(t *testing.T) {
 		t.Fatalf("bad: %s", err)
 	}
 
-	testClient(t, client)
+	TestClient(t, client)
 }
 
 func TestAtlasClient_noRetryOnBadCerts(t *testing.T) {
@@ -31,7 +31,7 @@ func TestAzureClient(t *testing.T) {
 		t.Fatalf("Error for valid config: %v", err)
 	}
 
-	testClient(t, client)
+	TestClient(t, client)
 }
 
 // This test is the same as TestAzureClient with the addition of passing an
@@ -48,7 +48,7 @@ func TestAzureClientEmptyLease(t *testing.T) {
 		t.Fatalf("Error for valid config: %v", err)
 	}
 
-	testClient(t, client)
+	TestClient(t, client)
 }
 
 // This test is the same as TestAzureClient with the addition of using the
@@ -79,7 +79,7 @@ func TestAzureClientLease(t *testing.T) {
 	}
 
 	// no need to release lease as blob is deleted in testing
-	testClient(t, client)
+	TestClient(t, client)
 }
 
 func getAzureConfig(t *testing.T) map[string]string {
@@ -0,0 +1,189 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChaosEnvVar is the environment variable that enables fault injection in
+// every remote state client, for soak tests of how Terraform copes with
+// unreliable remote storage. It must never be set for real use. The value
+// is a comma-separated list of settings:
+//
+//	fail=P    Fail each operation with the probability P, from 0 to 1.
+//	delay=D   Delay each operation by a random duration up to D.
+//	ops=OPS   Only affect the given operations, such as "put+delete".
+//	          Every operation is affected by default.
+//	seed=N    Seed the random choices, so that a run can be repeated.
+//
+// For example, TF_REMOTE_CHAOS="fail=0.2,delay=2s,ops=put".
+const ChaosEnvVar = "TF_REMOTE_CHAOS"
+
+// ErrChaos is the error of the operations failed by a ChaosClient.
+var ErrChaos = errors.New("remote state failure injected by " + ChaosEnvVar)
+
+// ChaosClient is a Client implementation that randomly delays and fails
+// the operations of the client it wraps. A failed put or delete may have
+// been made on the client before failing, as when a connection is lost
+// before the storage's response is received.
+type ChaosClient struct {
+	Client Client
+
+	// FailRate is the probability that an operation fails, and MaxDelay
+	// the longest an operation is delayed.
+	FailRate float64
+	MaxDelay time.Duration
+
+	// Ops are the operations affected, "get", "put" and "delete". Every
+	// operation is affected if it's empty.
+	Ops []string
+
+	rand *chaosRand
+}
+
+func (c *ChaosClient) Get() (*Payload, error) {
+	if err := c.inject("get", nil); err != nil {
+		return nil, err
+	}
+
+	return c.Client.Get()
+}
+
+func (c *ChaosClient) Put(data []byte) error {
+	if err := c.inject("put", func() error { return c.Client.Put(data) }); err != nil {
+		return err
+	}
+
+	return c.Client.Put(data)
+}
+
+func (c *ChaosClient) Delete() error {
+	if err := c.inject("delete", c.Client.Delete); err != nil {
+		return err
+	}
+
+	return c.Client.Delete()
+}
+
+// inject delays the operation op and returns ErrChaos if it fails. If the
+// operation changes the state, f makes it, and it's randomly made before
+// failing.
+func (c *ChaosClient) inject(op string, f func() error) error {
+	if !c.affects(op) {
+		return nil
+	}
+
+	if c.MaxDelay > 0 {
+		d := time.Duration(c.rand.Int63n(int64(c.MaxDelay)))
+		logger.Debug("chaos: delaying remote state %s by %s", op, d)
+		time.Sleep(d)
+	}
+	if c.rand.Float64() >= c.FailRate {
+		return nil
+	}
+
+	if f != nil && c.rand.Float64() < 0.5 {
+		logger.Warn("chaos: failing remote state %s after making it", op)
+		if err := f(); err != nil {
+			return err
+		}
+	} else {
+		logger.Warn("chaos: failing remote state %s", op)
+	}
+
+	return ErrChaos
+}
+
+func (c *ChaosClient) affects(op string) bool {
+	if len(c.Ops) == 0 {
+		return true
+	}
+	for _, o := range c.Ops {
+		if o == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wrap returns client wrapped in a ChaosClient with the same settings as
+// c, or client itself if c is nil.
+func (c *ChaosClient) wrap(client Client) Client {
+	if c == nil {
+		return client
+	}
+
+	result := *c
+	result.Client = client
+	return &result
+}
+
+// chaosConfig parses the value of ChaosEnvVar, and returns nil if it's
+// empty.
+func chaosConfig(v string) (*ChaosClient, error) {
+	if v == "" {
+		return nil, nil
+	}
+
+	result := &ChaosClient{}
+	seed := time.Now().UnixNano()
+	for _, setting := range strings.Split(v, ",") {
+		parts := strings.SplitN(strings.TrimSpace(setting), "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid %s setting %q", ChaosEnvVar, setting)
+		}
+
+		var err error
+		switch parts[0] {
+		case "fail":
+			result.FailRate, err = strconv.ParseFloat(parts[1], 64)
+			if err == nil && (result.FailRate < 0 || result.FailRate > 1) {
+				err = fmt.Errorf("must be from 0 to 1")
+			}
+		case "delay":
+			result.MaxDelay, err = time.ParseDuration(parts[1])
+		case "ops":
+			result.Ops = strings.Split(parts[1], "+")
+			for _, op := range result.Ops {
+				if op != "get" && op != "put" && op != "delete" {
+					err = fmt.Errorf("unknown operation %q", op)
+				}
+			}
+		case "seed":
+			seed, err = strconv.ParseInt(parts[1], 10, 64)
+		default:
+			err = fmt.Errorf("unknown setting")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s setting %q: %s", ChaosEnvVar, setting, err)
+		}
+	}
+
+	result.rand = &chaosRand{rand: rand.New(rand.NewSource(seed))}
+	return result, nil
+}
+
+// chaosRand is a source of random numbers that is safe for concurrent use
+// and shared by the ChaosClients of a configuration.
+type chaosRand struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func (r *chaosRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Float64()
+}
+
+func (r *chaosRand) Int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Int63n(n)
+}
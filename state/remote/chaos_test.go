@@ -0,0 +1,116 @@
+package remote
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChaosClient_impl(t *testing.T) {
+	var _ Client = new(ChaosClient)
+}
+
+func TestChaosClient(t *testing.T) {
+	client, err := chaosConfig("fail=0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	TestClient(t, client.wrap(new(InmemClient)))
+}
+
+func TestChaosClient_fail(t *testing.T) {
+	chaos, err := chaosConfig("fail=1,ops=put+delete,seed=1")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	inmem := &InmemClient{Data: []byte("foo")}
+	client := chaos.wrap(inmem)
+
+	if _, err := client.Get(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := client.Put([]byte("bar")); err != ErrChaos {
+		t.Fatalf("bad: %#v", err)
+	}
+	if err := client.Delete(); err != ErrChaos {
+		t.Fatalf("bad: %#v", err)
+	}
+}
+
+func TestChaosClient_delay(t *testing.T) {
+	chaos, err := chaosConfig("delay=10ms")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client := chaos.wrap(new(InmemClient))
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := client.Put([]byte("foo")); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("took too long: %s", d)
+	}
+}
+
+func TestChaosConfig(t *testing.T) {
+	cases := []struct {
+		Value    string
+		FailRate float64
+		MaxDelay time.Duration
+		Ops      []string
+		Err      bool
+	}{
+		{"fail=0.5", 0.5, 0, nil, false},
+		{"fail=0.1, delay=2s", 0.1, 2 * time.Second, nil, false},
+		{"delay=1m,ops=get+put,seed=3", 0, time.Minute, []string{"get", "put"}, false},
+		{"fail=2", 0, 0, nil, true},
+		{"fail", 0, 0, nil, true},
+		{"delay=soon", 0, 0, nil, true},
+		{"ops=lock", 0, 0, nil, true},
+		{"seed=x", 0, 0, nil, true},
+		{"foo=bar", 0, 0, nil, true},
+	}
+
+	for _, tc := range cases {
+		c, err := chaosConfig(tc.Value)
+		if (err != nil) != tc.Err {
+			t.Fatalf("%s: err: %s", tc.Value, err)
+		}
+		if err != nil {
+			continue
+		}
+		if c.FailRate != tc.FailRate || c.MaxDelay != tc.MaxDelay || !reflect.DeepEqual(c.Ops, tc.Ops) {
+			t.Fatalf("%s: bad: %#v", tc.Value, c)
+		}
+	}
+
+	if c, err := chaosConfig(""); c != nil || err != nil {
+		t.Fatalf("bad: %#v, %s", c, err)
+	}
+}
+
+func TestNewClient_chaos(t *testing.T) {
+	defer os.Setenv(ChaosEnvVar, os.Getenv(ChaosEnvVar))
+
+	os.Setenv(ChaosEnvVar, "fail=1")
+	client, err := NewClient("inmem", map[string]string{"name": "chaos"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := client.Put([]byte("foo")); err == nil {
+		t.Fatal("should fail")
+	}
+	if _, ok := Versioned(client); !ok {
+		t.Fatal("should be versioned")
+	}
+
+	os.Setenv(ChaosEnvVar, "fail=x")
+	if _, err := NewClient("inmem", map[string]string{"name": "chaos"}); err == nil {
+		t.Fatal("should error")
+	}
+}
@@ -2,15 +2,35 @@ package remote
 
 import (
 	"crypto/md5"
+	"strconv"
+	"sync"
+	"time"
 )
 
-// InmemClient is a Client implementation that stores data in memory.
+// InmemClient is a Client implementation that stores data in memory. Every
+// version of the data that is put is kept, so that it can be used as a
+// VersionedClient. It is safe for concurrent use.
 type InmemClient struct {
 	Data []byte
 	MD5  []byte
+
+	versions []*inmemVersion
+	mu       sync.Mutex
+}
+
+type inmemVersion struct {
+	Version
+	Data []byte
 }
 
 func (c *InmemClient) Get() (*Payload, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Data == nil {
+		return nil, nil
+	}
+
 	return &Payload{
 		Data: c.Data,
 		MD5:  c.MD5,
@@ -18,15 +38,57 @@ func (c *InmemClient) Get() (*Payload, error) {
 }
 
 func (c *InmemClient) Put(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	md5 := md5.Sum(data)
 
 	c.Data = data
 	c.MD5 = md5[:]
+	c.versions = append(c.versions, &inmemVersion{
+		Version: Version{
+			ID:           strconv.Itoa(len(c.versions) + 1),
+			LastModified: time.Now().UTC(),
+			Size:         int64(len(data)),
+		},
+		Data: data,
+	})
 	return nil
 }
 
 func (c *InmemClient) Delete() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.Data = nil
 	c.MD5 = nil
 	return nil
 }
+
+func (c *InmemClient) ListVersions() ([]*Version, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make([]*Version, 0, len(c.versions))
+	for i := len(c.versions) - 1; i >= 0; i-- {
+		v := c.versions[i].Version
+		v.Latest = c.Data != nil && i == len(c.versions)-1
+		result = append(result, &v)
+	}
+
+	return result, nil
+}
+
+func (c *InmemClient) GetVersion(id string) (*Payload, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, v := range c.versions {
+		if v.ID == id {
+			md5 := md5.Sum(v.Data)
+			return &Payload{Data: v.Data, MD5: md5[:]}, nil
+		}
+	}
+
+	return nil, nil
+}
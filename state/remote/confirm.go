@@ -0,0 +1,53 @@
+package remote
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	// confirmRequiredKey and confirmNameKey are the configuration keys
+	// accepted by every remote client type to require that changes to the
+	// state are confirmed by typing a name, for states that are too
+	// important to change by mistake. They're handled by the commands that
+	// change the state, and removed from the configuration by NewClient.
+	confirmRequiredKey = "confirm_required"
+	confirmNameKey     = "confirm_name"
+)
+
+// ConfirmName returns the name that must be typed to confirm a change to
+// the state of the given client type and configuration, or an empty
+// string if no confirmation is required. The name is the confirm_name
+// setting, or the client type if it isn't set.
+func ConfirmName(t string, conf map[string]string) (string, error) {
+	v, ok := conf[confirmRequiredKey]
+	if !ok {
+		return "", nil
+	}
+
+	required, err := strconv.ParseBool(v)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s value %q: %s", confirmRequiredKey, v, err)
+	}
+	if !required {
+		return "", nil
+	}
+
+	if name := conf[confirmNameKey]; name != "" {
+		return name, nil
+	}
+
+	return t, nil
+}
+
+// confirmConfig checks the confirmation settings and removes them from
+// the configuration, since they're of no use to the clients.
+func confirmConfig(conf map[string]string) error {
+	if _, err := ConfirmName("", conf); err != nil {
+		return err
+	}
+
+	delete(conf, confirmRequiredKey)
+	delete(conf, confirmNameKey)
+	return nil
+}
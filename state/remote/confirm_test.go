@@ -0,0 +1,56 @@
+package remote
+
+import (
+	"testing"
+)
+
+func TestConfirmName(t *testing.T) {
+	cases := []struct {
+		Config map[string]string
+		Name   string
+		Err    bool
+	}{
+		{map[string]string{}, "", false},
+		{map[string]string{"confirm_required": "false"}, "", false},
+		{map[string]string{"confirm_required": "true"}, "s3", false},
+		{map[string]string{"confirm_required": "true", "confirm_name": "prod"}, "prod", false},
+		{map[string]string{"confirm_name": "prod"}, "", false},
+		{map[string]string{"confirm_required": "maybe"}, "", true},
+	}
+
+	for i, tc := range cases {
+		name, err := ConfirmName("s3", tc.Config)
+		if (err != nil) != tc.Err {
+			t.Fatalf("%d: err: %s", i, err)
+		}
+		if name != tc.Name {
+			t.Fatalf("%d: bad: %q", i, name)
+		}
+	}
+}
+
+func TestNewClient_confirm(t *testing.T) {
+	var conf map[string]string
+	BuiltinClients["test-confirm"] = func(c map[string]string) (Client, error) {
+		conf = c
+		return new(InmemClient), nil
+	}
+	defer delete(BuiltinClients, "test-confirm")
+
+	_, err := NewClient("test-confirm", map[string]string{
+		"confirm_required": "true",
+		"confirm_name":     "prod",
+		"foo":              "bar",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(conf) != 1 || conf["foo"] != "bar" {
+		t.Fatalf("bad: %#v", conf)
+	}
+
+	_, err = NewClient("test-confirm", map[string]string{"confirm_required": "maybe"})
+	if err == nil {
+		t.Fatal("should error")
+	}
+}
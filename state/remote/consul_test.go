@@ -23,5 +23,5 @@ func TestConsulClient(t *testing.T) {
 		t.Fatalf("bad: %s", err)
 	}
 
-	testClient(t, client)
+	TestClient(t, client)
 }
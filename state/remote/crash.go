@@ -0,0 +1,92 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sort"
+)
+
+// CrashClient is a Client implementation that wraps another Client and,
+// if the client panics, logs what it was doing: the operation, the client
+// type and configuration with the secrets redacted, and the serial of the
+// last state read or written. The panic then carries on, so Terraform
+// writes the crash log as for any other panic, with this in it. The state
+// itself is never logged.
+//
+// It's added by NewClient inside the RetryClient and TimeoutClient, so that
+// it runs on the goroutine that panics.
+type CrashClient struct {
+	Client Client
+	Type   string
+	Config map[string]string
+
+	// serial is the serial of the last state read or written, if known.
+	serial *int64
+}
+
+func (c *CrashClient) Get() (*Payload, error) {
+	defer c.logPanic("get")
+
+	payload, err := c.Client.Get()
+	if err == nil && payload != nil {
+		c.recordSerial(payload.Data)
+	}
+
+	return payload, err
+}
+
+func (c *CrashClient) Put(data []byte) error {
+	defer c.logPanic("put")
+
+	c.recordSerial(data)
+	return c.Client.Put(data)
+}
+
+func (c *CrashClient) Delete() error {
+	defer c.logPanic("delete")
+
+	return c.Client.Delete()
+}
+
+// recordSerial records the serial of the given state, if it can be read.
+func (c *CrashClient) recordSerial(data []byte) {
+	var state struct {
+		Serial *int64 `json:"serial"`
+	}
+	if err := json.Unmarshal(data, &state); err == nil && state.Serial != nil {
+		c.serial = state.Serial
+	}
+}
+
+// logPanic must be deferred. It logs the context of a panic and panics
+// again with the same value.
+func (c *CrashClient) logPanic(op string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	serial := "unknown"
+	if c.serial != nil {
+		serial = fmt.Sprintf("%d", *c.serial)
+	}
+
+	conf := RedactConfig(c.Type, c.Config)
+	keys := make([]string, 0, len(conf))
+	for k := range conf {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		buf.WriteString(fmt.Sprintf("  %s = %q\n", k, conf[k]))
+	}
+
+	logger.Error(
+		"%s client panicked during %s: %v\n\nState serial: %s\nConfiguration:\n%s\n%s",
+		c.Type, op, r, serial, buf.String(), debug.Stack())
+	panic(r)
+}
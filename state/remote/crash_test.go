@@ -0,0 +1,86 @@
+package remote
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+// panicClient is a Client that panics on Put.
+type panicClient struct {
+	InmemClient
+}
+
+func (c *panicClient) Put([]byte) error {
+	panic("boom")
+}
+
+func TestCrashClient_impl(t *testing.T) {
+	var _ Client = new(CrashClient)
+}
+
+func TestCrashClient(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	client := &CrashClient{
+		Client: &panicClient{
+			InmemClient: InmemClient{Data: []byte(`{"version": 3, "serial": 4}`)},
+		},
+		Type: "s3",
+		Config: map[string]string{
+			"bucket":     "state",
+			"secret_key": "hunter2",
+		},
+	}
+
+	if _, err := client.Get(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("the panic should carry on: %#v", r)
+			}
+		}()
+
+		client.Put([]byte(`{"version": 3, "serial": 5, "lineage": "secret-lineage"}`))
+	}()
+
+	output := buf.String()
+	for _, s := range []string{
+		"[ERROR] remote: s3 client panicked during put: boom",
+		"State serial: 5",
+		`bucket = "state"`,
+		`secret_key = "<sensitive>"`,
+		"crash_test.go",
+	} {
+		if !strings.Contains(output, s) {
+			t.Fatalf("%q missing from:\n\n%s", s, output)
+		}
+	}
+	for _, s := range []string{"hunter2", "secret-lineage"} {
+		if strings.Contains(output, s) {
+			t.Fatalf("%q shouldn't be logged:\n\n%s", s, output)
+		}
+	}
+}
+
+func TestNewClient_crash(t *testing.T) {
+	client, err := NewClient("local", map[string]string{"path": "foo"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cc, ok := client.(*CrashClient)
+	if !ok {
+		t.Fatalf("bad: %#v", client)
+	}
+	if cc.Type != "local" || cc.Config["path"] != "foo" {
+		t.Fatalf("bad: %#v", cc)
+	}
+}
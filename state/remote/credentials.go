@@ -0,0 +1,40 @@
+package remote
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// CredentialsFunc returns the token to authenticate to the given host
+// with, or an empty string if there is none.
+type CredentialsFunc func(host string) (string, error)
+
+// Credentials, if set, is used by the http, artifactory and atlas clients
+// to look up a token for their host when their configuration doesn't
+// have one. The CLI sets it from the credentials in its configuration
+// file, so that secrets don't have to be part of the remote state
+// configuration.
+var Credentials CredentialsFunc
+
+// credentialsToken returns the token from Credentials for the host of
+// the given address.
+func credentialsToken(address string) (string, error) {
+	if Credentials == nil {
+		return "", nil
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", nil
+	}
+
+	token, err := Credentials(u.Host)
+	if err != nil {
+		return "", fmt.Errorf("Error looking up credentials for %s: %s", u.Host, err)
+	}
+
+	return token, nil
+}
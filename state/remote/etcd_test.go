@@ -34,5 +34,5 @@ func TestEtcdClient(t *testing.T) {
 		t.Fatalf("Error for valid config: %s", err)
 	}
 
-	testClient(t, client)
+	TestClient(t, client)
 }
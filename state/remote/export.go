@@ -0,0 +1,53 @@
+package remote
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// exportConfigKey is the configuration key accepted by every remote client
+// type to export its settings to the Terraform configuration, so that the
+// credentials and region of the remote state don't have to be configured
+// again for the providers. It's removed from the configuration by
+// NewClient.
+const exportConfigKey = "export_config"
+
+// ExportedConfig returns the settings that the remote state of the given
+// client type and configuration exports to the Terraform configuration,
+// as "${backend.NAME}" interpolations, or nil if it doesn't export them.
+// They're the settings of the client, apart from those handled by NewClient
+// for every client type, and "type", the client type.
+func ExportedConfig(t string, conf map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(conf)+1)
+	for k, v := range conf {
+		result[k] = v
+	}
+	export, err := exportConfig(result)
+	if err != nil || !export {
+		return nil, err
+	}
+
+	for _, k := range wrapperKeys {
+		delete(result, k)
+	}
+	result["type"] = t
+
+	return result, nil
+}
+
+// exportConfig returns whether the configuration exports its settings, and
+// removes the setting from it, since it's of no use to the clients.
+func exportConfig(conf map[string]string) (bool, error) {
+	v, ok := conf[exportConfigKey]
+	if !ok {
+		return false, nil
+	}
+	delete(conf, exportConfigKey)
+
+	export, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s value %q: %s", exportConfigKey, v, err)
+	}
+
+	return export, nil
+}
@@ -0,0 +1,69 @@
+package remote
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExportedConfig(t *testing.T) {
+	cases := []struct {
+		Config   map[string]string
+		Expected map[string]string
+		Err      bool
+	}{
+		{map[string]string{"region": "us-west-2"}, nil, false},
+		{map[string]string{"region": "us-west-2", "export_config": "false"}, nil, false},
+		{
+			map[string]string{
+				"region":           "us-west-2",
+				"access_key":       "foo",
+				"confirm_required": "true",
+				"export_config":    "true",
+			},
+			map[string]string{
+				"region":     "us-west-2",
+				"access_key": "foo",
+				"type":       "s3",
+			},
+			false,
+		},
+		{map[string]string{"export_config": "maybe"}, nil, true},
+	}
+
+	for i, tc := range cases {
+		actual, err := ExportedConfig("s3", tc.Config)
+		if (err != nil) != tc.Err {
+			t.Fatalf("%d: err: %s", i, err)
+		}
+		if !reflect.DeepEqual(actual, tc.Expected) {
+			t.Fatalf("%d: bad: %#v", i, actual)
+		}
+	}
+}
+
+func TestNewClient_export(t *testing.T) {
+	var conf map[string]string
+	BuiltinClients["test-export"] = func(c map[string]string) (Client, error) {
+		conf = c
+		return new(InmemClient), nil
+	}
+	defer delete(BuiltinClients, "test-export")
+
+	raw := map[string]string{
+		"export_config": "true",
+		"foo":           "bar",
+	}
+	if _, err := NewClient("test-export", raw); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(conf) != 1 || conf["foo"] != "bar" {
+		t.Fatalf("bad: %#v", conf)
+	}
+	if len(raw) != 2 {
+		t.Fatalf("configuration shouldn't be changed: %#v", raw)
+	}
+
+	if _, err := NewClient("test-export", map[string]string{"export_config": "maybe"}); err == nil {
+		t.Fatal("should error")
+	}
+}
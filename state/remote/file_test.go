@@ -25,5 +25,5 @@ func TestFileClient(t *testing.T) {
 		t.Fatalf("bad: %s", err)
 	}
 
-	testClient(t, client)
+	TestClient(t, client)
 }
@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/pathorcontents"
 	"github.com/hashicorp/terraform/terraform"
@@ -73,14 +75,15 @@ func gcsFactory(conf map[string]string) (Client, error) {
 
 		// Assume account_file is a JSON string
 		if err := parseJSON(&account, contents); err != nil {
-			return nil, fmt.Errorf("Error parsing credentials '%s': %s", contents, err)
+			// The contents aren't included, since they're the private key
+			return nil, fmt.Errorf("Error parsing credentials: %s", err)
 		}
 
 		// Get the token for use in our requests
-		log.Printf("[INFO] Requesting Google token...")
-		log.Printf("[INFO]   -- Email: %s", account.ClientEmail)
-		log.Printf("[INFO]   -- Scopes: %s", clientScopes)
-		log.Printf("[INFO]   -- Private Key Length: %d", len(account.PrivateKey))
+		logger.Info("Requesting Google token...")
+		logger.Info("  -- Email: %s", account.ClientEmail)
+		logger.Info("  -- Scopes: %s", clientScopes)
+		logger.Info("  -- Private Key Length: %d", len(account.PrivateKey))
 
 		conf := jwt.Config{
 			Email:      account.ClientEmail,
@@ -92,7 +95,7 @@ func gcsFactory(conf map[string]string) (Client, error) {
 		client = conf.Client(oauth2.NoContext)
 
 	} else {
-		log.Printf("[INFO] Authenticating using DefaultClient")
+		logger.Info("Authenticating using DefaultClient")
 		err := error(nil)
 		client, err = google.DefaultClient(oauth2.NoContext, clientScopes...)
 		if err != nil {
@@ -103,7 +106,7 @@ func gcsFactory(conf map[string]string) (Client, error) {
 	userAgent := fmt.Sprintf(
 		"(%s %s) Terraform/%s", runtime.GOOS, runtime.GOARCH, versionString)
 
-	log.Printf("[INFO] Instantiating Google Storage Client...")
+	logger.Info("Instantiating Google Storage Client...")
 	clientStorage, err := storage.New(client)
 	if err != nil {
 		return nil, err
@@ -120,12 +123,12 @@ func gcsFactory(conf map[string]string) (Client, error) {
 
 func (c *GCSClient) Get() (*Payload, error) {
 	// Read the object from bucket.
-	log.Printf("[INFO] Reading %s/%s", c.bucket, c.path)
+	logger.Info("Reading %s/%s", c.bucket, c.path)
 
 	resp, err := c.clientStorage.Objects.Get(c.bucket, c.path).Download()
 	if err != nil {
 		if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == 404 {
-			log.Printf("[INFO] %s/%s not found", c.bucket, c.path)
+			logger.Info("%s/%s not found", c.bucket, c.path)
 
 			return nil, nil
 		}
@@ -140,7 +143,7 @@ func (c *GCSClient) Get() (*Payload, error) {
 	if err != nil {
 		log.Fatalf("[WARN] error buffering %q: %v", c.path, err)
 	}
-	log.Printf("[INFO] Downloaded %d bytes", n)
+	logger.Info("Downloaded %d bytes", n)
 
 	payload := &Payload{
 		Data: w.Bytes(),
@@ -155,7 +158,7 @@ func (c *GCSClient) Get() (*Payload, error) {
 }
 
 func (c *GCSClient) Put(data []byte) error {
-	log.Printf("[INFO] Writing %s/%s", c.bucket, c.path)
+	logger.Info("Writing %s/%s", c.bucket, c.path)
 
 	r := bytes.NewReader(data)
 	_, err := c.clientStorage.Objects.Insert(c.bucket, &storage.Object{Name: c.path}).Media(r).Do()
@@ -167,9 +170,66 @@ func (c *GCSClient) Put(data []byte) error {
 }
 
 func (c *GCSClient) Delete() error {
-	log.Printf("[INFO] Deleting %s/%s", c.bucket, c.path)
+	logger.Info("Deleting %s/%s", c.bucket, c.path)
 
 	err := c.clientStorage.Objects.Delete(c.bucket, c.path).Do()
 	return err
 
 }
+
+func (c *GCSClient) ListVersions() ([]*Version, error) {
+	var result []*Version
+	call := c.clientStorage.Objects.List(c.bucket).Prefix(c.path).Versions(true)
+	err := call.Pages(context.Background(), func(objects *storage.Objects) error {
+		for _, o := range objects.Items {
+			// The prefix also matches longer names
+			if o.Name != c.path {
+				continue
+			}
+
+			updated, _ := time.Parse(time.RFC3339, o.Updated)
+			result = append(result, &Version{
+				ID:           strconv.FormatInt(o.Generation, 10),
+				LastModified: updated,
+				Size:         int64(o.Size),
+				Latest:       o.TimeDeleted == "",
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list state versions: %s", err)
+	}
+
+	// Generations are listed oldest first
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return result, nil
+}
+
+func (c *GCSClient) GetVersion(id string) (*Payload, error) {
+	generation, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid GCS generation %q: %s", id, err)
+	}
+
+	resp, err := c.clientStorage.Objects.Get(c.bucket, c.path).Generation(generation).Download()
+	if err != nil {
+		if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == 404 {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Error retrieving object %s/%s#%d: %s", c.bucket, c.path, generation, err)
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, resp.Body); err != nil {
+		return nil, fmt.Errorf("Failed to read state version: %s", err)
+	}
+
+	return &Payload{Data: buf.Bytes()}, nil
+}
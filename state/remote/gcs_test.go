@@ -11,6 +11,7 @@ import (
 
 func TestGCSClient_impl(t *testing.T) {
 	var _ Client = new(GCSClient)
+	var _ VersionedClient = new(GCSClient)
 }
 
 func TestGCSClient(t *testing.T) {
@@ -65,5 +66,5 @@ func TestGCSClient(t *testing.T) {
 		}
 	}()
 
-	testClient(t, client)
+	TestClient(t, client)
 }
@@ -0,0 +1,107 @@
+package remote
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+)
+
+// compressKey is the configuration key accepted by every remote client
+// type to store the state compressed. It's handled by NewClient and not
+// passed on to the client itself.
+const compressKey = "compress"
+
+// gzipMagic is the header every gzip stream starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// GzipClient is a Client implementation that wraps another Client to store
+// the state gzip compressed, which makes large states faster to transfer
+// and keeps them under the size limits of some storage.
+//
+// Compressed states are always decompressed when read, no matter whether
+// Compress is set, so that turning compression on or off doesn't break
+// reading a state that was written with the other setting.
+type GzipClient struct {
+	Client Client
+
+	// Compress, if true, compresses the state when it is written.
+	Compress bool
+}
+
+func (c *GzipClient) Get() (*Payload, error) {
+	payload, err := c.Client.Get()
+	if err != nil || payload == nil {
+		return payload, err
+	}
+
+	return gunzipPayload(payload)
+}
+
+// gunzipPayload returns the payload decompressed, or the payload itself
+// if it isn't compressed.
+func gunzipPayload(payload *Payload) (*Payload, error) {
+	if !bytes.HasPrefix(payload.Data, gzipMagic) {
+		return payload, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(payload.Data))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decompress state: %s", err)
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decompress state: %s", err)
+	}
+
+	hash := md5.Sum(data)
+	return &Payload{
+		Data: data,
+		MD5:  hash[:],
+	}, nil
+}
+
+func (c *GzipClient) Put(data []byte) error {
+	if !c.Compress {
+		return c.Client.Put(data)
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("Failed to compress state: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("Failed to compress state: %s", err)
+	}
+
+	return c.Client.Put(buf.Bytes())
+}
+
+func (c *GzipClient) Delete() error {
+	return c.Client.Delete()
+}
+
+// gzipConfig removes the compression setting from the given configuration
+// and returns the GzipClient to wrap the client in.
+func gzipConfig(conf map[string]string) (*GzipClient, error) {
+	result := new(GzipClient)
+
+	v, ok := conf[compressKey]
+	if !ok {
+		return result, nil
+	}
+
+	delete(conf, compressKey)
+
+	var err error
+	if result.Compress, err = strconv.ParseBool(v); err != nil {
+		return nil, fmt.Errorf("%s must be true or false, got %q", compressKey, v)
+	}
+
+	return result, nil
+}
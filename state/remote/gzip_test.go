@@ -0,0 +1,80 @@
+package remote
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestGzipClient_impl(t *testing.T) {
+	var _ Client = new(GzipClient)
+}
+
+func TestGzipClient(t *testing.T) {
+	tf, err := ioutil.TempFile("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	client, err := NewClient("local", map[string]string{
+		"path":     tf.Name(),
+		"compress": "true",
+	})
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+
+	TestClient(t, client)
+}
+
+func TestGzipClient_compress(t *testing.T) {
+	inmem := new(InmemClient)
+	c := &GzipClient{Client: inmem, Compress: true}
+
+	data := bytes.Repeat([]byte("foo"), 100)
+	if err := c.Put(data); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.HasPrefix(inmem.Data, gzipMagic) || len(inmem.Data) >= len(data) {
+		t.Fatalf("should be compressed: %#v", inmem.Data)
+	}
+
+	p, err := c.Get()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.Equal(p.Data, data) {
+		t.Fatalf("bad: %s", p.Data)
+	}
+
+	// Compressed states are read even if compression is off
+	c.Compress = false
+	p, err = c.Get()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.Equal(p.Data, data) {
+		t.Fatalf("bad: %s", p.Data)
+	}
+
+	// ...and written uncompressed
+	if err := c.Put(data); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.Equal(inmem.Data, data) {
+		t.Fatalf("bad: %s", inmem.Data)
+	}
+}
+
+func TestNewClient_compressInvalid(t *testing.T) {
+	_, err := NewClient("local", map[string]string{
+		"path":     "foo",
+		"compress": "maybe",
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+}
@@ -18,11 +18,15 @@ func httpFactory(conf map[string]string) (Client, error) {
 		return nil, fmt.Errorf("missing 'address' configuration")
 	}
 
-	url, err := url.Parse(address)
+	u, err := url.Parse(address)
 	if err != nil {
+		// The error has the address in it, which may have a password
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
 		return nil, fmt.Errorf("failed to parse HTTP URL: %s", err)
 	}
-	if url.Scheme != "http" && url.Scheme != "https" {
+	if u.Scheme != "http" && u.Scheme != "https" {
 		return nil, fmt.Errorf("address must be HTTP or HTTPS")
 	}
 
@@ -44,9 +48,19 @@ func httpFactory(conf map[string]string) (Client, error) {
 		}
 	}
 
+	// Credentials from the CLI configuration are only used if the
+	// address doesn't already have them.
+	var token string
+	if u.User == nil {
+		if token, err = credentialsToken(address); err != nil {
+			return nil, err
+		}
+	}
+
 	return &HTTPClient{
-		URL:    url,
+		URL:    u,
 		Client: client,
+		Token:  token,
 	}, nil
 }
 
@@ -54,10 +68,33 @@ func httpFactory(conf map[string]string) (Client, error) {
 type HTTPClient struct {
 	URL    *url.URL
 	Client *http.Client
+
+	// Token, if set, is sent as a bearer token with every request.
+	Token string
+}
+
+// do sends the request, adding the token if there is one.
+func (c *HTTPClient) do(req *http.Request) (*http.Response, error) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.Client.Do(req)
+	if uerr, ok := err.(*url.Error); ok {
+		// The URL in the error may have a password in it
+		uerr.URL = RedactValue("http", "address", uerr.URL)
+	}
+
+	return resp, err
 }
 
 func (c *HTTPClient) Get() (*Payload, error) {
-	resp, err := c.Client.Get(c.URL.String())
+	req, err := http.NewRequest("GET", c.URL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to make HTTP request: %s", err)
+	}
+
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +180,7 @@ func (c *HTTPClient) Put(data []byte) error {
 	req.ContentLength = int64(len(data))
 
 	// Make the request
-	resp, err := c.Client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("Failed to upload state: %v", err)
 	}
@@ -165,7 +202,7 @@ func (c *HTTPClient) Delete() error {
 	}
 
 	// Make the request
-	resp, err := c.Client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("Failed to delete state: %s", err)
 	}
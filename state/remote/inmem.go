@@ -0,0 +1,32 @@
+package remote
+
+import "sync"
+
+// inmemClients are the states of the inmem client type by name. They're
+// kept for as long as the process runs, so that every client configured
+// with the same name uses the same state.
+var inmemClients = struct {
+	sync.Mutex
+	clients map[string]*InmemClient
+}{clients: make(map[string]*InmemClient)}
+
+// inmemFactory returns the InmemClient with the name in the configuration,
+// "default" if it isn't set. Nothing is written to disk, so the state is
+// lost when Terraform exits.
+func inmemFactory(conf map[string]string) (Client, error) {
+	name := conf["name"]
+	if name == "" {
+		name = "default"
+	}
+
+	inmemClients.Lock()
+	defer inmemClients.Unlock()
+
+	client, ok := inmemClients.clients[name]
+	if !ok {
+		client = new(InmemClient)
+		inmemClients.clients[name] = client
+	}
+
+	return client, nil
+}
@@ -0,0 +1,58 @@
+package remote
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInmemClient_impl(t *testing.T) {
+	var _ Client = new(InmemClient)
+	var _ VersionedClient = new(InmemClient)
+}
+
+func TestInmemClient(t *testing.T) {
+	client, err := inmemFactory(map[string]string{"name": "test-inmem"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	TestClient(t, client)
+}
+
+func TestInmemFactory_shared(t *testing.T) {
+	a, err := NewClient("inmem", map[string]string{"name": "test-shared"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	b, err := NewClient("inmem", map[string]string{"name": "test-shared"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	other, err := NewClient("inmem", map[string]string{"name": "test-other"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	data := []byte(`{"version": 3, "serial": 1}`)
+	if err := a.Put(data); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Clients with the same name share the state...
+	p, err := b.Get()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if p == nil || !bytes.Equal(p.Data, data) {
+		t.Fatalf("bad: %#v", p)
+	}
+
+	// ...and others don't
+	p, err = other.Get()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if p != nil {
+		t.Fatalf("bad: %#v", p)
+	}
+}
@@ -0,0 +1,108 @@
+package remote
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// wrapperKeys are the settings handled by NewClient for every client type.
+// They control how the state is compressed, retried and timed out, and
+// whether changes to it are confirmed and its settings exported, and don't
+// change where it's stored. Neither do the read_ settings.
+var wrapperKeys = []string{
+	compressKey,
+	retryMaxKey,
+	retryWaitMinKey,
+	retryWaitMaxKey,
+	retryTimeoutKey,
+	refreshTimeoutKey,
+	persistTimeoutKey,
+	confirmRequiredKey,
+	confirmNameKey,
+	exportConfigKey,
+}
+
+// nonLocationKeys are the configuration keys of each client type that
+// don't change where the state is stored, such as credentials and TLS
+// settings. Changing them doesn't make the configuration refer to another
+// state. A new client type with such settings should be added here.
+var nonLocationKeys = map[string][]string{
+	"artifactory": []string{"username", "password"},
+	"atlas":       []string{"access_token"},
+	"azure": []string{
+		"access_key", "lease_id", "arm_subscription_id", "arm_client_id",
+		"arm_client_secret", "arm_tenant_id",
+	},
+	"consul": []string{"access_token", "http_auth", "scheme"},
+	"etcd":   []string{"username", "password"},
+	"gcs":    []string{"credentials"},
+	"http":   []string{"skip_cert_verification"},
+	"s3": []string{
+		"access_key", "secret_key", "token", "profile",
+		"shared_credentials_file", "role_arn", "external_id", "session_name",
+	},
+	"swift": []string{
+		"user_name", "user_id", "password", "token", "insecure",
+		"cacert_file", "cert", "key",
+	},
+}
+
+// SameLocation returns true if the two remote state configurations refer
+// to the same state. Unlike RemoteState.Equals, the settings that don't
+// change where the state is stored are ignored, so that changing a
+// credential or a retry setting isn't mistaken for moving the state.
+func SameLocation(a, b *terraform.RemoteState) bool {
+	if a.Empty() || b.Empty() {
+		return a.Empty() && b.Empty()
+	}
+	if a.Type != b.Type {
+		return false
+	}
+
+	aConf := LocationConfig(a.Type, a.Config)
+	bConf := LocationConfig(b.Type, b.Config)
+	if len(aConf) != len(bConf) {
+		return false
+	}
+	for k, v := range aConf {
+		if other, ok := bConf[k]; !ok || other != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LocationConfig returns a copy of the configuration of the given client
+// type with only the settings that determine where the state is stored.
+func LocationConfig(t string, conf map[string]string) map[string]string {
+	result := make(map[string]string, len(conf))
+	for k, v := range conf {
+		if IsLocationKey(t, k) {
+			result[k] = v
+		}
+	}
+
+	return result
+}
+
+// IsLocationKey returns true if the given configuration key of the client
+// type can change where the state is stored.
+func IsLocationKey(t, key string) bool {
+	if strings.HasPrefix(key, readConfigPrefix) {
+		return false
+	}
+	for _, k := range wrapperKeys {
+		if k == key {
+			return false
+		}
+	}
+	for _, k := range nonLocationKeys[t] {
+		if k == key {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,114 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestNonLocationKeys(t *testing.T) {
+	for k := range nonLocationKeys {
+		if _, ok := BuiltinClients[k]; !ok {
+			t.Fatalf("non-location keys for unknown client type: %s", k)
+		}
+	}
+}
+
+func TestSameLocation(t *testing.T) {
+	cases := []struct {
+		A, B   *terraform.RemoteState
+		Result bool
+	}{
+		{
+			nil,
+			nil,
+			true,
+		},
+		{
+			&terraform.RemoteState{
+				Type:   "s3",
+				Config: map[string]string{"bucket": "foo", "key": "bar"},
+			},
+			nil,
+			false,
+		},
+		{
+			&terraform.RemoteState{
+				Type:   "s3",
+				Config: map[string]string{"bucket": "foo", "key": "bar"},
+			},
+			&terraform.RemoteState{
+				Type: "s3",
+				Config: map[string]string{
+					"bucket":     "foo",
+					"key":        "bar",
+					"access_key": "AKIA",
+					"role_arn":   "arn:aws:iam::123456789012:role/ci",
+					"retry_max":  "5",
+					"read_key":   "baz",
+				},
+			},
+			true,
+		},
+		{
+			&terraform.RemoteState{
+				Type:   "s3",
+				Config: map[string]string{"bucket": "foo", "key": "bar"},
+			},
+			&terraform.RemoteState{
+				Type:   "s3",
+				Config: map[string]string{"bucket": "foo", "key": "baz"},
+			},
+			false,
+		},
+		{
+			&terraform.RemoteState{
+				Type:   "s3",
+				Config: map[string]string{"bucket": "foo", "key": "bar"},
+			},
+			&terraform.RemoteState{
+				Type: "s3",
+				Config: map[string]string{
+					"bucket": "foo",
+					"key":    "bar",
+					"region": "eu-west-1",
+				},
+			},
+			false,
+		},
+		{
+			&terraform.RemoteState{
+				Type:   "http",
+				Config: map[string]string{"address": "http://example.com"},
+			},
+			&terraform.RemoteState{
+				Type: "http",
+				Config: map[string]string{
+					"address":                "http://example.com",
+					"skip_cert_verification": "true",
+				},
+			},
+			true,
+		},
+		{
+			&terraform.RemoteState{
+				Type:   "consul",
+				Config: map[string]string{"path": "foo"},
+			},
+			&terraform.RemoteState{
+				Type:   "etcd",
+				Config: map[string]string{"path": "foo"},
+			},
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		if actual := SameLocation(tc.A, tc.B); actual != tc.Result {
+			t.Fatalf("%d: bad: %t", i, actual)
+		}
+		if actual := SameLocation(tc.B, tc.A); actual != tc.Result {
+			t.Fatalf("%d: bad reversed: %t", i, actual)
+		}
+	}
+}
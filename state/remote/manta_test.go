@@ -54,5 +54,5 @@ func TestMantaClient(t *testing.T) {
 		}
 	}()
 
-	testClient(t, client)
+	TestClient(t, client)
 }
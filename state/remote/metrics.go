@@ -0,0 +1,70 @@
+package remote
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/metrics"
+)
+
+// MetricsClient is a Client implementation that records how long reading,
+// writing and deleting the state take, the size of the state read and
+// written, and the number of failed operations, with the metrics package.
+//
+// The metrics are named after the client type and the operation, such as
+// "remote.s3.put" for the duration of writing the state, "remote.s3.put.bytes"
+// for its size and "remote.s3.put.errors" for the failures. Each attempt
+// of a retried operation is recorded separately.
+type MetricsClient struct {
+	Client Client
+	Type   string
+}
+
+func (c *MetricsClient) Get() (*Payload, error) {
+	start := time.Now()
+	payload, err := c.Client.Get()
+	c.record("get", start, err)
+	if err == nil && payload != nil {
+		metrics.SetGauge(c.name("get.bytes"), int64(len(payload.Data)))
+	}
+
+	return payload, err
+}
+
+func (c *MetricsClient) Put(data []byte) error {
+	start := time.Now()
+	err := c.Client.Put(data)
+	c.record("put", start, err)
+	if err == nil {
+		metrics.SetGauge(c.name("put.bytes"), int64(len(data)))
+	}
+
+	return err
+}
+
+func (c *MetricsClient) Delete() error {
+	start := time.Now()
+	err := c.Client.Delete()
+	c.record("delete", start, err)
+	return err
+}
+
+func (c *MetricsClient) record(op string, start time.Time, err error) {
+	metrics.MeasureSince(c.name(op), start)
+	if err != nil {
+		metrics.IncrCounter(c.name(op+".errors"), 1)
+	}
+}
+
+func (c *MetricsClient) name(metric string) string {
+	return fmt.Sprintf("remote.%s.%s", c.Type, metric)
+}
+
+// metricsClient wraps client in a MetricsClient if metrics are recorded.
+func metricsClient(t string, client Client) Client {
+	if !metrics.Enabled() {
+		return client
+	}
+
+	return &MetricsClient{Client: client, Type: t}
+}
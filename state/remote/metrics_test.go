@@ -0,0 +1,85 @@
+package remote
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/metrics"
+)
+
+func TestMetricsClient_impl(t *testing.T) {
+	var _ Client = new(MetricsClient)
+}
+
+func TestMetricsClient(t *testing.T) {
+	sink := new(testMetricsSink)
+	metrics.SetSink(sink)
+	defer metrics.SetSink(nil)
+
+	client, err := NewClient("local", map[string]string{"path": "foo"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	mc, ok := client.(*CrashClient).Client.(*GzipClient).Client.(*MetricsClient)
+	if !ok {
+		t.Fatalf("bad: %#v", client)
+	}
+	mc.Client = new(InmemClient)
+
+	if err := client.Put([]byte("{}")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := client.Get(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := client.Delete(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	mc.Client = &flakyClient{Client: new(InmemClient), Failures: 1}
+	client.Get()
+
+	expected := []string{
+		"remote.local.delete",
+		"remote.local.get",
+		"remote.local.get.bytes",
+		"remote.local.get.errors",
+		"remote.local.put",
+		"remote.local.put.bytes",
+	}
+	if actual := sink.Names(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+// testMetricsSink is a metrics.Sink that records the names of the metrics.
+type testMetricsSink struct {
+	sync.Mutex
+	names map[string]struct{}
+}
+
+func (s *testMetricsSink) IncrCounter(name string, v int64)       { s.add(name) }
+func (s *testMetricsSink) AddTiming(name string, d time.Duration) { s.add(name) }
+func (s *testMetricsSink) SetGauge(name string, v int64)          { s.add(name) }
+
+func (s *testMetricsSink) add(name string) {
+	s.Lock()
+	defer s.Unlock()
+	if s.names == nil {
+		s.names = make(map[string]struct{})
+	}
+	s.names[name] = struct{}{}
+}
+
+func (s *testMetricsSink) Names() []string {
+	s.Lock()
+	defer s.Unlock()
+	result := make([]string, 0, len(s.names))
+	for k := range s.names {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,84 @@
+package remote
+
+import (
+	"strings"
+)
+
+// readConfigPrefix is the prefix of the configuration keys accepted by
+// every remote client type to read the state from a replica or a cache
+// instead of from where it's written, such as read_address for the http
+// client or read_endpoint for the s3 client. Each such key replaces the
+// key without the prefix for reading. They're handled by NewClient and
+// not passed on to the client itself.
+const readConfigPrefix = "read_"
+
+// ReadReplicaClient is a Client implementation that reads the state with
+// one client and writes and deletes it with another, so that reading the
+// state, which is done far more often than writing it, can be served by a
+// read replica or a cache of the remote storage.
+//
+// The replica may lag behind, so commands that write the state read it
+// from where it's written instead. See PrimaryConfig.
+type ReadReplicaClient struct {
+	// Client writes and deletes the state.
+	Client Client
+
+	// Read reads the state.
+	Read Client
+}
+
+func (c *ReadReplicaClient) Get() (*Payload, error) {
+	return c.Read.Get()
+}
+
+func (c *ReadReplicaClient) Put(data []byte) error {
+	return c.Client.Put(data)
+}
+
+func (c *ReadReplicaClient) Delete() error {
+	return c.Client.Delete()
+}
+
+// readReplicaConfig removes the read_ settings from conf, and returns
+// the configuration to read the state with. It returns nil if there are
+// no read_ settings.
+func readReplicaConfig(conf map[string]string) map[string]string {
+	var read map[string]string
+	for k, v := range conf {
+		if !strings.HasPrefix(k, readConfigPrefix) {
+			continue
+		}
+
+		if read == nil {
+			read = make(map[string]string)
+		}
+		read[strings.TrimPrefix(k, readConfigPrefix)] = v
+		delete(conf, k)
+	}
+	if read == nil {
+		return nil
+	}
+
+	for k, v := range conf {
+		if _, ok := read[k]; !ok {
+			read[k] = v
+		}
+	}
+
+	return read
+}
+
+// PrimaryConfig returns a copy of the configuration without the read_
+// settings, so that the state is read from where it's written. This is
+// used when the state is going to be written, since a state read from a
+// replica that lags behind would overwrite newer changes.
+func PrimaryConfig(conf map[string]string) map[string]string {
+	result := make(map[string]string, len(conf))
+	for k, v := range conf {
+		if !strings.HasPrefix(k, readConfigPrefix) {
+			result[k] = v
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,93 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadReplicaClient_impl(t *testing.T) {
+	var _ Client = new(ReadReplicaClient)
+}
+
+func TestReadReplicaClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	primary := filepath.Join(dir, "primary.tfstate")
+	replica := filepath.Join(dir, "replica.tfstate")
+	if err := ioutil.WriteFile(replica, []byte("replica"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	client, err := NewClient("local", map[string]string{
+		"path":      primary,
+		"read_path": replica,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := client.Put([]byte("primary")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if data, err := ioutil.ReadFile(primary); err != nil || string(data) != "primary" {
+		t.Fatalf("bad: %q %v", data, err)
+	}
+
+	payload, err := client.Get()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(payload.Data) != "replica" {
+		t.Fatalf("bad: %q", payload.Data)
+	}
+
+	if err := client.Delete(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := os.Stat(primary); !os.IsNotExist(err) {
+		t.Fatalf("primary should be deleted: %v", err)
+	}
+	if _, err := os.Stat(replica); err != nil {
+		t.Fatalf("replica should be kept: %v", err)
+	}
+}
+
+func TestNewClient_readReplica(t *testing.T) {
+	// Without read_ settings the client isn't wrapped
+	client, err := NewClient("local", map[string]string{"path": "foo"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := client.(*CrashClient).Client.(*GzipClient).Client.(*ReadReplicaClient); ok {
+		t.Fatalf("bad: %#v", client)
+	}
+
+	// The read configuration is the configuration with the read_
+	// settings replacing the settings they're named after
+	conf := map[string]string{"path": "foo", "read_path": "bar", "compress": "true"}
+	read := readReplicaConfig(conf)
+	if expected := map[string]string{"path": "bar", "compress": "true"}; !reflect.DeepEqual(read, expected) {
+		t.Fatalf("bad: %#v", read)
+	}
+	if expected := map[string]string{"path": "foo", "compress": "true"}; !reflect.DeepEqual(conf, expected) {
+		t.Fatalf("bad: %#v", conf)
+	}
+}
+
+func TestPrimaryConfig(t *testing.T) {
+	conf := map[string]string{"address": "foo", "read_address": "bar"}
+	actual := PrimaryConfig(conf)
+	if expected := map[string]string{"address": "foo"}; !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+	if len(conf) != 2 {
+		t.Fatalf("conf should be unchanged: %#v", conf)
+	}
+}
@@ -2,8 +2,15 @@ package remote
 
 import (
 	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform/helper/logging"
 )
 
+// logger writes the log lines of the remote state clients, which can be
+// filtered with TF_LOG_REMOTE.
+var logger = logging.NewLogger("remote")
+
 // Client is the interface that must be implemented for a remote state
 // driver. It supports dumb put/get/delete, and the higher level structs
 // handle persisting the state properly here.
@@ -24,13 +31,96 @@ type Factory func(map[string]string) (Client, error)
 
 // NewClient returns a new Client with the given type and configuration.
 // The client is looked up in the BuiltinClients variable.
+//
+// Every client type also accepts the compress setting, handled by
+// GzipClient, the retry_max, retry_wait_min, retry_wait_max and
+// retry_timeout settings, handled by RetryClient, the refresh_timeout
+// and persist_timeout settings, handled by TimeoutClient, the read_
+// settings, handled by ReadReplicaClient, and the confirm_required and
+// confirm_name settings, returned by ConfirmName. These are removed from
+// the configuration before it is given to the client. Every client is also
+// wrapped in a CrashClient, in a MetricsClient if metrics are recorded,
+// and in a ChaosClient if ChaosEnvVar is set.
 func NewClient(t string, conf map[string]string) (Client, error) {
 	f, ok := BuiltinClients[t]
 	if !ok {
 		return nil, fmt.Errorf("unknown remote client type: %s", t)
 	}
 
-	return f(conf)
+	// Copy the configuration so we can remove our own settings from it
+	raw := conf
+	conf = make(map[string]string, len(raw))
+	for k, v := range raw {
+		conf[k] = v
+	}
+
+	gzip, err := gzipConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := retryConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := timeoutConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := confirmConfig(conf); err != nil {
+		return nil, err
+	}
+	if _, err := exportConfig(conf); err != nil {
+		return nil, err
+	}
+
+	read := readReplicaConfig(conf)
+
+	// Faults are injected closest to the storage, so that they're handled
+	// like real failures
+	chaos, err := chaosConfig(os.Getenv(ChaosEnvVar))
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := f(conf)
+	if err != nil {
+		return nil, redactError(t, conf, err)
+	}
+	client = metricsClient(t, chaos.wrap(client))
+
+	// The state may be read from a replica, configured with the read_
+	// settings
+	if read != nil {
+		readClient, err := f(read)
+		if err != nil {
+			return nil, redactError(t, read, err)
+		}
+		client = &ReadReplicaClient{
+			Client: client,
+			Read:   metricsClient(t, chaos.wrap(readClient)),
+		}
+	}
+
+	// Compressed states are always read transparently, so the client
+	// is wrapped even if compression isn't enabled.
+	gzip.Client = client
+	client = gzip
+
+	// A panic in the client is logged with the context of the operation
+	client = &CrashClient{Client: client, Type: t, Config: conf}
+
+	if retry != nil {
+		retry.Client = client
+		client = retry
+	}
+
+	// The timeouts are for the whole operation, retries included
+	if timeout != nil {
+		timeout.Client = client
+		client = timeout
+	}
+
+	return client, nil
 }
 
 // BuiltinClients is the list of built-in clients that can be used with
@@ -43,6 +133,7 @@ var BuiltinClients = map[string]Factory{
 	"etcd":        etcdFactory,
 	"gcs":         gcsFactory,
 	"http":        httpFactory,
+	"inmem":       inmemFactory,
 	"local":       fileFactory,
 	"s3":          s3Factory,
 	"swift":       swiftFactory,
@@ -10,40 +10,6 @@ import (
 	"github.com/hashicorp/terraform/terraform"
 )
 
-// testClient is a generic function to test any client.
-func testClient(t *testing.T, c Client) {
-	var buf bytes.Buffer
-	s := state.TestStateInitial()
-	if err := terraform.WriteState(s, &buf); err != nil {
-		t.Fatalf("err: %s", err)
-	}
-	data := buf.Bytes()
-
-	if err := c.Put(data); err != nil {
-		t.Fatalf("put: %s", err)
-	}
-
-	p, err := c.Get()
-	if err != nil {
-		t.Fatalf("get: %s", err)
-	}
-	if !bytes.Equal(p.Data, data) {
-		t.Fatalf("bad: %#v", p)
-	}
-
-	if err := c.Delete(); err != nil {
-		t.Fatalf("delete: %s", err)
-	}
-
-	p, err = c.Get()
-	if err != nil {
-		t.Fatalf("get: %s", err)
-	}
-	if p != nil {
-		t.Fatalf("bad: %#v", p)
-	}
-}
-
 func TestRemoteClient_noPayload(t *testing.T) {
 	s := &State{
 		Client: nilClient{},
@@ -0,0 +1,134 @@
+package remote
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// These are the configuration keys accepted by every remote client type
+// to control how failed operations are retried. They're handled by
+// NewClient and not passed on to the client itself.
+const (
+	retryMaxKey     = "retry_max"
+	retryWaitMinKey = "retry_wait_min"
+	retryWaitMaxKey = "retry_wait_max"
+	retryTimeoutKey = "retry_timeout"
+)
+
+// RetryClient is a Client implementation that wraps another Client and
+// retries failed operations with an exponential backoff, so that transient
+// errors talking to the remote storage don't fail the whole operation.
+type RetryClient struct {
+	Client Client
+
+	// Retries is the number of times a failed operation is retried. If
+	// this is zero, operations are attempted only once.
+	Retries int
+
+	// MinWait and MaxWait bound the time waited between attempts. The wait
+	// starts at MinWait and doubles after every attempt, up to MaxWait.
+	MinWait time.Duration
+	MaxWait time.Duration
+
+	// Timeout, if set, is the maximum duration of a single attempt. An
+	// attempt that takes longer is abandoned and counts as failed.
+	Timeout time.Duration
+}
+
+func (c *RetryClient) Get() (*Payload, error) {
+	var result *Payload
+	err := c.retry("get", func() error {
+		var err error
+		result, err = c.Client.Get()
+		return err
+	})
+
+	return result, err
+}
+
+func (c *RetryClient) Put(data []byte) error {
+	return c.retry("put", func() error {
+		return c.Client.Put(data)
+	})
+}
+
+func (c *RetryClient) Delete() error {
+	return c.retry("delete", c.Client.Delete)
+}
+
+func (c *RetryClient) retry(op string, f func() error) error {
+	wait := c.MinWait
+	for i := 0; ; i++ {
+		err := c.attempt(f)
+		if err == nil || i >= c.Retries {
+			return err
+		}
+
+		logger.Warn(
+			"remote state %s failed, retrying in %s (%d/%d): %s",
+			op, wait, i+1, c.Retries, err)
+		time.Sleep(wait)
+
+		wait *= 2
+		if c.MaxWait > 0 && wait > c.MaxWait {
+			wait = c.MaxWait
+		}
+	}
+}
+
+// attempt runs f once, giving up on it after the timeout if one is set.
+func (c *RetryClient) attempt(f func() error) error {
+	err := runWithTimeout(c.Timeout, f)
+	if err == errTimedOut {
+		return fmt.Errorf("timed out after %s", c.Timeout)
+	}
+
+	return err
+}
+
+// retryConfig removes the retry settings from the given configuration and
+// returns the RetryClient to wrap the client in, or nil if neither retries
+// nor a timeout are set.
+func retryConfig(conf map[string]string) (*RetryClient, error) {
+	rc := &RetryClient{
+		MinWait: 1 * time.Second,
+		MaxWait: 30 * time.Second,
+	}
+
+	var err error
+	if v, ok := conf[retryMaxKey]; ok {
+		delete(conf, retryMaxKey)
+		if rc.Retries, err = strconv.Atoi(v); err != nil || rc.Retries < 0 {
+			return nil, fmt.Errorf(
+				"%s must be zero or more, got %q", retryMaxKey, v)
+		}
+	}
+
+	durations := []struct {
+		Key   string
+		Value *time.Duration
+	}{
+		{retryWaitMinKey, &rc.MinWait},
+		{retryWaitMaxKey, &rc.MaxWait},
+		{retryTimeoutKey, &rc.Timeout},
+	}
+	for _, d := range durations {
+		v, ok := conf[d.Key]
+		if !ok {
+			continue
+		}
+
+		delete(conf, d.Key)
+		if *d.Value, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf(
+				"%s must be a duration such as \"10s\", got %q", d.Key, v)
+		}
+	}
+
+	if rc.Retries == 0 && rc.Timeout == 0 {
+		return nil, nil
+	}
+
+	return rc, nil
+}
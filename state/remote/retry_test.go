@@ -0,0 +1,156 @@
+package remote
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRetryClient_impl(t *testing.T) {
+	var _ Client = new(RetryClient)
+}
+
+func TestRetryClient(t *testing.T) {
+	tf, err := ioutil.TempFile("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	client, err := NewClient("local", map[string]string{
+		"path":      tf.Name(),
+		"retry_max": "1",
+	})
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if _, ok := client.(*RetryClient); !ok {
+		t.Fatalf("bad: %#v", client)
+	}
+
+	TestClient(t, client)
+}
+
+func TestRetryClient_retry(t *testing.T) {
+	flaky := &flakyClient{Client: new(InmemClient), Failures: 2}
+	c := &RetryClient{Client: flaky, Retries: 2}
+
+	if err := c.Put([]byte("foo")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if flaky.Calls != 3 {
+		t.Fatalf("bad: %d", flaky.Calls)
+	}
+
+	// Fails once out of retries
+	flaky.Calls = 0
+	flaky.Failures = 3
+	if err := c.Put([]byte("foo")); err == nil {
+		t.Fatal("should error")
+	}
+	if flaky.Calls != 3 {
+		t.Fatalf("bad: %d", flaky.Calls)
+	}
+}
+
+func TestRetryClient_timeout(t *testing.T) {
+	c := &RetryClient{
+		Client:  &flakyClient{Client: new(InmemClient), Delay: time.Second},
+		Timeout: 10 * time.Millisecond,
+	}
+
+	if _, err := c.Get(); err == nil {
+		t.Fatal("should time out")
+	}
+}
+
+func TestNewClient_retry(t *testing.T) {
+	conf := map[string]string{
+		"path":           "foo",
+		"retry_max":      "3",
+		"retry_wait_min": "2s",
+		"retry_timeout":  "1m",
+	}
+	client, err := NewClient("local", conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	rc, ok := client.(*RetryClient)
+	if !ok {
+		t.Fatalf("bad: %#v", client)
+	}
+	if rc.Retries != 3 || rc.MinWait != 2*time.Second || rc.Timeout != time.Minute {
+		t.Fatalf("bad: %#v", rc)
+	}
+	cc, ok := rc.Client.(*CrashClient)
+	if !ok {
+		t.Fatalf("bad: %#v", rc.Client)
+	}
+	if _, ok := cc.Client.(*GzipClient); !ok {
+		t.Fatalf("bad: %#v", cc.Client)
+	}
+	if _, ok := conf["retry_max"]; !ok {
+		t.Fatal("conf should not be modified")
+	}
+
+	// Without retry settings the client isn't wrapped
+	client, err = NewClient("local", map[string]string{"path": "foo"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := client.(*RetryClient); ok {
+		t.Fatalf("bad: %#v", client)
+	}
+
+	// Invalid settings are an error
+	if _, err := NewClient("local", map[string]string{"path": "foo", "retry_max": "x"}); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+// flakyClient fails the given number of calls before passing them on to
+// the wrapped client.
+type flakyClient struct {
+	Client   Client
+	Failures int
+	Delay    time.Duration
+	Calls    int
+}
+
+func (c *flakyClient) Get() (*Payload, error) {
+	if err := c.call(); err != nil {
+		return nil, err
+	}
+
+	return c.Client.Get()
+}
+
+func (c *flakyClient) Put(data []byte) error {
+	if err := c.call(); err != nil {
+		return err
+	}
+
+	return c.Client.Put(data)
+}
+
+func (c *flakyClient) Delete() error {
+	if err := c.call(); err != nil {
+		return err
+	}
+
+	return c.Client.Delete()
+}
+
+func (c *flakyClient) call() error {
+	c.Calls++
+	time.Sleep(c.Delay)
+	if c.Calls <= c.Failures {
+		return errors.New("flaky")
+	}
+
+	return nil
+}
@@ -7,11 +7,15 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/go-multierror"
 	terraformAws "github.com/hashicorp/terraform/builtin/providers/aws"
@@ -81,6 +85,13 @@ providing credentials for the AWS S3 remote`))
 		return nil, &multierror.Error{Errors: errs}
 	}
 
+	if conf["role_arn"] != "" {
+		creds, err = s3AssumeRole(creds, regionName, conf)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	awsConfig := &aws.Config{
 		Credentials: creds,
 		Endpoint:    aws.String(endpoint),
@@ -100,6 +111,51 @@ providing credentials for the AWS S3 remote`))
 	}, nil
 }
 
+// s3AssumeRoleExpiryWindow is how long before the credentials of the role
+// set with role_arn expire that the role is assumed again, so that the
+// requests of long operations aren't made with credentials that expire
+// before they complete.
+const s3AssumeRoleExpiryWindow = time.Minute
+
+// s3AssumeRole assumes the role set with role_arn with the given
+// credentials, and returns the credentials of the role.
+func s3AssumeRole(
+	creds *credentials.Credentials,
+	region string,
+	conf map[string]string) (*credentials.Credentials, error) {
+	roleARN := conf["role_arn"]
+	log.Printf("[INFO] Assuming role %s for AWS S3 remote (SessionName: %q, ExternalId: %q)",
+		roleARN, conf["session_name"], conf["external_id"])
+
+	stsclient := sts.New(session.New(&aws.Config{
+		Credentials: creds,
+		Region:      aws.String(region),
+		HTTPClient:  cleanhttp.DefaultClient(),
+	}))
+	provider := &stscreds.AssumeRoleProvider{
+		Client:       stsclient,
+		RoleARN:      roleARN,
+		ExpiryWindow: s3AssumeRoleExpiryWindow,
+	}
+	if v := conf["session_name"]; v != "" {
+		provider.RoleSessionName = v
+	}
+	if v := conf["external_id"]; v != "" {
+		provider.ExternalID = aws.String(v)
+	}
+
+	assumed := credentials.NewCredentials(provider)
+	if _, err := assumed.Get(); err != nil {
+		return nil, fmt.Errorf(
+			"The role %q cannot be assumed for AWS S3 remote: %s\n\n"+
+				"The credentials used to assume the role may be invalid or not\n"+
+				"have permission to assume it, or the role ARN may not be valid.",
+			roleARN, err)
+	}
+
+	return assumed, nil
+}
+
 type S3Client struct {
 	nativeClient         *s3.S3
 	bucketName           string
@@ -171,7 +227,7 @@ func (c *S3Client) Put(data []byte) error {
 		i.ACL = aws.String(c.acl)
 	}
 
-	log.Printf("[DEBUG] Uploading remote state to S3: %#v", i)
+	logger.Debug("Uploading remote state to S3: %#v", i)
 
 	if _, err := c.nativeClient.PutObject(i); err == nil {
 		return nil
@@ -188,3 +244,55 @@ func (c *S3Client) Delete() error {
 
 	return err
 }
+
+func (c *S3Client) ListVersions() ([]*Version, error) {
+	var result []*Version
+	err := c.nativeClient.ListObjectVersionsPages(&s3.ListObjectVersionsInput{
+		Bucket: &c.bucketName,
+		Prefix: &c.keyName,
+	}, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		for _, v := range page.Versions {
+			// The prefix also matches longer keys
+			if aws.StringValue(v.Key) != c.keyName {
+				continue
+			}
+
+			result = append(result, &Version{
+				ID:           aws.StringValue(v.VersionId),
+				LastModified: aws.TimeValue(v.LastModified),
+				Size:         aws.Int64Value(v.Size),
+				Latest:       aws.BoolValue(v.IsLatest),
+			})
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list state versions: %s", err)
+	}
+
+	return result, nil
+}
+
+func (c *S3Client) GetVersion(id string) (*Payload, error) {
+	output, err := c.nativeClient.GetObject(&s3.GetObjectInput{
+		Bucket:    &c.bucketName,
+		Key:       &c.keyName,
+		VersionId: aws.String(id),
+	})
+	if err != nil {
+		if awserr, ok := err.(awserr.Error); ok && awserr.Code() == "NoSuchVersion" {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buf, output.Body); err != nil {
+		return nil, fmt.Errorf("Failed to read state version: %s", err)
+	}
+
+	return &Payload{Data: buf.Bytes()}, nil
+}
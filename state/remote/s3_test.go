@@ -11,6 +11,7 @@ import (
 
 func TestS3Client_impl(t *testing.T) {
 	var _ Client = new(S3Client)
+	var _ VersionedClient = new(S3Client)
 }
 
 func TestS3Factory(t *testing.T) {
@@ -65,6 +66,29 @@ func TestS3Factory(t *testing.T) {
 	}
 }
 
+func TestS3Factory_assumeRoleError(t *testing.T) {
+	// This test calls AWS STS, so it will only run if TF_ACC is set. The
+	// role can't be assumed with these credentials, wherever the test is
+	// run, so the error is reported instead of the client returned.
+	if os.Getenv("TF_ACC") == "" {
+		t.Skip("skipping; TF_ACC must be set")
+	}
+
+	config := map[string]string{
+		"region":       "us-west-1",
+		"bucket":       "foo",
+		"key":          "bar",
+		"access_key":   "bazkey",
+		"secret_key":   "bazsecret",
+		"role_arn":     "arn:aws:iam::123456789012:role/terraform",
+		"session_name": "terraform-test",
+	}
+
+	if _, err := s3Factory(config); err == nil {
+		t.Fatal("should error")
+	}
+}
+
 func TestS3Client(t *testing.T) {
 	// This test creates a bucket in S3 and populates it.
 	// It may incur costs, so it will only run if AWS credential environment
@@ -127,5 +151,5 @@ func TestS3Client(t *testing.T) {
 		}
 	}()
 
-	testClient(t, client)
+	TestClient(t, client)
 }
@@ -0,0 +1,98 @@
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// SelfTestSuffix is appended to the location of the state to get the
+// location of the object that SelfTest writes and deletes.
+const SelfTestSuffix = ".selftest"
+
+// selfTestKeys are the settings that hold the location of the state, for
+// the clients that SelfTest can point next to the state. The location of
+// the other clients can't be changed without also changing where the
+// state is, so writing isn't tested with them.
+var selfTestKeys = map[string]string{
+	"azure":  "key",
+	"consul": "path",
+	"etcd":   "path",
+	"gcs":    "path",
+	"inmem":  "name",
+	"local":  "path",
+	"s3":     "key",
+}
+
+// SelfTestStep is the outcome of a step of SelfTest.
+type SelfTestStep struct {
+	// Name is the name of the step: "read", "write" or "delete".
+	Name string
+
+	// Err is the error of the step, if it failed.
+	Err error
+
+	// Skipped is the reason the step was skipped, if it was.
+	Skipped string
+}
+
+// SelfTest checks that the remote storage with the given type and
+// configuration can be used, before any real operation uses it. The state
+// is read, and an object is written next to the state, read back and
+// deleted. The state itself is never written.
+func SelfTest(t string, conf map[string]string) []*SelfTestStep {
+	read := &SelfTestStep{Name: "read"}
+	write := &SelfTestStep{Name: "write"}
+	del := &SelfTestStep{Name: "delete"}
+	steps := []*SelfTestStep{read, write, del}
+
+	client, err := NewClient(t, conf)
+	if err != nil {
+		read.Err = err
+		write.Skipped = "the remote state couldn't be configured"
+		del.Skipped = write.Skipped
+		return steps
+	}
+	if _, err := client.Get(); err != nil {
+		read.Err = err
+	}
+
+	key, ok := selfTestKeys[t]
+	if !ok || conf[key] == "" {
+		write.Skipped = fmt.Sprintf(
+			"the %q remote state can't write anywhere but the state itself", t)
+		del.Skipped = write.Skipped
+		return steps
+	}
+
+	// The object is read back from where it's written, not from a replica
+	testConf := PrimaryConfig(conf)
+	testConf[key] = conf[key] + SelfTestSuffix
+	testClient, err := NewClient(t, testConf)
+	if err != nil {
+		write.Err = err
+		del.Skipped = "writing failed"
+		return steps
+	}
+
+	data := []byte(fmt.Sprintf(
+		"{\"terraform_selftest\": %q}\n", time.Now().UTC().Format(time.RFC3339)))
+	if err := testClient.Put(data); err != nil {
+		write.Err = err
+		del.Skipped = "writing failed"
+		return steps
+	}
+	payload, err := testClient.Get()
+	switch {
+	case err != nil:
+		write.Err = fmt.Errorf("reading back the object written: %s", err)
+	case payload == nil || !bytes.Equal(payload.Data, data):
+		write.Err = fmt.Errorf("the object written couldn't be read back unchanged")
+	}
+
+	if err := testClient.Delete(); err != nil {
+		del.Err = err
+	}
+
+	return steps
+}
@@ -0,0 +1,66 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "terraform.tfstate")
+	if err := ioutil.WriteFile(path, []byte("state"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	steps := SelfTest("local", map[string]string{"path": path})
+	for _, s := range steps {
+		if s.Err != nil || s.Skipped != "" {
+			t.Fatalf("%s: bad: %#v", s.Name, s)
+		}
+	}
+
+	// The state is left alone, and the test object is deleted
+	if data, err := ioutil.ReadFile(path); err != nil || string(data) != "state" {
+		t.Fatalf("bad: %q %v", data, err)
+	}
+	if _, err := os.Stat(path + SelfTestSuffix); !os.IsNotExist(err) {
+		t.Fatalf("test object should be deleted: %v", err)
+	}
+}
+
+func TestSelfTest_writeFails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "missing", "terraform.tfstate")
+	steps := SelfTest("local", map[string]string{"path": path})
+	if steps[0].Err != nil {
+		t.Fatalf("read: %s", steps[0].Err)
+	}
+	if steps[1].Err == nil {
+		t.Fatal("write should fail")
+	}
+	if steps[2].Skipped == "" {
+		t.Fatalf("delete should be skipped: %#v", steps[2])
+	}
+}
+
+func TestSelfTest_writeSkipped(t *testing.T) {
+	steps := SelfTest("http", map[string]string{"address": "http://127.0.0.1:1"})
+	if steps[0].Err == nil {
+		t.Fatal("read should fail")
+	}
+	if steps[1].Skipped == "" || steps[2].Skipped == "" {
+		t.Fatalf("bad: %#v %#v", steps[1], steps[2])
+	}
+}
@@ -0,0 +1,102 @@
+package remote
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// SensitiveValue is shown in place of a secret in the configuration.
+const SensitiveValue = "<sensitive>"
+
+// sensitiveKeys are the configuration keys of each client type that hold
+// secrets, such as passwords and access tokens. These must never be shown
+// in output or logs, and are removed from the errors of the client
+// factories by NewClient. A new client type with secrets in its
+// configuration must be added here.
+var sensitiveKeys = map[string][]string{
+	"artifactory": []string{"password"},
+	"atlas":       []string{"access_token"},
+	"azure":       []string{"access_key", "arm_client_secret"},
+	"consul":      []string{"access_token", "http_auth"},
+	"etcd":        []string{"password"},
+	"gcs":         []string{"credentials"},
+	"s3":          []string{"access_key", "secret_key", "token"},
+	"swift":       []string{"password", "token"},
+}
+
+// IsSensitive returns true if the given configuration key of the client
+// type holds a secret. The read_ settings hold a secret if the setting
+// they replace does.
+func IsSensitive(t, key string) bool {
+	key = strings.TrimPrefix(key, readConfigPrefix)
+	for _, k := range sensitiveKeys[t] {
+		if k == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactConfig returns a copy of the configuration of the given client type
+// with the secrets replaced by SensitiveValue. The password of a URL, such
+// as the address of the http client, is redacted as well.
+func RedactConfig(t string, conf map[string]string) map[string]string {
+	result := make(map[string]string, len(conf))
+	for k, v := range conf {
+		result[k] = RedactValue(t, k, v)
+	}
+
+	return result
+}
+
+// RedactValue returns the value of the given configuration key, or
+// SensitiveValue if the key holds a secret.
+func RedactValue(t, key, value string) string {
+	if IsSensitive(t, key) {
+		return SensitiveValue
+	}
+
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			// Built by hand, since URL.String would escape SensitiveValue
+			user := url.User(u.User.Username()).String()
+			u.User = nil
+			return strings.Replace(
+				u.String(), "//", "//"+user+":"+SensitiveValue+"@", 1)
+		}
+	}
+
+	return value
+}
+
+// redactError returns the error with the secrets of the given configuration
+// removed from its message. This keeps a client factory that includes a
+// configuration value in an error from revealing a secret.
+func redactError(t string, conf map[string]string, err error) error {
+	msg := err.Error()
+	redacted := msg
+	for k, v := range conf {
+		if v == "" {
+			continue
+		}
+
+		if IsSensitive(t, k) {
+			redacted = strings.Replace(redacted, v, SensitiveValue, -1)
+			continue
+		}
+
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			if p, ok := u.User.Password(); ok && p != "" {
+				redacted = strings.Replace(redacted, p, SensitiveValue, -1)
+			}
+		}
+	}
+
+	if redacted == msg {
+		return err
+	}
+
+	return errors.New(redacted)
+}
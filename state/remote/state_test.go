@@ -19,6 +19,10 @@ func TestState(t *testing.T) {
 	state.TestState(t, s)
 }
 
+func TestClientState_inmem(t *testing.T) {
+	TestClientState(t, new(InmemClient))
+}
+
 func TestState_impl(t *testing.T) {
 	var _ state.StateReader = new(State)
 	var _ state.StateWriter = new(State)
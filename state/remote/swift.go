@@ -7,7 +7,6 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"os"
 	"strconv"
@@ -145,31 +144,31 @@ func (c *SwiftClient) validateConfig(conf map[string]string) (err error) {
 	c.path = path
 
 	if archivepath, ok := conf["archive_path"]; ok {
-		log.Printf("[DEBUG] Archivepath set, enabling object versioning")
+		logger.Debug("Archivepath set, enabling object versioning")
 		c.archive = true
 		c.archivepath = archivepath
 	}
 
 	if expire, ok := conf["expire_after"]; ok {
-		log.Printf("[DEBUG] Requested that remote state expires after %s", expire)
+		logger.Debug("Requested that remote state expires after %s", expire)
 
 		if strings.HasSuffix(expire, "d") {
-			log.Printf("[DEBUG] Got a days expire after duration. Converting to hours")
+			logger.Debug("Got a days expire after duration. Converting to hours")
 			days, err := strconv.Atoi(expire[:len(expire)-1])
 			if err != nil {
 				return fmt.Errorf("Error converting expire_after value %s to int: %s", expire, err)
 			}
 
 			expire = fmt.Sprintf("%dh", days*24)
-			log.Printf("[DEBUG] Expire after %s hours", expire)
+			logger.Debug("Expire after %s hours", expire)
 		}
 
 		expireDur, err := time.ParseDuration(expire)
 		if err != nil {
-			log.Printf("[DEBUG] Error parsing duration %s: %s", expire, err)
+			logger.Debug("Error parsing duration %s: %s", expire, err)
 			return fmt.Errorf("Error parsing expire_after duration '%s': %s", expire, err)
 		}
-		log.Printf("[DEBUG] Seconds duration = %d", int(expireDur.Seconds()))
+		logger.Debug("Seconds duration = %d", int(expireDur.Seconds()))
 		c.expireSecs = int(expireDur.Seconds())
 	}
 
@@ -235,7 +234,7 @@ func (c *SwiftClient) validateConfig(conf map[string]string) (err error) {
 	}
 
 	if c.insecure {
-		log.Printf("[DEBUG] Insecure mode set")
+		logger.Debug("Insecure mode set")
 		config.InsecureSkipVerify = true
 	}
 
@@ -272,7 +271,7 @@ func (c *SwiftClient) Get() (*Payload, error) {
 
 	// 404 response is to be expected if the object doesn't already exist!
 	if _, ok := err.(gophercloud.ErrDefault404); ok {
-		log.Printf("[DEBUG] Container doesn't exist to download.")
+		logger.Debug("Container doesn't exist to download.")
 		return nil, nil
 	}
 
@@ -295,14 +294,14 @@ func (c *SwiftClient) Put(data []byte) error {
 		return err
 	}
 
-	log.Printf("[DEBUG] Creating object %s at path %s", TFSTATE_NAME, c.path)
+	logger.Debug("Creating object %s at path %s", TFSTATE_NAME, c.path)
 	reader := bytes.NewReader(data)
 	createOpts := objects.CreateOpts{
 		Content: reader,
 	}
 
 	if c.expireSecs != 0 {
-		log.Printf("[DEBUG] ExpireSecs = %d", c.expireSecs)
+		logger.Debug("ExpireSecs = %d", c.expireSecs)
 		createOpts.DeleteAfter = c.expireSecs
 	}
 
@@ -320,18 +319,18 @@ func (c *SwiftClient) ensureContainerExists() error {
 	containerOpts := &containers.CreateOpts{}
 
 	if c.archive {
-		log.Printf("[DEBUG] Creating container %s", c.archivepath)
+		logger.Debug("Creating container %s", c.archivepath)
 		result := containers.Create(c.client, c.archivepath, nil)
 		if result.Err != nil {
-			log.Printf("[DEBUG] Error creating container %s: %s", c.archivepath, result.Err)
+			logger.Debug("Error creating container %s: %s", c.archivepath, result.Err)
 			return result.Err
 		}
 
-		log.Printf("[DEBUG] Enabling Versioning on container %s", c.path)
+		logger.Debug("Enabling Versioning on container %s", c.path)
 		containerOpts.VersionsLocation = c.archivepath
 	}
 
-	log.Printf("[DEBUG] Creating container %s", c.path)
+	logger.Debug("Creating container %s", c.path)
 	result := containers.Create(c.client, c.path, containerOpts)
 	if result.Err != nil {
 		return result.Err
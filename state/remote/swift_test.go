@@ -27,5 +27,5 @@ func TestSwiftClient(t *testing.T) {
 		t.Fatalf("bad: %s", err)
 	}
 
-	testClient(t, client)
+	TestClient(t, client)
 }
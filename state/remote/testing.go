@@ -0,0 +1,185 @@
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform/state"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// TestClient is a helper for testing Client implementations, including
+// ones that aren't built into Terraform. A state is written, read back and
+// deleted, and reading it after it's deleted must return nil. The client
+// should have no state when it's called, and has none afterwards.
+func TestClient(t *testing.T, c Client) {
+	var buf bytes.Buffer
+	s := state.TestStateInitial()
+	if err := terraform.WriteState(s, &buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	data := buf.Bytes()
+
+	if err := c.Put(data); err != nil {
+		t.Fatalf("put: %s", err)
+	}
+
+	p, err := c.Get()
+	if err != nil {
+		t.Fatalf("get: %s", err)
+	}
+	if !bytes.Equal(p.Data, data) {
+		t.Fatalf("bad: %#v", p)
+	}
+
+	if err := c.Delete(); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+
+	p, err = c.Get()
+	if err != nil {
+		t.Fatalf("get: %s", err)
+	}
+	if p != nil {
+		t.Fatalf("bad: %#v", p)
+	}
+}
+
+// TestVersionedClient is a helper for testing VersionedClient
+// implementations. Two states are written, and they must be listed as the
+// two newest versions, newest first, and be read back by their IDs. The
+// states written are left in place.
+func TestVersionedClient(t *testing.T, c VersionedClient) {
+	first := []byte(`{"version": 3, "serial": 1}`)
+	second := []byte(`{"version": 3, "serial": 2}`)
+	for _, data := range [][]byte{first, second} {
+		if err := c.Put(data); err != nil {
+			t.Fatalf("put: %s", err)
+		}
+	}
+
+	versions, err := c.ListVersions()
+	if err != nil {
+		t.Fatalf("list versions: %s", err)
+	}
+	if len(versions) < 2 {
+		t.Fatalf("bad: %#v", versions)
+	}
+	if !versions[0].Latest || versions[1].Latest {
+		t.Fatalf("only the newest version should be the latest: %#v", versions)
+	}
+	if versions[0].LastModified.Before(versions[1].LastModified) {
+		t.Fatalf("versions should be newest first: %#v", versions)
+	}
+
+	for i, expected := range [][]byte{second, first} {
+		p, err := c.GetVersion(versions[i].ID)
+		if err != nil {
+			t.Fatalf("get version %s: %s", versions[i].ID, err)
+		}
+		if p == nil || !bytes.Equal(p.Data, expected) {
+			t.Fatalf("bad version %s: %#v", versions[i].ID, p)
+		}
+	}
+}
+
+// TestClientState is a helper for testing that a Client implementation
+// can store the state the way Terraform does, through a State. The client
+// should have no state when it's called.
+func TestClientState(t *testing.T, c Client) {
+	s := &State{Client: c}
+	if err := s.WriteState(state.TestStateInitial()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := s.PersistState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state.TestState(t, &State{Client: c})
+}
+
+// ErrSpyFailure is the error of the operations SpyClient makes fail, if
+// its Err isn't set.
+var ErrSpyFailure = errors.New("remote state failure injected for testing")
+
+// SpyClient is a Client implementation for tests that records the
+// operations made on the client it wraps, and can make some of them fail.
+// To use it through the commands, register a factory returning it in
+// BuiltinClients under a type of the test's own.
+type SpyClient struct {
+	Client Client
+
+	// FailGet, FailPut and FailDelete make the call of the operation with
+	// that number, counting from 1, fail without calling Client. Zero
+	// never fails. FailAfter makes every call fail once that many calls of
+	// any operation were made, if it's not zero.
+	FailGet    int
+	FailPut    int
+	FailDelete int
+	FailAfter  int
+
+	// Err is the error of the failed operations, ErrSpyFailure if nil.
+	Err error
+
+	mu     sync.Mutex
+	calls  []string
+	counts map[string]int
+}
+
+// Calls returns the operations made so far, in order, as "get", "put" and
+// "delete", followed by " (failed)" if the operation was made to fail.
+func (c *SpyClient) Calls() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.calls...)
+}
+
+func (c *SpyClient) Get() (*Payload, error) {
+	if err := c.record("get", c.FailGet); err != nil {
+		return nil, err
+	}
+
+	return c.Client.Get()
+}
+
+func (c *SpyClient) Put(data []byte) error {
+	if err := c.record("put", c.FailPut); err != nil {
+		return err
+	}
+
+	return c.Client.Put(data)
+}
+
+func (c *SpyClient) Delete() error {
+	if err := c.record("delete", c.FailDelete); err != nil {
+		return err
+	}
+
+	return c.Client.Delete()
+}
+
+// record records a call of op, and returns the error to fail it with if
+// it's the call number fail or FailAfter is reached.
+func (c *SpyClient) record(op string, fail int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[op]++
+
+	if (fail != 0 && c.counts[op] == fail) || (c.FailAfter != 0 && len(c.calls) >= c.FailAfter) {
+		c.calls = append(c.calls, op+" (failed)")
+		if c.Err != nil {
+			return c.Err
+		}
+		return ErrSpyFailure
+	}
+
+	c.calls = append(c.calls, op)
+	return nil
+}
@@ -0,0 +1,47 @@
+package remote
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpyClient_impl(t *testing.T) {
+	var _ Client = new(SpyClient)
+}
+
+func TestSpyClient(t *testing.T) {
+	client := &SpyClient{Client: new(InmemClient)}
+	TestClient(t, client)
+
+	expected := []string{"put", "get", "delete", "get"}
+	if actual := client.Calls(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestSpyClient_fail(t *testing.T) {
+	client := &SpyClient{Client: new(InmemClient), FailPut: 2}
+	if err := client.Put([]byte("one")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := client.Put([]byte("two")); err != ErrSpyFailure {
+		t.Fatalf("bad: %v", err)
+	}
+	p, err := client.Get()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(p.Data) != "one" {
+		t.Fatalf("bad: %s", p.Data)
+	}
+
+	client.FailAfter = 3
+	if _, err := client.Get(); err != ErrSpyFailure {
+		t.Fatalf("bad: %v", err)
+	}
+
+	expected := []string{"put", "put (failed)", "get", "get (failed)"}
+	if actual := client.Calls(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
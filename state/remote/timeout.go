@@ -0,0 +1,136 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// These are the configuration keys accepted by every remote client type
+// to limit how long reading and writing the state may take in total,
+// retries included. They're handled by NewClient and not passed on to the
+// client itself.
+const (
+	refreshTimeoutKey = "refresh_timeout"
+	persistTimeoutKey = "persist_timeout"
+)
+
+// errTimedOut is returned by runWithTimeout if f doesn't finish in time.
+var errTimedOut = errors.New("timed out")
+
+// TimeoutClient is a Client implementation that wraps another Client and
+// gives up on operations that take too long, so that remote storage that
+// can't be reached fails the command instead of blocking it indefinitely.
+//
+// The operation given up on isn't canceled, since the Client interface has
+// no way to do so, but its result is ignored.
+type TimeoutClient struct {
+	Client Client
+
+	// RefreshTimeout, if set, is the maximum duration of Get.
+	RefreshTimeout time.Duration
+
+	// PersistTimeout, if set, is the maximum duration of Put and Delete.
+	PersistTimeout time.Duration
+}
+
+func (c *TimeoutClient) Get() (*Payload, error) {
+	var result *Payload
+	err := runWithTimeout(c.RefreshTimeout, func() error {
+		var err error
+		result, err = c.Client.Get()
+		return err
+	})
+	if err == errTimedOut {
+		return nil, fmt.Errorf(
+			"Timed out after %s reading the state from the remote storage.\n\n"+
+				"Check that the remote storage can be reached. If reading the state\n"+
+				"takes longer than that, raise the %s setting.",
+			c.RefreshTimeout, refreshTimeoutKey)
+	}
+
+	return result, err
+}
+
+func (c *TimeoutClient) Put(data []byte) error {
+	err := runWithTimeout(c.PersistTimeout, func() error {
+		return c.Client.Put(data)
+	})
+	if err == errTimedOut {
+		return fmt.Errorf(
+			"Timed out after %s writing the state to the remote storage.\n\n"+
+				"The state is kept in the local copy of the remote state, and can be\n"+
+				"written with \"terraform remote push\" once the remote storage can be\n"+
+				"reached. If writing the state takes longer than that, raise the %s\n"+
+				"setting.",
+			c.PersistTimeout, persistTimeoutKey)
+	}
+
+	return err
+}
+
+func (c *TimeoutClient) Delete() error {
+	err := runWithTimeout(c.PersistTimeout, c.Client.Delete)
+	if err == errTimedOut {
+		return fmt.Errorf(
+			"Timed out after %s deleting the state from the remote storage.",
+			c.PersistTimeout)
+	}
+
+	return err
+}
+
+// runWithTimeout runs f, giving up on it with errTimedOut after the
+// timeout if one is set.
+func runWithTimeout(timeout time.Duration, f func() error) error {
+	if timeout <= 0 {
+		return f()
+	}
+
+	// Buffered so that an abandoned call can still finish
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(timeout):
+		return errTimedOut
+	}
+}
+
+// timeoutConfig removes the timeout settings from the given configuration
+// and returns the TimeoutClient to wrap the client in, or nil if no
+// timeout is set.
+func timeoutConfig(conf map[string]string) (*TimeoutClient, error) {
+	tc := new(TimeoutClient)
+
+	durations := []struct {
+		Key   string
+		Value *time.Duration
+	}{
+		{refreshTimeoutKey, &tc.RefreshTimeout},
+		{persistTimeoutKey, &tc.PersistTimeout},
+	}
+	for _, d := range durations {
+		v, ok := conf[d.Key]
+		if !ok {
+			continue
+		}
+
+		delete(conf, d.Key)
+		var err error
+		if *d.Value, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf(
+				"%s must be a duration such as \"2m\", got %q", d.Key, v)
+		}
+	}
+
+	if tc.RefreshTimeout == 0 && tc.PersistTimeout == 0 {
+		return nil, nil
+	}
+
+	return tc, nil
+}
@@ -0,0 +1,104 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutClient_impl(t *testing.T) {
+	var _ Client = new(TimeoutClient)
+}
+
+func TestTimeoutClient(t *testing.T) {
+	tf, err := ioutil.TempFile("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	client, err := NewClient("local", map[string]string{
+		"path":            tf.Name(),
+		"refresh_timeout": "1m",
+		"persist_timeout": "1m",
+	})
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if _, ok := client.(*TimeoutClient); !ok {
+		t.Fatalf("bad: %#v", client)
+	}
+
+	TestClient(t, client)
+}
+
+func TestTimeoutClient_timeout(t *testing.T) {
+	c := &TimeoutClient{
+		Client:         &flakyClient{Client: new(InmemClient), Delay: time.Second},
+		RefreshTimeout: 10 * time.Millisecond,
+	}
+
+	_, err := c.Get()
+	if err == nil {
+		t.Fatal("should time out")
+	}
+	if !strings.Contains(err.Error(), refreshTimeoutKey) {
+		t.Fatalf("bad: %s", err)
+	}
+
+	// Put has no timeout
+	c.Client = &flakyClient{Client: new(InmemClient), Delay: 20 * time.Millisecond}
+	if err := c.Put([]byte("foo")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	c.PersistTimeout = 10 * time.Millisecond
+	err = c.Put([]byte("foo"))
+	if err == nil {
+		t.Fatal("should time out")
+	}
+	if !strings.Contains(err.Error(), persistTimeoutKey) {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestNewClient_timeout(t *testing.T) {
+	conf := map[string]string{
+		"path":            "foo",
+		"retry_max":       "3",
+		"refresh_timeout": "1m",
+		"persist_timeout": "2m",
+	}
+	client, err := NewClient("local", conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	tc, ok := client.(*TimeoutClient)
+	if !ok {
+		t.Fatalf("bad: %#v", client)
+	}
+	if tc.RefreshTimeout != time.Minute || tc.PersistTimeout != 2*time.Minute {
+		t.Fatalf("bad: %#v", tc)
+	}
+	if _, ok := tc.Client.(*RetryClient); !ok {
+		t.Fatalf("bad: %#v", tc.Client)
+	}
+
+	// Without timeout settings the client isn't wrapped
+	client, err = NewClient("local", map[string]string{"path": "foo"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := client.(*TimeoutClient); ok {
+		t.Fatalf("bad: %#v", client)
+	}
+
+	// Invalid settings are an error
+	if _, err := NewClient("local", map[string]string{"path": "foo", "persist_timeout": "x"}); err == nil {
+		t.Fatal("should error")
+	}
+}
@@ -0,0 +1,79 @@
+package remote
+
+import (
+	"time"
+)
+
+// VersionedClient is implemented by clients whose storage keeps the
+// earlier versions of the state, such as S3 buckets with versioning
+// enabled and GCS buckets with object versioning.
+//
+// There is no way to restore a version through the client: a restored
+// state has to be written as a new version with a higher serial, or the
+// local caches of the state would consider themselves newer and write the
+// restored version over again.
+type VersionedClient interface {
+	Client
+
+	// ListVersions returns the versions of the state, newest first.
+	ListVersions() ([]*Version, error)
+
+	// GetVersion returns the state with the given version ID, or nil
+	// if there is no such version.
+	GetVersion(id string) (*Payload, error)
+}
+
+// Version is a version of the state kept by a VersionedClient.
+type Version struct {
+	ID           string
+	LastModified time.Time
+	Size         int64
+
+	// Latest is true for the current version of the state.
+	Latest bool
+}
+
+// Versioned returns the VersionedClient that c wraps, if the storage of
+// the client keeps versions of the state. The GzipClient, CrashClient,
+// RetryClient, TimeoutClient, ReadReplicaClient, MetricsClient and
+// ChaosClient wrappers added by NewClient are looked through, and versions
+// are decompressed when they're read. The versions of a ReadReplicaClient
+// are those of the client the state is written with.
+func Versioned(c Client) (VersionedClient, bool) {
+	for {
+		switch w := c.(type) {
+		case *GzipClient:
+			c = w.Client
+		case *CrashClient:
+			c = w.Client
+		case *RetryClient:
+			c = w.Client
+		case *TimeoutClient:
+			c = w.Client
+		case *ReadReplicaClient:
+			c = w.Client
+		case *MetricsClient:
+			c = w.Client
+		case *ChaosClient:
+			c = w.Client
+		case VersionedClient:
+			return &gzipVersionedClient{VersionedClient: w}, true
+		default:
+			return nil, false
+		}
+	}
+}
+
+// gzipVersionedClient decompresses the versions of a VersionedClient.
+type gzipVersionedClient struct {
+	VersionedClient
+}
+
+func (c *gzipVersionedClient) GetVersion(id string) (*Payload, error) {
+	payload, err := c.VersionedClient.GetVersion(id)
+	if err != nil || payload == nil {
+		return payload, err
+	}
+
+	return gunzipPayload(payload)
+}
@@ -0,0 +1,62 @@
+package remote
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVersioned(t *testing.T) {
+	inmem := new(InmemClient)
+	c, err := NewClient("local", map[string]string{"path": "foo"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := Versioned(c); ok {
+		t.Fatal("local client shouldn't be versioned")
+	}
+
+	// Versions are found through the wrappers and decompressed
+	wrapped := &RetryClient{Client: &GzipClient{Client: inmem, Compress: true}}
+	if err := wrapped.Put([]byte("one")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := wrapped.Put([]byte("two")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	vc, ok := Versioned(wrapped)
+	if !ok {
+		t.Fatal("client should be versioned")
+	}
+
+	versions, err := vc.ListVersions()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("bad: %#v", versions)
+	}
+	if versions[0].ID != "2" || !versions[0].Latest || versions[1].Latest {
+		t.Fatalf("bad: %#v", versions)
+	}
+
+	p, err := vc.GetVersion(versions[1].ID)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if p == nil || !bytes.Equal(p.Data, []byte("one")) {
+		t.Fatalf("bad: %#v", p)
+	}
+
+	p, err = vc.GetVersion("nope")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if p != nil {
+		t.Fatalf("bad: %#v", p)
+	}
+}
+
+func TestInmemClient_versioned(t *testing.T) {
+	TestVersionedClient(t, new(InmemClient))
+}
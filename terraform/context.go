@@ -48,6 +48,11 @@ var (
 // ContextOpts are the user-configurable options to create a context with
 // NewContext.
 type ContextOpts struct {
+	// BackendValues are the settings the remote state configuration
+	// exports, for "${backend.NAME}" interpolations. It's nil if none
+	// are exported.
+	BackendValues map[string]string
+
 	Destroy            bool
 	Diff               *Diff
 	Hooks              []Hook
@@ -74,19 +79,20 @@ type Context struct {
 	// that newShadowContext still does the right thing. Tests should
 	// fail regardless but putting this note here as well.
 
-	components contextComponentFactory
-	destroy    bool
-	diff       *Diff
-	diffLock   sync.RWMutex
-	hooks      []Hook
-	module     *module.Tree
-	sh         *stopHook
-	shadow     bool
-	state      *State
-	stateLock  sync.RWMutex
-	targets    []string
-	uiInput    UIInput
-	variables  map[string]interface{}
+	backendValues map[string]string
+	components    contextComponentFactory
+	destroy       bool
+	diff          *Diff
+	diffLock      sync.RWMutex
+	hooks         []Hook
+	module        *module.Tree
+	sh            *stopHook
+	shadow        bool
+	state         *State
+	stateLock     sync.RWMutex
+	targets       []string
+	uiInput       UIInput
+	variables     map[string]interface{}
 
 	l                   sync.Mutex // Lock acquired during any task
 	parallelSem         Semaphore
@@ -168,6 +174,7 @@ func NewContext(opts *ContextOpts) (*Context, error) {
 	}
 
 	return &Context{
+		backendValues: opts.BackendValues,
 		components: &basicComponentFactory{
 			providers:    opts.Providers,
 			provisioners: opts.Provisioners,
@@ -289,6 +296,7 @@ func (c *Context) Interpolater() *Interpolater {
 	var varLock sync.Mutex
 	var stateLock sync.RWMutex
 	return &Interpolater{
+		BackendValues:      c.backendValues,
 		Operation:          walkApply,
 		Module:             c.module,
 		State:              c.state.DeepCopy(),
@@ -475,6 +483,7 @@ func (c *Context) Plan() (*Plan, error) {
 		Vars:    c.variables,
 		State:   c.state,
 		Targets: c.targets,
+		Destroy: c.destroy,
 	}
 
 	var operation walkOperation
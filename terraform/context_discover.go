@@ -0,0 +1,84 @@
+package terraform
+
+import (
+	"sort"
+
+	"github.com/hashicorp/terraform/config/module"
+)
+
+// DiscoverOpts are used as the configuration for Discover.
+type DiscoverOpts struct {
+	// Types are the resource types to list. Each must be Listable.
+	Types []string
+
+	// Module is optional, and specifies a config module that is loaded
+	// into the graph and evaluated, to provide provider configuration.
+	Module *module.Tree
+}
+
+// DiscoveredResource is a resource that a provider can see.
+type DiscoveredResource struct {
+	Type string
+	ID   string
+}
+
+// Discover asks the providers for the resources of the given types that
+// they can see, whether or not they are managed by Terraform. The state
+// isn't changed.
+//
+// The resources are returned sorted by type and ID.
+func (c *Context) Discover(opts *DiscoverOpts) ([]*DiscoveredResource, error) {
+	v := c.acquireRun("discover")
+	defer c.releaseRun(v)
+
+	// If no module is given, default to the module configured with
+	// the Context.
+	module := opts.Module
+	if module == nil {
+		module = c.module
+	}
+
+	builder := &DiscoverGraphBuilder{
+		Types:     opts.Types,
+		Module:    module,
+		Providers: c.components.ResourceProviders(),
+	}
+
+	graph, err := builder.Build(RootModulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The providers are configured as for an import, which is what the
+	// discovered resources are for.
+	if _, err := c.walk(graph, nil, walkImport); err != nil {
+		return nil, err
+	}
+
+	var result []*DiscoveredResource
+	for _, v := range graph.Vertices() {
+		n, ok := v.(*graphNodeDiscover)
+		if !ok {
+			continue
+		}
+
+		for _, s := range n.states {
+			result = append(result, &DiscoveredResource{Type: n.Type, ID: s.ID})
+		}
+	}
+
+	sort.Sort(discoveredResourcesByID(result))
+	return result, nil
+}
+
+type discoveredResourcesByID []*DiscoveredResource
+
+func (s discoveredResourcesByID) Len() int      { return len(s) }
+func (s discoveredResourcesByID) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s discoveredResourcesByID) Less(i, j int) bool {
+	if s[i].Type != s[j].Type {
+		return s[i].Type < s[j].Type
+	}
+
+	return s[i].ID < s[j].ID
+}
@@ -0,0 +1,104 @@
+package terraform
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestContextDiscover_basic(t *testing.T) {
+	p := testProvider("aws")
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	p.ListResourcesFn = func(info *InstanceInfo) ([]*InstanceState, error) {
+		switch info.Type {
+		case "aws_instance":
+			return []*InstanceState{
+				&InstanceState{ID: "i-2"},
+				&InstanceState{ID: "i-1"},
+			}, nil
+		case "aws_eip":
+			return []*InstanceState{&InstanceState{ID: "eip-1"}}, nil
+		}
+
+		return nil, fmt.Errorf("unexpected type: %s", info.Type)
+	}
+
+	actual, err := ctx.Discover(&DiscoverOpts{
+		Types: []string{"aws_instance", "aws_eip"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []*DiscoveredResource{
+		&DiscoveredResource{Type: "aws_eip", ID: "eip-1"},
+		&DiscoveredResource{Type: "aws_instance", ID: "i-1"},
+		&DiscoveredResource{Type: "aws_instance", ID: "i-2"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestContextDiscover_providerVarConfig(t *testing.T) {
+	p := testProvider("aws")
+	ctx := testContext2(t, &ContextOpts{
+		Module: testModule(t, "import-provider-vars"),
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		Variables: map[string]interface{}{
+			"foo": "bar",
+		},
+	})
+
+	configured := false
+	p.ConfigureFn = func(c *ResourceConfig) error {
+		configured = true
+
+		if v, ok := c.Get("foo"); !ok || v.(string) != "bar" {
+			return fmt.Errorf("bad value: %#v", v)
+		}
+
+		return nil
+	}
+
+	p.ListResourcesReturn = []*InstanceState{&InstanceState{ID: "i-1"}}
+
+	actual, err := ctx.Discover(&DiscoverOpts{
+		Types: []string{"aws_instance"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !configured {
+		t.Fatal("didn't configure provider")
+	}
+	if len(actual) != 1 || actual[0].ID != "i-1" {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestContextDiscover_error(t *testing.T) {
+	p := testProvider("aws")
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	p.ListResourcesReturnError = fmt.Errorf("access denied")
+
+	_, err := ctx.Discover(&DiscoverOpts{
+		Types: []string{"aws_instance"},
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+}
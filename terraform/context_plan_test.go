@@ -1791,6 +1791,9 @@ func TestContext2Plan_destroy(t *testing.T) {
 	if len(plan.Diff.RootModule().Resources) != 2 {
 		t.Fatalf("bad: %#v", plan.Diff.RootModule().Resources)
 	}
+	if !plan.Destroy {
+		t.Fatal("plan should be a destroy plan")
+	}
 
 	actual := strings.TrimSpace(plan.String())
 	expected := strings.TrimSpace(testTerraformPlanDestroyStr)
@@ -2667,6 +2670,36 @@ func TestContext2Plan_provider(t *testing.T) {
 	}
 }
 
+func TestContext2Plan_providerBackend(t *testing.T) {
+	m := testModule(t, "plan-provider-backend")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+
+	var value interface{}
+	p.ConfigureFn = func(c *ResourceConfig) error {
+		value, _ = c.Get("foo")
+		return nil
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		BackendValues: map[string]string{
+			"region": "us-west-2",
+		},
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if value != "us-west-2" {
+		t.Fatalf("bad: %#v", value)
+	}
+}
+
 func TestContext2Plan_varListErr(t *testing.T) {
 	m := testModule(t, "plan-var-list-err")
 	p := testProvider("aws")
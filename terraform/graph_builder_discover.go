@@ -0,0 +1,71 @@
+package terraform
+
+import (
+	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/dag"
+)
+
+// DiscoverGraphBuilder implements GraphBuilder and is responsible for
+// building a graph for listing the resources that providers can see. Like
+// the import graph, it only needs the providers to be configured.
+type DiscoverGraphBuilder struct {
+	// Types are the resource types to list.
+	Types []string
+
+	// Module is the module to add to the graph. See DiscoverOpts.Module.
+	Module *module.Tree
+
+	// Providers is the list of providers supported.
+	Providers []string
+}
+
+// Build builds the graph according to the steps returned by Steps.
+func (b *DiscoverGraphBuilder) Build(path []string) (*Graph, error) {
+	return (&BasicGraphBuilder{
+		Steps:    b.Steps(),
+		Validate: true,
+		Name:     "DiscoverGraphBuilder",
+	}).Build(path)
+}
+
+// Steps returns the ordered list of GraphTransformers that must be executed
+// to build a complete graph.
+func (b *DiscoverGraphBuilder) Steps() []GraphTransformer {
+	mod := b.Module
+	if mod == nil {
+		mod = module.NewEmptyTree()
+	}
+
+	// Custom factory for creating providers.
+	concreteProvider := func(a *NodeAbstractProvider) dag.Vertex {
+		return &NodeApplyableProvider{
+			NodeAbstractProvider: a,
+		}
+	}
+
+	steps := []GraphTransformer{
+		// Create all our resources from the configuration and state
+		&ConfigTransformerOld{Module: mod},
+
+		// Add the nodes listing the resources
+		&DiscoverTransformer{Types: b.Types},
+
+		// Provider-related transformations
+		&MissingProviderTransformer{Providers: b.Providers, Concrete: concreteProvider},
+		&ProviderTransformer{},
+		&DisableProviderTransformerOld{},
+		&PruneProviderTransformer{},
+		&AttachProviderConfigTransformer{Module: mod},
+
+		// This validates that the providers only depend on variables
+		&ImportProviderValidateTransformer{},
+
+		// Single root
+		&RootTransformer{},
+
+		// Optimize
+		&TransitiveReductionTransformer{},
+	}
+
+	return steps
+}
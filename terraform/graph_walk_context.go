@@ -80,6 +80,7 @@ func (w *ContextGraphWalker) EnterPath(path []string) EvalContext {
 		StateValue:          w.Context.state,
 		StateLock:           &w.Context.stateLock,
 		Interpolater: &Interpolater{
+			BackendValues:      w.Context.backendValues,
 			Operation:          w.Operation,
 			Module:             w.Context.module,
 			State:              w.Context.state,
@@ -24,6 +24,7 @@ const (
 // Interpolater is the structure responsible for determining the values
 // for interpolations such as `aws_instance.foo.bar`.
 type Interpolater struct {
+	BackendValues      map[string]string
 	Operation          walkOperation
 	Module             *module.Tree
 	State              *State
@@ -75,6 +76,8 @@ func (i *Interpolater) Values(
 	for n, rawV := range vars {
 		var err error
 		switch v := rawV.(type) {
+		case *config.BackendVariable:
+			err = i.valueBackendVar(scope, n, v, result)
 		case *config.CountVariable:
 			err = i.valueCountVar(scope, n, v, result)
 		case *config.ModuleVariable:
@@ -101,6 +104,34 @@ func (i *Interpolater) Values(
 	return result, nil
 }
 
+func (i *Interpolater) valueBackendVar(
+	scope *InterpolationScope,
+	n string,
+	v *config.BackendVariable,
+	result map[string]ast.Variable) error {
+	value, ok := i.BackendValues[v.Field]
+	if ok {
+		result[n] = ast.Variable{
+			Value: value,
+			Type:  ast.TypeString,
+		}
+		return nil
+	}
+
+	// The configuration can be validated without a remote state
+	if i.Operation == walkValidate {
+		result[n] = unknownVariable()
+		return nil
+	}
+
+	if i.BackendValues == nil {
+		return fmt.Errorf(
+			"%s: the remote state configuration doesn't export its settings. "+
+				"Set export_config to true in it to use backend variables.", n)
+	}
+	return fmt.Errorf("%s: the remote state configuration has no setting %q", n, v.Field)
+}
+
 func (i *Interpolater) valueCountVar(
 	scope *InterpolationScope,
 	n string,
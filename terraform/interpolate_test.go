@@ -100,6 +100,27 @@ func TestInterpolater_moduleVariable(t *testing.T) {
 	})
 }
 
+func TestInterpolater_backend(t *testing.T) {
+	i := &Interpolater{
+		BackendValues: map[string]string{"region": "us-west-2"},
+		Operation:     walkPlan,
+	}
+	scope := &InterpolationScope{}
+
+	testInterpolate(t, i, scope, "backend.region", ast.Variable{
+		Value: "us-west-2",
+		Type:  ast.TypeString,
+	})
+	testInterpolateErr(t, i, scope, "backend.profile")
+
+	// Without exported settings, it's only unknown when validating
+	i = &Interpolater{Operation: walkPlan}
+	testInterpolateErr(t, i, scope, "backend.region")
+
+	i.Operation = walkValidate
+	testInterpolate(t, i, scope, "backend.region", unknownVariable())
+}
+
 func TestInterpolater_pathCwd(t *testing.T) {
 	i := &Interpolater{}
 	scope := &InterpolationScope{}
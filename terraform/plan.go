@@ -27,14 +27,19 @@ type Plan struct {
 	Vars    map[string]interface{}
 	Targets []string
 
+	// Destroy is true if this is a plan to destroy the resources, created
+	// with the Destroy option. Applying it destroys them.
+	Destroy bool
+
 	once sync.Once
 }
 
 // Context returns a Context with the data encapsulated in this plan.
 //
 // The following fields in opts are overridden by the plan: Config,
-// Diff, State, Variables.
+// Destroy, Diff, State, Variables.
 func (p *Plan) Context(opts *ContextOpts) (*Context, error) {
+	opts.Destroy = p.Destroy
 	opts.Diff = p.Diff
 	opts.Module = p.Module
 	opts.State = p.State
@@ -53,6 +53,7 @@ func TestReadWritePlan(t *testing.T) {
 		Vars: map[string]interface{}{
 			"foo": "bar",
 		},
+		Destroy: true,
 	}
 
 	buf := new(bytes.Buffer)
@@ -70,4 +71,22 @@ func TestReadWritePlan(t *testing.T) {
 	if actualStr != expectedStr {
 		t.Fatalf("bad:\n\n%s\n\nexpected:\n\n%s", actualStr, expectedStr)
 	}
+	if !actual.Destroy {
+		t.Fatal("plan should be a destroy plan")
+	}
+}
+
+func TestPlanContext_destroy(t *testing.T) {
+	plan := &Plan{
+		Module:  testModule(t, "new-good"),
+		Destroy: true,
+	}
+
+	ctx, err := plan.Context(&ContextOpts{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !ctx.destroy {
+		t.Fatal("context should destroy")
+	}
 }
@@ -234,7 +234,8 @@ func (addr *ResourceAddress) Equals(raw interface{}) bool {
 }
 
 func ParseResourceIndex(s string) (int, error) {
-	if s == "" {
+	// "*" addresses all the instances, the same as no index
+	if s == "" || s == "*" {
 		return -1, nil
 	}
 	return strconv.Atoi(s)
@@ -282,8 +283,8 @@ func tokenizeResourceAddress(s string) (map[string]string, error) {
 		`(?:(?P<type>[^.]+)\.(?P<name>[^.[]+))?` +
 		// "tainted" (optional, omission implies: "primary")
 		`(?:\.(?P<instance_type>\w+))?` +
-		// "1" (optional, omission implies: "0")
-		`(?:\[(?P<index>\d+)\])?` +
+		// "1" (optional, omission implies: "0"), or "*" for all instances
+		`(?:\[(?P<index>\d+|\*)\])?` +
 		`\z`)
 	groupNames := re.SubexpNames()
 	rawMatches := re.FindAllStringSubmatch(s, -1)
@@ -150,6 +150,17 @@ func TestParseResourceAddress(t *testing.T) {
 			},
 			"",
 		},
+		"implicit primary, wildcard index": {
+			"aws_instance.foo[*]",
+			&ResourceAddress{
+				Mode:         config.ManagedResourceMode,
+				Type:         "aws_instance",
+				Name:         "foo",
+				InstanceType: TypePrimary,
+				Index:        -1,
+			},
+			"aws_instance.foo",
+		},
 		"explicit primary, explicit index": {
 			"aws_instance.foo.primary[2]",
 			&ResourceAddress{
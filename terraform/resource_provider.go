@@ -124,6 +124,14 @@ type ResourceProvider interface {
 	// therefore multiple states are returned.
 	ImportState(*InstanceInfo, string) ([]*InstanceState, error)
 
+	// ListResources requests the IDs of all the resources of the given type
+	// that the provider can see, so that the ones that aren't managed by
+	// Terraform can be found and imported.
+	//
+	// The returned InstanceStates only require ID be set. This is only
+	// called for resource types that are Listable.
+	ListResources(*InstanceInfo) ([]*InstanceState, error)
+
 	/*********************************************************************
 	* Functions related to data resources
 	*********************************************************************/
@@ -164,6 +172,7 @@ type ResourceProviderCloser interface {
 type ResourceType struct {
 	Name       string // Name of the resource, example "instance" (no provider prefix)
 	Importable bool   // Whether this resource supports importing
+	Listable   bool   // Whether the resources of this type can be listed
 }
 
 // DataSource is a data source that a resource provider implements.
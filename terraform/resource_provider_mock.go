@@ -85,6 +85,12 @@ type MockResourceProvider struct {
 	ImportStateReturn      []*InstanceState
 	ImportStateReturnError error
 	ImportStateFn          func(*InstanceInfo, string) ([]*InstanceState, error)
+
+	ListResourcesCalled      bool
+	ListResourcesInfo        *InstanceInfo
+	ListResourcesReturn      []*InstanceState
+	ListResourcesReturnError error
+	ListResourcesFn          func(*InstanceInfo) ([]*InstanceState, error)
 }
 
 func (p *MockResourceProvider) Close() error {
@@ -240,6 +246,27 @@ func (p *MockResourceProvider) ImportState(info *InstanceInfo, id string) ([]*In
 	return result, p.ImportStateReturnError
 }
 
+func (p *MockResourceProvider) ListResources(info *InstanceInfo) ([]*InstanceState, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.ListResourcesCalled = true
+	p.ListResourcesInfo = info
+	if p.ListResourcesFn != nil {
+		return p.ListResourcesFn(info)
+	}
+
+	var result []*InstanceState
+	if p.ListResourcesReturn != nil {
+		result = make([]*InstanceState, len(p.ListResourcesReturn))
+		for i, v := range p.ListResourcesReturn {
+			result[i] = v.DeepCopy()
+		}
+	}
+
+	return result, p.ListResourcesReturnError
+}
+
 func (p *MockResourceProvider) ValidateDataSource(t string, c *ResourceConfig) ([]string, []error) {
 	p.Lock()
 	defer p.Unlock()
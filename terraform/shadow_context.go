@@ -42,14 +42,15 @@ func newShadowContext(c *Context) (*Context, *Context, Shadow) {
 
 	// Create the shadow
 	shadow := &Context{
-		components: componentsShadow,
-		destroy:    c.destroy,
-		diff:       c.diff.DeepCopy(),
-		hooks:      nil,
-		module:     c.module,
-		state:      c.state.DeepCopy(),
-		targets:    targetRaw.([]string),
-		variables:  varRaw.(map[string]interface{}),
+		backendValues: c.backendValues,
+		components:    componentsShadow,
+		destroy:       c.destroy,
+		diff:          c.diff.DeepCopy(),
+		hooks:         nil,
+		module:        c.module,
+		state:         c.state.DeepCopy(),
+		targets:       targetRaw.([]string),
+		variables:     varRaw.(map[string]interface{}),
 
 		// NOTE(mitchellh): This is not going to work for shadows that are
 		// testing that input results in the proper end state. At the time
@@ -73,8 +74,9 @@ func newShadowContext(c *Context) (*Context, *Context, Shadow) {
 		components: componentsReal,
 
 		// The fields below are direct copies
-		destroy: c.destroy,
-		diff:    c.diff,
+		backendValues: c.backendValues,
+		destroy:       c.destroy,
+		diff:          c.diff,
 		// diffLock - no copy
 		hooks:  c.hooks,
 		module: c.module,
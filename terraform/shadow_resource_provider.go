@@ -738,6 +738,10 @@ func (p *shadowResourceProviderShadow) ImportState(info *InstanceInfo, id string
 	panic("import not supported by shadow graph")
 }
 
+func (p *shadowResourceProviderShadow) ListResources(info *InstanceInfo) ([]*InstanceState, error) {
+	panic("listing resources not supported by shadow graph")
+}
+
 // The structs for the various function calls are put below. These structs
 // are used to carry call information across the real/shadow boundaries.
 
@@ -0,0 +1,5 @@
+provider "aws" {
+    foo = "${backend.region}"
+}
+
+resource "aws_instance" "foo" {}
@@ -0,0 +1,88 @@
+package terraform
+
+import (
+	"fmt"
+)
+
+// DiscoverTransformer is a GraphTransformer that adds nodes to the graph
+// that list the resources of the given types.
+type DiscoverTransformer struct {
+	Types []string
+}
+
+func (t *DiscoverTransformer) Transform(g *Graph) error {
+	for _, typ := range t.Types {
+		g.Add(&graphNodeDiscover{Type: typ})
+	}
+
+	return nil
+}
+
+type graphNodeDiscover struct {
+	Type string // Type is the resource type to list
+
+	states []*InstanceState
+}
+
+func (n *graphNodeDiscover) Name() string {
+	return fmt.Sprintf("%s (discover)", n.Type)
+}
+
+func (n *graphNodeDiscover) ProvidedBy() []string {
+	return []string{resourceProvider(n.Type, "")}
+}
+
+// GraphNodeSubPath
+func (n *graphNodeDiscover) Path() []string {
+	return rootModulePath
+}
+
+// GraphNodeEvalable impl.
+func (n *graphNodeDiscover) EvalTree() EvalNode {
+	var provider ResourceProvider
+	info := &InstanceInfo{
+		Id:         n.Type,
+		ModulePath: n.Path(),
+		Type:       n.Type,
+	}
+
+	// Reset our states
+	n.states = nil
+
+	return &EvalSequence{
+		Nodes: []EvalNode{
+			&EvalGetProvider{
+				Name:   n.ProvidedBy()[0],
+				Output: &provider,
+			},
+			&EvalListResources{
+				Provider: &provider,
+				Info:     info,
+				Output:   &n.states,
+			},
+		},
+	}
+}
+
+// EvalListResources is an EvalNode implementation that lists the resources
+// of a type that a provider can see. It doesn't modify any state.
+type EvalListResources struct {
+	Provider *ResourceProvider
+	Info     *InstanceInfo
+	Output   *[]*InstanceState
+}
+
+func (n *EvalListResources) Eval(ctx EvalContext) (interface{}, error) {
+	provider := *n.Provider
+
+	states, err := provider.ListResources(n.Info)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %s", n.Info.Type, err)
+	}
+
+	if n.Output != nil {
+		*n.Output = states
+	}
+
+	return nil, nil
+}